@@ -2,47 +2,196 @@ package main
 
 import (
 	"bombs/internal/handlers"
+	"bombs/internal/logging"
+	"bombs/internal/metrics"
 	"bombs/internal/service"
-	"log"
+	"bombs/internal/storage"
+	"bombs/internal/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
 	"mime"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+const (
+	// shutdownTimeout bounds how long the server waits for players to be disconnected cleanly
+	shutdownTimeout = 10 * time.Second
+	// defaultRateLimitPerMinute is how many sessions an IP can create or join per minute
+	defaultRateLimitPerMinute = 10
+	// defaultRateLimitBurst is how many of those requests an IP can send at once
+	defaultRateLimitBurst = 5
+	// defaultSQLitePath is the SQLite database file used when SQLITE_PATH is unset
+	defaultSQLitePath = "bombs.db"
+	// gameRecordQueueSize is how many finished games can wait to be saved
+	gameRecordQueueSize = 256
+)
+
 func main() {
+	// Structured logging, configured with LOG_LEVEL (debug, info, warn, error) and LOG_FORMAT (text, json)
+	logger, err := logging.Setup(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+	if err != nil {
+		slog.Error("invalid logging configuration", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize game service
 	gameService := service.NewGameService()
+	if quarantine := os.Getenv("SESSION_CODE_QUARANTINE"); quarantine != "" {
+		d, err := time.ParseDuration(quarantine)
+		if err != nil {
+			logger.Error("invalid SESSION_CODE_QUARANTINE", "value", quarantine, "error", err)
+			os.Exit(1)
+		}
+		gameService.SetCodeQuarantine(d)
+	}
+	// 4-digit codes by default, "alphanumeric" for 6-character codes on public instances
+	if format := os.Getenv("SESSION_ID_FORMAT"); format != "" {
+		f, err := utils.ParseSessionIDFormat(format)
+		if err != nil {
+			logger.Error("invalid SESSION_ID_FORMAT", "value", format, "error", err)
+			os.Exit(1)
+		}
+		gameService.SetSessionIDFormat(f)
+	}
+	if ttl := os.Getenv("SESSION_EMPTY_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			logger.Error("invalid SESSION_EMPTY_TTL", "value", ttl, "error", err)
+			os.Exit(1)
+		}
+		gameService.SetEmptySessionTTL(d)
+	}
+	// How far around a button release the timer may have shown the target digit, to absorb network jitter
+	if window := os.Getenv("BUTTON_RELEASE_WINDOW"); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d < 0 {
+			logger.Error("invalid BUTTON_RELEASE_WINDOW", "value", window, "error", err)
+			os.Exit(1)
+		}
+		gameService.SetReleaseWindow(d)
+	}
+
+	// Webhook called when a game ends, hosts can override it per session
+	if webhookURL := os.Getenv("WEBHOOK_URL"); webhookURL != "" {
+		gameService.SetDefaultWebhookURL(webhookURL)
+	}
+
+	// Secret host tokens are signed with. Without it tokens change on every restart
+	if secret := os.Getenv("HOST_TOKEN_SECRET"); secret != "" {
+		gameService.SetHostTokenSecret([]byte(secret))
+	}
+	// Keep accepting the bare hostId as host credentials until clients send host tokens
+	if legacy := os.Getenv("LEGACY_HOST_ID"); legacy != "" {
+		allow, err := strconv.ParseBool(legacy)
+		if err != nil {
+			logger.Error("invalid LEGACY_HOST_ID", "value", legacy, "error", err)
+			os.Exit(1)
+		}
+		gameService.SetAllowLegacyHostID(allow)
+	}
+
+	// Persist finished games when a database driver is linked in (see storage/driver_*.go)
+	recorder := openRecorder(logger)
+	if recorder != nil {
+		gameService.SetRecorder(recorder)
+	}
+
+	// Origins allowed to call the API and open WebSockets, comma-separated (default: any origin)
+	allowlist := handlers.ParseOriginAllowlist(os.Getenv("CORS_ORIGIN"))
+
+	// Behind a reverse proxy, set TRUST_PROXY_HEADERS=true so clients are told apart by X-Forwarded-For
+	// and invite links use the X-Forwarded-Proto/Host the client sees
+	trustProxy, _ := strconv.ParseBool(os.Getenv("TRUST_PROXY_HEADERS"))
+
+	// External address invite links point to (default: derived from each request)
+	publicBaseURL, err := handlers.ParsePublicBaseURL(os.Getenv("PUBLIC_BASE_URL"))
+	if err != nil {
+		logger.Error("invalid PUBLIC_BASE_URL", "error", err)
+		os.Exit(1)
+	}
+	invites := handlers.NewInviteLinks(publicBaseURL, trustProxy)
 
 	// Initialize handlers
-	gameHandler := handlers.NewGameHandler(gameService)
-	wsHandler := handlers.NewWebSocketHandler(gameService)
+	gameHandler := handlers.NewGameHandler(gameService, invites)
+	wsHandler := handlers.NewWebSocketHandler(gameService, allowlist)
+	// Send the full game state every second, for clients that don't apply state deltas yet
+	if legacy := os.Getenv("LEGACY_FULL_STATE"); legacy != "" {
+		fullState, err := strconv.ParseBool(legacy)
+		if err != nil {
+			logger.Error("invalid LEGACY_FULL_STATE", "value", legacy, "error", err)
+			os.Exit(1)
+		}
+		wsHandler.SetLegacyFullState(fullState)
+	}
+	// How long disconnected players keep their seat, 0 removes them as soon as their socket closes
+	if grace := os.Getenv("RECONNECT_GRACE"); grace != "" {
+		d, err := time.ParseDuration(grace)
+		if err != nil {
+			logger.Error("invalid RECONNECT_GRACE", "value", grace, "error", err)
+			os.Exit(1)
+		}
+		wsHandler.SetReconnectGrace(d)
+	}
+
+	// Per-IP rate limit on creating and joining sessions, RATE_LIMIT_PER_MINUTE=0 disables it
+	perMinute := envInt(logger, "RATE_LIMIT_PER_MINUTE", defaultRateLimitPerMinute)
+	burst := envInt(logger, "RATE_LIMIT_BURST", defaultRateLimitBurst)
+	limit := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	if perMinute > 0 {
+		limit = handlers.NewRateLimiter(float64(perMinute), burst, trustProxy).Limit
+	}
 
 	// Setup router
 	r := mux.NewRouter()
 
-	// CORS middleware
-	corsOrigin := os.Getenv("CORS_ORIGIN")
-	if corsOrigin == "" {
-		corsOrigin = "*" // Default to allow all origins in development
-	}
-	r.Use(corsMiddleware(corsOrigin))
-
 	// REST API routes
 	api := r.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/game", gameHandler.CreateGame).Methods("POST")
-	api.HandleFunc("/game/join", gameHandler.JoinGame).Methods("POST")
+	api.HandleFunc("/game", limit(gameHandler.CreateGame)).Methods("POST")
+	api.HandleFunc("/game/join", limit(gameHandler.JoinGame)).Methods("POST")
 	api.HandleFunc("/game/{sessionId}", gameHandler.GetGameState).Methods("GET")
+	api.HandleFunc("/game/{sessionId}", gameHandler.DeleteGame).Methods("DELETE")
 	api.HandleFunc("/game/{sessionId}/lobby", gameHandler.GetLobbyState).Methods("GET")
+	api.HandleFunc("/game/{sessionId}/results", gameHandler.GetResults).Methods("GET")
+	api.HandleFunc("/game/{sessionId}/history", gameHandler.GetHistory).Methods("GET")
+	api.HandleFunc("/game/{sessionId}/replay", gameHandler.GetReplay).Methods("GET")
+	api.HandleFunc("/game/{sessionId}/manual", gameHandler.GetManual).Methods("GET")
+	api.HandleFunc("/game/{sessionId}/manual.pdf", gameHandler.GetManualPDF).Methods("GET")
 	api.HandleFunc("/game/{sessionId}/lobby/settings", gameHandler.UpdateLobbySettings).Methods("POST")
 	api.HandleFunc("/game/{sessionId}/start", gameHandler.StartGame).Methods("POST")
 	api.HandleFunc("/game/{sessionId}/return-to-lobby", gameHandler.ReturnToLobby).Methods("POST")
+	api.HandleFunc("/game/{sessionId}/pause", gameHandler.PauseGame).Methods("POST")
+	api.HandleFunc("/game/{sessionId}/resume", gameHandler.ResumeGame).Methods("POST")
+	api.HandleFunc("/game/{sessionId}/kick", gameHandler.KickPlayer).Methods("POST")
+	api.HandleFunc("/my/sessions", gameHandler.GetHostSessions).Methods("GET")
+	api.HandleFunc("/leaderboard", gameHandler.GetLeaderboard).Methods("GET")
+	api.HandleFunc("/stats/recent", gameHandler.GetRecentGames).Methods("GET")
+	api.HandleFunc("/stats/summary", gameHandler.GetStatsSummary).Methods("GET")
 
 	// WebSocket route
 	r.HandleFunc("/ws/{sessionId}", wsHandler.HandleWebSocket)
 
+	// Invite links, redirecting to the frontend with the session pre-filled
+	r.HandleFunc("/join/{sessionId}", invites.HandleJoin).Methods("GET")
+
+	// Prometheus metrics, off unless METRICS_ENABLED is set
+	if enabled, _ := strconv.ParseBool(os.Getenv("METRICS_ENABLED")); enabled {
+		r.Handle("/metrics", metrics.Handler(func() metrics.Gauges {
+			sessions, players := gameService.Stats()
+			return metrics.Gauges{ActiveSessions: sessions, ConnectedPlayers: players}
+		})).Methods("GET")
+		logger.Info("metrics enabled", "path", "/metrics")
+	}
+
 	// Serve frontend static files
 	frontendDir := "../frontend"
 	if _, err := os.Stat(frontendDir); err == nil {
@@ -62,24 +211,83 @@ func main() {
 		port = "5555"
 	}
 
-	log.Printf("Server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	server := &http.Server{
+		Addr: ":" + port,
+		// Wrap the whole router so preflights and 405s are handled and logged too
+		Handler: logging.Middleware(logger)(handlers.CORSMiddleware(allowlist)(r)),
+	}
+
+	go func() {
+		logger.Info("server starting", "port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("server stopped", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Wait for a deploy or Ctrl+C, then stop accepting requests and disconnect players cleanly
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-stop
+	logger.Info("shutting down", "signal", sig.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("http server shutdown failed", "error", err)
+	}
+	if err := wsHandler.Shutdown(ctx); err != nil {
+		logger.Error("websocket shutdown failed", "error", err)
+	}
+	if recorder != nil {
+		if err := recorder.Close(ctx); err != nil {
+			logger.Error("game records not all saved", "error", err)
+		}
+		recorder.Store().Close()
+	}
+	logger.Info("server stopped")
 }
 
-// corsMiddleware adds CORS headers with configurable origin
-func corsMiddleware(allowedOrigin string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
+// envInt reads an integer environment variable, returning def when it is unset
+func envInt(logger *slog.Logger, name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		logger.Error("invalid "+name, "value", value)
+		os.Exit(1)
+	}
+	return n
+}
+
+// openRecorder opens the game store: Postgres when DATABASE_URL is set, otherwise
+// SQLite at SQLITE_PATH (default bombs.db). Returns nil when the Postgres driver isn't linked in
+func openRecorder(logger *slog.Logger) *storage.Recorder {
+	driver, dsn := storage.DriverSQLite, os.Getenv("SQLITE_PATH")
+	if dsn == "" {
+		dsn = defaultSQLitePath
+	}
+	if url := os.Getenv("DATABASE_URL"); url != "" {
+		driver, dsn = storage.DriverPostgres, url
+	}
+
+	linked := false
+	for _, name := range sql.Drivers() {
+		linked = linked || name == driver
+	}
+	if !linked {
+		logger.Info("game history persistence disabled, database driver not linked in", "driver", driver)
+		return nil
+	}
 
-			next.ServeHTTP(w, r)
-		})
+	store, err := storage.OpenSQL(driver, dsn)
+	if err != nil {
+		logger.Error("failed to open game store", "driver", driver, "error", err)
+		os.Exit(1)
 	}
+	logger.Info("game history persistence enabled", "driver", driver)
+	return storage.NewRecorder(store, gameRecordQueueSize)
 }
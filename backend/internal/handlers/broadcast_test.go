@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"bombs/internal/models"
+	"bombs/internal/service"
+)
+
+func TestBroadcastLoopSurvivesRestarts(t *testing.T) {
+	h := NewWebSocketHandler(service.NewGameService(), nil)
+	session := newActiveSession(t, 1)
+
+	for round := 0; round < 20; round++ {
+		if round > 0 {
+			if err := models.StartTestGame(session); err != nil {
+				t.Fatalf("round %d: %v", round, err)
+			}
+		}
+		h.startBroadcastLoop(session)
+		h.startBroadcastLoop(session)
+		if _, started := session.StartBroadcast(); started {
+			t.Fatalf("round %d: a second broadcast loop could start", round)
+		}
+		if err := session.ReturnToLobby(); err != nil {
+			t.Fatalf("round %d: ReturnToLobby: %v", round, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.goroutines.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast loops still running after returning to lobby")
+	}
+
+	// The last loop released the session, the next game gets one again
+	stale, started := session.StartBroadcast()
+	if !started {
+		t.Fatal("no broadcast loop can start after the loops exited")
+	}
+
+	// A stopped loop exiting late doesn't release the loop of the next game
+	if err := models.StartTestGame(session); err != nil {
+		t.Fatal(err)
+	}
+	session.ReturnToLobby()
+	stop, _ := session.StartBroadcast()
+	session.FinishBroadcast(stale)
+	if _, started := session.StartBroadcast(); started {
+		t.Error("a stale loop released the running one")
+	}
+	session.FinishBroadcast(stop)
+}
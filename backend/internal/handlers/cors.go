@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OriginAllowlist is the set of browser origins allowed to call the API and open WebSockets
+type OriginAllowlist struct {
+	any     bool            // "*" was listed, every origin is allowed
+	origins map[string]bool // Normalized allowed origins
+}
+
+// ParseOriginAllowlist parses a comma-separated list of origins, e.g.
+// "https://bombz.gab1.fr,https://preview.bombz.gab1.fr". An empty list or "*" allows every origin
+func ParseOriginAllowlist(value string) *OriginAllowlist {
+	allowlist := &OriginAllowlist{origins: make(map[string]bool)}
+	for _, origin := range strings.Split(value, ",") {
+		origin = normalizeOrigin(origin)
+		if origin == "*" {
+			allowlist.any = true
+		} else if origin != "" {
+			allowlist.origins[origin] = true
+		}
+	}
+	if len(allowlist.origins) == 0 {
+		allowlist.any = true
+	}
+	return allowlist
+}
+
+// normalizeOrigin trims spaces and a trailing slash, and lowercases the origin
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
+
+// Allows reports whether requests from the origin may be answered
+func (a *OriginAllowlist) Allows(origin string) bool {
+	return a.any || a.origins[normalizeOrigin(origin)]
+}
+
+// CheckOrigin is the WebSocket upgrader check: listed origins, same-host pages and
+// non-browser clients (no Origin header) may connect
+func (a *OriginAllowlist) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || a.Allows(origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// CORSMiddleware adds CORS headers for allowed origins and answers preflight requests
+// It must wrap the whole router: preflights of routes registered for other methods
+// would otherwise get a 405 before any router middleware runs
+func CORSMiddleware(allowlist *OriginAllowlist) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses differ per origin, so caches must not share them across origins
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && allowlist.Allows(origin)
+			if allowed {
+				if allowlist.any {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
+
+			// Preflight request
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+					w.Header().Set("Access-Control-Max-Age", "600")
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
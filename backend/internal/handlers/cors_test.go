@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowlist   string
+		origin      string
+		wantOrigin  string
+		wantHeaders string
+	}{
+		{"listed origin", "https://bombz.gab1.fr", "https://bombz.gab1.fr", "https://bombz.gab1.fr", "Content-Type, Authorization"},
+		{"any origin", "*", "https://example.com", "*", "Content-Type, Authorization"},
+		{"unlisted origin", "https://bombz.gab1.fr", "https://example.com", "", ""},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("preflight reached the router")
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := CORSMiddleware(ParseOriginAllowlist(tt.allowlist))(next)
+			r := httptest.NewRequest(http.MethodOptions, "/api/my/sessions", nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			r.Header.Set("Access-Control-Request-Headers", "Authorization")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Headers"); got != tt.wantHeaders {
+				t.Errorf("Access-Control-Allow-Headers = %q, want %q", got, tt.wantHeaders)
+			}
+		})
+	}
+}
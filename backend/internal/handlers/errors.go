@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 )
 
 // ErrorResponse represents a standard error response
@@ -15,12 +16,12 @@ type ErrorResponse struct {
 func WriteError(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	
+
 	response := ErrorResponse{
 		Error:   http.StatusText(statusCode),
 		Message: message,
 	}
-	
+
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -34,13 +35,28 @@ func WriteNotFound(w http.ResponseWriter, message string) {
 	WriteError(w, http.StatusNotFound, message)
 }
 
+// WriteUnauthorized writes a 401 Unauthorized error
+func WriteUnauthorized(w http.ResponseWriter, message string) {
+	WriteError(w, http.StatusUnauthorized, message)
+}
+
 // WriteForbidden writes a 403 Forbidden error
 func WriteForbidden(w http.ResponseWriter, message string) {
 	WriteError(w, http.StatusForbidden, message)
 }
 
+// WriteGone writes a 410 Gone error
+func WriteGone(w http.ResponseWriter, message string) {
+	WriteError(w, http.StatusGone, message)
+}
+
 // WriteInternalServerError writes a 500 Internal Server Error
 func WriteInternalServerError(w http.ResponseWriter, message string) {
 	WriteError(w, http.StatusInternalServerError, message)
 }
 
+// WriteTooManyRequests writes a 429 Too Many Requests error with a Retry-After header
+func WriteTooManyRequests(w http.ResponseWriter, retryAfterSeconds int, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	WriteError(w, http.StatusTooManyRequests, message)
+}
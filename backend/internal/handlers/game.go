@@ -5,58 +5,119 @@ import (
 	"bombs/internal/service"
 	"bombs/internal/utils"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+const (
+	// defaultLeaderboardLimit is how many leaderboard entries are returned when no limit is given
+	defaultLeaderboardLimit = 20
+	// maxLeaderboardLimit bounds the limit query parameter of the leaderboard endpoint
+	maxLeaderboardLimit = 100
+	// defaultRecentGamesLimit is how many persisted games are returned when no limit is given
+	defaultRecentGamesLimit = 20
+	// maxRecentGamesLimit bounds the limit query parameter of the recent games endpoint
+	maxRecentGamesLimit = 100
+)
+
 // GameHandler handles REST API requests for game management
 type GameHandler struct {
 	gameService *service.GameService
+	invites     *InviteLinks
+	manualPDFs  *manualPDFCache
 }
 
 // NewGameHandler creates a new game handler
-func NewGameHandler(gameService *service.GameService) *GameHandler {
+func NewGameHandler(gameService *service.GameService, invites *InviteLinks) *GameHandler {
 	return &GameHandler{
 		gameService: gameService,
+		invites:     invites,
+		manualPDFs:  newManualPDFCache(),
 	}
 }
 
 // CreateGameRequest represents a request to create a new game
 type CreateGameRequest struct {
-	TimeLimit   int `json:"timeLimit"`   // in seconds
-	ModuleCount int `json:"moduleCount"` // 1-6, default 6
+	TimeLimit   int    `json:"timeLimit"`          // in seconds
+	ModuleCount int    `json:"moduleCount"`        // 3-12, default 6
+	HostID      string `json:"hostId,omitempty"`   // Optional, reuse a host ID from a previous session
+	Password    string `json:"password,omitempty"` // Optional, players need it to join
 }
 
 // CreateGameResponse represents the response when creating a game
 type CreateGameResponse struct {
 	SessionID string              `json:"sessionId"`
 	HostID    string              `json:"hostId"`
+	HostToken string              `json:"hostToken"` // Authenticates host-only requests, see requestHostID
+	JoinURL   string              `json:"joinUrl"`   // Invite link to share, embeds a join token for a private lobby
 	Lobby     *LobbyStateResponse `json:"lobby"`
 }
 
 // LobbyStateResponse represents the lobby state
 type LobbyStateResponse struct {
-	State           models.LobbyState `json:"state"`
-	HostID          string            `json:"hostId"`
-	Players         []*PlayerInfo     `json:"players"`
-	ModuleCount     int               `json:"moduleCount"`
-	DefuserID       string            `json:"defuserId"`
-	IsRandomDefuser bool              `json:"isRandomDefuser"`
-	TimeLimit       int               `json:"timeLimit"`
+	State                    models.LobbyState         `json:"state"`
+	HostID                   string                    `json:"hostId"`
+	Players                  []*PlayerInfo             `json:"players"`
+	ModuleCount              int                       `json:"moduleCount"`
+	DefuserID                string                    `json:"defuserId"`
+	IsRandomDefuser          bool                      `json:"isRandomDefuser"`
+	DefuserCount             int                       `json:"defuserCount"`
+	DefuserIDs               []string                  `json:"defuserIds"`
+	RotateDefuser            bool                      `json:"rotateDefuser"`
+	RotationMode             models.RotationMode       `json:"rotationMode"`
+	UpNext                   []string                  `json:"upNext"`
+	TimeLimit                int                       `json:"timeLimit"`
+	RequireReady             bool                      `json:"requireReady"`
+	MaxStrikes               int                       `json:"maxStrikes"`
+	StrikeTimePenalty        int                       `json:"strikeTimePenalty"`
+	TimerAcceleration        bool                      `json:"timerAcceleration"`
+	Mission                  []models.MissionBomb      `json:"mission"`
+	CarryStrikes             bool                      `json:"carryStrikes"`
+	AutopauseOnDefuserDrop   bool                      `json:"autopauseOnDefuserDrop"`
+	ExpertSeesModuleDetails  bool                      `json:"expertSeesModuleDetails"`
+	WaitForReconnecting      bool                      `json:"waitForReconnecting"`
+	EnableNeedyModules       bool                      `json:"enableNeedyModules"`
+	StripedWires             bool                      `json:"stripedWires"`
+	ButtonCyclingGauge       bool                      `json:"buttonCyclingGauge"`
+	TerminalHardMode         bool                      `json:"terminalHardMode"`
+	TerminalSequenceLength   int                       `json:"terminalSequenceLength"`
+	TerminalStrictness       models.TerminalStrictness `json:"terminalStrictness"`
+	TerminalLockoutThreshold int                       `json:"terminalLockoutThreshold"`
+	TerminalLockoutSeconds   int                       `json:"terminalLockoutSeconds"`
+	ModuleTypes              []string                  `json:"moduleTypes"`
+	ModuleMix                map[string]int            `json:"moduleMix"`
+	Difficulty               models.Difficulty         `json:"difficulty"`
+	PracticeMode             bool                      `json:"practiceMode"`
+	HasWebhook               bool                      `json:"hasWebhook"`  // Whether the session has its own webhook, the URL isn't shared
+	HasPassword              bool                      `json:"hasPassword"` // Whether joining requires a password, the password isn't shared
+	LastGame                 *models.GameHistoryEntry  `json:"lastGame,omitempty"`
 }
 
 // PlayerInfo represents player information in lobby
 type PlayerInfo struct {
-	ID       string            `json:"id"`
-	Name     string            `json:"name"`
-	Type     models.PlayerType `json:"type"`
-	JoinedAt string            `json:"joinedAt"`
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	Type           models.PlayerType     `json:"type"`
+	JoinedAt       string                `json:"joinedAt"`
+	Ready          bool                  `json:"ready"`
+	RolePreference models.RolePreference `json:"rolePreference"`
+	LatencyMs      int                   `json:"latencyMs"`
+	Reconnecting   bool                  `json:"reconnecting"`
 }
 
 // JoinGameRequest represents a request to join a game
 type JoinGameRequest struct {
 	SessionID string `json:"sessionId"`
+	Password  string `json:"password,omitempty"`  // Required when the lobby has a password
+	JoinToken string `json:"joinToken,omitempty"` // From an invite link, replaces the password
 }
 
 // JoinGameResponse represents the response when joining a game
@@ -66,11 +127,25 @@ type JoinGameResponse struct {
 }
 
 // UpdateLobbySettingsRequest represents a request to update lobby settings
-type UpdateLobbySettingsRequest struct {
-	ModuleCount     int    `json:"moduleCount"` // 1-6
-	DefuserID       string `json:"defuserId"`   // Empty if random
-	IsRandomDefuser bool   `json:"isRandomDefuser"`
-	TimeLimit       int    `json:"timeLimit"` // Time limit in seconds (60-300)
+type UpdateLobbySettingsRequest = models.LobbySettings
+
+// KickPlayerRequest represents a request to kick a player from the session
+type KickPlayerRequest struct {
+	PlayerID string `json:"playerId"`
+}
+
+// HostSessionInfo represents a session in the host dashboard
+type HostSessionInfo struct {
+	SessionID    string            `json:"sessionId"`
+	State        models.LobbyState `json:"state"`
+	PlayerCount  int               `json:"playerCount"`
+	CreatedAt    string            `json:"createdAt"`
+	LastActivity string            `json:"lastActivity"`
+}
+
+// HostSessionsResponse represents the list of sessions created by a host
+type HostSessionsResponse struct {
+	Sessions []*HostSessionInfo `json:"sessions"`
 }
 
 // StartGameRequest represents a request to start the game
@@ -86,36 +161,59 @@ func (h *GameHandler) CreateGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.TimeLimit <= 0 {
+	if req.TimeLimit < models.MinTimeLimit || req.TimeLimit > models.MaxTimeLimit {
 		req.TimeLimit = 300 // Default 5 minutes
 	}
 
-	if req.ModuleCount < 1 || req.ModuleCount > 6 {
-		req.ModuleCount = 6 // Default 6 modules
+	if req.ModuleCount < models.MinModuleCount || req.ModuleCount > models.MaxModuleCount {
+		req.ModuleCount = models.DefaultModuleCount
 	}
 
-	// Generate session ID
-	sessionID, err := utils.GenerateSessionID()
-	if err != nil {
-		WriteInternalServerError(w, "Failed to generate session ID")
+	if len(req.Password) > models.MaxLobbyPasswordLength {
+		WriteBadRequest(w, fmt.Sprintf("Password must be at most %d characters", models.MaxLobbyPasswordLength))
 		return
 	}
 
-	// Generate host ID
-	hostID, err := utils.GenerateHostID()
+	// Reuse the caller's host ID so their sessions are grouped together, but only when
+	// a host token of one of its sessions proves it; without a token generate a new one
+	hostID := ""
+	if _, found := bearerToken(r); found && req.HostID != "" {
+		if requestHostIdentity(h.gameService, r) != req.HostID {
+			WriteUnauthorized(w, "Host token required to reuse a host ID")
+			return
+		}
+		hostID = req.HostID
+	}
+	if !strings.HasPrefix(hostID, "host-") {
+		var err error
+		hostID, err = utils.GenerateHostID()
+		if err != nil {
+			WriteInternalServerError(w, "Failed to generate host ID")
+			return
+		}
+	}
+
+	// The host ID is either new or proven by the host token
+	session, err := h.gameService.CreateSession(hostID, req.TimeLimit, true)
 	if err != nil {
-		WriteInternalServerError(w, "Failed to generate host ID")
+		WriteInternalServerError(w, "Failed to generate session ID")
 		return
 	}
-
-	session := h.gameService.CreateSession(sessionID, hostID, req.TimeLimit)
+	sessionID := session.ID
 
 	// Set initial module count
 	session.SetModuleCount(req.ModuleCount)
 
+	if err := session.SetPassword(req.Password); err != nil {
+		WriteInternalServerError(w, "Failed to set password")
+		return
+	}
+
 	response := CreateGameResponse{
 		SessionID: sessionID,
 		HostID:    hostID,
+		HostToken: h.gameService.HostToken(sessionID, hostID),
+		JoinURL:   h.invites.JoinURL(r, sessionID, h.gameService.JoinToken(session)),
 		Lobby:     h.buildLobbyStateResponse(session),
 	}
 
@@ -131,9 +229,14 @@ func (h *GameHandler) JoinGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, exists := h.gameService.GetSession(req.SessionID)
-	if !exists {
-		WriteNotFound(w, "Session not found")
+	session, err := h.gameService.LookupSession(req.SessionID)
+	if err != nil {
+		writeSessionLookupError(w, err)
+		return
+	}
+
+	if !h.gameService.CanJoin(session, req.Password, req.JoinToken) {
+		WriteForbidden(w, "Wrong password")
 		return
 	}
 
@@ -159,8 +262,8 @@ func (h *GameHandler) GetGameState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return bomb if game is active, otherwise return lobby state
-	if session.GetLobbyState() == models.LobbyStateActive && session.Bomb != nil {
+	// Return bomb if game is starting or active, otherwise return lobby state
+	if session.GetLobbyState() != models.LobbyStateWaiting && session.GetCurrentBomb() != nil {
 		w.Header().Set("Content-Type", "application/json")
 
 		// If playerId is provided, return role-specific content
@@ -168,13 +271,13 @@ func (h *GameHandler) GetGameState(w http.ResponseWriter, r *http.Request) {
 			player, exists := session.GetPlayer(playerID)
 			if exists && player.Type == models.PlayerTypeExpert {
 				// Return manual content for experts
-				json.NewEncoder(w).Encode(models.GetManualContent(session.Bomb))
+				json.NewEncoder(w).Encode(session.GetManualContent(player.Language))
 				return
 			}
 		}
 
-		// Default: return bomb state (for defusers or when playerId not provided)
-		json.NewEncoder(w).Encode(session.Bomb)
+		// Default: return bomb state without solution data (for defusers or when playerId not provided)
+		json.NewEncoder(w).Encode(session.GetDefuserView())
 	} else {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(h.buildLobbyStateResponse(session))
@@ -196,15 +299,162 @@ func (h *GameHandler) GetLobbyState(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(h.buildLobbyStateResponse(session))
 }
 
+// GetResults handles GET /api/game/{sessionId}/results
+// Returns the last finished games of the session, oldest first
+func (h *GameHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+
+	session, exists := h.gameService.GetSession(sessionID)
+	if !exists {
+		WriteNotFound(w, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session.GetResults())
+}
+
+// GetHistory handles GET /api/game/{sessionId}/history
+// Returns a summary of every finished game of the session, oldest first
+func (h *GameHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+
+	session, exists := h.gameService.GetSession(sessionID)
+	if !exists {
+		WriteNotFound(w, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session.GetHistory())
+}
+
+// GetReplay handles GET /api/game/{sessionId}/replay
+// Returns the ordered event log of the last finished game, with the seed of each bomb
+func (h *GameHandler) GetReplay(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+
+	session, exists := h.gameService.GetSession(sessionID)
+	if !exists {
+		WriteNotFound(w, "Session not found")
+		return
+	}
+
+	replay := session.GetReplay()
+	if replay == nil {
+		WriteNotFound(w, "No finished game to replay yet")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(replay)
+}
+
+// GetLeaderboard handles GET /api/leaderboard?moduleCount=6&difficulty=normal&limit=20
+// Returns the fastest defusals across every session, fastest first
+func (h *GameHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	moduleCount := models.DefaultModuleCount
+	if value := query.Get("moduleCount"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < models.MinModuleCount || count > models.MaxModuleCount {
+			WriteBadRequest(w, fmt.Sprintf("moduleCount must be between %d and %d", models.MinModuleCount, models.MaxModuleCount))
+			return
+		}
+		moduleCount = count
+	}
+
+	difficulty := models.DifficultyNormal
+	if value := query.Get("difficulty"); value != "" {
+		difficulty = models.Difficulty(value)
+		if err := models.ValidateDifficulty(difficulty); err != nil {
+			WriteBadRequest(w, err.Error())
+			return
+		}
+	}
+
+	limit, err := parseLimitParam(r, defaultLeaderboardLimit, maxLeaderboardLimit)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.gameService.GetLeaderboard(moduleCount, difficulty, limit))
+}
+
+// parseLimitParam reads the limit query parameter, def if it is missing
+func parseLimitParam(r *http.Request, def int, max int) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > max {
+		return 0, fmt.Errorf("limit must be between 1 and %d", max)
+	}
+	return n, nil
+}
+
+// GetRecentGames handles GET /api/stats/recent?limit=20
+// Returns the last persisted games over every session, most recent first
+func (h *GameHandler) GetRecentGames(w http.ResponseWriter, r *http.Request) {
+	store := h.gameService.GameStore()
+	if store == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Game history persistence is not enabled")
+		return
+	}
+
+	limit, err := parseLimitParam(r, defaultRecentGamesLimit, maxRecentGamesLimit)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	records, err := store.RecentGames(r.Context(), limit)
+	if err != nil {
+		slog.Error("failed to read recent games", "error", err)
+		WriteInternalServerError(w, "Failed to read recent games")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+// GetStatsSummary handles GET /api/stats/summary
+// Returns totals over every persisted game, practice games excluded
+func (h *GameHandler) GetStatsSummary(w http.ResponseWriter, r *http.Request) {
+	store := h.gameService.GameStore()
+	if store == nil {
+		WriteError(w, http.StatusServiceUnavailable, "Game history persistence is not enabled")
+		return
+	}
+
+	summary, err := store.Summary(r.Context())
+	if err != nil {
+		slog.Error("failed to summarize games", "error", err)
+		WriteInternalServerError(w, "Failed to summarize games")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
 // UpdateLobbySettings handles POST /api/game/{sessionId}/lobby/settings
 func (h *GameHandler) UpdateLobbySettings(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sessionID := vars["sessionId"]
 
-	// Get host ID from query parameter or header
-	hostID := r.URL.Query().Get("hostId")
+	// Get host ID from the host token (or the legacy hostId query parameter)
+	hostID := requestHostID(h.gameService, r, sessionID)
 	if hostID == "" {
-		WriteBadRequest(w, "Host ID required")
+		WriteUnauthorized(w, "Host token required")
 		return
 	}
 
@@ -225,23 +475,14 @@ func (h *GameHandler) UpdateLobbySettings(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Update module count
-	if req.ModuleCount > 0 {
-		if err := session.SetModuleCount(req.ModuleCount); err != nil {
-			WriteBadRequest(w, err.Error())
-			return
-		}
-	}
-
-	// Update defuser settings
-	session.SetDefuser(req.DefuserID, req.IsRandomDefuser)
-
-	// Update time limit
-	if req.TimeLimit > 0 {
-		if err := session.SetTimeLimit(req.TimeLimit); err != nil {
-			WriteBadRequest(w, err.Error())
+	// The session refuses the update once a game is starting or running
+	if err := applyLobbySettings(session, &req); err != nil {
+		if errors.Is(err, models.ErrNotInLobby) {
+			WriteError(w, http.StatusConflict, "Lobby settings can only be changed in the lobby")
 			return
 		}
+		WriteBadRequest(w, err.Error())
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -253,10 +494,10 @@ func (h *GameHandler) StartGame(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sessionID := vars["sessionId"]
 
-	// Get host ID from query parameter or header
-	hostID := r.URL.Query().Get("hostId")
+	// Get host ID from the host token (or the legacy hostId query parameter)
+	hostID := requestHostID(h.gameService, r, sessionID)
 	if hostID == "" {
-		WriteBadRequest(w, "Host ID required")
+		WriteUnauthorized(w, "Host token required")
 		return
 	}
 
@@ -288,10 +529,10 @@ func (h *GameHandler) ReturnToLobby(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sessionID := vars["sessionId"]
 
-	// Get host ID from query parameter
-	hostID := r.URL.Query().Get("hostId")
+	// Get host ID from the host token (or the legacy hostId query parameter)
+	hostID := requestHostID(h.gameService, r, sessionID)
 	if hostID == "" {
-		http.Error(w, "Host ID required", http.StatusBadRequest)
+		WriteUnauthorized(w, "Host token required")
 		return
 	}
 
@@ -318,6 +559,163 @@ func (h *GameHandler) ReturnToLobby(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(h.buildLobbyStateResponse(session))
 }
 
+// PauseGame handles POST /api/game/{sessionId}/pause
+func (h *GameHandler) PauseGame(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, true)
+}
+
+// ResumeGame handles POST /api/game/{sessionId}/resume
+func (h *GameHandler) ResumeGame(w http.ResponseWriter, r *http.Request) {
+	h.setPaused(w, r, false)
+}
+
+// setPaused is the shared implementation of the pause and resume endpoints
+func (h *GameHandler) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+
+	// Get host ID from the host token (or the legacy hostId query parameter)
+	hostID := requestHostID(h.gameService, r, sessionID)
+	if hostID == "" {
+		WriteUnauthorized(w, "Host token required")
+		return
+	}
+
+	session, exists := h.gameService.GetSession(sessionID)
+	if !exists {
+		WriteNotFound(w, "Session not found")
+		return
+	}
+
+	if !session.IsHost(hostID) {
+		WriteForbidden(w, "Only host can pause or resume the game")
+		return
+	}
+
+	if err := setGamePaused(session, paused); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session.GetDefuserView())
+}
+
+// KickPlayer handles POST /api/game/{sessionId}/kick
+func (h *GameHandler) KickPlayer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+
+	// Get host ID from the host token (or the legacy hostId query parameter)
+	hostID := requestHostID(h.gameService, r, sessionID)
+	if hostID == "" {
+		WriteUnauthorized(w, "Host token required")
+		return
+	}
+
+	session, exists := h.gameService.GetSession(sessionID)
+	if !exists {
+		WriteNotFound(w, "Session not found")
+		return
+	}
+
+	if !session.IsHost(hostID) {
+		WriteForbidden(w, "Only host can kick players")
+		return
+	}
+
+	var req KickPlayerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteBadRequest(w, "Invalid request body")
+		return
+	}
+
+	if err := kickPlayer(session, req.PlayerID, kickedByHostReason); err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.buildLobbyStateResponse(session))
+}
+
+// GetHostSessions handles GET /api/my/sessions
+// Returns the sessions created by the host a host token (of any of its sessions) was issued to
+func (h *GameHandler) GetHostSessions(w http.ResponseWriter, r *http.Request) {
+	// Get host ID from the host token; the dashboard lists every session of the host,
+	// so the legacy hostId query parameter isn't enough
+	hostID := requestHostIdentity(h.gameService, r)
+	if hostID == "" {
+		WriteUnauthorized(w, "Host token required")
+		return
+	}
+
+	sessions := h.gameService.GetHostSessions(hostID)
+
+	// Most recently active sessions first
+	sort.Slice(sessions, func(i, j int) bool {
+		_, lastI := sessions[i].GetActivityInfo()
+		_, lastJ := sessions[j].GetActivityInfo()
+		return lastI.After(lastJ)
+	})
+
+	infos := make([]*HostSessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		createdAt, lastActivity := session.GetActivityInfo()
+		infos = append(infos, &HostSessionInfo{
+			SessionID:    session.ID,
+			State:        session.GetLobbyState(),
+			PlayerCount:  session.GetPlayerCount(),
+			CreatedAt:    createdAt.Format(time.RFC3339),
+			LastActivity: lastActivity.Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HostSessionsResponse{Sessions: infos})
+}
+
+// DeleteGame handles DELETE /api/game/{sessionId}
+func (h *GameHandler) DeleteGame(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+
+	// Get host ID from the host token (or the legacy hostId query parameter)
+	hostID := requestHostID(h.gameService, r, sessionID)
+	if hostID == "" {
+		WriteUnauthorized(w, "Host token required")
+		return
+	}
+
+	session, exists := h.gameService.GetSession(sessionID)
+	if !exists {
+		WriteNotFound(w, "Session not found")
+		return
+	}
+
+	if !session.IsHost(hostID) {
+		WriteForbidden(w, "Only host can delete the session")
+		return
+	}
+
+	if err := closeSession(h.gameService, session, sessionClosedByHostReason); err != nil {
+		WriteNotFound(w, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSessionLookupError writes the error response for a failed session lookup
+// Codes of recently ended sessions get 410 Gone so clients can tell players the game is over
+func writeSessionLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrSessionEnded) {
+		WriteGone(w, "This game has ended")
+		return
+	}
+	WriteNotFound(w, "Session not found")
+}
+
 // buildLobbyStateResponse builds a lobby state response from a session
 func (h *GameHandler) buildLobbyStateResponse(session *models.GameSession) *LobbyStateResponse {
 	lobbyData := buildLobbyData(session, "")
@@ -326,10 +724,14 @@ func (h *GameHandler) buildLobbyStateResponse(session *models.GameSession) *Lobb
 	players := make([]*PlayerInfo, 0, len(lobbyData.Players))
 	for _, p := range lobbyData.Players {
 		players = append(players, &PlayerInfo{
-			ID:       p.ID,
-			Name:     p.Name,
-			Type:     p.Type,
-			JoinedAt: p.JoinedAt,
+			ID:             p.ID,
+			Name:           p.Name,
+			Type:           p.Type,
+			JoinedAt:       p.JoinedAt,
+			Ready:          p.Ready,
+			RolePreference: p.RolePreference,
+			LatencyMs:      p.LatencyMs,
+			Reconnecting:   p.Reconnecting,
 		})
 	}
 
@@ -337,12 +739,41 @@ func (h *GameHandler) buildLobbyStateResponse(session *models.GameSession) *Lobb
 	timeLimit := session.GetTimeLimit()
 
 	return &LobbyStateResponse{
-		State:           lobbyData.State,
-		HostID:          lobbyData.HostID,
-		Players:         players,
-		ModuleCount:     lobbyData.ModuleCount,
-		DefuserID:       lobbyData.DefuserID,
-		IsRandomDefuser: lobbyData.IsRandomDefuser,
-		TimeLimit:       timeLimit,
+		State:                    lobbyData.State,
+		HostID:                   lobbyData.HostID,
+		Players:                  players,
+		ModuleCount:              lobbyData.ModuleCount,
+		DefuserID:                lobbyData.DefuserID,
+		IsRandomDefuser:          lobbyData.IsRandomDefuser,
+		DefuserCount:             lobbyData.DefuserCount,
+		DefuserIDs:               lobbyData.DefuserIDs,
+		RotateDefuser:            lobbyData.RotateDefuser,
+		RotationMode:             lobbyData.RotationMode,
+		UpNext:                   lobbyData.UpNext,
+		TimeLimit:                timeLimit,
+		RequireReady:             lobbyData.RequireReady,
+		MaxStrikes:               lobbyData.MaxStrikes,
+		StrikeTimePenalty:        lobbyData.StrikeTimePenalty,
+		TimerAcceleration:        lobbyData.TimerAcceleration,
+		Mission:                  lobbyData.Mission,
+		CarryStrikes:             lobbyData.CarryStrikes,
+		AutopauseOnDefuserDrop:   lobbyData.AutopauseOnDefuserDrop,
+		ExpertSeesModuleDetails:  lobbyData.ExpertSeesModuleDetails,
+		WaitForReconnecting:      lobbyData.WaitForReconnecting,
+		EnableNeedyModules:       lobbyData.EnableNeedyModules,
+		StripedWires:             lobbyData.StripedWires,
+		ButtonCyclingGauge:       lobbyData.ButtonCyclingGauge,
+		TerminalHardMode:         lobbyData.TerminalHardMode,
+		TerminalSequenceLength:   lobbyData.TerminalSequenceLength,
+		TerminalStrictness:       lobbyData.TerminalStrictness,
+		TerminalLockoutThreshold: lobbyData.TerminalLockoutThreshold,
+		TerminalLockoutSeconds:   lobbyData.TerminalLockoutSeconds,
+		ModuleTypes:              lobbyData.ModuleTypes,
+		ModuleMix:                lobbyData.ModuleMix,
+		Difficulty:               lobbyData.Difficulty,
+		PracticeMode:             lobbyData.PracticeMode,
+		HasWebhook:               lobbyData.HasWebhook,
+		HasPassword:              lobbyData.HasPassword,
+		LastGame:                 lobbyData.LastGame,
 	}
 }
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bombs/internal/models"
+	"bombs/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// newHostService returns a game service accepting host tokens only, with two sessions of
+// host-a and one of host-b, and a host token of each host
+func newHostService(t *testing.T) (gameService *service.GameService, tokenA string, tokenB string) {
+	t.Helper()
+	gameService = service.NewGameService()
+	gameService.SetAllowLegacyHostID(false)
+	first, _ := gameService.CreateSession("host-a", 300, true)
+	gameService.CreateSession("host-a", 300, true)
+	other, _ := gameService.CreateSession("host-b", 300, true)
+	return gameService, gameService.HostToken(first.ID, "host-a"), gameService.HostToken(other.ID, "host-b")
+}
+
+func TestHostSessionsNeedHostToken(t *testing.T) {
+	gameService, tokenA, tokenB := newHostService(t)
+	ended, _ := gameService.CreateSession("host-a", 300, true)
+	endedToken := gameService.HostToken(ended.ID, "host-a")
+	gameService.DeleteSession(ended.ID)
+
+	tests := []struct {
+		name         string
+		query        string
+		token        string
+		legacy       bool
+		wantStatus   int
+		wantSessions int
+	}{
+		{"host token", "", tokenA, false, http.StatusOK, 2},
+		{"other host's token", "?hostId=host-a", tokenB, false, http.StatusOK, 1},
+		{"bare host ID", "?hostId=host-a", "", false, http.StatusUnauthorized, 0},
+		{"bare host ID, legacy on", "?hostId=host-a", "", true, http.StatusUnauthorized, 0},
+		{"host token, legacy on", "", tokenA, true, http.StatusOK, 2},
+		{"token of an ended session", "", endedToken, false, http.StatusUnauthorized, 0},
+		{"forged token", "", forgeHostToken(tokenB, "host-a"), false, http.StatusUnauthorized, 0},
+	}
+	h := NewGameHandler(gameService, NewInviteLinks(nil, false))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameService.SetAllowLegacyHostID(tt.legacy)
+			r := httptest.NewRequest("GET", "/api/my/sessions"+tt.query, nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			h.GetHostSessions(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var response HostSessionsResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if len(response.Sessions) != tt.wantSessions {
+				t.Errorf("%d sessions listed, want %d", len(response.Sessions), tt.wantSessions)
+			}
+		})
+	}
+}
+
+func TestCreateGameReusesHostIDWithToken(t *testing.T) {
+	gameService, tokenA, tokenB := newHostService(t)
+	unproven, _ := gameService.CreateSession("host-a", 300, false)
+	unprovenToken := gameService.HostToken(unproven.ID, "host-a")
+
+	tests := []struct {
+		name       string
+		hostID     string
+		token      string
+		wantStatus int
+		wantHostA  bool // The session is created under host-a
+	}{
+		{"host token", "host-a", tokenA, http.StatusOK, true},
+		{"bare host ID", "host-a", "", http.StatusOK, false},
+		{"other host's token", "host-a", tokenB, http.StatusUnauthorized, false},
+		{"token of an unproven session", "host-a", unprovenToken, http.StatusUnauthorized, false},
+		{"new host", "", "", http.StatusOK, false},
+	}
+	h := NewGameHandler(gameService, NewInviteLinks(nil, false))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(CreateGameRequest{HostID: tt.hostID})
+			r := httptest.NewRequest("POST", "/api/game", strings.NewReader(string(body)))
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			h.CreateGame(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var response CreateGameResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if (response.HostID == "host-a") != tt.wantHostA || response.HostToken == "" {
+				t.Errorf("created under %q with token %q, want host-a %v", response.HostID, response.HostToken, tt.wantHostA)
+			}
+		})
+	}
+}
+
+func TestUpdateLobbySettingsOnlyInLobby(t *testing.T) {
+	tests := []struct {
+		name       string
+		start      func(session *models.GameSession) error // Moves the session out of the lobby, nil to stay
+		wantStatus int
+		wantCount  int
+	}{
+		{"lobby", nil, http.StatusOK, 5},
+		{"starting", (*models.GameSession).StartGame, http.StatusConflict, models.DefaultModuleCount},
+		{"playing", models.StartTestGame, http.StatusConflict, models.DefaultModuleCount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameService := service.NewGameService()
+			session, _ := gameService.CreateSession("host-a", 300, true)
+			if tt.start != nil {
+				session.AddPlayer("host-a", "Host", models.PlayerTypeDefuser, models.NewConnection())
+				session.SetPracticeMode(true)
+				if err := tt.start(session); err != nil {
+					t.Fatalf("start: %v", err)
+				}
+			}
+			h := NewGameHandler(gameService, NewInviteLinks(nil, false))
+
+			r := httptest.NewRequest("POST", "/api/game/"+session.ID+"/lobby/settings", strings.NewReader(`{"moduleCount":5}`))
+			r = mux.SetURLVars(r, map[string]string{"sessionId": session.ID})
+			r.Header.Set("Authorization", "Bearer "+gameService.HostToken(session.ID, "host-a"))
+			w := httptest.NewRecorder()
+			h.UpdateLobbySettings(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if _, count, _, _ := session.GetLobbyInfo(); count != tt.wantCount {
+				t.Errorf("module count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bombs/internal/models"
+	"bombs/internal/service"
+	"net/http"
+	"strings"
+)
+
+// requestHostID returns the host ID a REST request authenticates as, or "" if none
+// The host token goes in an "Authorization: Bearer" header. The bare hostId query
+// parameter is only accepted while the legacy compatibility flag is on
+// An empty sessionID accepts the token of any session of the host, for requests not about one session
+func requestHostID(gameService *service.GameService, r *http.Request, sessionID string) string {
+	return authenticateHostID(gameService, r, sessionID, r.URL.Query().Get("hostId"))
+}
+
+// authenticateHostID is requestHostID with the bare host ID the request claims,
+// trusted only while the legacy compatibility flag is on
+func authenticateHostID(gameService *service.GameService, r *http.Request, sessionID string, claimedHostID string) string {
+	if token, found := bearerToken(r); found {
+		var hostID string
+		var ok bool
+		if sessionID == "" {
+			hostID, ok = gameService.VerifyHostIdentity(token)
+		} else {
+			hostID, ok = gameService.VerifyHostToken(sessionID, token)
+		}
+		if !ok {
+			return ""
+		}
+		return hostID
+	}
+	if gameService.AllowLegacyHostID() {
+		return claimedHostID
+	}
+	return ""
+}
+
+// requestHostIdentity returns the host ID a REST request's host token proves for requests
+// not about one session, or "" if none. The bare host ID is never trusted here, whatever
+// the legacy compatibility flag: these requests reach every session of the host
+func requestHostIdentity(gameService *service.GameService, r *http.Request) string {
+	token, found := bearerToken(r)
+	if !found {
+		return ""
+	}
+	hostID, ok := gameService.VerifyHostIdentity(token)
+	if !ok {
+		return ""
+	}
+	return hostID
+}
+
+// bearerToken returns the token of a request's "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token), found
+}
+
+// isHost checks that a WebSocket message comes from the session's host
+// The message's auth field must hold a host token issued to the sender. Messages
+// without one are only accepted while the legacy compatibility flag is on
+func (h *WebSocketHandler) isHost(session *models.GameSession, playerID string, msg *WebSocketMessage) bool {
+	if !session.IsHost(playerID) {
+		return false
+	}
+	if msg.Auth == "" {
+		return h.gameService.AllowLegacyHostID()
+	}
+	hostID, ok := h.gameService.VerifyHostToken(session.ID, msg.Auth)
+	return ok && hostID == playerID
+}
+
+// sendYouAreHost tells a player they became the host, with the token to authenticate as such
+func (h *WebSocketHandler) sendYouAreHost(session *models.GameSession, hostID string) {
+	h.sendToPlayer(session, hostID, WebSocketMessage{
+		Type:      "youAreHost",
+		SessionID: session.ID,
+		PlayerID:  hostID,
+		Data:      mustMarshal(map[string]interface{}{"hostToken": h.gameService.HostToken(session.ID, hostID)}),
+	})
+}
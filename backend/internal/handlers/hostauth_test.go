@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bombs/internal/models"
+	"bombs/internal/service"
+)
+
+// forgeHostToken returns a token claiming hostID with the signature of another token
+func forgeHostToken(token string, hostID string) string {
+	_, mac, _ := strings.Cut(token, ".")
+	return base64.RawURLEncoding.EncodeToString([]byte(hostID)) + "." + mac
+}
+
+func TestAuthenticateHostID(t *testing.T) {
+	gameService := service.NewGameService()
+	session, _ := gameService.CreateSession("host-a", 300, true)
+	other, _ := gameService.CreateSession("host-a", 300, true)
+	token := gameService.HostToken(session.ID, "host-a")
+
+	tests := []struct {
+		name          string
+		authorization string
+		claimed       string
+		legacy        bool
+		want          string
+	}{
+		{"host token", "Bearer " + token, "", false, "host-a"},
+		{"host token and claimed ID", "Bearer " + token, "host-b", true, "host-a"},
+		{"token of another session", "Bearer " + gameService.HostToken(other.ID, "host-a"), "", false, ""},
+		{"forged token", "Bearer " + forgeHostToken(token, "host-b"), "", false, ""},
+		{"malformed token", "Bearer not-a-token", "", false, ""},
+		{"bad token with legacy ID", "Bearer not-a-token", "host-a", true, ""},
+		{"other scheme", "Basic " + token, "", false, ""},
+		{"legacy ID", "", "host-a", true, "host-a"},
+		{"legacy ID turned off", "", "host-a", false, ""},
+		{"nothing", "", "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameService.SetAllowLegacyHostID(tt.legacy)
+			r := httptest.NewRequest("POST", "/api/game/"+session.ID+"/start", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			if got := authenticateHostID(gameService, r, session.ID, tt.claimed); got != tt.want {
+				t.Errorf("authenticateHostID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostMessagesNeedHostToken(t *testing.T) {
+	gameService := service.NewGameService()
+	session, _ := gameService.CreateSession("host-a", 300, true)
+	for _, id := range []string{"host-a", "player-b"} {
+		if err := session.AddPlayer(id, id, models.PlayerTypeDefuser, models.NewConnection()); err != nil {
+			t.Fatalf("AddPlayer(%s): %v", id, err)
+		}
+	}
+	h := NewWebSocketHandler(gameService, nil)
+
+	tests := []struct {
+		name     string
+		playerID string
+		auth     string
+		legacy   bool
+		want     bool
+	}{
+		{"host with token", "host-a", gameService.HostToken(session.ID, "host-a"), false, true},
+		{"host with forged token", "host-a", forgeHostToken(gameService.HostToken(session.ID, "player-b"), "host-a"), false, false},
+		{"host without token", "host-a", "", true, true},
+		{"host without token, legacy off", "host-a", "", false, false},
+		{"player with a token of their own", "player-b", gameService.HostToken(session.ID, "player-b"), true, false},
+		{"player with the host's token", "player-b", gameService.HostToken(session.ID, "host-a"), true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameService.SetAllowLegacyHostID(tt.legacy)
+			msg := &WebSocketMessage{Type: "startGame", Auth: tt.auth}
+			if got := h.isHost(session, tt.playerID, msg); got != tt.want {
+				t.Errorf("isHost = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ParsePublicBaseURL validates the external address of the deployment (PUBLIC_BASE_URL)
+// It must be an absolute http(s) URL without query or fragment. An empty value returns nil:
+// links are then built from each request
+func ParsePublicBaseURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("public base URL must be an absolute http or https URL")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("public base URL must not have a query or fragment")
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u, nil
+}
+
+// InviteLinks builds the links players share to invite others to a session
+type InviteLinks struct {
+	baseURL    *url.URL // External address of the deployment, nil to derive it from requests
+	trustProxy bool     // Derive it from X-Forwarded-Proto/Host, as set by a reverse proxy
+}
+
+// NewInviteLinks creates an invite link builder
+// Without a base URL, links use the request's scheme and host, or the proxy headers if trustProxy
+func NewInviteLinks(baseURL *url.URL, trustProxy bool) *InviteLinks {
+	return &InviteLinks{baseURL: baseURL, trustProxy: trustProxy}
+}
+
+// JoinURL returns the invite link to a session, with its join token for a private lobby
+func (il *InviteLinks) JoinURL(r *http.Request, sessionID string, joinToken string) string {
+	link := il.base(r)
+	link.Path += "/join/" + url.PathEscape(sessionID)
+	if joinToken != "" {
+		link.RawQuery = url.Values{"token": {joinToken}}.Encode()
+	}
+	return link.String()
+}
+
+// base returns the external address of the deployment as seen by the request's client
+func (il *InviteLinks) base(r *http.Request) url.URL {
+	if il.baseURL != nil {
+		return *il.baseURL
+	}
+
+	scheme, host := "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if il.trustProxy {
+		// A proxy chain may list several values, the first one is the client-facing one
+		if proto := firstHeaderValue(r, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwardedHost := firstHeaderValue(r, "X-Forwarded-Host"); forwardedHost != "" {
+			host = forwardedHost
+		}
+	}
+	return url.URL{Scheme: scheme, Host: host}
+}
+
+// firstHeaderValue returns the first comma-separated value of a header, trimmed
+func firstHeaderValue(r *http.Request, name string) string {
+	value, _, _ := strings.Cut(r.Header.Get(name), ",")
+	return strings.TrimSpace(value)
+}
+
+// HandleJoin handles GET /join/{sessionId}
+// Redirects to the frontend with the session pre-filled, so invite links can be opened or scanned as QR codes
+func (il *InviteLinks) HandleJoin(w http.ResponseWriter, r *http.Request) {
+	query := url.Values{"join": {mux.Vars(r)["sessionId"]}}
+	if token := r.URL.Query().Get("token"); token != "" {
+		query.Set("joinToken", token)
+	}
+
+	// Stay relative unless the frontend lives under a path of the public base URL
+	target := "/"
+	if il.baseURL != nil {
+		target = il.baseURL.Path + "/"
+	}
+	http.Redirect(w, r, target+"?"+query.Encode(), http.StatusFound)
+}
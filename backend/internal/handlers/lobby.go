@@ -8,22 +8,55 @@ import (
 
 // LobbyData represents the lobby state data structure
 type LobbyData struct {
-	State           models.LobbyState `json:"state"`
-	HostID          string            `json:"hostId"`
-	PlayerID        string            `json:"playerId,omitempty"` // Optional, only included for specific player
-	Players         []PlayerData      `json:"players"`
-	ModuleCount     int               `json:"moduleCount"`
-	DefuserID       string            `json:"defuserId"`
-	IsRandomDefuser bool              `json:"isRandomDefuser"`
-	TimeLimit       int               `json:"timeLimit"`
+	State                    models.LobbyState         `json:"state"`
+	HostID                   string                    `json:"hostId"`
+	PlayerID                 string                    `json:"playerId,omitempty"` // Optional, only included for specific player
+	Players                  []PlayerData              `json:"players"`
+	ModuleCount              int                       `json:"moduleCount"`
+	DefuserID                string                    `json:"defuserId"`
+	IsRandomDefuser          bool                      `json:"isRandomDefuser"`
+	DefuserCount             int                       `json:"defuserCount"`
+	DefuserIDs               []string                  `json:"defuserIds"`
+	RotateDefuser            bool                      `json:"rotateDefuser"`
+	RotationMode             models.RotationMode       `json:"rotationMode"`
+	UpNext                   []string                  `json:"upNext"` // Who defuses when the game starts next, empty during a game
+	TimeLimit                int                       `json:"timeLimit"`
+	RequireReady             bool                      `json:"requireReady"`
+	MaxStrikes               int                       `json:"maxStrikes"`
+	StrikeTimePenalty        int                       `json:"strikeTimePenalty"`
+	TimerAcceleration        bool                      `json:"timerAcceleration"`
+	Mission                  []models.MissionBomb      `json:"mission"`
+	CarryStrikes             bool                      `json:"carryStrikes"`
+	AutopauseOnDefuserDrop   bool                      `json:"autopauseOnDefuserDrop"`
+	ExpertSeesModuleDetails  bool                      `json:"expertSeesModuleDetails"`
+	WaitForReconnecting      bool                      `json:"waitForReconnecting"`
+	EnableNeedyModules       bool                      `json:"enableNeedyModules"`
+	StripedWires             bool                      `json:"stripedWires"`
+	ButtonCyclingGauge       bool                      `json:"buttonCyclingGauge"`
+	TerminalHardMode         bool                      `json:"terminalHardMode"`
+	TerminalSequenceLength   int                       `json:"terminalSequenceLength"`
+	TerminalStrictness       models.TerminalStrictness `json:"terminalStrictness"`
+	TerminalLockoutThreshold int                       `json:"terminalLockoutThreshold"`
+	TerminalLockoutSeconds   int                       `json:"terminalLockoutSeconds"`
+	ModuleTypes              []string                  `json:"moduleTypes"`
+	ModuleMix                map[string]int            `json:"moduleMix"`
+	Difficulty               models.Difficulty         `json:"difficulty"`
+	PracticeMode             bool                      `json:"practiceMode"`
+	HasWebhook               bool                      `json:"hasWebhook"`         // Whether the session has its own webhook, the URL isn't shared
+	HasPassword              bool                      `json:"hasPassword"`        // Whether joining requires a password, the password isn't shared
+	LastGame                 *models.GameHistoryEntry  `json:"lastGame,omitempty"` // Most recent finished game, nil before the first one
 }
 
 // PlayerData represents player information in lobby data
 type PlayerData struct {
-	ID       string            `json:"id"`
-	Name     string            `json:"name"`
-	Type     models.PlayerType `json:"type"`
-	JoinedAt string            `json:"joinedAt"`
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	Type           models.PlayerType     `json:"type"`
+	JoinedAt       string                `json:"joinedAt"`
+	Ready          bool                  `json:"ready"`
+	RolePreference models.RolePreference `json:"rolePreference"` // Role wished for in random defuser picks
+	LatencyMs      int                   `json:"latencyMs"`      // Smoothed round-trip time, 0 until measured
+	Reconnecting   bool                  `json:"reconnecting"`   // Disconnected, their seat is kept for the reconnect grace period
 }
 
 // buildLobbyData builds lobby data from a session
@@ -40,10 +73,14 @@ func buildLobbyData(session *models.GameSession, playerID string) *LobbyData {
 	players := make([]PlayerData, 0, len(playersMap))
 	for _, player := range playersMap {
 		players = append(players, PlayerData{
-			ID:       player.ID,
-			Name:     player.Name,
-			Type:     player.Type,
-			JoinedAt: player.JoinedAt.Format(time.RFC3339),
+			ID:             player.ID,
+			Name:           player.Name,
+			Type:           player.Type,
+			JoinedAt:       player.JoinedAt.Format(time.RFC3339),
+			Ready:          player.Ready,
+			RolePreference: player.RolePreference,
+			LatencyMs:      player.LatencyMillis(),
+			Reconnecting:   player.Disconnected,
 		})
 	}
 
@@ -71,13 +108,42 @@ func buildLobbyData(session *models.GameSession, playerID string) *LobbyData {
 	timeLimit := session.GetTimeLimit()
 
 	lobbyData := &LobbyData{
-		State:           state,
-		HostID:          hostID,
-		Players:         players,
-		ModuleCount:     moduleCount,
-		DefuserID:       defuserID,
-		IsRandomDefuser: isRandomDefuser,
-		TimeLimit:       timeLimit,
+		State:                    state,
+		HostID:                   hostID,
+		Players:                  players,
+		ModuleCount:              moduleCount,
+		DefuserID:                defuserID,
+		IsRandomDefuser:          isRandomDefuser,
+		DefuserCount:             session.GetDefuserCount(),
+		DefuserIDs:               session.GetDefuserIDs(),
+		RotateDefuser:            session.GetRotateDefuser(),
+		RotationMode:             session.GetRotationMode(),
+		UpNext:                   session.GetUpNextDefusers(),
+		TimeLimit:                timeLimit,
+		RequireReady:             session.GetRequireReady(),
+		MaxStrikes:               session.GetMaxStrikes(),
+		StrikeTimePenalty:        session.GetStrikeTimePenalty(),
+		TimerAcceleration:        session.GetTimerAcceleration(),
+		Mission:                  session.GetMission(),
+		CarryStrikes:             session.GetCarryStrikes(),
+		AutopauseOnDefuserDrop:   session.GetAutopauseOnDefuserDrop(),
+		ExpertSeesModuleDetails:  session.GetExpertSeesModuleDetails(),
+		WaitForReconnecting:      session.GetWaitForReconnecting(),
+		EnableNeedyModules:       session.GetEnableNeedyModules(),
+		StripedWires:             session.GetStripedWires(),
+		ButtonCyclingGauge:       session.GetButtonCyclingGauge(),
+		TerminalHardMode:         session.GetTerminalHardMode(),
+		TerminalSequenceLength:   session.GetTerminalSequenceLength(),
+		TerminalStrictness:       session.GetTerminalStrictness(),
+		TerminalLockoutThreshold: session.GetTerminalLockoutThreshold(),
+		TerminalLockoutSeconds:   session.GetTerminalLockoutSeconds(),
+		ModuleTypes:              session.GetModuleTypes(),
+		ModuleMix:                session.GetModuleMix(),
+		Difficulty:               session.GetDifficulty(),
+		PracticeMode:             session.GetPracticeMode(),
+		HasWebhook:               session.GetWebhookURL() != "",
+		HasPassword:              session.HasPassword(),
+		LastGame:                 session.GetLastGame(),
 	}
 
 	// Include playerID if provided
@@ -88,3 +154,16 @@ func buildLobbyData(session *models.GameSession, playerID string) *LobbyData {
 	return lobbyData
 }
 
+// applyLobbySettings applies a lobby settings update from the host
+// Shared by the REST endpoint and the updateLobbySettings WebSocket message
+// Zero values leave the corresponding setting unchanged; an invalid setting changes nothing
+func applyLobbySettings(session *models.GameSession, req *UpdateLobbySettingsRequest) error {
+	if err := session.ApplyLobbySettings(*req); err != nil {
+		return err
+	}
+
+	// Players have to confirm again after the host changes settings
+	session.ResetReady()
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"bombs/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// manualPage renders the manual of a session as a printable page
+var manualPage = template.Must(template.New("manual").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<title>Bombz Manual - Session {{.SessionID}}</title>
+<style>
+body { font-family: Georgia, serif; max-width: 50em; margin: 2em auto; color: #111; }
+section { page-break-inside: avoid; margin-bottom: 2em; }
+h2 { border-bottom: 1px solid #111; }
+.rule { margin: 0.4em 0 0.4em 2em; text-indent: -2em; }
+footer { margin-top: 3em; font-size: 0.8em; color: #555; }
+</style>
+</head>
+<body>
+<h1>Bombz Manual</h1>
+{{range .Modules}}<section>
+<h2>{{.Title}}</h2>
+<p>{{.Instructions}}</p>
+{{range .Rules}}{{if not .Description}}{{else if eq .Number 0}}<h3>{{.Description}}</h3>
+{{else}}<p class="rule"><b>{{.Number}}.</b> {{.Description}}</p>
+{{end}}{{end}}</section>
+{{end}}<footer>Session {{.SessionID}}, bomb {{.BombNumber}} of {{.BombCount}} - MANUAL {{.ManualCode}}</footer>
+</body>
+</html>
+`))
+
+// manualPageData is what manualPage renders
+type manualPageData struct {
+	SessionID  string
+	Language   string
+	Modules    []*models.ModuleManual
+	BombNumber int
+	BombCount  int
+	ManualCode string
+}
+
+// manualSession returns the session whose manual a request may get, or writes why it may not
+// The manual is available once the game has started, and to the host during the start countdown
+func (h *GameHandler) manualSession(w http.ResponseWriter, r *http.Request, sessionID string) (*models.GameSession, bool) {
+	session, exists := h.gameService.GetSession(sessionID)
+	if !exists {
+		WriteNotFound(w, "Session not found")
+		return nil, false
+	}
+
+	switch session.GetLobbyState() {
+	case models.LobbyStateWaiting:
+		WriteError(w, http.StatusConflict, "The manual is available once the game has started")
+		return nil, false
+	case models.LobbyStateStarting:
+		// The bombs exist during the countdown, the host may print their manual
+		if hostID := requestHostID(h.gameService, r, sessionID); hostID == "" || !session.IsHost(hostID) {
+			WriteForbidden(w, "Only the host can get the manual before the game starts")
+			return nil, false
+		}
+	}
+	if session.GetCurrentBomb() == nil {
+		WriteError(w, http.StatusConflict, "The manual is available once the game has started")
+		return nil, false
+	}
+	return session, true
+}
+
+// manualLanguage returns the language asked for by a request's lang parameter
+// Without one, an expert reads their own language and anyone else the default one
+func manualLanguage(r *http.Request, session *models.GameSession) (string, error) {
+	if tag := r.URL.Query().Get("lang"); tag != "" {
+		return models.ParseLanguage(tag)
+	}
+	if player, exists := session.GetPlayer(r.URL.Query().Get("playerId")); exists && player.Language != "" {
+		return player.Language, nil
+	}
+	return models.DefaultLanguage, nil
+}
+
+// GetManual handles GET /api/game/{sessionId}/manual?format={json|html}&lang={en|fr}&playerId={playerId}
+// The bomb state is only included for an expert's playerId, so a printout can't show the bomb
+func (h *GameHandler) GetManual(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "html" {
+		WriteBadRequest(w, "format must be json or html")
+		return
+	}
+
+	session, ok := h.manualSession(w, r, sessionID)
+	if !ok {
+		return
+	}
+	language, err := manualLanguage(r, session)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		if player, exists := session.GetPlayer(query.Get("playerId")); exists && player.Type == models.PlayerTypeExpert {
+			// Same bytes as the manualContent message, bomb state included
+			w.Write(session.GetManualContentJSON(language))
+			return
+		}
+		content := session.GetManualContent(language)
+		content.BombState = nil
+		json.NewEncoder(w).Encode(content)
+		return
+	}
+
+	content := session.GetManualContent(language)
+	data := manualPageData{
+		SessionID:  session.ID,
+		Language:   language,
+		Modules:    sortedManuals(content),
+		BombNumber: content.BombIndex + 1,
+		BombCount:  content.BombCount,
+		ManualCode: content.ManualCode,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	manualPage.Execute(w, data)
+}
+
+// sortedManuals returns the module manuals of a manual sorted by module type, so printouts are stable
+func sortedManuals(content *models.ManualContent) []*models.ModuleManual {
+	types := make([]string, 0, len(content.Modules))
+	for moduleType := range content.Modules {
+		types = append(types, moduleType)
+	}
+	sort.Strings(types)
+
+	manuals := make([]*models.ModuleManual, 0, len(types))
+	for _, moduleType := range types {
+		manuals = append(manuals, content.Modules[moduleType])
+	}
+	return manuals
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"bombs/internal/models"
+	"bombs/internal/pdf"
+
+	"github.com/gorilla/mux"
+)
+
+// maxCachedManualPDFs bounds how many rendered manuals are kept
+const maxCachedManualPDFs = 64
+
+// manualPDFKey identifies a rendered manual; the seed decides the rules, the session code is in the footer
+type manualPDFKey struct {
+	sessionID string
+	seed      int64
+	language  string
+}
+
+// manualPDFCache keeps rendered manuals, which only depend on their key, safe for concurrent use
+type manualPDFCache struct {
+	entries map[manualPDFKey][]byte
+	mu      sync.Mutex
+}
+
+// newManualPDFCache creates an empty cache
+func newManualPDFCache() *manualPDFCache {
+	return &manualPDFCache{entries: make(map[manualPDFKey][]byte)}
+}
+
+// get returns the manual cached for key, rendering it with render the first time
+func (c *manualPDFCache) get(key manualPDFKey, render func() []byte) []byte {
+	c.mu.Lock()
+	data, cached := c.entries[key]
+	c.mu.Unlock()
+	if cached {
+		return data
+	}
+
+	data = render()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedManualPDFs {
+		// Finished sessions are never asked again, dropping any entry is fine
+		for old := range c.entries {
+			delete(c.entries, old)
+			break
+		}
+	}
+	c.entries[key] = data
+	return data
+}
+
+// GetManualPDF handles GET /api/game/{sessionId}/manual.pdf
+// Renders the manual of the bomb being played for printing, one module type per section,
+// with the session code, the bomb seed and the manual code in the footer. Available, and localized, like GetManual
+func (h *GameHandler) GetManualPDF(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["sessionId"]
+
+	session, ok := h.manualSession(w, r, sessionID)
+	if !ok {
+		return
+	}
+	language, err := manualLanguage(r, session)
+	if err != nil {
+		WriteBadRequest(w, err.Error())
+		return
+	}
+	bomb := session.GetCurrentBomb()
+
+	key := manualPDFKey{sessionID: session.ID, seed: bomb.Seed, language: language}
+	data := h.manualPDFs.get(key, func() []byte {
+		return renderManualPDF(session.GetManualContent(language), key)
+	})
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"bombz-manual-%s.pdf\"", session.ID))
+	w.Write(data)
+}
+
+// renderManualPDF lays a manual out as a PDF, each module manual starting a new page
+func renderManualPDF(content *models.ManualContent, key manualPDFKey) []byte {
+	doc := pdf.New()
+	for _, manual := range sortedManuals(content) {
+		doc.NewPage()
+		doc.Heading(manual.Title)
+		if manual.Instructions != "" {
+			doc.Paragraph(manual.Instructions)
+		}
+		for _, rule := range manual.Rules {
+			switch {
+			case rule.Description == "":
+			case rule.Number == 0:
+				// Rule 0 titles a group of rules, as in the in-game manual
+				doc.Subheading(rule.Description)
+			default:
+				doc.Numbered(rule.Number, rule.Description)
+			}
+		}
+	}
+	return doc.Bytes(func(page, pages int) string {
+		return fmt.Sprintf("Session %s - seed %d - MANUAL %s - page %d of %d", key.sessionID, key.seed, content.ManualCode, page, pages)
+	})
+}
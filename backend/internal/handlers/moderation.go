@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bombs/internal/models"
+	"bombs/internal/service"
+	"encoding/json"
+)
+
+const (
+	// kickedByHostReason is the reason sent to players the host kicks
+	kickedByHostReason = "You were removed from the session by the host"
+	// sessionClosedByHostReason is the reason sent to players when the host closes the session
+	sessionClosedByHostReason = "The host closed the session"
+)
+
+// kickPlayer removes a player from the session, tells them why and closes their connection
+// Shared by the kickPlayer WebSocket message and the REST kick endpoint
+func kickPlayer(session *models.GameSession, playerID string, reason string) error {
+	player, err := session.KickPlayer(playerID)
+	if err != nil {
+		return err
+	}
+
+	if player.Conn != nil {
+		msg := WebSocketMessage{
+			Type:      "kicked",
+			SessionID: session.ID,
+			PlayerID:  playerID,
+			Data:      mustMarshal(map[string]interface{}{"reason": reason}),
+		}
+		msgBytes, _ := json.Marshal(msg)
+		player.Conn.SendCritical(msgBytes)
+
+		// The write pump flushes the kicked message before closing the socket
+		player.Conn.Close()
+	}
+
+	// Let everyone else know the player is gone
+	lobbyUpdate := WebSocketMessage{
+		Type:      "lobbyUpdate",
+		SessionID: session.ID,
+		Data:      mustMarshal(buildLobbyData(session, "")),
+	}
+	lobbyUpdateBytes, _ := json.Marshal(lobbyUpdate)
+	session.Broadcast(lobbyUpdateBytes)
+
+	return nil
+}
+
+// closeSession tells every player the session is over, then deletes it
+// Deleting the session closes the connections once the message is flushed and stops the broadcast loop
+// Shared by the closeSession WebSocket message and the REST delete endpoint
+func closeSession(gameService *service.GameService, session *models.GameSession, reason string) error {
+	msg := WebSocketMessage{
+		Type:      "sessionClosed",
+		SessionID: session.ID,
+		Data:      mustMarshal(map[string]interface{}{"reason": reason}),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	session.BroadcastCritical(msgBytes)
+
+	return gameService.DeleteSession(session.ID)
+}
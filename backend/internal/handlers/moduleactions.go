@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"bombs/internal/models"
+)
+
+// legacyModuleAction is the module action a per-module message from before moduleAction stands for
+// The message data is the action payload; the module type comes from moduleTypeByMessage
+type legacyModuleAction struct {
+	action string
+	result string   // Type of the result message, "" for actions without one
+	echo   []string // Message data fields repeated in the result
+}
+
+// legacyModuleActions translates the per-module messages into module actions
+var legacyModuleActions = map[string]legacyModuleAction{
+	"cutWire":              {"cut", "wireCutResult", []string{"wireIndex"}},
+	"pressButton":          {"press", "buttonActionResult", nil},
+	"buttonPress":          {"press", "buttonActionResult", nil},
+	"holdButton":           {"hold", "buttonActionResult", nil},
+	"buttonHold":           {"hold", "buttonActionResult", nil},
+	"releaseButton":        {"release", "buttonActionResult", nil},
+	"buttonRelease":        {"release", "buttonActionResult", nil},
+	"enterTerminalCommand": {"command", "terminalCommandResult", []string{"command"}},
+	"terminalCommand":      {"command", "terminalCommandResult", []string{"command"}},
+	"simonPress":           {"press", "simonPressResult", []string{"color"}},
+	"keypadPress":          {"press", "keypadPressResult", []string{"position"}},
+	"memoryPress":          {"press", "memoryPressResult", []string{"position"}},
+	"passwordSpin":         {"spin", "", nil},
+	"passwordSubmit":       {"submit", "passwordSubmitResult", nil},
+	"morseTune":            {"tune", "", nil},
+	"morseSubmit":          {"submit", "morseSubmitResult", nil},
+	"wofPress":             {"press", "wofPressResult", []string{"position"}},
+	"cutComplicatedWire":   {"cut", "cutComplicatedWireResult", []string{"wireIndex"}},
+	"mazeMove":             {"move", "mazeMoveResult", []string{"direction"}},
+	"rotateKnob":           {"rotate", "", nil},
+	"confirmKnob":          {"confirm", "confirmKnobResult", nil},
+}
+
+// playerAction describes a message acting on a module, for the replay log
+func playerAction(playerID string, msg *WebSocketMessage, moduleType string, moduleIndex int) models.PlayerAction {
+	return models.PlayerAction{PlayerID: playerID, Action: msg.Type, ModuleType: moduleType, ModuleIndex: moduleIndex, Payload: msg.Data, RequestID: msg.RequestID}
+}
+
+// handleLegacyModuleAction handles a per-module message as the module action it stands for,
+// replying with the result message that module's clients expect
+func (h *WebSocketHandler) handleLegacyModuleAction(session *models.GameSession, playerID string, msg *WebSocketMessage, legacy legacyModuleAction, logger *slog.Logger) {
+	var data struct {
+		ModuleIndex int `json:"moduleIndex"`
+	}
+	if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+		return
+	}
+
+	response, ok := h.runModuleAction(session, playerID, msg, moduleTypeByMessage[msg.Type], data.ModuleIndex, legacy.action, msg.Data, logger)
+	if !ok || legacy.result == "" {
+		return
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(msg.Data, &fields)
+	for _, field := range legacy.echo {
+		if value, exists := fields[field]; exists {
+			response[field] = value
+		}
+	}
+	h.sendActionResult(session, playerID, msg, WebSocketMessage{
+		Type:     legacy.result,
+		PlayerID: playerID,
+		Data:     mustMarshal(response),
+	})
+}
+
+// runModuleAction applies a module action for a player through Bomb.HandleModuleAction
+// and broadcasts the new state. It returns the fields of the result message, or false if
+// the game rejected the action, which the player has been told
+func (h *WebSocketHandler) runModuleAction(session *models.GameSession, playerID string, msg *WebSocketMessage, moduleType string, moduleIndex int, action string, payload json.RawMessage, logger *slog.Logger) (map[string]interface{}, bool) {
+	// A defuser on a slow connection saw the digit later than it was on the server
+	graceSeconds := 0
+	if session.GetPlayerRTT(playerID) > releaseGraceRTT {
+		graceSeconds = 1
+	}
+
+	var result models.ActionResult
+	var actionErr error
+	var details map[string]interface{}
+	err := session.DoPlayerAction(playerAction(playerID, msg, moduleType, moduleIndex), func(bomb *models.Bomb) {
+		result, actionErr = bomb.HandleModuleAction(moduleType, moduleIndex, action, payload, playerID, graceSeconds)
+		if module, err := bomb.ModuleOfType(moduleType, moduleIndex); err == nil {
+			details = moduleResultDetails(module)
+		}
+	})
+	if err != nil {
+		// Only allow module actions while the game is active
+		logger.Debug("action rejected", "type", msg.Type, "error", err)
+		h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+		return nil, false
+	}
+
+	if actionErr == nil {
+		// Broadcast updated state to all players
+		h.broadcastGameState(session)
+	}
+
+	// An action that did nothing (e.g. the wire was already cut) carries a reason and no strike
+	response := map[string]interface{}{
+		"correct":     result.Correct,
+		"strike":      result.Strike,
+		"moduleIndex": moduleIndex,
+		"action":      action,
+	}
+	for field, value := range details {
+		response[field] = value
+	}
+	if actionErr != nil {
+		response["error"] = actionErr.Error()
+		response["reason"] = actionRejectionReason(actionErr)
+		response["code"] = errorCodeFor(actionErr)
+	}
+	return response, true
+}
+
+// moduleResultDetails is the module state a result message carries after an action,
+// for modules whose clients show more than right or wrong
+func moduleResultDetails(module models.Module) map[string]interface{} {
+	switch m := module.(type) {
+	case *models.TerminalModule:
+		return map[string]interface{}{
+			"currentStep":      m.CurrentStep,
+			"terminalText":     m.GetCurrentTerminalText(),
+			"lockoutRemaining": m.Lockout(),
+		}
+	case *models.SimonModule:
+		return map[string]interface{}{"stage": m.CurrentStage}
+	}
+	return nil
+}
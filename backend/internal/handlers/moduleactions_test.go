@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"bombs/internal/models"
+	"bombs/internal/service"
+)
+
+const testPlayerID = "player-1"
+
+// newActiveSession starts a practice game with one module of every type, the same for every seed
+func newActiveSession(t *testing.T, seed int64) *models.GameSession {
+	t.Helper()
+	session, err := models.NewTestSession(testPlayerID, models.BombConfig{ModuleMix: models.OneOfEachModule()}, seed)
+	if err != nil {
+		t.Fatalf("NewTestSession: %v", err)
+	}
+	return session
+}
+
+// sendTestMessage handles a message from the test player and returns the first reply of a type
+// Data given as json.RawMessage is sent as is, so it can be malformed
+func sendTestMessage(t *testing.T, h *WebSocketHandler, session *models.GameSession, msgType string, data interface{}, replyType string) map[string]interface{} {
+	t.Helper()
+	player, _ := session.GetPlayer(testPlayerID)
+	for len(player.Conn.Send) > 0 {
+		<-player.Conn.Send
+	}
+	raw, ok := data.(json.RawMessage)
+	if !ok {
+		raw = mustMarshal(data)
+	}
+	msg := &WebSocketMessage{Type: msgType, Data: raw}
+	h.handleMessage(nil, session, testPlayerID, msg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var reply map[string]interface{}
+	for len(player.Conn.Send) > 0 {
+		var sent WebSocketMessage
+		if err := json.Unmarshal(<-player.Conn.Send, &sent); err != nil || sent.Type != replyType || reply != nil {
+			continue
+		}
+		json.Unmarshal(sent.Data, &reply)
+	}
+	return reply
+}
+
+// moduleState is the view of a module, to compare what two actions did to it
+func moduleState(t *testing.T, session *models.GameSession, moduleType string) string {
+	t.Helper()
+	module, err := session.GetCurrentBomb().ModuleOfType(moduleType, 0)
+	if err != nil {
+		t.Fatalf("no %s module: %v", moduleType, err)
+	}
+	return string(mustMarshal(module.View()))
+}
+
+func TestLegacyModuleActionsAreDispatched(t *testing.T) {
+	for msgType, legacy := range legacyModuleActions {
+		if moduleTypeByMessage[msgType] == "" {
+			t.Errorf("%s has no module type in moduleTypeByMessage", msgType)
+		}
+		if !actionMessageTypes[msgType] {
+			t.Errorf("%s is not in actionMessageTypes", msgType)
+		}
+		if legacy.action == "" {
+			t.Errorf("%s translates to no action", msgType)
+		}
+	}
+}
+
+func TestLegacyMessagesMatchModuleAction(t *testing.T) {
+	tests := []struct {
+		legacyType string
+		data       map[string]interface{}
+	}{
+		{"cutWire", map[string]interface{}{"wireIndex": 0}},
+		{"pressButton", nil},
+		{"buttonHold", nil},
+		{"releaseButton", nil},
+		{"terminalCommand", map[string]interface{}{"command": "help"}},
+		{"enterTerminalCommand", map[string]interface{}{"command": "status"}},
+		{"simonPress", map[string]interface{}{"color": "red"}},
+		{"keypadPress", map[string]interface{}{"position": 0}},
+		{"keypadPress", map[string]interface{}{"position": 99}},
+		{"memoryPress", map[string]interface{}{"position": 1}},
+		{"passwordSpin", map[string]interface{}{"column": 0, "direction": "up"}},
+		{"passwordSubmit", nil},
+		{"morseTune", map[string]interface{}{"direction": "down"}},
+		{"morseSubmit", nil},
+		{"wofPress", map[string]interface{}{"position": 2}},
+		{"cutComplicatedWire", map[string]interface{}{"wireIndex": 0}},
+		{"mazeMove", map[string]interface{}{"direction": "up"}},
+		{"mazeMove", map[string]interface{}{"direction": "sideways"}},
+		{"rotateKnob", nil},
+		{"confirmKnob", nil},
+	}
+
+	h := NewWebSocketHandler(service.NewGameService(), nil)
+	for _, tt := range tests {
+		t.Run(tt.legacyType, func(t *testing.T) {
+			legacy := legacyModuleActions[tt.legacyType]
+			moduleType := moduleTypeByMessage[tt.legacyType]
+
+			legacyData := map[string]interface{}{"moduleIndex": 0}
+			for field, value := range tt.data {
+				legacyData[field] = value
+			}
+			legacySession := newActiveSession(t, 42)
+			legacyReply := sendTestMessage(t, h, legacySession, tt.legacyType, legacyData, legacy.result)
+
+			moduleSession := newActiveSession(t, 42)
+			moduleReply := sendTestMessage(t, h, moduleSession, "moduleAction", map[string]interface{}{
+				"moduleType":  moduleType,
+				"moduleIndex": 0,
+				"action":      legacy.action,
+				"payload":     tt.data,
+			}, "moduleActionResult")
+
+			if legacy.result != "" {
+				if legacyReply == nil {
+					t.Fatalf("no %s reply", legacy.result)
+				}
+				for _, field := range []string{"correct", "strike", "reason", "code"} {
+					if legacyReply[field] != moduleReply[field] {
+						t.Errorf("%s = %v, moduleAction gave %v", field, legacyReply[field], moduleReply[field])
+					}
+				}
+				for _, field := range legacy.echo {
+					if fmt.Sprint(legacyReply[field]) != fmt.Sprint(tt.data[field]) {
+						t.Errorf("%s echoed as %v, want %v", field, legacyReply[field], tt.data[field])
+					}
+				}
+			}
+			if got, want := legacySession.GetCurrentBomb().Strikes, moduleSession.GetCurrentBomb().Strikes; got != want {
+				t.Errorf("strikes = %d, moduleAction gave %d", got, want)
+			}
+			if got, want := moduleState(t, legacySession, moduleType), moduleState(t, moduleSession, moduleType); got != want {
+				t.Errorf("module state = %s, moduleAction left %s", got, want)
+			}
+		})
+	}
+}
+
+func TestModuleResultDetails(t *testing.T) {
+	session := newActiveSession(t, 7)
+	h := NewWebSocketHandler(service.NewGameService(), nil)
+
+	reply := sendTestMessage(t, h, session, "terminalCommand", map[string]interface{}{"moduleIndex": 0, "command": "help"}, "terminalCommandResult")
+	for _, field := range []string{"currentStep", "terminalText", "lockoutRemaining"} {
+		if _, ok := reply[field]; !ok {
+			t.Errorf("terminalCommandResult has no %s", field)
+		}
+	}
+
+	reply = sendTestMessage(t, h, session, "simonPress", map[string]interface{}{"moduleIndex": 0, "color": "blue"}, "simonPressResult")
+	if _, ok := reply["stage"]; !ok {
+		t.Error("simonPressResult has no stage")
+	}
+}
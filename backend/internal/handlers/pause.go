@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"bombs/internal/models"
+	"encoding/json"
+	"time"
+)
+
+// setGamePaused pauses or resumes the game and tells every player about it
+// Shared by the pauseGame/resumeGame WebSocket messages and the REST endpoints
+func setGamePaused(session *models.GameSession, paused bool) error {
+	if paused {
+		if err := session.PauseGame(); err != nil {
+			return err
+		}
+	} else {
+		if err := session.ResumeGame(); err != nil {
+			return err
+		}
+	}
+
+	broadcastPauseState(session, paused)
+	return nil
+}
+
+// broadcastPauseState tells every player the game was paused or resumed
+func broadcastPauseState(session *models.GameSession, paused bool) {
+	messageType := "gameResumed"
+	if paused {
+		messageType = "gamePaused"
+	}
+
+	timeRemaining := 0
+	var deadline int64
+	if view := session.GetDefuserView(); view != nil {
+		timeRemaining = view.TimeRemaining
+		deadline = view.Deadline
+	}
+
+	// The deadline moves on resume, clients get the new one right away
+	msg := WebSocketMessage{
+		Type:       messageType,
+		SessionID:  session.ID,
+		Data:       mustMarshal(map[string]interface{}{"paused": paused, "timeRemaining": timeRemaining, "deadline": deadline}),
+		ServerTime: time.Now().UnixMilli(),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	session.BroadcastCritical(msgBytes)
+}
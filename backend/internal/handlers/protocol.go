@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bombs/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// Versions of the WebSocket message format, negotiated with the protocolVersion query parameter
+const (
+	// protocolFullState is the first format, with the full game state every second.
+	// Clients that don't send a version are assumed to speak it
+	protocolFullState = 1
+	// protocolDeltas sends ticks with the changed modules once the client has a full state
+	protocolDeltas = 2
+	// protocolModuleList adds every module as one ordered "modules" list to the game state,
+	// next to the per-type lists
+	protocolModuleList = 3
+
+	minProtocolVersion = protocolFullState
+	maxProtocolVersion = protocolModuleList
+
+	// closeUnsupportedProtocol is the close code sent to clients asking for a version the server doesn't speak
+	closeUnsupportedProtocol = 4001
+)
+
+// HelloData is sent first on every connection, with the protocol version the server will speak
+type HelloData struct {
+	ProtocolVersion   int    `json:"protocolVersion"`
+	SupportedVersions []int  `json:"supportedVersions"`
+	PlayerID          string `json:"playerId"`
+	ReconnectToken    string `json:"reconnectToken"` // Send back as &reconnectToken= with &playerId= to keep the seat
+}
+
+// supportedProtocolVersions lists the versions the server speaks, oldest first
+func supportedProtocolVersions() []int {
+	versions := make([]int, 0, maxProtocolVersion-minProtocolVersion+1)
+	for v := minProtocolVersion; v <= maxProtocolVersion; v++ {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// negotiateProtocol returns the version to speak with a client from its protocolVersion parameter
+func negotiateProtocol(param string) (int, error) {
+	if param == "" {
+		return protocolFullState, nil
+	}
+	version, err := strconv.Atoi(param)
+	if err != nil || version < minProtocolVersion || version > maxProtocolVersion {
+		return 0, fmt.Errorf("unsupported protocol version %q, this server speaks %d to %d", param, minProtocolVersion, maxProtocolVersion)
+	}
+	return version, nil
+}
+
+// rejectProtocol closes a freshly upgraded socket, telling the client why
+func rejectProtocol(conn *websocket.Conn, err error) {
+	reason := err.Error()
+	// Close reasons are limited to 123 bytes
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeUnsupportedProtocol, reason), time.Now().Add(time.Second))
+	conn.Close()
+}
+
+// helloMessage builds the first message of a connection
+func helloMessage(session *models.GameSession, playerID string, reconnectToken string, version int) []byte {
+	msg, _ := json.Marshal(WebSocketMessage{
+		Type:      "hello",
+		SessionID: session.ID,
+		PlayerID:  playerID,
+		Data: mustMarshal(HelloData{
+			ProtocolVersion:   version,
+			SupportedVersions: supportedProtocolVersions(),
+			PlayerID:          playerID,
+			ReconnectToken:    reconnectToken,
+		}),
+	})
+	return msg
+}
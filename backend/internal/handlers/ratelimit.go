@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiterIdleTTL is how long an IP with a full bucket is remembered before it is pruned
+const rateLimiterIdleTTL = 10 * time.Minute
+
+// RateLimiter is a per-IP token bucket limiter, safe for concurrent use
+type RateLimiter struct {
+	rate         float64 // Tokens added per second
+	burst        float64 // Bucket capacity
+	trustProxy   bool    // Read the client IP from X-Forwarded-For / X-Real-IP
+	buckets      map[string]*tokenBucket
+	lastPrunedAt time.Time
+	mu           sync.Mutex
+}
+
+// tokenBucket is the state of one client IP
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per minute per IP, with bursts of up to burst requests
+// trustProxy makes it use the client IP forwarded by a reverse proxy instead of the connection address
+func NewRateLimiter(perMinute float64, burst int, trustProxy bool) *RateLimiter {
+	return &RateLimiter{
+		rate:         perMinute / 60,
+		burst:        float64(burst),
+		trustProxy:   trustProxy,
+		buckets:      make(map[string]*tokenBucket),
+		lastPrunedAt: time.Now(),
+	}
+}
+
+// Allow takes a token from the IP's bucket
+// Returns false and how long until a token is available when the bucket is empty
+func (rl *RateLimiter) Allow(ip string) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.pruneLocked(now)
+
+	bucket, exists := rl.buckets[ip]
+	if !exists {
+		bucket = &tokenBucket{tokens: rl.burst, updatedAt: now}
+		rl.buckets[ip] = bucket
+	}
+
+	bucket.tokens = math.Min(rl.burst, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rl.rate)
+	bucket.updatedAt = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+	return false, wait
+}
+
+// pruneLocked forgets IPs idle long enough for their bucket to be full again (caller must hold rl.mu)
+// Runs at most once per minute so Allow stays cheap
+func (rl *RateLimiter) pruneLocked(now time.Time) {
+	if now.Sub(rl.lastPrunedAt) < time.Minute {
+		return
+	}
+	rl.lastPrunedAt = now
+	for ip, bucket := range rl.buckets {
+		if now.Sub(bucket.updatedAt) > rateLimiterIdleTTL {
+			delete(rl.buckets, ip)
+		}
+	}
+}
+
+// Limit wraps a handler so requests over the IP's rate get a 429
+func (rl *RateLimiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		allowed, wait := rl.Allow(rl.clientIP(r))
+		if !allowed {
+			WriteTooManyRequests(w, int(math.Ceil(wait.Seconds())), "Too many requests, please try again later")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// clientIP returns the IP the request came from
+func (rl *RateLimiter) clientIP(r *http.Request) string {
+	if rl.trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			// The first entry is the original client
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return strings.TrimSpace(realIP)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
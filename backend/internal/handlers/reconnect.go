@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log/slog"
+	"time"
+
+	"bombs/internal/models"
+)
+
+// DefaultReconnectGrace is how long a disconnected player keeps their seat by default
+const DefaultReconnectGrace = 60 * time.Second
+
+// SetReconnectGrace sets how long a disconnected player keeps their seat, zero to remove
+// them as soon as their socket closes; only call it before serving connections
+func (h *WebSocketHandler) SetReconnectGrace(grace time.Duration) {
+	h.reconnectGrace = grace
+}
+
+// disconnectPlayer handles a closed socket: the player is shown as reconnecting until the grace
+// period ends, or removed right away without one
+// Nothing happens if the player already reconnected on a new socket
+func (h *WebSocketHandler) disconnectPlayer(session *models.GameSession, playerID string, wsConn *models.Connection, logger *slog.Logger) {
+	var drop *models.PlayerDrop
+	if h.reconnectGrace > 0 {
+		var marked bool
+		marked, drop = session.DisconnectPlayer(playerID, wsConn, h.reconnectGrace, func(newHostID string) {
+			logger.Info("player did not reconnect in time", "grace", h.reconnectGrace)
+			h.playerRemoved(session, newHostID)
+		})
+		// The lobby shows them as reconnecting
+		if marked && session.GetLobbyState() == models.LobbyStateWaiting {
+			h.broadcastLobbyUpdate(session)
+		}
+	} else {
+		var removed bool
+		var newHostID string
+		removed, newHostID, drop = session.RemovePlayerConnection(playerID, wsConn)
+		if removed {
+			h.playerRemoved(session, newHostID)
+		}
+	}
+
+	// Players still in the game hear about it, and see the bomb pause if it waits for the defuser
+	if drop != nil {
+		h.broadcastPlayerPresence(session, "playerDisconnected", drop)
+		if drop.Autopaused {
+			broadcastPauseState(session, true)
+		}
+	}
+}
+
+// playerRemoved updates everyone once a player left the session for good
+func (h *WebSocketHandler) playerRemoved(session *models.GameSession, newHostID string) {
+	// Broadcast lobby update when player leaves (if in lobby) or the host changed
+	if newHostID != "" || session.GetLobbyState() == models.LobbyStateWaiting {
+		h.broadcastLobbyUpdate(session)
+	}
+	if newHostID != "" {
+		h.sendYouAreHost(session, newHostID)
+	}
+}
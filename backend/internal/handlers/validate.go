@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"bombs/internal/models"
+)
+
+const (
+	// maxMessageSize bounds a client message in bytes; a lobby settings update with a long mission is the largest
+	maxMessageSize = 32 * 1024
+	// maxStringLength bounds string fields without a limit of their own, in characters
+	maxStringLength = 256
+	// maxListLength bounds the arrays and objects of message data
+	maxListLength = 64
+	// maxPayloadDepth bounds how deeply message data can nest
+	maxPayloadDepth = 8
+	// maxRequestIDLength bounds request IDs, which are kept for ActionRequestWindow
+	maxRequestIDLength = 64
+)
+
+// fieldLengthLimits bound string fields by JSON name, in characters
+var fieldLengthLimits = map[string]int{
+	"command": models.MaxTerminalCommandLength,
+	"text":    models.MaxChatMessageLength,
+}
+
+// moduleTypeByMessage is the module type the moduleIndex of a module action counts in
+// moduleAction names its module type in the message data instead
+// Module actions may name their module by moduleId instead of moduleIndex (deprecated)
+var moduleTypeByMessage = map[string]string{
+	"cutWire":              models.ModuleTypeWires,
+	"pressButton":          models.ModuleTypeButton,
+	"buttonPress":          models.ModuleTypeButton,
+	"holdButton":           models.ModuleTypeButton,
+	"buttonHold":           models.ModuleTypeButton,
+	"releaseButton":        models.ModuleTypeButton,
+	"buttonRelease":        models.ModuleTypeButton,
+	"enterTerminalCommand": models.ModuleTypeTerminal,
+	"terminalCommand":      models.ModuleTypeTerminal,
+	"simonPress":           models.ModuleTypeSimon,
+	"keypadPress":          models.ModuleTypeKeypad,
+	"memoryPress":          models.ModuleTypeMemory,
+	"passwordSpin":         models.ModuleTypePassword,
+	"passwordSubmit":       models.ModuleTypePassword,
+	"morseTune":            models.ModuleTypeMorse,
+	"morseSubmit":          models.ModuleTypeMorse,
+	"wofPress":             models.ModuleTypeWhosOnFirst,
+	"cutComplicatedWire":   models.ModuleTypeComplicatedWires,
+	"mazeMove":             models.ModuleTypeMaze,
+	"rotateKnob":           models.ModuleTypeKnob,
+	"confirmKnob":          models.ModuleTypeKnob,
+	"answerNeedy":          models.ModuleTypeNeedyVent,
+	"startDischarge":       models.ModuleTypeNeedyCapacitor,
+	"stopDischarge":        models.ModuleTypeNeedyCapacitor,
+}
+
+// validateMessage checks a client message against the bounds above before it is dispatched
+// An out-of-bounds message is answered with MALFORMED_PAYLOAD, a module index the bomb
+// doesn't have with INVALID_MODULE_INDEX
+func (h *WebSocketHandler) validateMessage(session *models.GameSession, playerID string, msg *WebSocketMessage) bool {
+	code, err := checkMessage(session, msg)
+	if err == nil {
+		return true
+	}
+	h.sendError(session, playerID, msg, code, err.Error())
+	return false
+}
+
+// checkMessage returns why a client message is out of bounds, and the code to answer with
+func checkMessage(session *models.GameSession, msg *WebSocketMessage) (ErrorCode, error) {
+	if len(msg.RequestID) > maxRequestIDLength {
+		return ErrCodeMalformedPayload, fmt.Errorf("requestId must be at most %d characters", maxRequestIDLength)
+	}
+	if len(msg.Data) == 0 {
+		return "", nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(msg.Data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return ErrCodeMalformedPayload, fmt.Errorf("message data is malformed")
+	}
+	if err := checkValue("data", value, 0); err != nil {
+		return ErrCodeMalformedPayload, err
+	}
+
+	moduleType, isModuleAction := moduleTypeByMessage[msg.Type]
+	if !isModuleAction && msg.Type != "moduleAction" {
+		return "", nil
+	}
+	var target struct {
+		ModuleType  string `json:"moduleType"`
+		ModuleIndex int    `json:"moduleIndex"`
+		ModuleID    string `json:"moduleId"`
+	}
+	if err := json.Unmarshal(msg.Data, &target); err != nil {
+		return ErrCodeMalformedPayload, fmt.Errorf("message data is malformed")
+	}
+	if !isModuleAction {
+		moduleType = target.ModuleType
+	}
+	if target.ModuleID != "" {
+		return resolveModuleID(session, msg, moduleType, target.ModuleID)
+	}
+	if target.ModuleIndex < 0 {
+		return ErrCodeMalformedPayload, fmt.Errorf("moduleIndex must not be negative")
+	}
+	// Without a game the action is rejected as GAME_NOT_ACTIVE when dispatched
+	if count, ok := session.GetModuleCount(moduleType); ok && target.ModuleIndex >= count {
+		return ErrCodeInvalidModuleIndex, fmt.Errorf("no %s module at index %d", moduleType, target.ModuleIndex)
+	}
+	return "", nil
+}
+
+// resolveModuleID turns the moduleId of a module action into the moduleType and moduleIndex
+// the handlers act on, rewriting the message data
+// moduleType is the type the action is for, empty for a moduleAction that only gives the ID
+func resolveModuleID(session *models.GameSession, msg *WebSocketMessage, moduleType string, moduleID string) (ErrorCode, error) {
+	foundType, moduleIndex, ok, err := session.GetModuleByID(moduleID)
+	if !ok {
+		// Without a game the action is rejected as GAME_NOT_ACTIVE when dispatched
+		return "", nil
+	}
+	if err != nil {
+		return ErrCodeInvalidModuleIndex, err
+	}
+	if moduleType != "" && moduleType != foundType {
+		return ErrCodeInvalidModuleIndex, fmt.Errorf("module %s is a %s module, not %s", moduleID, foundType, moduleType)
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return ErrCodeMalformedPayload, fmt.Errorf("message data is malformed")
+	}
+	data["moduleType"] = mustMarshal(foundType)
+	data["moduleIndex"] = mustMarshal(moduleIndex)
+	msg.Data = mustMarshal(data)
+	return "", nil
+}
+
+// withModuleID adds the moduleId of the module an action result is about to its data
+// The module is found from the moduleType and moduleIndex of the result, or the request's type
+func withModuleID(session *models.GameSession, request *WebSocketMessage, data json.RawMessage) json.RawMessage {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil || result["moduleId"] != nil || result["moduleIndex"] == nil {
+		return data
+	}
+	var moduleIndex int
+	if err := json.Unmarshal(result["moduleIndex"], &moduleIndex); err != nil {
+		return data
+	}
+	moduleType, ok := moduleTypeByMessage[request.Type]
+	if !ok && json.Unmarshal(result["moduleType"], &moduleType) != nil {
+		return data
+	}
+	moduleID := session.GetModuleID(moduleType, moduleIndex)
+	if moduleID == "" {
+		return data
+	}
+	result["moduleId"] = mustMarshal(moduleID)
+	return mustMarshal(result)
+}
+
+// checkValue walks decoded message data, bounding string lengths, list lengths and nesting
+// field is the JSON name the value was found under, which picks its string limit
+func checkValue(field string, value interface{}, depth int) error {
+	if depth > maxPayloadDepth {
+		return fmt.Errorf("message data is nested too deeply")
+	}
+	switch v := value.(type) {
+	case string:
+		limit, ok := fieldLengthLimits[field]
+		if !ok {
+			limit = maxStringLength
+		}
+		if utf8.RuneCountInString(v) > limit {
+			return fmt.Errorf("%s must be at most %d characters", field, limit)
+		}
+	case []interface{}:
+		if len(v) > maxListLength {
+			return fmt.Errorf("%s must have at most %d entries", field, maxListLength)
+		}
+		for _, item := range v {
+			if err := checkValue(field, item, depth+1); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		if len(v) > maxListLength {
+			return fmt.Errorf("%s must have at most %d entries", field, maxListLength)
+		}
+		for key, item := range v {
+			if err := checkValue(key, item, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"bombs/internal/models"
+	"bombs/internal/service"
+)
+
+// nestedData is message data nesting maps depth levels below the top one
+func nestedData(depth int) json.RawMessage {
+	return json.RawMessage(`{"moduleIndex":0,"wireIndex":0,"extra":` + strings.Repeat(`{"a":`, depth-1) + "1" + strings.Repeat("}", depth-1) + "}")
+}
+
+// listData is cutWire data with an extra list of length entries
+func listData(length int) map[string]interface{} {
+	return map[string]interface{}{"moduleIndex": 0, "wireIndex": 0, "extra": make([]int, length)}
+}
+
+func TestMessageBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		msgType  string
+		data     interface{}
+		wantCode ErrorCode // "" when the message must not be rejected
+	}{
+		{"valid", "cutWire", map[string]interface{}{"moduleIndex": 0, "wireIndex": 0}, ""},
+		{"not JSON", "cutWire", json.RawMessage(`{"moduleIndex":`), ErrCodeMalformedPayload},
+		{"index not a number", "cutWire", map[string]interface{}{"moduleIndex": "0"}, ErrCodeMalformedPayload},
+		{"negative index", "cutWire", map[string]interface{}{"moduleIndex": -1}, ErrCodeMalformedPayload},
+		{"index past the last module", "cutWire", map[string]interface{}{"moduleIndex": 1, "wireIndex": 0}, ErrCodeInvalidModuleIndex},
+		{"moduleAction past the last module", "moduleAction", map[string]interface{}{"moduleType": models.ModuleTypeKeypad, "moduleIndex": 1, "action": "press"}, ErrCodeInvalidModuleIndex},
+		{"moduleAction negative index", "moduleAction", map[string]interface{}{"moduleType": models.ModuleTypeKeypad, "moduleIndex": -1, "action": "press"}, ErrCodeMalformedPayload},
+		{"command at its limit", "terminalCommand", map[string]interface{}{"moduleIndex": 0, "command": strings.Repeat("x", models.MaxTerminalCommandLength)}, ""},
+		{"command over its limit", "terminalCommand", map[string]interface{}{"moduleIndex": 0, "command": strings.Repeat("x", models.MaxTerminalCommandLength+1)}, ErrCodeMalformedPayload},
+		{"string at the limit", "cutWire", map[string]interface{}{"moduleIndex": 0, "wireIndex": 0, "extra": strings.Repeat("é", maxStringLength)}, ""},
+		{"string over the limit", "cutWire", map[string]interface{}{"moduleIndex": 0, "wireIndex": 0, "extra": strings.Repeat("é", maxStringLength+1)}, ErrCodeMalformedPayload},
+		{"list at the limit", "cutWire", listData(maxListLength), ""},
+		{"list over the limit", "cutWire", listData(maxListLength + 1), ErrCodeMalformedPayload},
+		{"nesting at the limit", "cutWire", nestedData(maxPayloadDepth), ""},
+		{"nesting over the limit", "cutWire", nestedData(maxPayloadDepth + 1), ErrCodeMalformedPayload},
+		{"unknown moduleId", "cutWire", map[string]interface{}{"moduleId": "missing", "wireIndex": 0}, ErrCodeInvalidModuleIndex},
+	}
+
+	h := NewWebSocketHandler(service.NewGameService(), nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := newActiveSession(t, 42)
+			reply := sendTestMessage(t, h, session, tt.msgType, tt.data, "error")
+
+			var code ErrorCode
+			if reply != nil {
+				code = ErrorCode(reply["code"].(string))
+			}
+			if code != tt.wantCode {
+				t.Errorf("error code = %q (%v), want %q", code, reply["message"], tt.wantCode)
+			}
+		})
+	}
+}
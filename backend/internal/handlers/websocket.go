@@ -1,166 +1,274 @@
 package handlers
 
 import (
+	"bombs/internal/metrics"
 	"bombs/internal/models"
 	"bombs/internal/service"
 	"bombs/internal/utils"
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// pingPeriod is how often each connection is pinged, which also measures its round trip
+	pingPeriod = 10 * time.Second
+	// latencyReportTicks is how many broadcast ticks pass between two latencyReport messages
+	latencyReportTicks = 5
+	// releaseGraceRTT is the round trip above which a button release is accepted one digit-second late
+	releaseGraceRTT = 250 * time.Millisecond
+)
+
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
-	gameService *service.GameService
-	upgrader    websocket.Upgrader
+	gameService    *service.GameService
+	upgrader       websocket.Upgrader
+	goroutines     sync.WaitGroup // Write pumps and broadcast loops, waited for on shutdown
+	fullState      bool           // Broadcast the full state every time instead of deltas (legacy clients)
+	reconnectGrace time.Duration  // How long a disconnected player keeps their seat, zero to remove them right away
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(gameService *service.GameService) *WebSocketHandler {
+// Upgrades are only accepted from the origins the REST API allows
+func NewWebSocketHandler(gameService *service.GameService, allowlist *OriginAllowlist) *WebSocketHandler {
 	return &WebSocketHandler{
 		gameService: gameService,
 		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true // Allow all origins in development
-			},
+			CheckOrigin: allowlist.CheckOrigin,
 		},
+		reconnectGrace: DefaultReconnectGrace,
 	}
 }
 
+// SetLegacyFullState sets whether every broadcast carries the full game state, as before
+// state deltas; only call it before serving connections
+func (h *WebSocketHandler) SetLegacyFullState(enabled bool) {
+	h.fullState = enabled
+}
+
 // WebSocketMessage represents a message sent over WebSocket
 type WebSocketMessage struct {
-	Type      string          `json:"type"`
-	SessionID string          `json:"sessionId,omitempty"`
-	PlayerID  string          `json:"playerId,omitempty"`
-	Data      json.RawMessage `json:"data,omitempty"`
+	Type       string          `json:"type"`
+	SessionID  string          `json:"sessionId,omitempty"`
+	PlayerID   string          `json:"playerId,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Auth       string          `json:"auth,omitempty"`       // Host token, sent with host-only messages
+	RequestID  string          `json:"requestId,omitempty"`  // Client-generated ID of an action, echoed in its result
+	ServerTime int64           `json:"serverTime,omitempty"` // Unix millis when a state message was sent, to correct the deadline for clock skew
+}
+
+// actionMessageTypes are the messages acting on the bomb, acknowledged by request ID
+var actionMessageTypes = map[string]bool{
+	"cutWire": true, "pressButton": true, "buttonPress": true, "holdButton": true, "buttonHold": true,
+	"releaseButton": true, "buttonRelease": true, "enterTerminalCommand": true, "terminalCommand": true,
+	"simonPress": true, "keypadPress": true, "memoryPress": true, "passwordSpin": true, "passwordSubmit": true,
+	"morseTune": true, "morseSubmit": true, "wofPress": true, "cutComplicatedWire": true, "mazeMove": true,
+	"rotateKnob": true, "confirmKnob": true, "moduleAction": true, "answerNeedy": true,
+	"startDischarge": true, "stopDischarge": true,
 }
 
 // HandleWebSocket handles WebSocket connections at /ws/{sessionId}
 func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sessionID := vars["sessionId"]
-	
+
 	if sessionID == "" {
 		WriteBadRequest(w, "Session ID required")
 		return
 	}
-	
-	session, exists := h.gameService.GetSession(sessionID)
-	if !exists {
-		WriteNotFound(w, "Session not found")
+
+	session, err := h.gameService.LookupSession(sessionID)
+	if err != nil {
+		writeSessionLookupError(w, err)
 		return
 	}
-	
-	conn, err := h.upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+
+	// Check if the host authenticates with their host token (or, in compatibility
+	// mode, their bare hostId). If it matches the session's host, use it as playerID
+	hostIDParam, _ := h.gameService.VerifyHostToken(sessionID, r.URL.Query().Get("hostToken"))
+	if hostIDParam == "" && h.gameService.AllowLegacyHostID() {
+		hostIDParam = r.URL.Query().Get("hostId")
+	}
+	playerIDParam := r.URL.Query().Get("playerId")
+	isHost := hostIDParam != "" && session.IsHost(hostIDParam)
+
+	// Private lobbies: everyone but the host must give the join password or an invite token
+	if !isHost && !h.gameService.CanJoin(session, r.URL.Query().Get("password"), r.URL.Query().Get("joinToken")) {
+		WriteForbidden(w, "Wrong password")
+		return
+	}
+
+	// Kicked players can't come back, under their ID or with their reconnect token
+	if !isHost && session.IsBanned(playerIDParam, r.URL.Query().Get("reconnectToken")) {
+		WriteForbidden(w, "You were kicked from this session")
 		return
 	}
-	
-	// Check if hostId is provided in query parameter
-	// If it matches the session's hostId, use it as playerID
-	hostIDParam := r.URL.Query().Get("hostId")
+
+	// Old cached frontends send no version and get the format they were written for
+	protocolVersion, protocolErr := negotiateProtocol(r.URL.Query().Get("protocolVersion"))
+
 	var playerID string
-	if hostIDParam != "" && session.IsHost(hostIDParam) {
+	if isHost {
 		// This is the host connecting, use their hostId as playerID
 		playerID = hostIDParam
+	} else if strings.HasPrefix(playerIDParam, "player-") && session.VerifyReconnectToken(playerIDParam, r.URL.Query().Get("reconnectToken")) {
+		// Returning player, keep the ID they had before the socket dropped
+		// Player IDs are public, the reconnect token proves it is them
+		playerID = playerIDParam
 	} else {
 		// Generate new player ID for regular players
 		var err error
 		playerID, err = utils.GeneratePlayerID()
 		if err != nil {
-			log.Printf("Failed to generate player ID: %v", err)
+			slog.Error("failed to generate player ID", "sessionId", sessionID, "error", err)
 			WriteInternalServerError(w, "Failed to generate player ID")
 			return
 		}
 	}
-	
-	// Create connection wrapper
-	wsConn := &models.Connection{
-		Send: make(chan []byte, 256),
+
+	logger := slog.Default().With("sessionId", sessionID, "playerId", playerID)
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.Warn("websocket upgrade failed", "error", err)
+		return
+	}
+
+	// Reject unsupported versions after the upgrade, browsers only see the reason of a close frame
+	if protocolErr != nil {
+		logger.Info("websocket protocol rejected", "error", protocolErr)
+		rejectProtocol(conn, protocolErr)
+		return
 	}
-	
-	// Default player type (will be reassigned when game starts)
-	playerType := models.PlayerTypeDefuser
-	
-	// Add player to session
-	session.AddPlayer(playerID, playerType, wsConn)
-	
+
+	// A new reconnect token on every connection, only this client learns it
+	reconnectToken, err := session.IssueReconnectToken(playerID)
+	if err != nil {
+		logger.Error("failed to issue reconnect token", "error", err)
+		conn.Close()
+		return
+	}
+
+	// Create connection wrapper, greeting the client before anything else is queued
+	wsConn := models.NewConnection()
+	wsConn.SetProtocolVersion(protocolVersion)
+	wsConn.TrySend(helloMessage(session, playerID, reconnectToken, protocolVersion))
+
+	// Reattach the player if they are still in the session (their old socket
+	// may not have timed out yet), so they keep their name and role
+	if oldConn, reattached := session.ReattachPlayer(playerID, wsConn); reattached {
+		if oldConn != nil {
+			oldConn.Close()
+		}
+	} else {
+		// Default player type (will be reassigned when game starts)
+		playerType := models.PlayerTypeDefuser
+
+		// Add player to session
+		// Optional display name from the name query parameter
+		if err := session.AddPlayer(playerID, r.URL.Query().Get("name"), playerType, wsConn); err != nil {
+			// Kicked between the check above and now
+			logger.Info("websocket connection refused", "error", err)
+			wsConn.Close()
+			conn.Close()
+			return
+		}
+	}
+
+	// A player coming back mid-game gets their role back, and the bomb resumes if it paused for them
+	if drop, resumed := session.ReturnDroppedPlayer(playerID); drop != nil {
+		h.broadcastPlayerPresence(session, "playerReconnected", drop)
+		if resumed {
+			broadcastPauseState(session, false)
+		}
+	}
+
 	// Set up broadcast function if not already set
 	session.SetBroadcastFunc(func(msg []byte) {
 		session.Broadcast(msg)
 	})
-	
+
 	// Broadcast lobby update when player joins
 	if session.GetLobbyState() == models.LobbyStateWaiting {
 		h.broadcastLobbyUpdate(session)
 	}
-	
+
 	// Start goroutines for reading and writing
-	go h.writePump(conn, wsConn, session, playerID)
-	go h.readPump(conn, session, playerID)
-	
-	// Start broadcast loop only if game is active and not already running
-	if session.GetLobbyState() == models.LobbyStateActive && session.StartBroadcast() {
-		go h.broadcastLoop(session)
-	}
-	
-	// Send initial state via channel (lobby or game state)
-	if session.GetLobbyState() == models.LobbyStateWaiting {
-		h.sendLobbyStateToConnection(wsConn, session, playerID)
-	} else if session.Bomb != nil {
-		h.sendGameStateToConnection(wsConn, session, playerID)
+	logger.Info("player connected")
+	h.track(func() { h.writePump(conn, wsConn, session, playerID, logger) })
+	go h.readPump(conn, wsConn, session, playerID, logger)
+
+	// Start broadcast loop only if a game is running and no loop is. A game that
+	// already ended stays without one, so its results aren't announced twice
+	if session.IsGameRunning() {
+		h.startBroadcastLoop(session)
 	}
+
+	// Send initial state via channel (lobby or game state)
+	h.sendFullStateToConnection(wsConn, session, playerID)
 }
 
 // readPump reads messages from the WebSocket connection
-func (h *WebSocketHandler) readPump(conn *websocket.Conn, session *models.GameSession, playerID string) {
+func (h *WebSocketHandler) readPump(conn *websocket.Conn, wsConn *models.Connection, session *models.GameSession, playerID string, logger *slog.Logger) {
 	defer func() {
-		session.RemovePlayer(playerID)
-		// Broadcast lobby update when player leaves (if in lobby)
-		if session.GetLobbyState() == models.LobbyStateWaiting {
-			h.broadcastLobbyUpdate(session)
-		}
+		h.disconnectPlayer(session, playerID, wsConn, logger)
+		wsConn.Close()
 		conn.Close()
+		logger.Info("player disconnected", "dropped", wsConn.DroppedMessages())
 	}()
-	
+
+	// Larger messages close the connection before they are buffered
+	conn.SetReadLimit(maxMessageSize)
 	conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-	conn.SetPongHandler(func(string) error {
+	conn.SetPongHandler(func(appData string) error {
 		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		// Pings carry the time they were sent, the pong echoes it back
+		if sentAt, err := strconv.ParseInt(appData, 10, 64); err == nil {
+			wsConn.RecordRTT(time.Since(time.UnixMilli(sentAt)))
+		}
 		return nil
 	})
-	
+
 	for {
 		_, messageBytes, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+			if errors.Is(err, websocket.ErrReadLimit) {
+				logger.Warn("websocket message too large", "limit", maxMessageSize)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logger.Warn("websocket read failed", "error", err)
 			}
 			break
 		}
-		
+
 		var msg WebSocketMessage
 		if err := json.Unmarshal(messageBytes, &msg); err != nil {
-			log.Printf("Error unmarshaling message: %v", err)
+			logger.Debug("rejecting malformed websocket message", "error", err)
+			h.sendError(session, playerID, &msg, ErrCodeMalformedPayload, "message is not valid JSON")
 			continue
 		}
-		
-		h.handleMessage(conn, session, playerID, &msg)
+
+		h.handleMessage(conn, session, playerID, &msg, logger)
 	}
 }
 
 // writePump writes messages to the WebSocket connection
-func (h *WebSocketHandler) writePump(conn *websocket.Conn, wsConn *models.Connection, session *models.GameSession, playerID string) {
-	ticker := time.NewTicker(54 * time.Second)
+func (h *WebSocketHandler) writePump(conn *websocket.Conn, wsConn *models.Connection, session *models.GameSession, playerID string, logger *slog.Logger) {
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
 		conn.Close()
 	}()
-	
+
 	for {
 		select {
 		case message, ok := <-wsConn.Send:
@@ -169,365 +277,609 @@ func (h *WebSocketHandler) writePump(conn *websocket.Conn, wsConn *models.Connec
 				conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
-			
+
 			w, err := conn.NextWriter(websocket.TextMessage)
 			if err != nil {
+				logger.Debug("websocket write failed", "error", err)
 				return
 			}
 			w.Write(message)
-			
+			metrics.MessagesOut.Inc(metrics.MessageType(message))
+
 			// Add queued messages
 			n := len(wsConn.Send)
 			for i := 0; i < n; i++ {
+				queued := <-wsConn.Send
 				w.Write([]byte{'\n'})
-				w.Write(<-wsConn.Send)
+				w.Write(queued)
+				metrics.MessagesOut.Inc(metrics.MessageType(queued))
 			}
-			
+
 			if err := w.Close(); err != nil {
+				logger.Debug("websocket write failed", "error", err)
 				return
 			}
 		case <-ticker.C:
 			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			sentAt := strconv.FormatInt(time.Now().UnixMilli(), 10)
+			if err := conn.WriteMessage(websocket.PingMessage, []byte(sentAt)); err != nil {
+				logger.Debug("websocket ping failed", "error", err)
 				return
 			}
+		case <-wsConn.Closed():
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			// A client that fell behind is told to reconnect, it resyncs then; its backlog is stale
+			if wsConn.Slow() {
+				logger.Warn("closing slow websocket client", "dropped", wsConn.DroppedMessages())
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too slow, reconnect"))
+				return
+			}
+			// Connection replaced by a reconnect, kicked or shut down
+			// Flush what is still queued (e.g. the kicked message) before closing
+			n := len(wsConn.Send)
+			for i := 0; i < n; i++ {
+				queued := <-wsConn.Send
+				if err := conn.WriteMessage(websocket.TextMessage, queued); err != nil {
+					return
+				}
+				metrics.MessagesOut.Inc(metrics.MessageType(queued))
+			}
+			conn.WriteMessage(websocket.CloseMessage, h.closeFrame())
+			return
 		}
 	}
 }
 
-// handleMessage processes incoming WebSocket messages
-func (h *WebSocketHandler) handleMessage(conn *websocket.Conn, session *models.GameSession, playerID string, msg *WebSocketMessage) {
-	switch msg.Type {
-	case "cutWire":
-		// Only allow cutting wires if game is active
-		if session.GetLobbyState() != models.LobbyStateActive || session.Bomb == nil {
-			return
-		}
-		
-		var data struct {
-			ModuleIndex int `json:"moduleIndex"`
-			WireIndex   int `json:"wireIndex"`
-		}
-		if err := json.Unmarshal(msg.Data, &data); err != nil {
-			return
+// closeFrame returns the payload of the close frame sent when the server closes a socket
+func (h *WebSocketHandler) closeFrame() []byte {
+	if h.gameService.Context().Err() != nil {
+		return websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	}
+	return websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+}
+
+// track runs fn on a new goroutine that Shutdown waits for
+func (h *WebSocketHandler) track(fn func()) {
+	h.goroutines.Add(1)
+	go func() {
+		defer h.goroutines.Done()
+		fn()
+	}()
+}
+
+// Shutdown tells every connected player the server is going away, closes all sessions
+// and waits for the write pumps to flush and the broadcast loops to stop, or for ctx to be done
+func (h *WebSocketHandler) Shutdown(ctx context.Context) error {
+	for _, session := range h.gameService.Sessions() {
+		msg := WebSocketMessage{
+			Type:      "serverShutdown",
+			SessionID: session.ID,
 		}
-		
-		correct := session.Bomb.CutWire(data.ModuleIndex, data.WireIndex)
-		
-		// Broadcast updated state to all players
-		h.broadcastGameState(session)
-		
-		// Send response to the player who cut the wire via their connection channel
-		player, exists := session.GetPlayer(playerID)
-		if exists && player.Conn != nil {
-			response := WebSocketMessage{
-				Type:     "wireCutResult",
-				PlayerID: playerID,
-				Data:     mustMarshal(map[string]interface{}{"correct": correct, "moduleIndex": data.ModuleIndex, "wireIndex": data.WireIndex}),
-			}
-			responseBytes, _ := json.Marshal(response)
-			select {
-			case player.Conn.Send <- responseBytes:
-			default:
-				// Channel full, skip
+		msgBytes, _ := json.Marshal(msg)
+		session.Broadcast(msgBytes)
+	}
+
+	if err := h.gameService.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.goroutines.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handleMessage processes incoming WebSocket messages
+// logger carries the session and player IDs so every action can be traced back to its sender
+func (h *WebSocketHandler) handleMessage(conn *websocket.Conn, session *models.GameSession, playerID string, msg *WebSocketMessage, logger *slog.Logger) {
+	session.Touch()
+	metrics.MessagesIn.Inc(msg.Type)
+	logger.Debug("websocket message", "type", msg.Type)
+
+	// Excess actions are not applied, so a flooding client can't hog the session lock
+	if !h.requireRate(session, playerID, msg) {
+		logger.Debug("rate limited", "type", msg.Type)
+		return
+	}
+
+	// Bound every payload before anything trusts it
+	if !h.validateMessage(session, playerID, msg) {
+		logger.Debug("rejecting out-of-bounds message", "type", msg.Type)
+		return
+	}
+
+	// Actions carrying a request ID are handled once; a retry gets the first result again
+	if msg.RequestID != "" && actionMessageTypes[msg.Type] {
+		if result, duplicate := session.BeginActionRequest(playerID, msg.RequestID); duplicate {
+			logger.Debug("duplicate action request", "type", msg.Type, "requestId", msg.RequestID)
+			if player, exists := session.GetPlayer(playerID); exists && player.Conn != nil && result != nil {
+				player.Conn.SendReliable(result)
 			}
-		}
-		
-	case "buttonPress":
-		// Only allow pressing buttons if game is active
-		if session.GetLobbyState() != models.LobbyStateActive || session.Bomb == nil {
 			return
 		}
-		
+		defer h.acknowledgeActionRequest(session, playerID, msg)
+	}
+
+	switch msg.Type {
+	case "cutWire", "pressButton", "buttonPress", "holdButton", "buttonHold", "releaseButton", "buttonRelease",
+		"enterTerminalCommand", "terminalCommand", "simonPress", "keypadPress", "memoryPress",
+		"passwordSpin", "passwordSubmit", "morseTune", "morseSubmit", "wofPress",
+		"cutComplicatedWire", "mazeMove", "rotateKnob", "confirmKnob":
+		h.handleLegacyModuleAction(session, playerID, msg, legacyModuleActions[msg.Type], logger)
+
+	case "moduleAction":
+		// Generic module action, routed through the Module interface
 		var data struct {
-			ModuleIndex int `json:"moduleIndex"`
+			ModuleType  string          `json:"moduleType"`
+			ModuleIndex int             `json:"moduleIndex"` // Index among the modules of that type
+			Action      string          `json:"action"`
+			Payload     json.RawMessage `json:"payload"`
 		}
-		if err := json.Unmarshal(msg.Data, &data); err != nil {
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
 			return
 		}
-		
-		correct := session.Bomb.PressButton(data.ModuleIndex)
-		
-		// Broadcast updated state to all players
-		h.broadcastGameState(session)
-		
-		// Send response to the player who pressed the button via their connection channel
-		player, exists := session.GetPlayer(playerID)
-		if exists && player.Conn != nil {
-			response := WebSocketMessage{
-				Type:     "buttonActionResult",
-				PlayerID: playerID,
-				Data:     mustMarshal(map[string]interface{}{"correct": correct, "moduleIndex": data.ModuleIndex, "action": "press"}),
-			}
-			responseBytes, _ := json.Marshal(response)
-			select {
-			case player.Conn.Send <- responseBytes:
-			default:
-				// Channel full, skip
-			}
+		if len(data.Payload) == 0 {
+			data.Payload = json.RawMessage("{}")
 		}
-		
-	case "buttonHold":
-		// Only allow holding buttons if game is active
-		if session.GetLobbyState() != models.LobbyStateActive || session.Bomb == nil {
+
+		response, ok := h.runModuleAction(session, playerID, msg, data.ModuleType, data.ModuleIndex, data.Action, data.Payload, logger)
+		if !ok {
 			return
 		}
-		
+		response["moduleType"] = data.ModuleType
+		h.sendActionResult(session, playerID, msg, WebSocketMessage{
+			Type:     "moduleActionResult",
+			PlayerID: playerID,
+			Data:     mustMarshal(response),
+		})
+
+	case "answerNeedy":
 		var data struct {
-			ModuleIndex int `json:"moduleIndex"`
+			ModuleIndex int    `json:"moduleIndex"`
+			Answer      string `json:"answer"` // "Y"/"YES" or "N"/"NO"
 		}
-		if err := json.Unmarshal(msg.Data, &data); err != nil {
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
 			return
 		}
-		
-		correct := session.Bomb.HoldButton(data.ModuleIndex)
-		
-		// Broadcast updated state to all players (gauge colors may have changed)
-		h.broadcastGameState(session)
-		
-		// Send response to the player who is holding the button via their connection channel
-		player, exists := session.GetPlayer(playerID)
-		if exists && player.Conn != nil {
-			response := WebSocketMessage{
-				Type:     "buttonActionResult",
-				PlayerID: playerID,
-				Data:     mustMarshal(map[string]interface{}{"correct": correct, "moduleIndex": data.ModuleIndex, "action": "hold"}),
-			}
-			responseBytes, _ := json.Marshal(response)
-			select {
-			case player.Conn.Send <- responseBytes:
-			default:
-				// Channel full, skip
-			}
-		}
-		
-	case "buttonRelease":
-		// Only allow releasing buttons if game is active
-		if session.GetLobbyState() != models.LobbyStateActive || session.Bomb == nil {
+
+		var correct, strike bool
+		err := session.DoPlayerAction(playerAction(playerID, msg, moduleTypeByMessage[msg.Type], data.ModuleIndex), func(bomb *models.Bomb) {
+			strikesBefore := bomb.Strikes
+			correct = bomb.AnswerNeedy(data.ModuleIndex, data.Answer, playerID)
+			strike = bomb.Strikes > strikesBefore
+		})
+		if err != nil {
+			// Only allow answering needy modules while the game is active
+			logger.Debug("action rejected", "type", msg.Type, "error", err)
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
 			return
 		}
-		
+
+		// Broadcast updated state to all players (the prompt is gone)
+		h.broadcastGameState(session)
+
+		// Send the response to the player who answered and the other defusers
+		h.sendActionResult(session, playerID, msg, WebSocketMessage{
+			Type:     "needyAnswerResult",
+			PlayerID: playerID,
+			Data: mustMarshal(map[string]interface{}{
+				"correct":     correct,
+				"strike":      strike,
+				"moduleIndex": data.ModuleIndex,
+			}),
+		})
+
+	case "startDischarge", "stopDischarge":
 		var data struct {
 			ModuleIndex int `json:"moduleIndex"`
 		}
-		if err := json.Unmarshal(msg.Data, &data); err != nil {
-			return
-		}
-		
-		correct := session.Bomb.ReleaseButton(data.ModuleIndex)
-		
-		// Broadcast updated state to all players
-		h.broadcastGameState(session)
-		
-		// Send response to the player who released the button via their connection channel
-		player, exists := session.GetPlayer(playerID)
-		if exists && player.Conn != nil {
-			response := WebSocketMessage{
-				Type:     "buttonActionResult",
-				PlayerID: playerID,
-				Data:     mustMarshal(map[string]interface{}{"correct": correct, "moduleIndex": data.ModuleIndex, "action": "release"}),
-			}
-			responseBytes, _ := json.Marshal(response)
-			select {
-			case player.Conn.Send <- responseBytes:
-			default:
-				// Channel full, skip
-			}
-		}
-		
-	case "terminalCommand":
-		// Only allow entering terminal commands if game is active
-		if session.GetLobbyState() != models.LobbyStateActive || session.Bomb == nil {
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
 			return
 		}
-		
-		var data struct {
-			ModuleIndex int    `json:"moduleIndex"`
-			Command     string `json:"command"`
-		}
-		if err := json.Unmarshal(msg.Data, &data); err != nil {
+
+		err := session.DoPlayerAction(playerAction(playerID, msg, moduleTypeByMessage[msg.Type], data.ModuleIndex), func(bomb *models.Bomb) {
+			bomb.SetDischarging(data.ModuleIndex, msg.Type == "startDischarge")
+		})
+		if err != nil {
+			// Only allow discharging while the game is active
+			logger.Debug("action rejected", "type", msg.Type, "error", err)
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
 			return
 		}
-		
-		correct := session.Bomb.EnterTerminalCommand(data.ModuleIndex, data.Command)
-		
-		// Broadcast updated state to all players
+
+		// Broadcast updated state so the gauge reflects the lever right away
 		h.broadcastGameState(session)
-		
-		// Send response to the player who entered the command via their connection channel
-		player, exists := session.GetPlayer(playerID)
-		if exists && player.Conn != nil {
-			response := WebSocketMessage{
-				Type:     "terminalCommandResult",
-				PlayerID: playerID,
-				Data:     mustMarshal(map[string]interface{}{"correct": correct, "moduleIndex": data.ModuleIndex, "command": data.Command}),
-			}
-			responseBytes, _ := json.Marshal(response)
-			select {
-			case player.Conn.Send <- responseBytes:
-			default:
-				// Channel full, skip
-			}
-		}
-		
+
 	case "updateLobbySettings":
 		// Only allow host to update settings, and only in waiting state
-		if session.GetLobbyState() != models.LobbyStateWaiting {
+		if !h.requireLobby(session, playerID, msg) {
 			return
 		}
-		
-		if !session.IsHost(playerID) {
+
+		if !h.requireHost(session, playerID, msg) {
 			return
 		}
-		
-		var data struct {
-			ModuleCount     int    `json:"moduleCount"`
-			DefuserID       string `json:"defuserId"`
-			IsRandomDefuser bool   `json:"isRandomDefuser"`
-			TimeLimit       int    `json:"timeLimit"`
-		}
-		if err := json.Unmarshal(msg.Data, &data); err != nil {
+
+		var data UpdateLobbySettingsRequest
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
 			return
 		}
-		
-		// Update module count
-		if data.ModuleCount > 0 {
-			if err := session.SetModuleCount(data.ModuleCount); err != nil {
-				return
-			}
-		}
-		
-		// Update defuser settings
-		session.SetDefuser(data.DefuserID, data.IsRandomDefuser)
-		
-		// Update time limit
-		if data.TimeLimit > 0 {
-			if err := session.SetTimeLimit(data.TimeLimit); err != nil {
-				return
-			}
+
+		if err := applyLobbySettings(session, &data); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+			return
 		}
-		
+
 		// Broadcast lobby update
 		h.broadcastLobbyUpdate(session)
-		
+
 	case "startGame":
 		// Only allow host to start game, and only in waiting state
-		if session.GetLobbyState() != models.LobbyStateWaiting {
+		if !h.requireLobby(session, playerID, msg) {
 			return
 		}
-		
-		if !session.IsHost(playerID) {
+
+		if !h.requireHost(session, playerID, msg) {
 			return
 		}
-		
+
 		// Start the game
 		if err := h.gameService.StartGame(session.ID); err != nil {
 			// Send error to host
-			player, exists := session.GetPlayer(playerID)
-			if exists && player.Conn != nil {
-				response := WebSocketMessage{
-					Type:     "error",
-					PlayerID: playerID,
-					Data:     mustMarshal(map[string]interface{}{"message": err.Error()}),
-				}
-				responseBytes, _ := json.Marshal(response)
-				select {
-				case player.Conn.Send <- responseBytes:
-				default:
-				}
-			}
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
 			return
 		}
-		
+
 		// Refresh session
 		session, _ = h.gameService.GetSession(session.ID)
-		
+
 		// Broadcast lobby update with updated player types
 		h.broadcastLobbyUpdate(session)
-		
+
 		// Start broadcast loop if not already running
-		if session.StartBroadcast() {
-			go h.broadcastLoop(session)
-		}
-		
+		h.startBroadcastLoop(session)
+
 		// Broadcast game starting message
 		h.broadcastGameStarting(session)
-		
+
 		// Broadcast initial game state
 		h.broadcastGameState(session)
-		
-	case "returnToLobby":
-		// Only allow host to return to lobby
-		if !session.IsHost(playerID) {
+
+	case "returnToLobby", "cancelStart":
+		// Only allow host to return to lobby (or cancel the start during the countdown)
+		if !h.requireHost(session, playerID, msg) {
 			return
 		}
-		
+
 		// Return to lobby
 		if err := h.gameService.ReturnToLobby(session.ID, playerID); err != nil {
 			// Send error to host
-			player, exists := session.GetPlayer(playerID)
-			if exists && player.Conn != nil {
-				response := WebSocketMessage{
-					Type:     "error",
-					PlayerID: playerID,
-					Data:     mustMarshal(map[string]interface{}{"message": err.Error()}),
-				}
-				responseBytes, _ := json.Marshal(response)
-				select {
-				case player.Conn.Send <- responseBytes:
-				default:
-				}
-			}
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
 			return
 		}
-		
+
 		// Refresh session
 		session, _ = h.gameService.GetSession(session.ID)
-		
+
 		// Broadcast returned to lobby message
 		h.broadcastReturnedToLobby(session)
-		
+
 		// Broadcast updated lobby state
 		h.broadcastLobbyUpdate(session)
-		
-	case "updatePlayerName":
-		// Allow any player to rename themselves, but only in waiting state
-		if session.GetLobbyState() != models.LobbyStateWaiting {
+
+	case "pauseGame", "resumeGame":
+		// Only the host can pause or resume the game
+		if !h.requireHost(session, playerID, msg) {
+			return
+		}
+
+		if err := setGamePaused(session, msg.Type == "pauseGame"); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
 			return
 		}
-		
+
+		// Push the frozen/unfrozen state right away instead of waiting for the next tick
+		h.broadcastGameState(session)
+
+	case "setName", "updatePlayerName":
+		// Allow any player to rename themselves, but only in the lobby
 		var data struct {
 			Name string `json:"name"`
 		}
-		if err := json.Unmarshal(msg.Data, &data); err != nil {
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
 			return
 		}
-		
-		// Validate name is not empty
-		if data.Name == "" {
+
+		// Update player name (validates length and rejects renames during a game)
+		if err := session.SetPlayerName(playerID, data.Name); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
 			return
 		}
-		
-		// Update player name
-		if err := session.SetPlayerName(playerID, data.Name); err != nil {
+
+		// Broadcast lobby update
+		h.broadcastLobbyUpdate(session)
+
+	case "kickPlayer":
+		// Only the host can kick players
+		if !h.requireHost(session, playerID, msg) {
+			return
+		}
+
+		var data struct {
+			PlayerID string `json:"playerId"`
+		}
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+			return
+		}
+
+		if err := kickPlayer(session, data.PlayerID, kickedByHostReason); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+		}
+
+	case "closeSession":
+		// Only the host can close the session
+		if !h.requireHost(session, playerID, msg) {
+			return
+		}
+
+		if err := closeSession(h.gameService, session, sessionClosedByHostReason); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+		}
+
+	case "transferHost":
+		// Only the current host can hand over the host role
+		if !h.requireHost(session, playerID, msg) {
+			return
+		}
+
+		var data struct {
+			PlayerID string `json:"playerId"`
+		}
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+			return
+		}
+
+		if err := session.TransferHost(data.PlayerID); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
 			return
 		}
-		
+
+		// Broadcast lobby update so clients re-render who the host is
+		h.broadcastLobbyUpdate(session)
+		h.sendYouAreHost(session, data.PlayerID)
+
+	case "chat":
+		var data struct {
+			Text string `json:"text"`
+		}
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+			return
+		}
+
+		// Drop empty messages
+		text := strings.TrimSpace(data.Text)
+		if text == "" {
+			return
+		}
+
+		if !session.AllowChatMessage(playerID) {
+			h.sendError(session, playerID, msg, ErrCodeRateLimited, "you are sending messages too fast")
+			return
+		}
+
+		player, exists := session.GetPlayer(playerID)
+		if !exists {
+			return
+		}
+
+		// Stamp the message with the sender and relay it to everyone
+		chatMsg := WebSocketMessage{
+			Type:      "chat",
+			SessionID: session.ID,
+			PlayerID:  playerID,
+			Data: mustMarshal(map[string]interface{}{
+				"playerId":  playerID,
+				"name":      player.Name,
+				"role":      player.Type,
+				"text":      text,
+				"timestamp": time.Now().UnixMilli(),
+			}),
+		}
+		chatMsgBytes, _ := json.Marshal(chatMsg)
+		session.Broadcast(chatMsgBytes)
+
+	case "setReady":
+		// Ready flags only matter in the lobby
+		if !h.requireLobby(session, playerID, msg) {
+			return
+		}
+
+		// Toggle unless an explicit ready value is given
+		var data struct {
+			Ready *bool `json:"ready"`
+		}
+		if len(msg.Data) > 0 {
+			if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+				return
+			}
+		}
+
+		if err := session.SetPlayerReady(playerID, data.Ready); err != nil {
+			logger.Debug("ready toggle rejected", "error", err)
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+			return
+		}
+
 		// Broadcast lobby update
 		h.broadcastLobbyUpdate(session)
-		
+
+	case "setRolePreference":
+		// Preferences only matter for the next start
+		if !h.requireLobby(session, playerID, msg) {
+			return
+		}
+
+		var data struct {
+			Preference models.RolePreference `json:"preference"`
+		}
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+			return
+		}
+
+		if err := session.SetRolePreference(playerID, data.Preference); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+			return
+		}
+
+		// Broadcast lobby update, who is up next may have changed
+		h.broadcastLobbyUpdate(session)
+
+	case "setLanguage":
+		var data struct {
+			Language string `json:"language"`
+		}
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+			return
+		}
+
+		if err := session.SetPlayerLanguage(playerID, data.Language); err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+			return
+		}
+
+		// A player reading the manual gets it again in their language
+		player, exists := session.GetPlayer(playerID)
+		if exists && player.Conn != nil && session.GetCurrentBomb() != nil {
+			h.sendGameStateToConnection(player.Conn, session, playerID)
+		}
+
+	case "focusModule":
+		// The defuser opened a module, or left it with an empty ID
+		var data struct {
+			ModuleID string `json:"moduleId"`
+		}
+		if !h.decodeMessageData(session, playerID, msg, &data, logger) {
+			return
+		}
+
+		focus, err := session.SetDefuserFocus(playerID, data.ModuleID)
+		if err != nil {
+			h.sendError(session, playerID, msg, errorCodeFor(err), err.Error())
+			return
+		}
+		h.broadcastDefuserFocus(session, focus)
+
+	case "requestState":
+		// A client that missed messages (a state delta, or anything while its tab was suspended)
+		// asks for a full snapshot to build on again
+		player, exists := session.GetPlayer(playerID)
+		if exists && player.Conn != nil {
+			h.sendFullStateToConnection(player.Conn, session, playerID)
+			player.Conn.TrySend(stateSyncedMessage(session))
+		}
+
 	case "ping":
 		// Respond to ping via connection channel
+		// The client's timestamp is echoed back so it can measure its own round trip
+		var data struct {
+			ClientTime int64 `json:"clientTime"`
+		}
+		if len(msg.Data) > 0 && !h.decodeMessageData(session, playerID, msg, &data, logger) {
+			return
+		}
 		player, exists := session.GetPlayer(playerID)
 		if exists && player.Conn != nil {
-			response := WebSocketMessage{Type: "pong"}
-			responseBytes, _ := json.Marshal(response)
-			select {
-			case player.Conn.Send <- responseBytes:
-			default:
-				// Channel full, skip
+			response := WebSocketMessage{
+				Type: "pong",
+				Data: mustMarshal(map[string]interface{}{
+					"clientTime": data.ClientTime,
+					"serverTime": time.Now().UnixMilli(),
+					"rttMs":      player.LatencyMillis(),
+				}),
 			}
+			responseBytes, _ := json.Marshal(response)
+			player.Conn.TrySend(responseBytes)
 		}
+
+	default:
+		h.sendError(session, playerID, msg, ErrCodeUnknownMessageType, fmt.Sprintf("unknown message type %q", msg.Type))
+	}
+
+	// Announce the strikes and solved modules the message caused
+	h.broadcastBombEvents(session)
+}
+
+// actionRejectionReason names why a defuser action did nothing, for the client's result message
+func actionRejectionReason(err error) string {
+	switch {
+	case errors.Is(err, models.ErrWireAlreadyCut):
+		return "alreadyCut"
+	case errors.Is(err, models.ErrModuleSolved):
+		return "alreadySolved"
+	case errors.Is(err, models.ErrModuleLocked):
+		return "locked"
+	}
+	return "invalid"
+}
+
+// sendToPlayer sends a message to a single player via their connection channel
+func (h *WebSocketHandler) sendToPlayer(session *models.GameSession, playerID string, msg WebSocketMessage) {
+	player, exists := session.GetPlayer(playerID)
+	if !exists || player.Conn == nil {
+		return
+	}
+
+	msgBytes, _ := json.Marshal(msg)
+	player.Conn.TrySend(msgBytes)
+}
+
+// sendActionResult sends the result of a defuser action to the player who acted and to the
+// other defusers sharing the bomb; msg.PlayerID tells them who acted
+// The result echoes the request's ID and is never dropped, see models.Connection.SendReliable
+func (h *WebSocketHandler) sendActionResult(session *models.GameSession, playerID string, request *WebSocketMessage, msg WebSocketMessage) {
+	msg.RequestID = request.RequestID
+	msg.Data = withModuleID(session, request, msg.Data)
+	msgBytes, _ := json.Marshal(msg)
+	if request.RequestID != "" {
+		session.FinishActionRequest(playerID, request.RequestID, msgBytes)
+	}
+	for id, player := range session.GetPlayersCopy() {
+		if player.Conn == nil || (id != playerID && player.Type != models.PlayerTypeDefuser) {
+			continue
+		}
+		player.Conn.SendReliable(msgBytes)
+	}
+}
+
+// acknowledgeActionRequest answers an action with a request ID that got no result message
+// Applied actions are acknowledged for good; rejected ones were already answered with an error
+// (see sendError), anything else is forgotten so that a retry is handled again
+func (h *WebSocketHandler) acknowledgeActionRequest(session *models.GameSession, playerID string, request *WebSocketMessage) {
+	applied, answered, known := session.ActionRequestOutcome(playerID, request.RequestID)
+	if answered || !known {
+		return
+	}
+
+	msgBytes, _ := json.Marshal(WebSocketMessage{
+		Type:      "actionAck",
+		PlayerID:  playerID,
+		RequestID: request.RequestID,
+		Data:      mustMarshal(map[string]interface{}{"action": request.Type, "applied": applied}),
+	})
+	if applied {
+		session.FinishActionRequest(playerID, request.RequestID, msgBytes)
+	} else {
+		session.ForgetActionRequest(playerID, request.RequestID)
+	}
+	if player, exists := session.GetPlayer(playerID); exists && player.Conn != nil {
+		player.Conn.SendReliable(msgBytes)
 	}
 }
 
@@ -539,71 +891,127 @@ func (h *WebSocketHandler) sendGameStateToConnection(wsConn *models.Connection,
 		return
 	}
 
-	var content interface{}
-	var messageType string
+	for _, msgBytes := range gameStateMessages(session, player, session.GetDefuserView()) {
+		wsConn.TrySend(msgBytes)
+	}
+}
+
+// gameStateMessages builds the game state messages for one player
+// Experts get manual content, defusers the bomb state; in practice mode
+// the defuser gets both so a single player can see both sides
+func gameStateMessages(session *models.GameSession, player *models.Player, defuserView *models.DefuserBombView) [][]byte {
+	var messages [][]byte
+	addMessage := func(messageType string, content interface{}) {
+		msg := WebSocketMessage{
+			Type:       messageType,
+			SessionID:  session.ID,
+			Data:       mustMarshal(content),
+			ServerTime: time.Now().UnixMilli(),
+		}
+		msgBytes, _ := json.Marshal(msg)
+		messages = append(messages, msgBytes)
+	}
 
 	if player.Type == models.PlayerTypeExpert {
 		// Send manual content with bomb state to experts (so they can see wire configurations)
-		content = models.GetManualContent(session.Bomb)
-		messageType = "manualContent"
-	} else {
-		// Send bomb state to defusers
-		content = session.Bomb
-		messageType = "gameState"
+		addMessage("manualContent", session.GetManualContentJSON(player.Language))
+		return messages
 	}
 
-	msg := WebSocketMessage{
-		Type:      messageType,
-		SessionID: session.ID,
-		Data:      mustMarshal(content),
+	// Send bomb state to defusers, without solution data
+	// Clients from before the module list only know the per-type lists
+	if defuserView != nil && defuserView.Modules != nil && (player.Conn == nil || player.Conn.ProtocolVersion() < protocolModuleList) {
+		legacyView := *defuserView
+		legacyView.Modules = nil
+		defuserView = &legacyView
 	}
-	msgBytes, _ := json.Marshal(msg)
-	select {
-	case wsConn.Send <- msgBytes:
-	default:
-		// Channel full, skip
+	addMessage("gameState", defuserView)
+	if session.GetPracticeMode() {
+		addMessage("manualContent", session.GetManualContentJSON(player.Language))
 	}
+	return messages
 }
 
 // broadcastGameState broadcasts the current game state to all players in the session
 // Sends bomb state to defusers, manual content to experts
+// Clients get a small tick with the timer and the modules that changed, unless the
+// legacy full state mode is on or they speak the first protocol version; nothing is
+// sent while the bomb state is unchanged
 func (h *WebSocketHandler) broadcastGameState(session *models.GameSession) {
-	if session.Bomb == nil {
+	if session.GetCurrentBomb() == nil {
 		return
 	}
-	
+
+	session.EmitStateDelta(func(delta *models.StateDelta) {
+		// The first version of a bomb has nothing to build on
+		if h.fullState || delta.Version == 1 {
+			h.broadcastFullGameState(session)
+			return
+		}
+		var defuserView *models.DefuserBombView
+		for _, player := range session.GetPlayersCopy() {
+			if player.Conn == nil {
+				continue
+			}
+			var messages [][]byte
+			if player.Conn.ProtocolVersion() < protocolDeltas {
+				// Build the defuser view once, and only if a legacy client needs it
+				if defuserView == nil {
+					defuserView = session.GetDefuserView()
+				}
+				messages = gameStateMessages(session, player, defuserView)
+			} else {
+				messages = stateDeltaMessages(session, player, delta)
+			}
+			for _, msgBytes := range messages {
+				player.Conn.TrySend(msgBytes)
+			}
+		}
+	})
+}
+
+// stateDeltaMessages builds the messages carrying a state delta to one player
+// Everyone gets the tick; players who see the manual get it again when a module changed,
+// since its bomb state includes every module
+func stateDeltaMessages(session *models.GameSession, player *models.Player, delta *models.StateDelta) [][]byte {
+	serverTime := time.Now().UnixMilli()
+	tick, _ := json.Marshal(WebSocketMessage{
+		Type:       "tick",
+		SessionID:  session.ID,
+		Data:       mustMarshal(delta),
+		ServerTime: serverTime,
+	})
+	messages := [][]byte{tick}
+
+	seesManual := player.Type == models.PlayerTypeExpert || session.GetPracticeMode()
+	if seesManual && len(delta.Modules) > 0 {
+		manual, _ := json.Marshal(WebSocketMessage{
+			Type:       "manualContent",
+			SessionID:  session.ID,
+			Data:       session.GetManualContentJSON(player.Language),
+			ServerTime: serverTime,
+		})
+		messages = append(messages, manual)
+	}
+	return messages
+}
+
+// broadcastFullGameState sends every player their full role-specific game state
+func (h *WebSocketHandler) broadcastFullGameState(session *models.GameSession) {
 	// Get players copy to iterate safely
 	playersMap := session.GetPlayersCopy()
-	
-	// Send role-specific content to each player
-	for _, player := range playersMap {
-		var content interface{}
-		var messageType string
 
-		if player.Type == models.PlayerTypeExpert {
-			// Send manual content with bomb state to experts (so they can see wire configurations)
-			content = models.GetManualContent(session.Bomb)
-			messageType = "manualContent"
-		} else {
-			// Send bomb state to defusers
-			content = session.Bomb
-			messageType = "gameState"
-		}
+	// Build the defuser view once for all defusers
+	defuserView := session.GetDefuserView()
 
-		msg := WebSocketMessage{
-			Type:      messageType,
-			SessionID: session.ID,
-			Data:      mustMarshal(content),
-		}
-		msgBytes, _ := json.Marshal(msg)
-		
+	// Send role-specific content to each player
+	for _, player := range playersMap {
 		// Send to specific player's connection
-		if player.Conn != nil {
-			select {
-			case player.Conn.Send <- msgBytes:
-			default:
-				// Channel full, skip
-			}
+		if player.Conn == nil {
+			continue
+		}
+		for _, msgBytes := range gameStateMessages(session, player, defuserView) {
+			player.Conn.TrySend(msgBytes)
 		}
 	}
 }
@@ -611,7 +1019,7 @@ func (h *WebSocketHandler) broadcastGameState(session *models.GameSession) {
 // broadcastLobbyUpdate broadcasts lobby state to all players
 func (h *WebSocketHandler) broadcastLobbyUpdate(session *models.GameSession) {
 	lobbyData := buildLobbyData(session, "")
-	
+
 	msg := WebSocketMessage{
 		Type:      "lobbyUpdate",
 		SessionID: session.ID,
@@ -623,12 +1031,27 @@ func (h *WebSocketHandler) broadcastLobbyUpdate(session *models.GameSession) {
 
 // broadcastGameStarting broadcasts that the game is starting
 func (h *WebSocketHandler) broadcastGameStarting(session *models.GameSession) {
+	session.BroadcastCritical(gameStartingMessage(session))
+}
+
+// gameStartingMessage builds the gameStarting message: the seconds left before the
+// bomb timer starts and the role every player was assigned
+func gameStartingMessage(session *models.GameSession) []byte {
+	roles := make(map[string]models.PlayerType)
+	for id, player := range session.GetPlayersCopy() {
+		roles[id] = player.Type
+	}
+
 	msg := WebSocketMessage{
 		Type:      "gameStarting",
 		SessionID: session.ID,
+		Data: mustMarshal(map[string]interface{}{
+			"countdown": session.GetStartCountdown(),
+			"roles":     roles,
+		}),
 	}
 	msgBytes, _ := json.Marshal(msg)
-	session.Broadcast(msgBytes)
+	return msgBytes
 }
 
 // broadcastReturnedToLobby broadcasts that the game has returned to lobby
@@ -638,46 +1061,298 @@ func (h *WebSocketHandler) broadcastReturnedToLobby(session *models.GameSession)
 		SessionID: session.ID,
 	}
 	msgBytes, _ := json.Marshal(msg)
-	session.Broadcast(msgBytes)
+	session.BroadcastCritical(msgBytes)
+}
+
+// broadcastPlayerPresence tells every player that someone dropped out of or came back to the game
+func (h *WebSocketHandler) broadcastPlayerPresence(session *models.GameSession, messageType string, drop *models.PlayerDrop) {
+	msg := WebSocketMessage{
+		Type:      messageType,
+		SessionID: session.ID,
+		PlayerID:  drop.PlayerID,
+		Data:      mustMarshal(drop),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	session.BroadcastCritical(msgBytes)
+}
+
+// sendFullStateToConnection sends a player everything needed to show the session as it is now:
+// the lobby while waiting, the role-specific game state once the game starts, and how the game
+// ended once it is over
+func (h *WebSocketHandler) sendFullStateToConnection(wsConn *models.Connection, session *models.GameSession, playerID string) {
+	state := session.GetLobbyState()
+	if state == models.LobbyStateWaiting {
+		h.sendLobbyStateToConnection(wsConn, session, playerID)
+		return
+	}
+	if session.GetCurrentBomb() == nil {
+		return
+	}
+
+	// Players connecting during the countdown learn their role and when the bomb starts
+	if state == models.LobbyStateStarting {
+		wsConn.TrySend(gameStartingMessage(session))
+	}
+	h.sendGameStateToConnection(wsConn, session, playerID)
+	for _, focus := range session.GetDefuserFocus() {
+		wsConn.TrySend(defuserFocusMessage(session, focus))
+	}
+	if !session.IsGameRunning() {
+		if msgBytes := gameOverMessage(session); msgBytes != nil {
+			wsConn.TrySend(msgBytes)
+		}
+		if msgBytes := missionResultsMessage(session); msgBytes != nil {
+			wsConn.TrySend(msgBytes)
+		}
+	}
+}
+
+// stateSyncedMessage builds the stateSynced message ending a requestState answer
+// It carries the lobby state and the state version the snapshot matches, so the client
+// can check later deltas build on it
+func stateSyncedMessage(session *models.GameSession) []byte {
+	msg := WebSocketMessage{
+		Type:      "stateSynced",
+		SessionID: session.ID,
+		Data: mustMarshal(map[string]interface{}{
+			"lobbyState": session.GetLobbyState(),
+			"version":    session.GetStateVersion(),
+		}),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	return msgBytes
 }
 
 // sendLobbyStateToConnection sends the current lobby state to a connection
 func (h *WebSocketHandler) sendLobbyStateToConnection(wsConn *models.Connection, session *models.GameSession, playerID string) {
 	lobbyData := buildLobbyData(session, playerID)
-	
+
 	msg := WebSocketMessage{
 		Type:      "lobbyUpdate",
 		SessionID: session.ID,
 		Data:      mustMarshal(lobbyData),
 	}
 	msgBytes, _ := json.Marshal(msg)
-	select {
-	case wsConn.Send <- msgBytes:
-	default:
-		// Channel full, skip
+	wsConn.TrySend(msgBytes)
+}
+
+// startBroadcastLoop starts the session's broadcast loop for the current game, unless one already runs
+func (h *WebSocketHandler) startBroadcastLoop(session *models.GameSession) {
+	if stop, ok := session.StartBroadcast(); ok {
+		h.track(func() { h.broadcastLoop(session, stop) })
 	}
 }
 
 // broadcastLoop periodically broadcasts game state updates
-func (h *WebSocketHandler) broadcastLoop(session *models.GameSession) {
+// Each game run owns its loop: it exits when the game ends, when stop is closed
+// (return to lobby) or when the session is deleted, and always releases the session's flag
+func (h *WebSocketHandler) broadcastLoop(session *models.GameSession, stop <-chan struct{}) {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
-	
-	for range ticker.C {
+	defer session.FinishBroadcast(stop)
+
+	for tick, running := 0, true; running; tick++ {
+		select {
+		case <-stop:
+			// Returned to lobby, the next game gets a loop of its own
+			return
+		case <-session.Done():
+			// Session deleted, nobody is left to tell
+			return
+		case <-h.gameService.Context().Done():
+			// Server shutting down
+			return
+		case <-ticker.C:
+		}
+
+		tickStart := time.Now()
 		session.Update()
 		h.broadcastGameState(session)
-		
-		// Stop broadcasting if game is over or bomb is nil (returned to lobby)
-		if session.Bomb == nil || session.Bomb.State != models.BombStateActive {
-			break
+		h.broadcastBombEvents(session)
+		if tick%latencyReportTicks == 0 {
+			h.broadcastLatencyReport(session)
 		}
+		metrics.BroadcastTickSeconds.ObserveSince(tickStart)
+
+		// Stop broadcasting once the mission is over or the game returned to lobby
+		running = session.IsGameRunning()
+	}
+
+	// Only a game that just ended is recorded, not one the host sent back to lobby
+	if result := session.RecordGameResult(); result != nil {
+		h.gameService.PersistGame(session.ID, result)
+		h.gameService.NotifyGameOver(session, result)
+		h.broadcastNewRecords(session, h.gameService.RecordDefusals(session))
+	}
+	for _, focus := range session.ClearDefuserFocus("") {
+		h.broadcastDefuserFocus(session, focus)
 	}
+	h.broadcastGameOver(session)
+	h.broadcastMissionResults(session)
+}
+
+// broadcastLatencyReport sends every player the measured round-trip time of each player
+func (h *WebSocketHandler) broadcastLatencyReport(session *models.GameSession) {
+	msg := WebSocketMessage{
+		Type:      "latencyReport",
+		SessionID: session.ID,
+		Data:      mustMarshal(map[string]interface{}{"players": session.GetLatencies()}),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	session.Broadcast(msgBytes)
+}
+
+// broadcastNewRecords tells the session about each of its defusals that made the leaderboard
+func (h *WebSocketHandler) broadcastNewRecords(session *models.GameSession, placed []service.RankedEntry) {
+	for _, record := range placed {
+		msg := WebSocketMessage{
+			Type:      "newRecord",
+			SessionID: session.ID,
+			Data:      mustMarshal(record),
+		}
+		msgBytes, _ := json.Marshal(msg)
+		session.Broadcast(msgBytes)
+	}
+}
+
+// broadcastBombEvents sends a strike or moduleSolved message to every player, experts included,
+// for each strike and solved module since the last call
+func (h *WebSocketHandler) broadcastBombEvents(session *models.GameSession) {
+	for _, event := range session.TakeBombEvents() {
+		var msg WebSocketMessage
+		switch event.Type {
+		case models.ReplayEventStrike:
+			msg = WebSocketMessage{
+				Type:      "strike",
+				SessionID: session.ID,
+				PlayerID:  event.PlayerID,
+				Data: mustMarshal(map[string]interface{}{
+					"moduleType":  event.ModuleType,
+					"moduleIndex": event.ModuleIndex,
+					"moduleId":    event.ModuleID,
+					"position":    event.Position,
+					"cause":       event.Cause,
+					"strikes":     event.Strikes,
+				}),
+			}
+		case models.ReplayEventModuleSolved:
+			msg = WebSocketMessage{
+				Type:      "moduleSolved",
+				SessionID: session.ID,
+				Data: mustMarshal(map[string]interface{}{
+					"moduleType":  event.ModuleType,
+					"moduleIndex": event.ModuleIndex,
+					"moduleId":    event.ModuleID,
+					"position":    event.Position,
+					"solvedBy":    event.PlayerID,
+				}),
+			}
+		case models.ReplayEventModuleLocked, models.ReplayEventModuleUnlocked:
+			data := map[string]interface{}{
+				"moduleType":  event.ModuleType,
+				"moduleIndex": event.ModuleIndex,
+				"moduleId":    event.ModuleID,
+				"position":    event.Position,
+			}
+			if event.Type == models.ReplayEventModuleLocked {
+				data["seconds"] = event.Seconds
+			}
+			msg = WebSocketMessage{
+				Type:      string(event.Type),
+				SessionID: session.ID,
+				Data:      mustMarshal(data),
+			}
+		default:
+			continue
+		}
+		msgBytes, _ := json.Marshal(msg)
+		session.BroadcastCritical(msgBytes)
+
+		// Nothing is left to look up on a solved module
+		if event.Type == models.ReplayEventModuleSolved {
+			for _, focus := range session.ClearDefuserFocus(event.ModuleID) {
+				h.broadcastDefuserFocus(session, focus)
+			}
+		}
+	}
+}
+
+// broadcastDefuserFocus tells every player which module a defuser has open
+// An empty moduleId means the defuser has no module open anymore
+func (h *WebSocketHandler) broadcastDefuserFocus(session *models.GameSession, focus *models.DefuserFocus) {
+	session.Broadcast(defuserFocusMessage(session, focus))
+}
+
+// defuserFocusMessage builds the defuserFocus message for a defuser's focus
+func defuserFocusMessage(session *models.GameSession, focus *models.DefuserFocus) []byte {
+	msg := WebSocketMessage{
+		Type:      "defuserFocus",
+		SessionID: session.ID,
+		PlayerID:  focus.PlayerID,
+		Data:      mustMarshal(focus),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	return msgBytes
+}
+
+// broadcastGameOver tells every player, experts included, how the game ended
+// Sent once, when the broadcast loop stops
+func (h *WebSocketHandler) broadcastGameOver(session *models.GameSession) {
+	if msgBytes := gameOverMessage(session); msgBytes != nil {
+		session.BroadcastCritical(msgBytes)
+	}
+}
+
+// gameOverMessage builds the gameOver message, nil if no game was played
+func gameOverMessage(session *models.GameSession) []byte {
+	report := session.GetGameOverReport()
+	if report == nil {
+		// Returned to lobby, nothing to report
+		return nil
+	}
+
+	msg := WebSocketMessage{
+		Type:      "gameOver",
+		SessionID: session.ID,
+		Data:      mustMarshal(report),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	return msgBytes
+}
+
+// broadcastMissionResults sends the outcome of every bomb once the mission is over
+func (h *WebSocketHandler) broadcastMissionResults(session *models.GameSession) {
+	if msgBytes := missionResultsMessage(session); msgBytes != nil {
+		session.BroadcastCritical(msgBytes)
+	}
+}
+
+// missionResultsMessage builds the missionResults message, nil if no game was played
+func missionResultsMessage(session *models.GameSession) []byte {
+	bomb := session.GetCurrentBomb()
+	if bomb == nil {
+		// Returned to lobby, nothing to report
+		return nil
+	}
+
+	msg := WebSocketMessage{
+		Type:      "missionResults",
+		SessionID: session.ID,
+		Data: mustMarshal(map[string]interface{}{
+			"result":   bomb.State,
+			"bombs":    session.GetMissionSummary(),
+			"practice": bomb.Practice,
+		}),
+	}
+	msgBytes, _ := json.Marshal(msg)
+	return msgBytes
 }
 
 // Helper functions
 func mustMarshal(v interface{}) json.RawMessage {
-	data, _ := json.Marshal(v)
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Warn("failed to marshal message data", "dataType", fmt.Sprintf("%T", v), "error", err)
+	}
 	return json.RawMessage(data)
 }
-
-
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"bombs/internal/service"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// newWebSocketServer serves the WebSocket endpoint of a new game service over HTTP
+func newWebSocketServer(t *testing.T) (*service.GameService, *httptest.Server) {
+	t.Helper()
+	gameService := service.NewGameService()
+	router := mux.NewRouter()
+	router.HandleFunc("/ws/{sessionId}", NewWebSocketHandler(gameService, nil).HandleWebSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return gameService, server
+}
+
+// dialSession connects to a session and returns its hello, or the HTTP status it was refused with
+func dialSession(t *testing.T, server *httptest.Server, sessionID string, query url.Values) (HelloData, int) {
+	t.Helper()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/" + sessionID + "?" + query.Encode()
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		if resp == nil {
+			t.Fatalf("dial: %v", err)
+		}
+		return HelloData{}, resp.StatusCode
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var msg WebSocketMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "hello" {
+		t.Fatalf("first message = %s, %v; want hello", msg.Type, err)
+	}
+	var hello HelloData
+	json.Unmarshal(msg.Data, &hello)
+	return hello, resp.StatusCode
+}
+
+func TestReconnectKeepsSeatWithToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    func(first, other, stale string) string
+		wantSeat bool
+	}{
+		{"reconnect token", func(first, other, stale string) string { return first }, true},
+		{"no token", func(first, other, stale string) string { return "" }, false},
+		{"other player's token", func(first, other, stale string) string { return other }, false},
+		{"replaced token", func(first, other, stale string) string { return stale }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameService, server := newWebSocketServer(t)
+			session, _ := gameService.CreateSession("host-a", 300, true)
+			first, _ := dialSession(t, server, session.ID, url.Values{})
+			other, _ := dialSession(t, server, session.ID, url.Values{})
+			// A reconnection replaces the token, the one before it is stale
+			stale := first.ReconnectToken
+			reconnected, _ := dialSession(t, server, session.ID, url.Values{"playerId": {first.PlayerID}, "reconnectToken": {stale}})
+			if reconnected.PlayerID != first.PlayerID || reconnected.ReconnectToken == stale {
+				t.Fatalf("reconnected as %s with token %q, want %s with a new token", reconnected.PlayerID, reconnected.ReconnectToken, first.PlayerID)
+			}
+			first = reconnected
+
+			hello, status := dialSession(t, server, session.ID, url.Values{
+				"playerId":       {first.PlayerID},
+				"reconnectToken": {tt.token(first.ReconnectToken, other.ReconnectToken, stale)},
+			})
+			if status != http.StatusSwitchingProtocols {
+				t.Fatalf("status = %d, want a connection", status)
+			}
+			if (hello.PlayerID == first.PlayerID) != tt.wantSeat {
+				t.Errorf("reconnected as %s, first as %s; want the same seat %v", hello.PlayerID, first.PlayerID, tt.wantSeat)
+			}
+			if hello.ReconnectToken == "" {
+				t.Error("no reconnect token issued")
+			}
+		})
+	}
+}
+
+func TestKickedPlayerCannotReconnect(t *testing.T) {
+	gameService, server := newWebSocketServer(t)
+	session, _ := gameService.CreateSession("host-a", 300, true)
+	kicked, _ := dialSession(t, server, session.ID, url.Values{})
+	if _, err := session.KickPlayer(kicked.PlayerID); err != nil {
+		t.Fatalf("KickPlayer: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+	}{
+		{"same ID and token", url.Values{"playerId": {kicked.PlayerID}, "reconnectToken": {kicked.ReconnectToken}}, http.StatusForbidden},
+		{"same ID", url.Values{"playerId": {kicked.PlayerID}}, http.StatusForbidden},
+		{"token under a new ID", url.Values{"playerId": {"player-new"}, "reconnectToken": {kicked.ReconnectToken}}, http.StatusForbidden},
+		{"new player", url.Values{}, http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, status := dialSession(t, server, session.ID, tt.query); status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bombs/internal/metrics"
+	"bombs/internal/models"
+	"encoding/json"
+	"errors"
+	"log/slog"
+)
+
+// ErrorCode tells clients why the server rejected a WebSocket message
+// It is sent in the code field of "error" messages and of rejected action results;
+// clients should branch on it rather than on the human-readable message
+type ErrorCode string
+
+const (
+	ErrCodeMalformedPayload   ErrorCode = "MALFORMED_PAYLOAD"    // The message data doesn't decode or is out of bounds
+	ErrCodeUnknownMessageType ErrorCode = "UNKNOWN_MESSAGE_TYPE" // The server doesn't know the message type
+	ErrCodeNotHost            ErrorCode = "NOT_HOST"             // Host-only message from another player, or without a valid host token
+	ErrCodeNotInLobby         ErrorCode = "NOT_IN_LOBBY"         // Lobby-only message while a game is starting or running
+	ErrCodeGameNotActive      ErrorCode = "GAME_NOT_ACTIVE"      // Game message while no game is being played
+	ErrCodeGamePaused         ErrorCode = "GAME_PAUSED"          // Module action while the game is paused
+	ErrCodeInvalidModuleIndex ErrorCode = "INVALID_MODULE_INDEX" // The bomb has no such module
+	ErrCodeAlreadySolved      ErrorCode = "ALREADY_SOLVED"       // Action on a module that is already solved
+	ErrCodeModuleLocked       ErrorCode = "MODULE_LOCKED"        // Action on a module that is locked out after wrong actions
+	ErrCodeInvalidAction      ErrorCode = "INVALID_ACTION"       // The action doesn't apply to the module (e.g. wire already cut)
+	ErrCodeRateLimited        ErrorCode = "RATE_LIMITED"         // The player is sending messages too fast
+	ErrCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"      // Anything else the server refused (settings, names, ...)
+)
+
+// errorCodeFor returns the error code for an error returned by the models
+func errorCodeFor(err error) ErrorCode {
+	switch {
+	case errors.Is(err, models.ErrGameNotActive):
+		return ErrCodeGameNotActive
+	case errors.Is(err, models.ErrGamePaused):
+		return ErrCodeGamePaused
+	case errors.Is(err, models.ErrNotInLobby):
+		return ErrCodeNotInLobby
+	case errors.Is(err, models.ErrNoSuchModule):
+		return ErrCodeInvalidModuleIndex
+	case errors.Is(err, models.ErrModuleSolved):
+		return ErrCodeAlreadySolved
+	case errors.Is(err, models.ErrModuleLocked):
+		return ErrCodeModuleLocked
+	case errors.Is(err, models.ErrWireAlreadyCut):
+		return ErrCodeInvalidAction
+	}
+	return ErrCodeInvalidRequest
+}
+
+// sendError tells a player why their message was rejected, echoing its request ID
+// A rejected action is forgotten so that retrying it is handled again
+func (h *WebSocketHandler) sendError(session *models.GameSession, playerID string, request *WebSocketMessage, code ErrorCode, message string) {
+	data := map[string]interface{}{"code": code, "message": message}
+	if request.RequestID != "" {
+		data["requestId"] = request.RequestID
+		if actionMessageTypes[request.Type] {
+			session.ForgetActionRequest(playerID, request.RequestID)
+		}
+	}
+
+	player, exists := session.GetPlayer(playerID)
+	if !exists || player.Conn == nil {
+		return
+	}
+	msgBytes, _ := json.Marshal(WebSocketMessage{
+		Type:      "error",
+		PlayerID:  playerID,
+		RequestID: request.RequestID,
+		Data:      mustMarshal(data),
+	})
+	player.Conn.SendReliable(msgBytes)
+}
+
+// decodeMessageData unmarshals the data of a client message
+// Data that doesn't decode is answered with a MALFORMED_PAYLOAD error
+func (h *WebSocketHandler) decodeMessageData(session *models.GameSession, playerID string, msg *WebSocketMessage, v interface{}, logger *slog.Logger) bool {
+	if err := json.Unmarshal(msg.Data, v); err != nil {
+		logger.Debug("rejecting malformed message data", "type", msg.Type, "error", err)
+		h.sendError(session, playerID, msg, ErrCodeMalformedPayload, "message data is malformed")
+		return false
+	}
+	return true
+}
+
+// requireHost reports whether a message comes from the authenticated host, answering NOT_HOST otherwise
+func (h *WebSocketHandler) requireHost(session *models.GameSession, playerID string, msg *WebSocketMessage) bool {
+	if h.isHost(session, playerID, msg) {
+		return true
+	}
+	h.sendError(session, playerID, msg, ErrCodeNotHost, "only the host can do this")
+	return false
+}
+
+// requireRate reports whether a player is under the rate limit of a message, answering RATE_LIMITED otherwise
+// Module actions and sync messages (pings, state requests) have separate limits; other messages have none here
+func (h *WebSocketHandler) requireRate(session *models.GameSession, playerID string, msg *WebSocketMessage) bool {
+	player, exists := session.GetPlayer(playerID)
+	if !exists || player.Conn == nil {
+		return true
+	}
+
+	allowed := true
+	switch {
+	case actionMessageTypes[msg.Type]:
+		allowed = player.Conn.AllowAction()
+	case msg.Type == "ping" || msg.Type == "requestState":
+		allowed = player.Conn.AllowSync()
+	}
+	if allowed {
+		return true
+	}
+	metrics.RateLimited.Inc(msg.Type)
+	h.sendError(session, playerID, msg, ErrCodeRateLimited, "you are sending messages too fast")
+	return false
+}
+
+// requireLobby reports whether the session is in the lobby, answering NOT_IN_LOBBY otherwise
+func (h *WebSocketHandler) requireLobby(session *models.GameSession, playerID string, msg *WebSocketMessage) bool {
+	if session.GetLobbyState() == models.LobbyStateWaiting {
+		return true
+	}
+	h.sendError(session, playerID, msg, ErrCodeNotInLobby, models.ErrNotInLobby.Error())
+	return false
+}
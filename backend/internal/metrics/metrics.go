@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxLabelValues bounds the label values of a labeled counter, so clients
+// sending made-up message types can't grow it forever
+const maxLabelValues = 64
+
+// otherLabel is the label value used once a labeled counter is full
+const otherLabel = "other"
+
+var (
+	// GamesStarted counts games started from the lobby
+	GamesStarted Counter
+	// GamesDefused counts games that ended with every bomb defused
+	GamesDefused Counter
+	// GamesExploded counts games that ended with a bomb exploding
+	GamesExploded Counter
+	// StrikesIssued counts strikes over every bomb
+	StrikesIssued Counter
+	// MessagesIn counts WebSocket messages received, by message type
+	MessagesIn = NewLabeledCounter()
+	// MessagesOut counts WebSocket messages written to sockets, by message type
+	MessagesOut = NewLabeledCounter()
+	// DroppedMessages counts messages dropped because a connection's send channel was full
+	DroppedMessages Counter
+	// SlowClientsClosed counts connections closed because they couldn't keep up with their messages
+	SlowClientsClosed Counter
+	// RateLimited counts WebSocket messages rejected for coming too fast, by message type
+	RateLimited = NewLabeledCounter()
+	// BroadcastTickSeconds measures how long one broadcast loop tick takes
+	BroadcastTickSeconds = NewHistogram([]float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25})
+)
+
+// Counter is a monotonically increasing count, safe for concurrent use without locks
+type Counter struct {
+	value atomic.Int64
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Value returns the current count
+func (c *Counter) Value() int64 {
+	return c.value.Load()
+}
+
+// LabeledCounter is a set of counters keyed by a single label value
+type LabeledCounter struct {
+	counters sync.Map // label value -> *Counter
+	size     atomic.Int64
+}
+
+// NewLabeledCounter creates an empty labeled counter
+func NewLabeledCounter() *LabeledCounter {
+	return &LabeledCounter{}
+}
+
+// Inc adds one to the counter of a label value
+// Values past the first maxLabelValues are counted under "other"
+func (lc *LabeledCounter) Inc(label string) {
+	if counter, ok := lc.counters.Load(label); ok {
+		counter.(*Counter).Inc()
+		return
+	}
+	if lc.size.Load() >= maxLabelValues {
+		label = otherLabel
+	}
+	counter, loaded := lc.counters.LoadOrStore(label, &Counter{})
+	if !loaded {
+		lc.size.Add(1)
+	}
+	counter.(*Counter).Inc()
+}
+
+// Values returns a snapshot of every label value and its count
+func (lc *LabeledCounter) Values() map[string]int64 {
+	values := make(map[string]int64)
+	lc.counters.Range(func(key, value interface{}) bool {
+		values[key.(string)] = value.(*Counter).Value()
+		return true
+	})
+	return values
+}
+
+// Histogram counts observations into fixed buckets, safe for concurrent use without locks
+type Histogram struct {
+	bounds  []float64 // Upper bounds of the buckets, ascending
+	buckets []atomic.Int64
+	count   atomic.Int64
+	sumBits atomic.Uint64 // float64 sum of the observations
+}
+
+// NewHistogram creates a histogram with the given ascending bucket upper bounds
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds:  bounds,
+		buckets: make([]atomic.Int64, len(bounds)),
+	}
+}
+
+// Observe records one value
+func (h *Histogram) Observe(value float64) {
+	if i := sort.SearchFloat64s(h.bounds, value); i < len(h.bounds) {
+		h.buckets[i].Add(1)
+	}
+	h.count.Add(1)
+	for {
+		old := h.sumBits.Load()
+		sum := math.Float64frombits(old) + value
+		if h.sumBits.CompareAndSwap(old, math.Float64bits(sum)) {
+			return
+		}
+	}
+}
+
+// ObserveSince records the time elapsed since start, in seconds
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Gauges reports values computed when metrics are scraped
+type Gauges struct {
+	ActiveSessions   int
+	ConnectedPlayers int
+}
+
+// Handler serves the metrics in the Prometheus text exposition format
+// gauges is called on every scrape for the values that aren't counted as they happen
+func Handler(gauges func() Gauges) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		current := gauges()
+
+		writeGauge(w, "bombs_active_sessions", "Sessions currently kept by the server.", current.ActiveSessions)
+		writeGauge(w, "bombs_connected_players", "Players currently in a session.", current.ConnectedPlayers)
+		writeCounter(w, "bombs_games_started_total", "Games started.", GamesStarted.Value())
+		writeCounter(w, "bombs_games_defused_total", "Games ended with every bomb defused.", GamesDefused.Value())
+		writeCounter(w, "bombs_games_exploded_total", "Games ended with a bomb exploding.", GamesExploded.Value())
+		writeCounter(w, "bombs_strikes_total", "Strikes issued.", StrikesIssued.Value())
+		writeLabeledCounter(w, "bombs_ws_messages_in_total", "WebSocket messages received, by type.", MessagesIn)
+		writeLabeledCounter(w, "bombs_ws_messages_out_total", "WebSocket messages sent, by type.", MessagesOut)
+		writeCounter(w, "bombs_ws_messages_dropped_total", "Messages dropped because a send channel was full.", DroppedMessages.Value())
+		writeCounter(w, "bombs_ws_slow_clients_closed_total", "Connections closed for not keeping up with their messages.", SlowClientsClosed.Value())
+		writeLabeledCounter(w, "bombs_ws_rate_limited_total", "WebSocket messages rejected for coming too fast, by type.", RateLimited)
+		writeHistogram(w, "bombs_broadcast_tick_seconds", "Duration of one broadcast loop tick.", BroadcastTickSeconds)
+	})
+}
+
+func writeGauge(w io.Writer, name, help string, value int) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, value)
+}
+
+func writeCounter(w io.Writer, name, help string, value int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+func writeLabeledCounter(w io.Writer, name, help string, lc *LabeledCounter) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	values := lc.Values()
+	labels := make([]string, 0, len(values))
+	for label := range values {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		fmt.Fprintf(w, "%s{type=%q} %d\n", name, label, values[label])
+	}
+}
+
+func writeHistogram(w io.Writer, name, help string, h *Histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var cumulative int64
+	for i, bound := range h.bounds {
+		cumulative += h.buckets[i].Load()
+		fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", name, bound, cumulative)
+	}
+	count := h.count.Load()
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, count)
+	fmt.Fprintf(w, "%s_sum %g\n%s_count %d\n", name, math.Float64frombits(h.sumBits.Load()), name, count)
+}
+
+// MessageType returns the type of an outgoing WebSocket message without decoding it
+// Messages are marshaled from a struct whose first field is the type
+func MessageType(message []byte) string {
+	const prefix = `{"type":"`
+	if len(message) <= len(prefix) || string(message[:len(prefix)]) != prefix {
+		return "unknown"
+	}
+	for i := len(prefix); i < len(message); i++ {
+		if message[i] == '"' {
+			return string(message[len(prefix):i])
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ActionRecord tells who performed an action on a module and when
+type ActionRecord struct {
+	PlayerID string    `json:"playerId,omitempty"` // Empty for actions no player performed
+	At       time.Time `json:"at"`
+}
+
+// actionBy records an action of a player performed now
+func actionBy(playerID string) ActionRecord {
+	return ActionRecord{PlayerID: playerID, At: time.Now()}
+}
+
+// AttributedAction is one recorded action on a module, for the expert view and the results
+type AttributedAction struct {
+	Action  string `json:"action"`            // "cut", "command", "press", "hold" or "release"
+	Wire    *int   `json:"wire,omitempty"`    // Cuts only: index of the wire
+	Command string `json:"command,omitempty"` // Commands only: the command as entered
+	ActionRecord
+}
+
+// attributedModule is a module that records who performed each action on it
+type attributedModule interface {
+	ActionLog() []AttributedAction
+}
+
+// moduleActionLog returns the recorded actions of a module, nil if it doesn't record them
+func moduleActionLog(module interface{}) []AttributedAction {
+	if attributed, ok := module.(attributedModule); ok {
+		return attributed.ActionLog()
+	}
+	return nil
+}
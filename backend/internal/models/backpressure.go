@@ -0,0 +1,78 @@
+package models
+
+import "bombs/internal/metrics"
+
+// MaxConsecutiveDrops is how many messages in a row a connection may miss before it is closed as too slow
+const MaxConsecutiveDrops = 32
+
+// recordSent notes that a message made it into the send channel
+func (c *Connection) recordSent() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.consecutiveDrops = 0
+}
+
+// recordDrop notes that a message was dropped, closing the connection once too many were dropped in a row
+func (c *Connection) recordDrop() {
+	metrics.DroppedMessages.Inc()
+
+	c.mu.Lock()
+	c.droppedMessages++
+	c.consecutiveDrops++
+	tooSlow := c.consecutiveDrops >= MaxConsecutiveDrops
+	c.mu.Unlock()
+
+	if tooSlow {
+		c.closeSlow()
+	}
+}
+
+// closeSlow closes a connection that can't keep up with its messages
+// The client is told with a policy violation close frame, so it knows to reconnect and resync
+func (c *Connection) closeSlow() {
+	c.mu.Lock()
+	alreadySlow := c.slow
+	c.slow = true
+	c.mu.Unlock()
+
+	if !alreadySlow {
+		metrics.SlowClientsClosed.Inc()
+	}
+	c.Close()
+}
+
+// SendCritical queues a message the client can't do without (strikes, game over, ...)
+// Rather than dropping it, a connection whose send channel is full is closed as too slow
+func (c *Connection) SendCritical(message []byte) bool {
+	if c.trySendQuiet(message) {
+		c.recordSent()
+		return true
+	}
+	c.recordDrop()
+	c.closeSlow()
+	return false
+}
+
+// Slow reports whether the connection was closed for not keeping up with its messages
+func (c *Connection) Slow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.slow
+}
+
+// DroppedMessages returns how many messages the connection dropped because its send channel was full
+func (c *Connection) DroppedMessages() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.droppedMessages
+}
+
+// BroadcastCritical sends a message every player must get to all players in the session
+// Players too slow to take it are disconnected, see Connection.SendCritical
+func (gs *GameSession) BroadcastCritical(message []byte) {
+	for _, player := range gs.GetPlayersCopy() {
+		if player.Conn != nil {
+			player.Conn.SendCritical(message)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "errors"
+
+// ErrPlayerBanned is returned when a player kicked from the session tries to come back
+var ErrPlayerBanned = errors.New("player was kicked from this session")
+
+// banPlayerLocked keeps a kicked player out of the session: their ID and the reconnect token
+// they were issued are refused from then on (caller must hold gs.mu)
+func (gs *GameSession) banPlayerLocked(playerID string) {
+	if gs.bannedIDs == nil {
+		gs.bannedIDs = make(map[string]bool)
+	}
+	gs.bannedIDs[playerID] = true
+	if token, issued := gs.reconnectTokens[playerID]; issued {
+		if gs.bannedTokens == nil {
+			gs.bannedTokens = make(map[string]bool)
+		}
+		gs.bannedTokens[token] = true
+		delete(gs.reconnectTokens, playerID)
+	}
+}
+
+// IsBanned reports whether a connection with this player ID or reconnect token was kicked
+// Either may be empty when the client didn't send it
+func (gs *GameSession) IsBanned(playerID string, reconnectToken string) bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.isBannedLocked(playerID, reconnectToken)
+}
+
+// isBannedLocked is IsBanned for callers holding gs.mu
+func (gs *GameSession) isBannedLocked(playerID string, reconnectToken string) bool {
+	return (playerID != "" && gs.bannedIDs[playerID]) || (reconnectToken != "" && gs.bannedTokens[reconnectToken])
+}
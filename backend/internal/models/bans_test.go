@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestKickedPlayerIsBanned(t *testing.T) {
+	gs := NewGameSession("SESSION", "host", 300, rand.New(rand.NewSource(1)))
+	for _, id := range []string{"host", "player-kicked", "player-left", "player-stays"} {
+		if err := gs.AddPlayer(id, id, PlayerTypeDefuser, NewConnection()); err != nil {
+			t.Fatalf("AddPlayer(%s): %v", id, err)
+		}
+	}
+	kickedToken, _ := gs.IssueReconnectToken("player-kicked")
+	leftToken, _ := gs.IssueReconnectToken("player-left")
+	if _, err := gs.KickPlayer("player-kicked"); err != nil {
+		t.Fatalf("KickPlayer: %v", err)
+	}
+	if _, err := gs.KickPlayer("host"); err == nil {
+		t.Error("the host was kicked")
+	}
+	gs.RemovePlayer("player-left")
+
+	tests := []struct {
+		name     string
+		playerID string
+		token    string
+		want     bool
+	}{
+		{"kicked player", "player-kicked", "", true},
+		{"kicked player's token under a new ID", "player-new", kickedToken, true},
+		{"player who left", "player-left", leftToken, false},
+		{"player still there", "player-stays", "", false},
+		{"host", "host", "", false},
+		{"new player", "player-new", "", false},
+		{"nothing", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gs.IsBanned(tt.playerID, tt.token); got != tt.want {
+				t.Errorf("IsBanned(%q, %q) = %v, want %v", tt.playerID, tt.token, got, tt.want)
+			}
+			if tt.token != "" || tt.playerID == "" {
+				return
+			}
+			if err := gs.AddPlayer(tt.playerID, tt.playerID, PlayerTypeDefuser, NewConnection()); errors.Is(err, ErrPlayerBanned) != tt.want {
+				t.Errorf("AddPlayer(%s) = %v, want banned %v", tt.playerID, err, tt.want)
+			}
+		})
+	}
+	if gs.VerifyReconnectToken("player-kicked", kickedToken) {
+		t.Error("the kicked player's reconnect token is still valid")
+	}
+}
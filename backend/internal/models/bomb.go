@@ -1,9 +1,8 @@
 package models
 
 import (
-	"fmt"
+	"bombs/internal/metrics"
 	"math/rand"
-	"strings"
 	"time"
 )
 
@@ -18,73 +17,448 @@ const (
 
 // Bomb represents the bomb with its modules and state
 type Bomb struct {
-	ID              string                   `json:"id"`
-	State           BombState                `json:"state"`
-	Strikes         int                      `json:"strikes"`
-	MaxStrikes      int                      `json:"maxStrikes"`
-	TimeRemaining   int                      `json:"timeRemaining"` // seconds
-	TimeLimit       int                      `json:"-"`             // initial time limit (not serialized)
-	StartTime       time.Time                `json:"startTime"`
-	WiresModules    []*WiresModule           `json:"wiresModules"`    // Wire modules
-	ButtonModules   []*ButtonModule          `json:"buttonModules"`   // Button modules
-	TerminalModules []*TerminalModule        `json:"terminalModules"` // Terminal modules
-	ModuleRules     map[string]*ModuleManual `json:"moduleRules"`     // Rules for each module type
-	Seed            int64                    `json:"seed"`            // Random seed used for rule generation (ensures manual and modules are aligned)
+	ID                      string                    `json:"id"`
+	State                   BombState                 `json:"state"`
+	Strikes                 int                       `json:"strikes"`
+	MaxStrikes              int                       `json:"maxStrikes"`
+	TimeRemaining           int                       `json:"timeRemaining"`     // seconds
+	SerialNumber            string                    `json:"serialNumber"`      // serial number shown on the bomb casing
+	Batteries               int                       `json:"batteries"`         // number of batteries on the bomb casing
+	Indicators              []Indicator               `json:"indicators"`        // indicator lights on the bomb casing
+	TimeLimit               int                       `json:"-"`                 // initial time limit (not serialized)
+	StrikeTimePenalty       int                       `json:"strikeTimePenalty"` // seconds taken off the timer per strike
+	penaltySeconds          int                       // total seconds lost to strikes so far
+	SpeedMultiplier         float64                   `json:"speedMultiplier"`   // how fast the timer currently ticks
+	TimerAcceleration       bool                      `json:"timerAcceleration"` // whether strikes speed up the timer
+	Paused                  bool                      `json:"paused"`            // timer is frozen and actions are rejected
+	ReleaseWindow           time.Duration             `json:"-"`                 // how far on each side of a button release the target digit is accepted
+	elapsed                 float64                   // seconds of bomb time used so far, scaled by the speed multiplier
+	lastTick                time.Time                 // when elapsed was last advanced
+	StartTime               time.Time                 `json:"startTime"`                         // zero until Start, when the timer starts running
+	WiresModules            []*WiresModule            `json:"wiresModules,omitempty"`            // Wire modules
+	ButtonModules           []*ButtonModule           `json:"buttonModules,omitempty"`           // Button modules
+	TerminalModules         []*TerminalModule         `json:"terminalModules,omitempty"`         // Terminal modules
+	SimonModules            []*SimonModule            `json:"simonModules,omitempty"`            // Simon Says modules
+	KeypadModules           []*KeypadModule           `json:"keypadModules,omitempty"`           // Keypad modules
+	MemoryModules           []*MemoryModule           `json:"memoryModules,omitempty"`           // Memory modules
+	PasswordModules         []*PasswordModule         `json:"passwordModules,omitempty"`         // Password modules
+	MorseModules            []*MorseModule            `json:"morseModules,omitempty"`            // Morse modules
+	WhosOnFirstModules      []*WhosOnFirstModule      `json:"whosOnFirstModules,omitempty"`      // Who's on First modules
+	ComplicatedWiresModules []*ComplicatedWiresModule `json:"complicatedWiresModules,omitempty"` // Complicated wires modules
+	MazeModules             []*MazeModule             `json:"mazeModules,omitempty"`             // Maze modules
+	KnobModules             []*KnobModule             `json:"knobModules,omitempty"`             // Knob modules
+	NeedyVentModules        []*NeedyVentModule        `json:"needyVentModules,omitempty"`        // Needy vent gas modules (never solved)
+	NeedyCapacitorModules   []*NeedyCapacitorModule   `json:"needyCapacitorModules,omitempty"`   // Needy capacitor modules (never solved)
+	Modules                 []Module                  `json:"-"`                                 // Every solvable module, in bomb order (the typed slices above are kept for the JSON shape)
+	ModuleRules             map[string]*ModuleManual  `json:"moduleRules"`                       // Rules for each module type
+	Seed                    int64                     `json:"seed"`                              // Random seed used for rule generation (ensures manual and modules are aligned)
+	ManualCode              string                    `json:"manualCode"`                        // Fingerprint of the manual rules, shown as "MANUAL K7Q2"
+	Difficulty              Difficulty                `json:"difficulty"`                        // Difficulty the bomb was built with
+	RuleOptions             RuleGenOptions            `json:"-"`                                 // Rule generation options of the difficulty, needed to rebuild the manual
+	Practice                bool                      `json:"practice"`                          // Played in practice mode, kept out of stats
+	EndReason               BombEndReason             `json:"endReason,omitempty"`               // Why the bomb stopped, empty while active
+	LastStrike              *StrikeSource             `json:"lastStrike,omitempty"`              // Module that caused the latest strike
+	events                  []ReplayEvent             // Event log replayed after the game (at most MaxReplayEvents)
+	droppedEvents           int                       // Events not logged because the log was full
+	endLogged               bool                      // Whether the game over event is logged
+	pending                 []BombEvent               // Strikes and solved modules not yet announced to the players
+	stats                   map[string]*ModuleStats   // How each module was played, by module ID
+	version                 uint64                    // State version last sent to the players, see GameSession.EmitStateDelta
+	revision                uint64                    // Bumped by every module change, see stateStamp
+	dirty                   map[moduleKey]bool        // Modules changed since the last state delta
+	wireRules               map[int]*WireRuleSet      // Wire rules per wire count, shared by the wires modules and the manual
+}
+
+// DefuserBombView is the bomb state sent to defusers
+// It leaves out solution data (correct wires, rule manuals, seed) that only experts may see
+// Module types the bomb has none of are left out to keep the per-second broadcast small
+type DefuserBombView struct {
+	ID                      string                        `json:"id"`
+	State                   BombState                     `json:"state"`
+	Strikes                 int                           `json:"strikes"`
+	MaxStrikes              int                           `json:"maxStrikes"`
+	TimeRemaining           int                           `json:"timeRemaining"`
+	SerialNumber            string                        `json:"serialNumber"`
+	Batteries               int                           `json:"batteries"`
+	Indicators              []Indicator                   `json:"indicators"`
+	SpeedMultiplier         float64                       `json:"speedMultiplier"`
+	Paused                  bool                          `json:"paused"`
+	Difficulty              Difficulty                    `json:"difficulty"`
+	ManualCode              string                        `json:"manualCode"`           // Fingerprint of the manual the experts should be reading
+	BombIndex               int                           `json:"bombIndex"`            // Position of this bomb in the mission
+	BombCount               int                           `json:"bombCount"`            // Number of bombs in the mission
+	NextBombIn              int                           `json:"nextBombIn,omitempty"` // Seconds before the next bomb starts after a defusal
+	Deadline                int64                         `json:"deadline,omitempty"`   // Unix millis when the timer runs out at its current speed, 0 while it isn't running
+	StartTime               time.Time                     `json:"startTime"`
+	WiresModules            []*WiresModuleView            `json:"wiresModules,omitempty"`
+	ButtonModules           []*ButtonModuleView           `json:"buttonModules,omitempty"`
+	TerminalModules         []*TerminalModuleView         `json:"terminalModules,omitempty"`
+	SimonModules            []*SimonModuleView            `json:"simonModules,omitempty"`
+	KeypadModules           []*KeypadModuleView           `json:"keypadModules,omitempty"`
+	MemoryModules           []*MemoryModuleView           `json:"memoryModules,omitempty"`
+	PasswordModules         []*PasswordModuleView         `json:"passwordModules,omitempty"`
+	MorseModules            []*MorseModuleView            `json:"morseModules,omitempty"`
+	WhosOnFirstModules      []*WhosOnFirstModuleView      `json:"whosOnFirstModules,omitempty"`
+	ComplicatedWiresModules []*ComplicatedWiresModuleView `json:"complicatedWiresModules,omitempty"`
+	MazeModules             []*MazeModuleView             `json:"mazeModules,omitempty"`
+	KnobModules             []*KnobModuleView             `json:"knobModules,omitempty"`
+	NeedyVentModules        []*NeedyVentModuleView        `json:"needyVentModules,omitempty"`
+	NeedyCapacitorModules   []*NeedyCapacitorModuleView   `json:"needyCapacitorModules,omitempty"`
+	Modules                 []ModuleEntry                 `json:"modules,omitempty"` // Every module in canonical order, only sent to clients speaking the module list protocol
+	Version                 uint64                        `json:"version"`           // State version the view matches, later deltas build on it
+}
+
+// DefuserView builds the defuser-facing view of the bomb
+func (b *Bomb) DefuserView() *DefuserBombView {
+	wiresModules := make([]*WiresModuleView, len(b.WiresModules))
+	for i, module := range b.WiresModules {
+		wiresModules[i] = module.DefuserView()
+	}
+
+	buttonModules := make([]*ButtonModuleView, len(b.ButtonModules))
+	for i, module := range b.ButtonModules {
+		buttonModules[i] = module.DefuserView()
+	}
+
+	terminalModules := make([]*TerminalModuleView, len(b.TerminalModules))
+	for i, module := range b.TerminalModules {
+		terminalModules[i] = module.DefuserView()
+	}
+
+	simonModules := make([]*SimonModuleView, len(b.SimonModules))
+	for i, module := range b.SimonModules {
+		simonModules[i] = module.DefuserView()
+	}
+
+	keypadModules := make([]*KeypadModuleView, len(b.KeypadModules))
+	for i, module := range b.KeypadModules {
+		keypadModules[i] = module.DefuserView()
+	}
+
+	memoryModules := make([]*MemoryModuleView, len(b.MemoryModules))
+	for i, module := range b.MemoryModules {
+		memoryModules[i] = module.DefuserView()
+	}
+
+	passwordModules := make([]*PasswordModuleView, len(b.PasswordModules))
+	for i, module := range b.PasswordModules {
+		passwordModules[i] = module.DefuserView()
+	}
+
+	morseModules := make([]*MorseModuleView, len(b.MorseModules))
+	for i, module := range b.MorseModules {
+		morseModules[i] = module.DefuserView()
+	}
+
+	whosOnFirstModules := make([]*WhosOnFirstModuleView, len(b.WhosOnFirstModules))
+	for i, module := range b.WhosOnFirstModules {
+		whosOnFirstModules[i] = module.DefuserView()
+	}
+
+	complicatedWiresModules := make([]*ComplicatedWiresModuleView, len(b.ComplicatedWiresModules))
+	for i, module := range b.ComplicatedWiresModules {
+		complicatedWiresModules[i] = module.DefuserView()
+	}
+
+	mazeModules := make([]*MazeModuleView, len(b.MazeModules))
+	for i, module := range b.MazeModules {
+		mazeModules[i] = module.DefuserView()
+	}
+
+	knobModules := make([]*KnobModuleView, len(b.KnobModules))
+	for i, module := range b.KnobModules {
+		knobModules[i] = module.DefuserView()
+	}
+
+	needyVentModules := make([]*NeedyVentModuleView, len(b.NeedyVentModules))
+	for i, module := range b.NeedyVentModules {
+		needyVentModules[i] = module.DefuserView()
+	}
+
+	needyCapacitorModules := make([]*NeedyCapacitorModuleView, len(b.NeedyCapacitorModules))
+	for i, module := range b.NeedyCapacitorModules {
+		needyCapacitorModules[i] = module.DefuserView()
+	}
+
+	return &DefuserBombView{
+		ID:                      b.ID,
+		State:                   b.State,
+		Strikes:                 b.Strikes,
+		MaxStrikes:              b.MaxStrikes,
+		TimeRemaining:           b.TimeRemaining,
+		SerialNumber:            b.SerialNumber,
+		Batteries:               b.Batteries,
+		Indicators:              b.Indicators,
+		SpeedMultiplier:         b.SpeedMultiplier,
+		Paused:                  b.Paused,
+		Difficulty:              b.Difficulty,
+		ManualCode:              b.ManualCode,
+		Deadline:                b.DeadlineMillis(),
+		StartTime:               b.StartTime,
+		WiresModules:            wiresModules,
+		ButtonModules:           buttonModules,
+		TerminalModules:         terminalModules,
+		SimonModules:            simonModules,
+		KeypadModules:           keypadModules,
+		MemoryModules:           memoryModules,
+		PasswordModules:         passwordModules,
+		MorseModules:            morseModules,
+		WhosOnFirstModules:      whosOnFirstModules,
+		ComplicatedWiresModules: complicatedWiresModules,
+		MazeModules:             mazeModules,
+		KnobModules:             knobModules,
+		NeedyVentModules:        needyVentModules,
+		NeedyCapacitorModules:   needyCapacitorModules,
+		Modules:                 b.ModuleList(),
+		Version:                 b.version,
+	}
+}
+
+const (
+	// DefaultMaxStrikes is the number of strikes that makes the bomb explode by default
+	DefaultMaxStrikes = 3
+	// MinMaxStrikes and MaxMaxStrikes bound the configurable strike limit
+	MinMaxStrikes = 1
+	MaxMaxStrikes = 10
+)
+
+const (
+	// MinModuleCount and MaxModuleCount bound the configurable number of solvable modules
+	MinModuleCount = 3 // One each of wires, button and terminal
+	MaxModuleCount = 12
+	// DefaultModuleCount is the number of modules a new game gets
+	DefaultModuleCount = 6
+)
+
+// MaxStrikeTimePenalty is the largest number of seconds a strike can cost
+const MaxStrikeTimePenalty = 300
+
+// TimerAccelerationStep is how much faster the timer ticks for each strike
+// when timer acceleration is enabled (1.25x after one strike, 1.5x after two...)
+const TimerAccelerationStep = 0.25
+
+// Module types the host can enable in the lobby
+const (
+	ModuleTypeWires            = "wires"
+	ModuleTypeButton           = "button"
+	ModuleTypeTerminal         = "terminal"
+	ModuleTypeSimon            = "simon"
+	ModuleTypeKeypad           = "keypad"
+	ModuleTypeMemory           = "memory"
+	ModuleTypePassword         = "password"
+	ModuleTypeMorse            = "morse"
+	ModuleTypeWhosOnFirst      = "whosOnFirst"
+	ModuleTypeComplicatedWires = "complicatedWires"
+	ModuleTypeMaze             = "maze"
+	ModuleTypeKnob             = "knob"
+)
+
+// Needy module types, used to report which module caused a strike
+// They are not in AllModuleTypes: needy modules are added on top of the module count
+const (
+	ModuleTypeNeedyVent      = "needyVent"
+	ModuleTypeNeedyCapacitor = "needyCapacitor"
+)
+
+// BombEndReason tells why a bomb is no longer active
+type BombEndReason string
+
+const (
+	BombEndDefused    BombEndReason = "defused"    // Every module was solved
+	BombEndTimeout    BombEndReason = "timeout"    // The timer ran out
+	BombEndMaxStrikes BombEndReason = "maxStrikes" // The strike limit was reached
+)
+
+// StrikeSource identifies the module that caused a strike
+// ModuleIndex counts modules of that type, like the per-type actions do
+type StrikeSource struct {
+	ModuleType  string `json:"moduleType"`
+	ModuleIndex int    `json:"moduleIndex"`
+	ModuleID    string `json:"moduleId"`
+}
+
+// AllModuleTypes lists every module type, in the order the random distribution draws them
+var AllModuleTypes = []string{
+	ModuleTypeWires,
+	ModuleTypeButton,
+	ModuleTypeTerminal,
+	ModuleTypeSimon,
+	ModuleTypeKeypad,
+	ModuleTypeMemory,
+	ModuleTypePassword,
+	ModuleTypeMorse,
+	ModuleTypeWhosOnFirst,
+	ModuleTypeComplicatedWires,
+	ModuleTypeMaze,
+	ModuleTypeKnob,
+}
+
+// BombConfig holds the lobby settings used to build a bomb
+type BombConfig struct {
+	TimeLimit   int // Time limit in seconds
+	ModuleCount int // Number of modules (3-MaxModuleCount)
+	MaxStrikes  int // Strikes before the bomb explodes
+	// StrikeTimePenalty is how many seconds each strike takes off the timer
+	StrikeTimePenalty int
+	// TimerAcceleration makes the timer tick faster after each strike
+	TimerAcceleration bool
+	// EnableNeedyModules adds needy modules on top of the regular ones
+	EnableNeedyModules bool
+	// ModuleTypes lists the module types the bomb can use, empty means all of them
+	ModuleTypes []string
+	// ModuleMix sets an explicit number of modules per type
+	// It is only used when it adds up to ModuleCount, otherwise the modules are split randomly
+	ModuleMix map[string]int
+	// Difficulty picks the rule generation options and scales the time limit
+	Difficulty Difficulty
+	// Practice marks the bomb as played in practice mode
+	Practice bool
+	// ReleaseWindow is how far on each side of a button release the target digit is accepted
+	ReleaseWindow time.Duration
+	// StripedWires gives some wires a stripe of a second color, and the wire rules conditions on stripes
+	StripedWires bool
+	// ButtonCyclingGauge makes the gauge of a held button cycle through 2 or 3 colors
+	ButtonCyclingGauge bool
+	// TerminalHardMode makes a wrong terminal command restart the sequence with new texts
+	TerminalHardMode bool
+	// TerminalStrictness is how closely terminal commands have to match, strict if empty
+	TerminalStrictness TerminalStrictness
+	// TerminalSequenceLength is how many commands solve a terminal, 0 for the default
+	TerminalSequenceLength int
+	// TerminalLockoutThreshold is how many wrong commands lock a terminal out, 0 disables the lockout
+	TerminalLockoutThreshold int
+	// TerminalLockoutSeconds is how long a terminal lockout lasts
+	TerminalLockoutSeconds int
+}
+
+// ModuleMixTotal returns the number of modules a module mix adds up to
+func ModuleMixTotal(mix map[string]int) int {
+	total := 0
+	for _, count := range mix {
+		total += count
+	}
+	return total
 }
 
 // NewBomb creates a new bomb with initial configuration
-func NewBomb(id string, timeLimit int, moduleCount int) *Bomb {
+// rng draws the bomb's seed, everything else about the bomb is derived from that seed
+func NewBomb(id string, config BombConfig, rng *rand.Rand) *Bomb {
+	difficulty := config.Difficulty
+	if ValidateDifficulty(difficulty) != nil {
+		difficulty = DifficultyNormal
+	}
+	preset := PresetFor(difficulty)
+	timeLimit := preset.ScaleTimeLimit(config.TimeLimit)
+	moduleCount := config.ModuleCount
+
+	maxStrikes := config.MaxStrikes
+	if maxStrikes < MinMaxStrikes || maxStrikes > MaxMaxStrikes {
+		maxStrikes = DefaultMaxStrikes
+	}
+
 	// Validate module count
 	// Need at least 3 modules to have one of each type (wires, button, terminal)
-	if moduleCount < 3 {
-		moduleCount = 3
+	if moduleCount < MinModuleCount {
+		moduleCount = MinModuleCount
 	}
-	if moduleCount > 6 {
-		moduleCount = 6
+	if moduleCount > MaxModuleCount {
+		moduleCount = MaxModuleCount
 	}
 
 	// Generate a random seed for this bomb
 	// This seed will be used for both manual and module rules to ensure they are aligned
-	seed := rand.Int63()
-
-	// Ensure at least one module of each type, then randomly distribute the remaining
-	// Create a seeded RNG for module type distribution
-	moduleTypeRNG := rand.New(rand.NewSource(seed))
-
-	// Start with at least one of each type
-	numWireModules := 1
-	numButtonModules := 1
-	numTerminalModules := 1
-	remainingModules := moduleCount - 3 // We've already allocated 3 modules
-
-	// Randomly distribute the remaining modules between the three types
-	for remainingModules > 0 {
-		moduleType := moduleTypeRNG.Intn(3) // 0 = wire, 1 = button, 2 = terminal
-		switch moduleType {
-		case 0:
-			numWireModules++
-		case 1:
-			numButtonModules++
-		case 2:
-			numTerminalModules++
+	seed := rng.Int63()
+
+	// Edgework (serial number, batteries, indicators) is derived from the same seed so it matches the rules
+	ctx := NewBombContext(seed)
+
+	moduleCounts := make(map[string]int, len(AllModuleTypes))
+	if mixTotal := ModuleMixTotal(config.ModuleMix); mixTotal > 0 && mixTotal == config.ModuleCount {
+		// The host chose how many modules of each type the bomb gets
+		for moduleType, count := range config.ModuleMix {
+			moduleCounts[moduleType] = count
+		}
+		moduleCount = mixTotal
+	} else {
+		// Ensure at least one module of each base type, then randomly distribute the remaining
+		// Only module types enabled in the lobby are used, all of them by default
+		moduleTypes := config.ModuleTypes
+		if len(moduleTypes) == 0 {
+			moduleTypes = AllModuleTypes
+		}
+
+		// Create a seeded RNG for module type distribution
+		moduleTypeRNG := rand.New(rand.NewSource(seed))
+
+		// Start with one of each enabled base type (wires, button, terminal)
+		remainingModules := moduleCount
+		for _, moduleType := range []string{ModuleTypeWires, ModuleTypeButton, ModuleTypeTerminal} {
+			if indexOf(moduleTypes, moduleType) >= 0 {
+				moduleCounts[moduleType]++
+				remainingModules--
+			}
+		}
+
+		// Deal the remaining modules from a shuffled deck of the enabled module types,
+		// so every type shows up once before any type gets another module
+		deck := []string{}
+		for _, moduleType := range moduleTypes {
+			if moduleCounts[moduleType] == 0 {
+				deck = append(deck, moduleType)
+			}
+		}
+		for remainingModules > 0 {
+			if len(deck) == 0 {
+				deck = append(deck, moduleTypes...)
+			}
+			moduleTypeRNG.Shuffle(len(deck), func(a, b int) {
+				deck[a], deck[b] = deck[b], deck[a]
+			})
+			moduleCounts[deck[0]]++
+			deck = deck[1:]
+			remainingModules--
 		}
-		remainingModules--
 	}
 
-	// Store module rules - each module will have its own manual
+	numWireModules := moduleCounts[ModuleTypeWires]
+	numButtonModules := moduleCounts[ModuleTypeButton]
+	numTerminalModules := moduleCounts[ModuleTypeTerminal]
+	numSimonModules := moduleCounts[ModuleTypeSimon]
+	numKeypadModules := moduleCounts[ModuleTypeKeypad]
+	numMemoryModules := moduleCounts[ModuleTypeMemory]
+	numPasswordModules := moduleCounts[ModuleTypePassword]
+	numMorseModules := moduleCounts[ModuleTypeMorse]
+	numWhosOnFirstModules := moduleCounts[ModuleTypeWhosOnFirst]
+	numComplicatedWiresModules := moduleCounts[ModuleTypeComplicatedWires]
+	numMazeModules := moduleCounts[ModuleTypeMaze]
+	numKnobModules := moduleCounts[ModuleTypeKnob]
+
+	// Store the manual of each module type, keyed like ManualContent.Modules
+	// Every module of a type shares its rules, this is the manual experts read
 	moduleRules := make(map[string]*ModuleManual)
 
-	// Create wire modules - each generates its own rules based on wire count using the random seed
+	// Every solvable module also goes into a single ordered list, in creation order
+	modules := make([]Module, 0, moduleCount)
+
+	// Wire rules are generated once per wire count; modules and the manual share them
+	// The wires manual is always there, older frontends expect it
+	// Striped wires come with rules about stripes
+	ruleOpts := preset.Rules
+	ruleOpts.StripedWires = config.StripedWires
+	wireRules := GenerateWireRuleSets(seed, ruleOpts)
+	moduleRules["wireModule"] = ComprehensiveWireModuleManual(wireRules).ModuleManual()
+	if config.StripedWires {
+		moduleRules["wireModule"].withNote(msg("wires.note.stripes"))
+	}
+
+	// Create wire modules - each picks the rules of its wire count
 	wiresModules := make([]*WiresModule, numWireModules)
 	for i := 0; i < numWireModules; i++ {
 		// Use seed + moduleIndex to differentiate each module's wire generation
-		// But still use the base seed for rules to match the manual
 		moduleSeed := seed + int64(i)*1000000 // Large multiplier to avoid overlap with rule seeds
-		module, moduleManual := NewWiresModuleWithRules(moduleSeed, seed)
+		module, _ := NewWiresModuleWithRules(moduleSeed, wireRules, ctx)
 		wiresModules[i] = module
-
-		// Store manual with module index key (e.g., "wireModule0", "wireModule1")
-		moduleRules[fmt.Sprintf("wireModule%d", i)] = moduleManual
+		modules = append(modules, module)
 	}
 
 	// Create button modules - each generates its own rules using the random seed
@@ -92,323 +466,519 @@ func NewBomb(id string, timeLimit int, moduleCount int) *Bomb {
 	for i := 0; i < numButtonModules; i++ {
 		// Use seed + offset + moduleIndex to differentiate each module's button generation
 		buttonSeed := seed + int64(10000000) + int64(i)*1000000 // Different offset from wire modules
-		module, moduleManual := NewButtonModuleWithRules(buttonSeed, seed)
+		module, moduleManual := NewButtonModuleWithRules(buttonSeed, seed, ctx, preset.Rules)
+		if config.ButtonCyclingGauge {
+			module.CyclingGauge = true
+			moduleManual.withNote(msg("button.note.cyclingGauge", GaugeStageSeconds))
+		}
 		buttonModules[i] = module
+		modules = append(modules, module)
 
-		// Store manual with module index key (e.g., "buttonModule0", "buttonModule1")
-		moduleRules[fmt.Sprintf("buttonModule%d", i)] = moduleManual
+		// All button modules use the rules of the bomb seed
+		moduleRules["buttonModule"] = moduleManual
 	}
 
-	// Create terminal modules - each randomly selects 3 of 20 rules from the comprehensive manual
-	// First, generate the comprehensive manual with 20 rules
-	comprehensiveManual := GenerateComprehensiveTerminalModuleManual(seed)
-	moduleRules["terminalModule"] = comprehensiveManual
+	// Create terminal modules - each randomly selects as many of the 20 rules of the comprehensive
+	// manual as it asks for commands. First, generate the comprehensive manual with 20 rules
+	sequenceLength := config.TerminalSequenceLength
+	if ValidateTerminalSequenceLength(sequenceLength) != nil {
+		sequenceLength = DefaultTerminalSequenceLength
+	}
+	comprehensiveManual := GenerateComprehensiveTerminalModuleManual(seed, preset.Rules, sequenceLength)
+	strictness := config.TerminalStrictness
+	if ValidateTerminalStrictness(strictness) != nil {
+		strictness = TerminalStrictnessStrict
+	}
+	comprehensiveManual.withNote(strictness.note())
+	if config.TerminalHardMode {
+		comprehensiveManual.withNote(msg("terminal.note.hardMode"))
+	}
+	if numTerminalModules > 0 {
+		moduleRules["terminalModule"] = comprehensiveManual
+	}
 
-	// Parse the 20 rules from the manual to create a lookup map
+	// Map the manual's terminal texts to their commands
 	ruleMap := make(map[string]string) // terminal text -> command
 	for _, rule := range comprehensiveManual.Rules {
-		// Parse rule description: "If terminal says \"X\", type Y."
-		// Extract terminal text and command
-		desc := rule.Description
-		if strings.Contains(desc, "If terminal says \"") {
-			start := strings.Index(desc, "\"") + 1
-			end := strings.Index(desc[start:], "\"")
-			if end > 0 {
-				terminalText := desc[start : start+end]
-				// Extract command (after "type ")
-				cmdStart := strings.Index(desc, "type ") + 5
-				cmdEnd := strings.Index(desc[cmdStart:], ".")
-				if cmdEnd > 0 {
-					command := desc[cmdStart : cmdStart+cmdEnd]
-					ruleMap[terminalText] = command
-				}
-			}
+		if rule.TerminalText != "" {
+			ruleMap[rule.TerminalText] = rule.Command
 		}
 	}
 
-	// Create terminal modules - each randomly selects 3 rules
+	// Create terminal modules - each randomly selects sequenceLength rules
 	terminalModules := make([]*TerminalModule, numTerminalModules)
 	for i := 0; i < numTerminalModules; i++ {
 		// Use seed + offset + moduleIndex for deterministic random selection per module
 		moduleRNG := rand.New(rand.NewSource(seed + int64(20000000) + int64(i)*1000000))
 
-		// Get all terminal texts from the rule map
-		allTexts := make([]string, 0, len(ruleMap))
-		for text := range ruleMap {
-			allTexts = append(allTexts, text)
-		}
-
-		// Randomly select 3 unique terminal texts (and their corresponding commands)
-		selectedTexts := make([]string, 0, 3)
-		selectedCommands := make([]string, 0, 3)
-		usedIndices := make(map[int]bool)
-
-		for len(selectedTexts) < 3 && len(selectedTexts) < len(allTexts) {
-			idx := moduleRNG.Intn(len(allTexts))
-			if !usedIndices[idx] {
-				usedIndices[idx] = true
-				text := allTexts[idx]
-				selectedTexts = append(selectedTexts, text)
-				selectedCommands = append(selectedCommands, ruleMap[text])
-			}
-		}
-
-		// Create rule set for this module
-		rules := make([]TerminalRule, 0, 3)
-		for j := 0; j < len(selectedTexts); j++ {
-			text := selectedTexts[j]
-			cmd := selectedCommands[j]
-
-			evaluator := func(inputText string) string {
-				if strings.Contains(strings.ToUpper(inputText), strings.ToUpper(text)) {
-					return cmd
-				}
-				return ""
-			}
-
-			rules = append(rules, TerminalRule{
-				Number:      j + 1,
-				Description: fmt.Sprintf("If terminal says \"%s\", type %s.", text, cmd),
-				Evaluator:   evaluator,
-				Command:     cmd,
-			})
-		}
-
-		ruleSet := &TerminalRuleSet{Rules: rules}
+		// Randomly select unique terminal texts (and their corresponding commands)
+		selectedTexts, selectedCommands := drawTerminalSequence(moduleRNG, ruleMap, sequenceLength)
 
 		module := &TerminalModule{
-			TerminalTexts:   selectedTexts,
-			CurrentStep:     0,
-			EnteredCommands: []string{},
-			CorrectCommands: selectedCommands,
-			IsSolved:        false,
-			RuleSet:         ruleSet,
-			TerminalSeed:    seed + int64(20000000) + int64(i)*1000000,
+			TerminalTexts:    selectedTexts,
+			CurrentStep:      0,
+			EnteredCommands:  []EnteredCommand{},
+			CorrectCommands:  selectedCommands,
+			IsSolved:         false,
+			RuleSet:          newTerminalRuleSet(selectedTexts, selectedCommands),
+			TerminalSeed:     seed + int64(20000000) + int64(i)*1000000,
+			HardMode:         config.TerminalHardMode,
+			Strictness:       strictness,
+			LockoutThreshold: config.TerminalLockoutThreshold,
+			LockoutSeconds:   config.TerminalLockoutSeconds,
+			commandTable:     ruleMap,
+			manual:           comprehensiveManual,
 		}
 		terminalModules[i] = module
-	}
-
-	return &Bomb{
-		ID:              id,
-		State:           BombStateActive,
-		Strikes:         0,
-		MaxStrikes:      3,
-		TimeRemaining:   timeLimit,
-		TimeLimit:       timeLimit,
-		StartTime:       time.Now(),
-		WiresModules:    wiresModules,
-		ButtonModules:   buttonModules,
-		TerminalModules: terminalModules,
-		ModuleRules:     moduleRules,
-		Seed:            seed,
-	}
+		modules = append(modules, module)
+	}
+
+	// Create Simon modules - all of them share the same color translation tables
+	simonModules := make([]*SimonModule, numSimonModules)
+	for i := 0; i < numSimonModules; i++ {
+		simonSeed := seed + int64(50000000) + int64(i)*1000000
+		module, moduleManual := NewSimonModuleWithRules(simonSeed, seed)
+		simonModules[i] = module
+		modules = append(modules, module)
+		moduleRules["simonModule"] = moduleManual
+	}
+
+	// Create keypad modules - all of them share the same rules
+	keypadModules := make([]*KeypadModule, numKeypadModules)
+	for i := 0; i < numKeypadModules; i++ {
+		keypadSeed := seed + int64(60000000) + int64(i)*1000000
+		module, moduleManual := NewKeypadModuleWithRules(keypadSeed, seed)
+		keypadModules[i] = module
+		modules = append(modules, module)
+		moduleRules["keypadModule"] = moduleManual
+	}
+
+	// Create memory modules - all of them share the same rules
+	memoryModules := make([]*MemoryModule, numMemoryModules)
+	for i := 0; i < numMemoryModules; i++ {
+		memorySeed := seed + int64(70000000) + int64(i)*1000000
+		module, moduleManual := NewMemoryModuleWithRules(memorySeed, seed)
+		memoryModules[i] = module
+		modules = append(modules, module)
+		moduleRules["memoryModule"] = moduleManual
+	}
+
+	// Create password modules - all of them share the same rules
+	passwordModules := make([]*PasswordModule, numPasswordModules)
+	for i := 0; i < numPasswordModules; i++ {
+		passwordSeed := seed + int64(80000000) + int64(i)*1000000
+		module, moduleManual := NewPasswordModuleWithRules(passwordSeed, seed)
+		passwordModules[i] = module
+		modules = append(modules, module)
+		moduleRules["passwordModule"] = moduleManual
+	}
+
+	// Create Morse modules - all of them share the same rules
+	morseModules := make([]*MorseModule, numMorseModules)
+	for i := 0; i < numMorseModules; i++ {
+		morseSeed := seed + int64(90000000) + int64(i)*1000000
+		module, moduleManual := NewMorseModuleWithRules(morseSeed, seed)
+		morseModules[i] = module
+		modules = append(modules, module)
+		moduleRules["morseModule"] = moduleManual
+	}
+
+	// Create Who's on First modules - all of them share the same rules
+	whosOnFirstModules := make([]*WhosOnFirstModule, numWhosOnFirstModules)
+	for i := 0; i < numWhosOnFirstModules; i++ {
+		whosOnFirstSeed := seed + int64(100000000) + int64(i)*1000000
+		module, moduleManual := NewWhosOnFirstModuleWithRules(whosOnFirstSeed, seed)
+		whosOnFirstModules[i] = module
+		modules = append(modules, module)
+		moduleRules["whosOnFirstModule"] = moduleManual
+	}
+
+	// Create complicated wires modules - all of them share the same rules
+	complicatedWiresModules := make([]*ComplicatedWiresModule, numComplicatedWiresModules)
+	for i := 0; i < numComplicatedWiresModules; i++ {
+		complicatedWiresSeed := seed + int64(110000000) + int64(i)*1000000
+		module, moduleManual := NewComplicatedWiresModuleWithRules(complicatedWiresSeed, seed, ctx)
+		complicatedWiresModules[i] = module
+		modules = append(modules, module)
+		moduleRules["complicatedWiresModule"] = moduleManual
+	}
+
+	// Create maze modules - all of them share the same rules
+	mazeModules := make([]*MazeModule, numMazeModules)
+	for i := 0; i < numMazeModules; i++ {
+		mazeSeed := seed + int64(120000000) + int64(i)*1000000
+		module, moduleManual := NewMazeModuleWithRules(mazeSeed, seed)
+		mazeModules[i] = module
+		modules = append(modules, module)
+		moduleRules["mazeModule"] = moduleManual
+	}
+
+	// Create knob modules - all of them share the same rules
+	knobModules := make([]*KnobModule, numKnobModules)
+	for i := 0; i < numKnobModules; i++ {
+		knobSeed := seed + int64(130000000) + int64(i)*1000000
+		module, moduleManual := NewKnobModuleWithRules(knobSeed, seed)
+		knobModules[i] = module
+		modules = append(modules, module)
+		moduleRules["knobModule"] = moduleManual
+	}
+
+	// Needy modules come on top of the regular ones and don't count towards the module count
+	needyVentModules := []*NeedyVentModule{}
+	needyCapacitorModules := []*NeedyCapacitorModule{}
+	if config.EnableNeedyModules {
+		needyVentModules = append(needyVentModules, NewNeedyVentModule(seed+int64(40000000)))
+		moduleRules["needyVentModule"] = GenerateNeedyVentModuleManual()
+		needyCapacitorModules = append(needyCapacitorModules, NewNeedyCapacitorModule(seed+int64(41000000)))
+		moduleRules["needyCapacitorModule"] = GenerateNeedyCapacitorModuleManual()
+	}
+
+	// The timer starts with Start, not when the bomb is built
+	bomb := &Bomb{
+		ID:                      id,
+		State:                   BombStateActive,
+		Strikes:                 0,
+		MaxStrikes:              maxStrikes,
+		StrikeTimePenalty:       config.StrikeTimePenalty,
+		SerialNumber:            ctx.SerialNumber,
+		Batteries:               ctx.Batteries,
+		Indicators:              ctx.Indicators,
+		TimeRemaining:           timeLimit,
+		TimeLimit:               timeLimit,
+		SpeedMultiplier:         1,
+		Difficulty:              difficulty,
+		Practice:                config.Practice,
+		RuleOptions:             ruleOpts,
+		TimerAcceleration:       config.TimerAcceleration,
+		ReleaseWindow:           config.ReleaseWindow,
+		WiresModules:            wiresModules,
+		ButtonModules:           buttonModules,
+		TerminalModules:         terminalModules,
+		SimonModules:            simonModules,
+		KeypadModules:           keypadModules,
+		MemoryModules:           memoryModules,
+		PasswordModules:         passwordModules,
+		MorseModules:            morseModules,
+		WhosOnFirstModules:      whosOnFirstModules,
+		ComplicatedWiresModules: complicatedWiresModules,
+		MazeModules:             mazeModules,
+		KnobModules:             knobModules,
+		NeedyVentModules:        needyVentModules,
+		NeedyCapacitorModules:   needyCapacitorModules,
+		Modules:                 modules,
+		ModuleRules:             moduleRules,
+		Seed:                    seed,
+		wireRules:               wireRules,
+	}
+	bomb.assignModuleIDs()
+	bomb.ManualCode = computeManualCode(bomb)
+	return bomb
 }
 
-// UpdateTimeRemaining updates the time remaining based on elapsed time
-// Also updates gauge colors for button modules
+// UpdateTimeRemaining advances the bomb clock by the time since the last update,
+// scaled by the current speed multiplier, and recomputes the time remaining
 func (b *Bomb) UpdateTimeRemaining() {
-	if b.State != BombStateActive {
+	if b.State != BombStateActive || b.Paused {
 		return
 	}
 
-	elapsed := int(time.Since(b.StartTime).Seconds())
-	b.TimeRemaining = b.TimeLimit - elapsed
+	now := time.Now()
+	b.elapsed += now.Sub(b.lastTick).Seconds() * b.SpeedMultiplier
+	b.lastTick = now
+
+	b.TimeRemaining = b.TimeLimit - int(b.elapsed) - b.penaltySeconds
 
 	if b.TimeRemaining <= 0 {
 		b.State = BombStateExploded
+		b.EndReason = BombEndTimeout
 		b.TimeRemaining = 0
 		return
 	}
 
-	// Gauge colors are now static and only shown when button is pressed
-	// No need to update them here
-}
-
-// AddStrike adds a strike to the bomb
-func (b *Bomb) AddStrike() {
-	b.Strikes++
-	if b.Strikes >= b.MaxStrikes {
-		b.State = BombStateExploded
+	// Drive needy module prompts, a missed prompt is a strike
+	for i, module := range b.NeedyVentModules {
+		before := *module.DefuserView()
+		missed := module.Tick(b.elapsed)
+		if *module.DefuserView() != before {
+			b.markDirty(ModuleTypeNeedyVent, i)
+		}
+		if missed {
+			b.strike(ModuleTypeNeedyVent, i, "", StrikeCauseTimeout)
+			if b.State != BombStateActive {
+				return
+			}
+		}
 	}
-}
 
-// CutWire attempts to cut a wire in a specific wires module
-func (b *Bomb) CutWire(moduleIndex int, wireIndex int) bool {
-	if b.State != BombStateActive {
-		return false
+	// Charge or drain capacitors, a full capacitor is a strike
+	for i, module := range b.NeedyCapacitorModules {
+		before := *module.DefuserView()
+		full := module.Tick(b.elapsed)
+		if *module.DefuserView() != before {
+			b.markDirty(ModuleTypeNeedyCapacitor, i)
+		}
+		if full {
+			b.strike(ModuleTypeNeedyCapacitor, i, "", StrikeCauseTimeout)
+			if b.State != BombStateActive {
+				return
+			}
+		}
 	}
 
-	if moduleIndex < 0 || moduleIndex >= len(b.WiresModules) {
-		return false // Invalid module index
-	}
+	// Count terminal lockouts down
+	b.tickTerminalLockouts()
 
-	module := b.WiresModules[moduleIndex]
-	if module.IsSolved {
-		return false // Already solved
+	// Cycle the gauges of held buttons; a release is judged on the color shown at that time
+	for i, module := range b.ButtonModules {
+		if module == nil {
+			continue
+		}
+		before := module.GaugeColor
+		module.Tick(b.elapsed)
+		if module.GaugeColor != before {
+			b.markDirty(ModuleTypeButton, i)
+		}
 	}
+}
 
-	correct := module.CutWire(wireIndex)
-	if !correct {
-		b.AddStrike()
-		return false
+// Deadline returns when the timer runs out at its current speed, zero while it isn't running
+// Pauses, strike penalties and timer acceleration move it, and every state update carries the new one
+func (b *Bomb) Deadline() time.Time {
+	if b.State != BombStateActive || b.Paused || b.lastTick.IsZero() {
+		return time.Time{}
 	}
-
-	// Check if all modules are solved
-	b.CheckWinCondition()
-
-	return true
+	speed := b.SpeedMultiplier
+	if speed <= 0 {
+		speed = 1
+	}
+	left := (float64(b.TimeLimit-b.penaltySeconds) - b.elapsed) / speed
+	return b.lastTick.Add(time.Duration(left * float64(time.Second)))
 }
 
-// PressButton handles pressing a button in a specific button module
-func (b *Bomb) PressButton(moduleIndex int) bool {
-	if b.State != BombStateActive {
-		return false
+// timeShownAt returns what the timer shows once elapsed seconds of bomb time are used
+func (b *Bomb) timeShownAt(elapsed float64) int {
+	if elapsed < 0 {
+		elapsed = 0
 	}
-
-	if moduleIndex < 0 || moduleIndex >= len(b.ButtonModules) {
-		return false // Invalid module index
+	shown := b.TimeLimit - int(elapsed) - b.penaltySeconds
+	if shown < 0 {
+		return 0
 	}
+	return shown
+}
 
-	module := b.ButtonModules[moduleIndex]
-	if module == nil {
-		return false
-	}
-	if module.IsSolved {
-		return false // Already solved
+// timeShownAround returns the lowest and highest times the timer showed within window of
+// the last timer update, with sub-second precision and at the current speed
+func (b *Bomb) timeShownAround(window time.Duration) (lowest int, highest int) {
+	speed := b.SpeedMultiplier
+	if speed <= 0 {
+		speed = 1
 	}
+	spread := window.Seconds() * speed
+	return b.timeShownAt(b.elapsed + spread), b.timeShownAt(b.elapsed - spread)
+}
 
-	correct := module.PressButton()
-	if !correct {
-		b.AddStrike()
-		return false
+// DeadlineMillis returns the deadline as Unix millis, 0 while the timer isn't running
+func (b *Bomb) DeadlineMillis() int64 {
+	deadline := b.Deadline()
+	if deadline.IsZero() {
+		return 0
 	}
+	return deadline.UnixMilli()
+}
 
-	// Check if all modules are solved
-	b.CheckWinCondition()
+// Start starts the bomb timer from its full time limit
+func (b *Bomb) Start() {
+	now := time.Now()
+	b.StartTime = now
+	b.lastTick = now
+	b.elapsed = 0
+}
 
-	return true
+// Pause freezes the timer, banking the time elapsed so far
+func (b *Bomb) Pause() {
+	b.UpdateTimeRemaining()
+	b.Paused = true
 }
 
-// HoldButton handles holding a button in a specific button module
-func (b *Bomb) HoldButton(moduleIndex int) bool {
-	if b.State != BombStateActive {
-		return false
-	}
+// Resume restarts the timer; the time spent paused doesn't count
+func (b *Bomb) Resume() {
+	b.lastTick = time.Now()
+	b.Paused = false
+}
 
-	if moduleIndex < 0 || moduleIndex >= len(b.ButtonModules) {
-		return false // Invalid module index
-	}
+// AddStrike adds a strike to the bomb
+// If a strike time penalty is set, the timer loses that many seconds and
+// the bomb explodes if that runs the clock out
+func (b *Bomb) AddStrike() {
+	// Bank the time elapsed at the old speed before anything changes
+	b.UpdateTimeRemaining()
 
-	module := b.ButtonModules[moduleIndex]
-	if module == nil {
-		return false
+	b.Strikes++
+	metrics.StrikesIssued.Inc()
+	if b.Strikes >= b.MaxStrikes {
+		b.State = BombStateExploded
+		b.EndReason = BombEndMaxStrikes
+		return
 	}
-	if module.IsSolved {
-		return false // Already solved
+
+	b.applyStrikeSpeed()
+
+	if b.StrikeTimePenalty > 0 {
+		b.penaltySeconds += b.StrikeTimePenalty
+		b.UpdateTimeRemaining()
 	}
+}
 
-	correct := module.HoldButton()
-	if !correct {
-		b.AddStrike()
-		return false
+// applyStrikeSpeed speeds the timer up for the strikes so far, when timer acceleration is on
+func (b *Bomb) applyStrikeSpeed() {
+	if b.TimerAcceleration {
+		b.SpeedMultiplier = 1 + TimerAccelerationStep*float64(b.Strikes)
 	}
+}
 
-	return true
+// strike adds a strike caused by a module, remembering it for the game-over report
+// playerID and cause are who gave it and with which action; a needy module running out
+// passes no player and StrikeCauseTimeout
+func (b *Bomb) strike(moduleType string, moduleIndex int, playerID string, cause string) {
+	moduleID := b.ModuleIDOf(moduleType, moduleIndex)
+	b.LastStrike = &StrikeSource{ModuleType: moduleType, ModuleIndex: moduleIndex, ModuleID: moduleID}
+	b.AddStrike()
+	if playerID != "" {
+		b.recordWrongAttempt(moduleID)
+	}
+	b.logEvent(ReplayEvent{Type: ReplayEventStrike, ModuleType: moduleType, ModuleIndex: &moduleIndex, ModuleID: moduleID})
+	b.announce(BombEvent{Type: ReplayEventStrike, ModuleType: moduleType, ModuleIndex: moduleIndex, ModuleID: moduleID, Position: b.ModulePosition(moduleType, moduleIndex), PlayerID: playerID, Cause: cause, Strikes: b.Strikes})
 }
 
-// ReleaseButton handles releasing a button in a specific button module
-func (b *Bomb) ReleaseButton(moduleIndex int) bool {
+// AnswerNeedy answers the prompt of a needy vent gas module
+// A wrong answer gives a strike; answering while no prompt is shown does nothing
+// playerID is who answered
+func (b *Bomb) AnswerNeedy(moduleIndex int, answer string, playerID string) bool {
 	if b.State != BombStateActive {
 		return false
 	}
 
-	if moduleIndex < 0 || moduleIndex >= len(b.ButtonModules) {
+	if moduleIndex < 0 || moduleIndex >= len(b.NeedyVentModules) {
 		return false // Invalid module index
 	}
+	b.markDirty(ModuleTypeNeedyVent, moduleIndex)
 
-	module := b.ButtonModules[moduleIndex]
-	if module == nil {
-		return false
-	}
-	if module.IsSolved {
-		return false // Already solved
+	module := b.NeedyVentModules[moduleIndex]
+	if !module.Active {
+		return false // Nothing to answer
 	}
 
-	correct := module.ReleaseButton(b.TimeRemaining)
-	if !correct {
-		b.AddStrike()
+	// Bring the bomb clock up to date so the answer isn't judged against a stale deadline
+	b.UpdateTimeRemaining()
+	if b.State != BombStateActive || !module.Active {
 		return false
 	}
 
-	// Check if all modules are solved
-	b.CheckWinCondition()
+	if !module.Answer(answer, b.elapsed) {
+		b.strike(ModuleTypeNeedyVent, moduleIndex, playerID, "answer")
+		return false
+	}
 
 	return true
 }
 
-// EnterTerminalCommand handles entering a command in a specific terminal module
-func (b *Bomb) EnterTerminalCommand(moduleIndex int, command string) bool {
+// SetDischarging starts or stops discharging a needy capacitor module
+// Returns false if the module doesn't exist or the bomb isn't active
+func (b *Bomb) SetDischarging(moduleIndex int, discharging bool) bool {
 	if b.State != BombStateActive {
 		return false
 	}
 
-	if moduleIndex < 0 || moduleIndex >= len(b.TerminalModules) {
+	if moduleIndex < 0 || moduleIndex >= len(b.NeedyCapacitorModules) {
 		return false // Invalid module index
 	}
+	b.markDirty(ModuleTypeNeedyCapacitor, moduleIndex)
 
-	module := b.TerminalModules[moduleIndex]
-	if module == nil {
+	// Bring the charge up to date before switching direction
+	b.UpdateTimeRemaining()
+	if b.State != BombStateActive {
 		return false
 	}
-	if module.IsSolved {
-		return false // Already solved
-	}
 
-	correct := module.EnterCommand(command)
-	if !correct {
-		b.AddStrike()
-		return false
-	}
+	b.NeedyCapacitorModules[moduleIndex].Discharging = discharging
+	return true
+}
 
-	// Check if all modules are solved
-	b.CheckWinCondition()
+// SolvableModuleCount returns the number of modules that must be solved to defuse the bomb
+func (b *Bomb) SolvableModuleCount() int {
+	return len(b.Modules)
+}
 
-	return true
+// ModuleOutcome tells whether one module was solved by the end of the game, and how it was played
+type ModuleOutcome struct {
+	ModuleType  string `json:"moduleType"`
+	ModuleIndex int    `json:"moduleIndex"` // Index among the modules of the same type
+	ModuleID    string `json:"moduleId"`
+	BombIndex   int    `json:"bombIndex"` // Position of the module's bomb in the mission
+	Solved      bool   `json:"solved"`
+	ModuleStats
+	Actions []AttributedAction `json:"actions,omitempty"` // Who did what on the module, for the modules that record it
 }
 
-// CheckWinCondition checks if the bomb is defused
-func (b *Bomb) CheckWinCondition() {
-	allSolved := true
+// GameOverReport is the outcome of a finished bomb, sent to every player when the game ends
+type GameOverReport struct {
+	Outcome       BombState       `json:"outcome"` // Defused or exploded
+	Reason        BombEndReason   `json:"reason"`
+	LastStrike    *StrikeSource   `json:"lastStrike,omitempty"` // Module behind the latest strike, the fatal one on maxStrikes
+	TimeRemaining int             `json:"timeRemaining"`
+	Strikes       int             `json:"strikes"`
+	MaxStrikes    int             `json:"maxStrikes"`
+	Modules       []ModuleOutcome `json:"modules"`
+	Practice      bool            `json:"practice"`
+	BombIndex     int             `json:"bombIndex"` // Position of the bomb in the mission
+	BombCount     int             `json:"bombCount"` // Number of bombs in the mission
+}
 
-	// Check wire modules
-	for _, module := range b.WiresModules {
-		if module != nil && !module.IsSolved {
-			allSolved = false
-			break
+// ModuleOutcomes returns the outcome and stats of every solvable module, in bomb order
+func (b *Bomb) ModuleOutcomes(bombIndex int) []ModuleOutcome {
+	modules := make([]ModuleOutcome, len(b.Modules))
+	typeIndex := make(map[string]int)
+	for i, module := range b.Modules {
+		modules[i] = ModuleOutcome{
+			ModuleType:  module.Type(),
+			ModuleIndex: typeIndex[module.Type()],
+			ModuleID:    module.ModuleID(),
+			BombIndex:   bombIndex,
+			Solved:      module.Solved(),
+			ModuleStats: b.ModuleStats(module.ModuleID()),
+			Actions:     moduleActionLog(module),
 		}
+		typeIndex[module.Type()]++
 	}
+	return modules
+}
 
-	// Check button modules
-	if allSolved {
-		for _, module := range b.ButtonModules {
-			if module != nil && !module.IsSolved {
-				allSolved = false
-				break
-			}
-		}
+// GameOverReport summarizes how the bomb ended
+// The modules are reported as bomb 0 of the mission, see GameSession.GetGameOverReport
+func (b *Bomb) GameOverReport() *GameOverReport {
+	modules := b.ModuleOutcomes(0)
+
+	return &GameOverReport{
+		Outcome:       b.State,
+		Reason:        b.EndReason,
+		LastStrike:    b.LastStrike,
+		TimeRemaining: b.TimeRemaining,
+		Strikes:       b.Strikes,
+		MaxStrikes:    b.MaxStrikes,
+		Modules:       modules,
+		Practice:      b.Practice,
 	}
+}
 
-	// Check terminal modules
-	if allSolved {
-		for _, module := range b.TerminalModules {
-			if module != nil && !module.IsSolved {
-				allSolved = false
-				break
-			}
+// CheckWinCondition checks if the bomb is defused
+// Needy modules can't be solved, so they are left out of the check
+func (b *Bomb) CheckWinCondition() {
+	for _, module := range b.Modules {
+		if !module.Solved() {
+			return
 		}
 	}
-
-	if allSolved {
-		b.State = BombStateDefused
-	}
+	b.State = BombStateDefused
+	b.EndReason = BombEndDefused
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestModuleCountBoundsAgree(t *testing.T) {
+	tests := []struct {
+		count   int
+		wantErr bool
+	}{
+		{MinModuleCount - 1, true},
+		{MinModuleCount, false},
+		{MaxModuleCount, false},
+		{MaxModuleCount + 1, true},
+	}
+	for _, tt := range tests {
+		gs := NewGameSession("SESSION", "host", 300, rand.New(rand.NewSource(1)))
+		if err := gs.SetModuleCount(tt.count); (err != nil) != tt.wantErr {
+			t.Errorf("SetModuleCount(%d) = %v, want error %v", tt.count, err, tt.wantErr)
+		}
+		if err := gs.SetMission([]MissionBomb{{ModuleCount: tt.count, TimeLimit: 300}}); (err != nil) != tt.wantErr {
+			t.Errorf("SetMission with %d modules = %v, want error %v", tt.count, err, tt.wantErr)
+		}
+		if tt.wantErr {
+			continue
+		}
+		bomb := NewBomb("BOMB", BombConfig{TimeLimit: 300, ModuleCount: tt.count, MaxStrikes: 3}, rand.New(rand.NewSource(1)))
+		if len(bomb.Modules) != tt.count {
+			t.Errorf("NewBomb with %d modules built %d", tt.count, len(bomb.Modules))
+		}
+	}
+}
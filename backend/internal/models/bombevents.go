@@ -0,0 +1,42 @@
+package models
+
+// maxPendingBombEvents bounds the events waiting to be announced, older ones are dropped
+const maxPendingBombEvents = 100
+
+// StrikeCauseTimeout is the cause of a strike no player action gave, e.g. a needy module running out
+const StrikeCauseTimeout = "timeout"
+
+// BombEvent is a strike, a solved module or a lockout, announced to the players as it happens
+type BombEvent struct {
+	Type        ReplayEventType // ReplayEventStrike, ReplayEventModuleSolved, ReplayEventModuleLocked or ReplayEventModuleUnlocked
+	ModuleType  string
+	ModuleIndex int    // Index among the modules of that type
+	ModuleID    string // Stable ID of the module
+	Position    int    // Position of the module in the unified module list
+	PlayerID    string // Who caused the strike or solved the module, empty for timeouts
+	Cause       string // Strikes only: the action that caused it, or StrikeCauseTimeout
+	Strikes     int    // Strikes only: the bomb's strike count after it
+	Seconds     int    // Lockouts only: how long the module stays locked
+}
+
+// announce queues an event until the session's events are taken
+func (b *Bomb) announce(event BombEvent) {
+	if len(b.pending) >= maxPendingBombEvents {
+		b.pending = b.pending[1:]
+	}
+	b.pending = append(b.pending, event)
+}
+
+// TakeBombEvents returns the strikes, solved modules and lockouts since the last call, oldest first
+// Events of every bomb of a mission are returned, so none is lost when the next bomb starts
+func (gs *GameSession) TakeBombEvents() []BombEvent {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	var events []BombEvent
+	for _, bomb := range gs.Bombs {
+		events = append(events, bomb.pending...)
+		bomb.pending = nil
+	}
+	return events
+}
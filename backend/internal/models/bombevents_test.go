@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// wrongWire returns the index of a wire that must not be cut
+func wrongWire(module *WiresModule) int {
+	if module.CorrectCut == 0 {
+		return 1
+	}
+	return 0
+}
+
+func TestStrikeEventNamesPlayerAndCause(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending int // Events already waiting to be announced
+	}{
+		{"empty queue", 0},
+		{"full queue", maxPendingBombEvents},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bomb := newTestSession(t, BombConfig{MaxStrikes: 5, ModuleMix: map[string]int{ModuleTypeWires: 3}}, 1).Bombs[0]
+			for i := 0; i < tt.pending; i++ {
+				bomb.announce(BombEvent{Type: ReplayEventModuleSolved})
+			}
+
+			payload, _ := json.Marshal(map[string]int{"wireIndex": wrongWire(bomb.WiresModules[0])})
+			result, err := bomb.HandleModuleAction(ModuleTypeWires, 0, "cut", payload, "player-1", 0)
+			if err != nil || !result.Strike {
+				t.Fatalf("HandleModuleAction = %+v, %v; want a strike", result, err)
+			}
+
+			if len(bomb.pending) > maxPendingBombEvents {
+				t.Errorf("%d events pending, want at most %d", len(bomb.pending), maxPendingBombEvents)
+			}
+			event := bomb.pending[len(bomb.pending)-1]
+			if event.Type != ReplayEventStrike || event.PlayerID != "player-1" || event.Cause != "cut" || event.Strikes != 1 {
+				t.Errorf("strike event = %+v, want player-1 cutting for strike 1", event)
+			}
+			if got := bomb.ModuleStats(bomb.WiresModules[0].ModuleID()).WrongAttempts; got != 1 {
+				t.Errorf("%d wrong attempts recorded, want 1", got)
+			}
+		})
+	}
+}
+
+func TestTimeoutStrikeHasNoPlayer(t *testing.T) {
+	bomb := newTestSession(t, BombConfig{MaxStrikes: 5, ModuleMix: map[string]int{ModuleTypeWires: 3}}, 1).Bombs[0]
+	bomb.strike(ModuleTypeNeedyVent, 0, "", StrikeCauseTimeout)
+
+	event := bomb.pending[len(bomb.pending)-1]
+	if event.PlayerID != "" || event.Cause != StrikeCauseTimeout {
+		t.Errorf("strike event = %+v, want a timeout without player", event)
+	}
+}
@@ -1,7 +1,9 @@
 package models
 
 import (
+	"encoding/json"
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -14,17 +16,27 @@ const (
 	ButtonTextHold     ButtonText = "HOLD"
 	ButtonTextPress    ButtonText = "PRESS"
 	ButtonTextOther    ButtonText = "OTHER"
+	ButtonTextDefuse   ButtonText = "DEFUSE"
+	ButtonTextWait     ButtonText = "WAIT"
 )
 
+// buttonTexts are the texts a button can show
+var buttonTexts = []ButtonText{ButtonTextAbort, ButtonTextDetonate, ButtonTextHold, ButtonTextPress, ButtonTextOther, ButtonTextDefuse, ButtonTextWait}
+
 // ButtonColor represents the color of the button
 type ButtonColor string
 
 const (
-	ButtonColorRed   ButtonColor = "red"
-	ButtonColorBlue  ButtonColor = "blue"
-	ButtonColorWhite ButtonColor = "white"
+	ButtonColorRed    ButtonColor = "red"
+	ButtonColorBlue   ButtonColor = "blue"
+	ButtonColorWhite  ButtonColor = "white"
+	ButtonColorGreen  ButtonColor = "green"
+	ButtonColorYellow ButtonColor = "yellow"
 )
 
+// buttonColors are the colors a button can have
+var buttonColors = []ButtonColor{ButtonColorRed, ButtonColorBlue, ButtonColorWhite, ButtonColorGreen, ButtonColorYellow}
+
 // GaugeColor represents the color of the gauge
 type GaugeColor string
 
@@ -34,6 +46,13 @@ const (
 	GaugeColorWhite GaugeColor = "white"
 )
 
+// Cycling gauges: while a button is held the gauge shows 2 or 3 colors in turn
+const (
+	GaugeStageSeconds = 7 // Bomb clock seconds each color of a cycling gauge is shown
+	MinGaugeStages    = 2
+	MaxGaugeStages    = 3
+)
+
 // ButtonAction represents the action to take for a button
 type ButtonAction string
 
@@ -45,36 +64,72 @@ const (
 
 // ButtonModule represents the button module on the bomb
 type ButtonModule struct {
+	moduleIdentity
 	ButtonText       ButtonText     `json:"buttonText"`
 	ButtonColor      ButtonColor    `json:"buttonColor"`
 	GaugeColor       GaugeColor     `json:"gaugeColor"`
 	IsSolved         bool           `json:"isSolved"`
 	IsPressed        bool           `json:"isPressed"`
-	HoldStartTime    *time.Time     `json:"-"` // When button was pressed (for hold actions)
-	RuleSet          *ButtonRuleSet `json:"-"` // Rules for this module (not serialized)
-	CorrectAction    ButtonAction   `json:"-"` // The correct action to take
-	TargetTimerDigit int            `json:"-"` // Which timer digit to wait for (0-9)
-	ButtonSeed       int64          `json:"-"` // Seed used for this module (for deterministic gauge color selection)
+	HoldStartTime    *time.Time     `json:"-"`            // When button was pressed (for hold actions)
+	RuleSet          *ButtonRuleSet `json:"-"`            // Rules for this module (not serialized)
+	CorrectAction    ButtonAction   `json:"-"`            // The correct action to take
+	TargetTimerDigit int            `json:"-"`            // Which timer digit to wait for (0-9)
+	ButtonSeed       int64          `json:"-"`            // Seed used for this module (for deterministic gauge color selection)
+	PressCount       int            `json:"-"`            // Holds started so far, mixed into the gauge color seed
+	CyclingGauge     bool           `json:"cyclingGauge"` // While held, the gauge cycles through GaugeSchedule
+	GaugeSchedule    []GaugeColor   `json:"-"`            // Colors the gauge cycles through during the current hold
+	holdElapsed      float64        // Bomb clock seconds the button has been held
+	lastTick         float64        // Bomb clock second of the last update
+	Inputs           []ButtonInput  `json:"inputs"` // Presses, holds and releases, with who performed them
+	manual           *ModuleManual  // Manual generated alongside the module
+}
+
+// ButtonInput is a press, hold or release of the button and who performed it
+type ButtonInput struct {
+	Action ButtonAction `json:"action"`
+	ActionRecord
+}
+
+// ButtonModuleView is the defuser-facing view of a button module (no solution data)
+type ButtonModuleView struct {
+	ID          string      `json:"id"` // Stable module ID
+	ButtonText  ButtonText  `json:"buttonText"`
+	ButtonColor ButtonColor `json:"buttonColor"`
+	GaugeColor  GaugeColor  `json:"gaugeColor"`
+	IsSolved    bool        `json:"isSolved"`
+	IsPressed   bool        `json:"isPressed"`
+}
+
+// DefuserView returns the button module state that can be shown to the defuser
+func (bm *ButtonModule) DefuserView() *ButtonModuleView {
+	return &ButtonModuleView{
+		ID:          bm.ID,
+		ButtonText:  bm.ButtonText,
+		ButtonColor: bm.ButtonColor,
+		GaugeColor:  bm.GetGaugeColor(),
+		IsSolved:    bm.IsSolved,
+		IsPressed:   bm.IsPressed,
+	}
 }
 
 // NewButtonModuleWithRules creates a new button module with random button configuration and generates rules
 // buttonSeed: seed for generating random button configuration (different for each module)
 // ruleSeed: seed for generating rules (same for all modules to match the manual)
+// ctx: the bomb's edgework, used by rules about batteries and indicators
+// opts: rule generation options of the bomb's difficulty
 // Returns the module and its corresponding manual
-func NewButtonModuleWithRules(buttonSeed int64, ruleSeed int64) (*ButtonModule, *ModuleManual) {
+func NewButtonModuleWithRules(buttonSeed int64, ruleSeed int64, ctx *BombContext, opts RuleGenOptions) (*ButtonModule, *ModuleManual) {
 	// Create a seeded RNG for button generation using the buttonSeed (unique per module)
 	rng := rand.New(rand.NewSource(buttonSeed))
 
 	// Random button text
-	buttonTexts := []ButtonText{ButtonTextAbort, ButtonTextDetonate, ButtonTextHold, ButtonTextPress, ButtonTextOther}
 	buttonText := buttonTexts[rng.Intn(len(buttonTexts))]
 
 	// Random button color
-	buttonColors := []ButtonColor{ButtonColorRed, ButtonColorBlue, ButtonColorWhite}
 	buttonColor := buttonColors[rng.Intn(len(buttonColors))]
 
 	// Generate rules and manual using ruleSeed (same for all modules)
-	ruleSet, moduleManual := GenerateButtonModuleRulesWithSeed(ruleSeed)
+	ruleSet, moduleManual := GenerateButtonModuleRulesWithSeed(ruleSeed, opts)
 
 	module := &ButtonModule{
 		ButtonText:  buttonText,
@@ -84,17 +139,19 @@ func NewButtonModuleWithRules(buttonSeed int64, ruleSeed int64) (*ButtonModule,
 		IsPressed:   false,
 		RuleSet:     ruleSet,
 		ButtonSeed:  buttonSeed, // Store seed for deterministic gauge color selection
+		manual:      moduleManual,
 	}
 
 	// Determine correct action based on rules
-	module.determineCorrectAction()
+	module.determineCorrectAction(ctx)
 
 	return module, moduleManual
 }
 
 // determineCorrectAction calculates which action should be taken based on rules
 // Only determines press vs hold - gauge color and timer digit are set when button is pressed
-func (bm *ButtonModule) determineCorrectAction() {
+// ctx may be nil, in which case rules about the bomb's edgework never match
+func (bm *ButtonModule) determineCorrectAction(ctx *BombContext) {
 	if bm.RuleSet == nil || len(bm.RuleSet.Rules) == 0 {
 		// Fallback: default to hold
 		bm.CorrectAction = ButtonActionHold
@@ -103,7 +160,7 @@ func (bm *ButtonModule) determineCorrectAction() {
 
 	// Evaluate rules in order
 	for _, rule := range bm.RuleSet.Rules {
-		result := rule.Evaluator(bm.ButtonText, bm.ButtonColor)
+		result := rule.Evaluator(bm.ButtonText, bm.ButtonColor, ctx)
 		if result != nil {
 			bm.CorrectAction = result.Action
 			// Gauge color and timer digit will be set when button is pressed (for hold actions)
@@ -114,7 +171,7 @@ func (bm *ButtonModule) determineCorrectAction() {
 	// No rule matched, use default rule (should be the last rule in the set)
 	if len(bm.RuleSet.Rules) > 0 {
 		lastRule := bm.RuleSet.Rules[len(bm.RuleSet.Rules)-1]
-		result := lastRule.Evaluator(bm.ButtonText, bm.ButtonColor)
+		result := lastRule.Evaluator(bm.ButtonText, bm.ButtonColor, ctx)
 		if result != nil {
 			bm.CorrectAction = result.Action
 			return
@@ -125,6 +182,12 @@ func (bm *ButtonModule) determineCorrectAction() {
 	bm.CorrectAction = ButtonActionHold
 }
 
+// buttonTextSharesLetter reports whether a button's text and the English name of its color
+// have a letter in common, e.g. ABORT on a red button
+func buttonTextSharesLetter(text ButtonText, color ButtonColor) bool {
+	return strings.ContainsAny(strings.ToUpper(string(color)), string(text))
+}
+
 // GetGaugeColor returns the gauge color to display (only when pressed)
 func (bm *ButtonModule) GetGaugeColor() GaugeColor {
 	if bm.IsPressed {
@@ -133,9 +196,19 @@ func (bm *ButtonModule) GetGaugeColor() GaugeColor {
 	return "" // No gauge color when not pressed
 }
 
-// PressButton handles a button press action
+// ActionLog returns every press, hold and release of the button with who performed it
+func (bm *ButtonModule) ActionLog() []AttributedAction {
+	log := make([]AttributedAction, len(bm.Inputs))
+	for i, input := range bm.Inputs {
+		log[i] = AttributedAction{Action: string(input.Action), ActionRecord: input.ActionRecord}
+	}
+	return log
+}
+
+// PressButton handles a button press action, by records who pressed it
 // Returns true if correct, false if wrong (strike)
-func (bm *ButtonModule) PressButton() bool {
+func (bm *ButtonModule) PressButton(by ActionRecord) bool {
+	bm.Inputs = append(bm.Inputs, ButtonInput{Action: ButtonActionPress, ActionRecord: by})
 	if bm.IsSolved {
 		return false // Already solved
 	}
@@ -152,16 +225,34 @@ func (bm *ButtonModule) PressButton() bool {
 
 	// Otherwise, start holding and randomly select gauge color
 	bm.IsPressed = true
+	bm.TargetTimerDigit = 0
 
 	// Randomly select gauge color (red, white, or blue) using deterministic seed
+	// The press count is mixed in so every new hold rolls a new color, replayably
 	gaugeColors := []GaugeColor{GaugeColorRed, GaugeColorBlue, GaugeColorWhite}
-	gaugeColorRNG := rand.New(rand.NewSource(bm.ButtonSeed + 999999)) // Offset to avoid conflicts
+	gaugeColorRNG := rand.New(rand.NewSource(bm.ButtonSeed + 999999 + int64(bm.PressCount)*7919)) // Offset to avoid conflicts
+	bm.PressCount++
 	selectedGaugeColor := gaugeColors[gaugeColorRNG.Intn(len(gaugeColors))]
-	bm.GaugeColor = selectedGaugeColor
+	bm.GaugeSchedule = nil
+	bm.holdElapsed = 0
+	if bm.CyclingGauge {
+		// The gauge starts on the color drawn above, the others follow in a seeded order
+		bm.GaugeSchedule = gaugeSchedule(gaugeColorRNG, gaugeColors, selectedGaugeColor)
+	}
+	bm.setGaugeColor(selectedGaugeColor)
+
+	now := time.Now()
+	bm.HoldStartTime = &now
+	return true
+}
+
+// setGaugeColor shows a gauge color and sets the timer digit it maps to
+func (bm *ButtonModule) setGaugeColor(color GaugeColor) {
+	bm.GaugeColor = color
 
 	// Look up timer digit from gauge color mapping
 	if bm.RuleSet != nil && bm.RuleSet.GaugeColorToDigitMap != nil {
-		if digit, exists := bm.RuleSet.GaugeColorToDigitMap[selectedGaugeColor]; exists {
+		if digit, exists := bm.RuleSet.GaugeColorToDigitMap[color]; exists {
 			bm.TargetTimerDigit = digit
 		} else {
 			// Fallback if mapping doesn't exist
@@ -171,15 +262,43 @@ func (bm *ButtonModule) PressButton() bool {
 		// Fallback if RuleSet or mapping doesn't exist
 		bm.TargetTimerDigit = 0
 	}
+}
 
-	now := time.Now()
-	bm.HoldStartTime = &now
-	return true
+// gaugeSchedule draws the 2 or 3 colors a cycling gauge shows, starting with first
+func gaugeSchedule(rng *rand.Rand, colors []GaugeColor, first GaugeColor) []GaugeColor {
+	stages := MinGaugeStages + rng.Intn(MaxGaugeStages-MinGaugeStages+1)
+	schedule := []GaugeColor{first}
+	for _, i := range rng.Perm(len(colors)) {
+		if len(schedule) >= stages {
+			break
+		}
+		if colors[i] != first {
+			schedule = append(schedule, colors[i])
+		}
+	}
+	return schedule
+}
+
+// Tick advances the gauge of a held button to the given bomb clock time
+// A cycling gauge moves to its next color every GaugeStageSeconds, and so does the digit to release on
+func (bm *ButtonModule) Tick(elapsed float64) {
+	delta := elapsed - bm.lastTick
+	bm.lastTick = elapsed
+	if !bm.IsPressed || len(bm.GaugeSchedule) < 2 {
+		return
+	}
+
+	bm.holdElapsed += delta
+	stage := int(bm.holdElapsed/GaugeStageSeconds) % len(bm.GaugeSchedule)
+	if bm.GaugeSchedule[stage] != bm.GaugeColor {
+		bm.setGaugeColor(bm.GaugeSchedule[stage])
+	}
 }
 
 // HoldButton handles holding the button (called when button is being held)
 // Returns true if still holding correctly, false if should release or strike
-func (bm *ButtonModule) HoldButton() bool {
+func (bm *ButtonModule) HoldButton(by ActionRecord) bool {
+	bm.Inputs = append(bm.Inputs, ButtonInput{Action: ButtonActionHold, ActionRecord: by})
 	if bm.IsSolved {
 		return false
 	}
@@ -198,10 +317,20 @@ func (bm *ButtonModule) HoldButton() bool {
 	return true
 }
 
+// DefaultReleaseWindow is how far on each side of a release the timer may have shown the target digit
+const DefaultReleaseWindow = 250 * time.Millisecond
+
 // ReleaseButton handles releasing the button
 // timeRemaining: current time remaining on bomb timer (for release timing)
 // Returns true if correct, false if wrong (strike)
-func (bm *ButtonModule) ReleaseButton(timeRemaining int) bool {
+func (bm *ButtonModule) ReleaseButton(timeRemaining int, by ActionRecord) bool {
+	return bm.ReleaseButtonWithin(timeRemaining, timeRemaining, by)
+}
+
+// ReleaseButtonWithin releases the button like ReleaseButton, a hold being correct if the timer
+// showed the target digit at any second from lowest to highest (the times it showed around the release)
+func (bm *ButtonModule) ReleaseButtonWithin(lowest int, highest int, by ActionRecord) bool {
+	bm.Inputs = append(bm.Inputs, ButtonInput{Action: ButtonActionRelease, ActionRecord: by})
 	if bm.IsSolved {
 		return false
 	}
@@ -217,11 +346,15 @@ func (bm *ButtonModule) ReleaseButton(timeRemaining int) bool {
 
 	// For hold actions, check if timer's last digit matches target
 	if bm.CorrectAction == ButtonActionHold {
-		// Check if timer's last digit matches the target digit
-		lastDigit := timeRemaining % 10
-		if lastDigit != bm.TargetTimerDigit {
+		// Check if timer's last digit matched the target digit at some point around the release
+		matched := false
+		for shown := lowest; shown <= highest && !matched; shown++ {
+			matched = shown%10 == bm.TargetTimerDigit
+		}
+		if !matched {
 			bm.IsPressed = false
 			bm.GaugeColor = ""
+			bm.TargetTimerDigit = 0
 			bm.HoldStartTime = nil
 			return false // Wrong timer digit = strike
 		}
@@ -239,3 +372,37 @@ func (bm *ButtonModule) ReleaseButton(timeRemaining int) bool {
 	bm.HoldStartTime = nil
 	return false
 }
+
+// Type returns the module type
+func (bm *ButtonModule) Type() string {
+	return ModuleTypeButton
+}
+
+// Solved reports whether the button module is disarmed
+func (bm *ButtonModule) Solved() bool {
+	return bm.IsSolved
+}
+
+// View returns the defuser-facing state of the button module
+func (bm *ButtonModule) View() interface{} {
+	return bm.DefuserView()
+}
+
+// Manual returns the manual the button module was generated with
+func (bm *ButtonModule) Manual() *ModuleManual {
+	return bm.manual
+}
+
+// HandleAction applies a defuser action to the button module
+// Supports "press", "hold" and "release"; releasing is judged against the timer
+func (bm *ButtonModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	switch action {
+	case "press":
+		return actionOutcome(bm.PressButton(ctx.By)), nil
+	case "hold":
+		return actionOutcome(bm.HoldButton(ctx.By)), nil
+	case "release":
+		return actionOutcome(bm.ReleaseButtonWithin(ctx.TimeShownLowest, ctx.TimeShownHighest, ctx.By)), nil
+	}
+	return ActionResult{}, unknownActionError(ModuleTypeButton, action)
+}
@@ -0,0 +1,252 @@
+package models
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newHoldButton builds a button module that must be held and released on a digit
+func newHoldButton(t *testing.T, seed int64) *ButtonModule {
+	t.Helper()
+	module, _ := NewButtonModuleWithRules(seed, 1, nil, DefaultRuleGenOptions())
+	module.CorrectAction = ButtonActionHold
+	return module
+}
+
+func TestEveryHoldRollsGaugeColor(t *testing.T) {
+	const holds = 20
+	for seed := int64(0); seed < 10; seed++ {
+		module, replay := newHoldButton(t, seed), newHoldButton(t, seed)
+		colors := make(map[GaugeColor]bool)
+		for i := 0; i < holds; i++ {
+			if !module.PressButton(actionBy("player-1")) || !replay.PressButton(actionBy("player-1")) {
+				t.Fatalf("seed %d hold %d: press rejected", seed, i)
+			}
+			if module.GaugeColor != replay.GaugeColor {
+				t.Fatalf("seed %d hold %d: gauge %s, the same presses gave %s", seed, i, module.GaugeColor, replay.GaugeColor)
+			}
+			target := module.RuleSet.GaugeColorToDigitMap[module.GaugeColor]
+			if module.TargetTimerDigit != target {
+				t.Fatalf("seed %d hold %d: target digit %d for a %s gauge, want %d", seed, i, module.TargetTimerDigit, module.GaugeColor, target)
+			}
+			colors[module.GaugeColor] = true
+
+			// Release on the digit after the target, a strike
+			wrong := target + 1
+			if module.ReleaseButton(wrong, actionBy("player-1")) {
+				t.Fatalf("seed %d hold %d: release at %d solved for target %d", seed, i, wrong, target)
+			}
+			replay.ReleaseButton(wrong, actionBy("player-1"))
+			if module.IsPressed || module.GaugeColor != "" || module.TargetTimerDigit != 0 {
+				t.Fatalf("seed %d hold %d: after a wrong release the button is pressed=%v gauge=%q target=%d", seed, i, module.IsPressed, module.GaugeColor, module.TargetTimerDigit)
+			}
+		}
+		if module.PressCount != holds {
+			t.Errorf("seed %d: press count %d, want %d", seed, module.PressCount, holds)
+		}
+		if len(colors) < 2 {
+			t.Errorf("seed %d: %d holds all showed %v", seed, holds, colors)
+		}
+	}
+}
+
+func TestReleaseWindowAtDigitBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed float64 // Seconds into a 300s timer at the release, it shows 290 until 11s
+		speed   float64
+		window  time.Duration
+		grace   int
+		target  int
+		want    bool
+	}{
+		{"digit shown", 10.5, 1, DefaultReleaseWindow, 0, 0, true},
+		{"digit shown within the window", 10.9, 1, DefaultReleaseWindow, 0, 9, true},
+		{"digit shown after the window", 10.6, 1, DefaultReleaseWindow, 0, 9, false},
+		{"digit changed within the window", 11.1, 1, DefaultReleaseWindow, 0, 0, true},
+		{"digit changed before the window", 11.4, 1, DefaultReleaseWindow, 0, 0, false},
+		{"no window", 11.1, 1, 0, 0, 0, false},
+		{"window at double speed", 11.3, 2, DefaultReleaseWindow, 0, 0, true},
+		{"window at normal speed", 11.3, 1, DefaultReleaseWindow, 0, 0, false},
+		{"grace second", 11.5, 1, 0, 1, 0, true},
+		{"other digit", 10.5, 1, DefaultReleaseWindow, 0, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bomb := NewBomb("BOMB", BombConfig{
+				TimeLimit:     300,
+				ModuleCount:   3,
+				MaxStrikes:    3,
+				ModuleMix:     map[string]int{ModuleTypeButton: 3},
+				ReleaseWindow: tt.window,
+			}, rand.New(rand.NewSource(1)))
+			bomb.Start()
+			module := bomb.ButtonModules[0]
+			module.CorrectAction = ButtonActionHold
+			if result, err := bomb.HandleModuleAction(ModuleTypeButton, 0, "press", nil, "player-1", 0); err != nil || !result.Correct {
+				t.Fatalf("press = %+v, %v", result, err)
+			}
+
+			module.TargetTimerDigit = tt.target
+			bomb.SpeedMultiplier = tt.speed
+			bomb.elapsed = tt.elapsed
+			bomb.lastTick = time.Now()
+			result, err := bomb.HandleModuleAction(ModuleTypeButton, 0, "release", nil, "player-1", tt.grace)
+			if err != nil {
+				t.Fatalf("release: %v", err)
+			}
+			if result.Correct != tt.want || result.Strike == tt.want {
+				t.Errorf("release on %d at %.1fs = %+v, want correct %v", tt.target, tt.elapsed, result, tt.want)
+			}
+		})
+	}
+}
+
+// followButtonManual reads the structured button manual like an expert would: the first
+// rule of the pre-hold section whose condition holds says whether to press or hold
+func followButtonManual(t *testing.T, manual *ModuleManual, text ButtonText, color ButtonColor, ctx *BombContext) ButtonAction {
+	t.Helper()
+	for _, rule := range manual.Rules {
+		if rule.Action == nil {
+			continue // Section header
+		}
+		if rule.Condition != nil && !buttonConditionHolds(t, rule.Condition, text, color, ctx) {
+			continue
+		}
+		switch rule.Action.Type {
+		case ActionPressButton:
+			return ButtonActionPress
+		case ActionHoldButton:
+			return ButtonActionHold
+		}
+		t.Fatalf("button rule %d has action %q", rule.Number, rule.Action.Type)
+	}
+	t.Fatal("no rule of the button manual applies")
+	return ""
+}
+
+// buttonConditionHolds evaluates a structured button manual condition
+func buttonConditionHolds(t *testing.T, c *RuleCondition, text ButtonText, color ButtonColor, ctx *BombContext) bool {
+	switch c.Type {
+	case ConditionButton:
+		_, colored := c.Params["color"]
+		return text == ButtonText(param(c.Params, "text")) && (!colored || color == ButtonColor(param(c.Params, "color")))
+	case ConditionButtonColor:
+		return color == ButtonColor(param(c.Params, "color"))
+	case ConditionButtonTextLonger:
+		return len(text) > c.Params["count"].(int)
+	case ConditionButtonSharesLetter:
+		return strings.ContainsAny(strings.ToUpper(string(color)), string(text))
+	case ConditionBatteriesMoreThan:
+		return ctx.Batteries > c.Params["count"].(int)
+	case ConditionNoBatteries:
+		return ctx.Batteries == 0
+	case ConditionLitIndicator:
+		return ctx.HasLitIndicator(param(c.Params, "label"))
+	case ConditionAll:
+		for _, part := range c.Conditions {
+			if !buttonConditionHolds(t, part, text, color, ctx) {
+				return false
+			}
+		}
+		return true
+	}
+	t.Fatalf("unknown button condition %q", c.Type)
+	return false
+}
+
+func TestButtonManualMatchesCorrectAction(t *testing.T) {
+	for _, difficulty := range []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard, DifficultyExpert} {
+		t.Run(string(difficulty), func(t *testing.T) {
+			for seed := int64(0); seed < 100; seed++ {
+				ruleSet, manual := GenerateButtonModuleRulesWithSeed(seed, difficultyPresets[difficulty].Rules)
+				ctx := NewBombContext(seed)
+				for _, text := range buttonTexts {
+					for _, color := range buttonColors {
+						module := &ButtonModule{ButtonText: text, ButtonColor: color, RuleSet: ruleSet}
+						module.determineCorrectAction(ctx)
+						if want := followButtonManual(t, manual, text, color, ctx); module.CorrectAction != want {
+							t.Fatalf("seed %d %s %s button: the manual says %s, the module expects %s", seed, color, text, want, module.CorrectAction)
+						}
+					}
+				}
+
+				for _, rule := range manual.Rules {
+					if rule.Action == nil || rule.Action.Type != ActionReleaseOnDigit {
+						continue
+					}
+					gauge := GaugeColor(param(rule.Condition.Params, "color"))
+					if digit := rule.Action.Params["digit"]; digit != ruleSet.GaugeColorToDigitMap[gauge] {
+						t.Fatalf("seed %d: the manual releases a %s gauge on %v, the module on %d", seed, gauge, digit, ruleSet.GaugeColorToDigitMap[gauge])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestButtonTextSharesLetter(t *testing.T) {
+	tests := []struct {
+		text  ButtonText
+		color ButtonColor
+		want  bool
+	}{
+		{ButtonTextAbort, ButtonColorRed, true},
+		{ButtonTextHold, ButtonColorRed, true},
+		{ButtonTextWait, ButtonColorRed, false},
+		{ButtonTextWait, ButtonColorBlue, false},
+		{ButtonTextHold, ButtonColorGreen, false},
+		{ButtonTextDetonate, ButtonColorGreen, true},
+		{ButtonTextDefuse, ButtonColorYellow, true},
+		{ButtonTextAbort, ButtonColorWhite, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.text)+" "+string(tt.color), func(t *testing.T) {
+			if got := buttonTextSharesLetter(tt.text, tt.color); got != tt.want {
+				t.Errorf("buttonTextSharesLetter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstMatchingButtonRuleWins(t *testing.T) {
+	// rule answers action for buttons matching a check
+	rule := func(action ButtonAction, match func(ButtonText, ButtonColor) bool) ButtonRule {
+		return ButtonRule{Evaluator: func(text ButtonText, color ButtonColor, ctx *BombContext) *ButtonRuleResult {
+			if match(text, color) {
+				return &ButtonRuleResult{Action: action}
+			}
+			return nil
+		}}
+	}
+	isRed := func(text ButtonText, color ButtonColor) bool { return color == ButtonColorRed }
+	isLong := func(text ButtonText, color ButtonColor) bool { return len(text) > 5 }
+	rules := []ButtonRule{
+		rule(ButtonActionPress, isRed),
+		rule(ButtonActionHold, isLong),
+		rule(ButtonActionPress, func(ButtonText, ButtonColor) bool { return true }),
+	}
+
+	tests := []struct {
+		text  ButtonText
+		color ButtonColor
+		want  ButtonAction
+	}{
+		{ButtonTextDetonate, ButtonColorRed, ButtonActionPress}, // Both of the first rules match
+		{ButtonTextDetonate, ButtonColorBlue, ButtonActionHold}, // Only the second
+		{ButtonTextAbort, ButtonColorBlue, ButtonActionPress},   // Only the default
+		{ButtonTextAbort, ButtonColorYellow, ButtonActionPress}, // ABORT is not longer than 5
+		{ButtonTextDefuse, ButtonColorYellow, ButtonActionHold}, // DEFUSE is
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.text)+" "+string(tt.color), func(t *testing.T) {
+			module := &ButtonModule{ButtonText: tt.text, ButtonColor: tt.color, RuleSet: &ButtonRuleSet{Rules: rules}}
+			module.determineCorrectAction(nil)
+			if module.CorrectAction != tt.want {
+				t.Errorf("correct action = %s, want %s", module.CorrectAction, tt.want)
+			}
+		})
+	}
+}
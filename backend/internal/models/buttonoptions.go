@@ -0,0 +1,15 @@
+package models
+
+// SetButtonCyclingGauge sets whether the gauge of a held button cycles through several colors
+func (gs *GameSession) SetButtonCyclingGauge(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.ButtonCyclingGauge = enabled
+}
+
+// GetButtonCyclingGauge returns whether button gauges cycle through colors in a thread-safe way
+func (gs *GameSession) GetButtonCyclingGauge() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.ButtonCyclingGauge
+}
@@ -0,0 +1,293 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+)
+
+// ComplicatedWireInstruction tells whether a complicated wire has to be cut
+type ComplicatedWireInstruction string
+
+const (
+	ComplicatedCut       ComplicatedWireInstruction = "C" // Cut the wire
+	ComplicatedDontCut   ComplicatedWireInstruction = "D" // Do not cut the wire
+	ComplicatedSerial    ComplicatedWireInstruction = "S" // Cut if the last digit of the serial number is even
+	ComplicatedBatteries ComplicatedWireInstruction = "B" // Cut if the bomb has two or more batteries
+)
+
+// complicatedInstructions is the pool the decision table draws from
+var complicatedInstructions = []ComplicatedWireInstruction{ComplicatedCut, ComplicatedDontCut, ComplicatedSerial, ComplicatedBatteries}
+
+// complicatedInstructionText is the message each instruction reads as in the manual
+var complicatedInstructionText = map[ComplicatedWireInstruction]string{
+	ComplicatedCut:       "complicatedWires.instruction.cut",
+	ComplicatedDontCut:   "complicatedWires.instruction.dontCut",
+	ComplicatedSerial:    "complicatedWires.instruction.serial",
+	ComplicatedBatteries: "complicatedWires.instruction.batteries",
+}
+
+const (
+	// complicatedCombinations is the number of attribute combinations (red, blue, LED, star)
+	complicatedCombinations = 16
+	// complicatedMinWires and complicatedMaxWires bound the number of wires on the module
+	complicatedMinWires = 3
+	complicatedMaxWires = 6
+	// complicatedMaxAttempts bounds the search for a layout with at least one wire to cut
+	complicatedMaxAttempts = 100
+)
+
+// ComplicatedWire is a wire with a color combination, an optional LED and an optional star
+type ComplicatedWire struct {
+	Red  bool `json:"red"`
+	Blue bool `json:"blue"` // A wire with neither red nor blue is white
+	LED  bool `json:"led"`
+	Star bool `json:"star"`
+}
+
+// combination returns the index of the wire's attributes in the decision table
+func (w ComplicatedWire) combination() int {
+	index := 0
+	if w.Red {
+		index |= 1
+	}
+	if w.Blue {
+		index |= 2
+	}
+	if w.LED {
+		index |= 4
+	}
+	if w.Star {
+		index |= 8
+	}
+	return index
+}
+
+// complicatedWireFromCombination rebuilds the wire attributes of a decision table index
+func complicatedWireFromCombination(index int) ComplicatedWire {
+	return ComplicatedWire{
+		Red:  index&1 != 0,
+		Blue: index&2 != 0,
+		LED:  index&4 != 0,
+		Star: index&8 != 0,
+	}
+}
+
+// describe spells out the wire attributes for the manual
+func (w ComplicatedWire) describe() *Message {
+	color := "complicatedWires.color.white"
+	switch {
+	case w.Red && w.Blue:
+		color = "complicatedWires.color.redBlue"
+	case w.Red:
+		color = "complicatedWires.color.red"
+	case w.Blue:
+		color = "complicatedWires.color.blue"
+	}
+
+	led := "complicatedWires.led.off"
+	if w.LED {
+		led = "complicatedWires.led.on"
+	}
+	star := "complicatedWires.star.off"
+	if w.Star {
+		star = "complicatedWires.star.on"
+	}
+	return msg("complicatedWires.wire", msg(color), msg(led), msg(star))
+}
+
+// ComplicatedWiresModule represents the complicated wires module on the bomb
+// Every wire has to be judged on its own: cut it or leave it
+type ComplicatedWiresModule struct {
+	moduleIdentity
+	Wires     []ComplicatedWire        `json:"wires"`
+	ShouldCut []bool                   `json:"shouldCut"` // Whether each wire has to be cut
+	CutWires  []int                    `json:"cutWires"`  // Indices of cut wires
+	IsSolved  bool                     `json:"isSolved"`
+	RuleSet   *ComplicatedWiresRuleSet `json:"-"` // Rules for this module (not serialized)
+	manual    *ModuleManual            // Manual generated alongside the module
+}
+
+// ComplicatedWiresModuleView is the defuser-facing view of a complicated wires module (no solution data)
+type ComplicatedWiresModuleView struct {
+	ID       string            `json:"id"` // Stable module ID
+	Wires    []ComplicatedWire `json:"wires"`
+	CutWires []int             `json:"cutWires"`
+	IsSolved bool              `json:"isSolved"`
+}
+
+// DefuserView returns the complicated wires module state that can be shown to the defuser
+func (cm *ComplicatedWiresModule) DefuserView() *ComplicatedWiresModuleView {
+	return &ComplicatedWiresModuleView{
+		ID:       cm.ID,
+		Wires:    cm.Wires,
+		CutWires: append([]int{}, cm.CutWires...),
+		IsSolved: cm.IsSolved,
+	}
+}
+
+// ComplicatedWiresRuleSet is the decision table printed in the manual, one instruction per combination
+type ComplicatedWiresRuleSet struct {
+	Instructions [complicatedCombinations]ComplicatedWireInstruction `json:"-"`
+}
+
+// shouldCut resolves the instruction for a wire against the bomb's edgework
+func (rs *ComplicatedWiresRuleSet) shouldCut(wire ComplicatedWire, ctx *BombContext) bool {
+	switch rs.Instructions[wire.combination()] {
+	case ComplicatedCut:
+		return true
+	case ComplicatedSerial:
+		digit := ctx.SerialLastDigit()
+		return digit >= 0 && digit%2 == 0
+	case ComplicatedBatteries:
+		return ctx != nil && ctx.Batteries >= 2
+	}
+	return false
+}
+
+// NewComplicatedWiresModuleWithRules creates a new complicated wires module with at least one wire to cut
+// complicatedWiresSeed: seed for generating the wires (different for each module)
+// ruleSeed: seed for generating the decision table (same for all modules to match the manual)
+// ctx: the bomb's edgework, used by the serial number and battery instructions
+// Returns the module and its corresponding manual
+func NewComplicatedWiresModuleWithRules(complicatedWiresSeed int64, ruleSeed int64, ctx *BombContext) (*ComplicatedWiresModule, *ModuleManual) {
+	rng := rand.New(rand.NewSource(complicatedWiresSeed))
+	ruleSet, moduleManual := GenerateComplicatedWiresModuleRulesWithSeed(ruleSeed)
+
+	var wires []ComplicatedWire
+	var shouldCut []bool
+	anyCut := false
+	for attempt := 0; attempt < complicatedMaxAttempts && !anyCut; attempt++ {
+		numWires := complicatedMinWires + rng.Intn(complicatedMaxWires-complicatedMinWires+1)
+		wires = make([]ComplicatedWire, numWires)
+		shouldCut = make([]bool, numWires)
+		for i := range wires {
+			wires[i] = complicatedWireFromCombination(rng.Intn(complicatedCombinations))
+			shouldCut[i] = ruleSet.shouldCut(wires[i], ctx)
+			// A module with nothing to cut would be solved from the start
+			anyCut = anyCut || shouldCut[i]
+		}
+	}
+
+	// Fall back to a wire the table always says to cut
+	if !anyCut {
+		for combination, instruction := range ruleSet.Instructions {
+			if instruction == ComplicatedCut {
+				wires[0] = complicatedWireFromCombination(combination)
+				shouldCut[0] = true
+				break
+			}
+		}
+	}
+
+	module := &ComplicatedWiresModule{
+		Wires:     wires,
+		ShouldCut: shouldCut,
+		CutWires:  []int{},
+		RuleSet:   ruleSet,
+		manual:    moduleManual,
+	}
+	return module, moduleManual
+}
+
+// CutWire cuts the wire at the given index
+// Returns true if the wire had to be cut, false if not (strike)
+func (cm *ComplicatedWiresModule) CutWire(index int) bool {
+	if cm.IsSolved || index < 0 || index >= len(cm.Wires) {
+		return false
+	}
+
+	cm.CutWires = append(cm.CutWires, index)
+	if !cm.ShouldCut[index] {
+		return false
+	}
+
+	// Solved once every wire that had to be cut is cut
+	for i, cut := range cm.ShouldCut {
+		if cut && !cm.isCut(i) {
+			return true
+		}
+	}
+	cm.IsSolved = true
+	return true
+}
+
+// isCut reports whether the wire at the given index was cut
+func (cm *ComplicatedWiresModule) isCut(index int) bool {
+	for _, cut := range cm.CutWires {
+		if cut == index {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateComplicatedWiresModuleRulesWithSeed generates the decision table for complicated wires modules
+// The table always has at least one plain "cut" so every bomb can have a wire to cut
+func GenerateComplicatedWiresModuleRulesWithSeed(seed int64) (*ComplicatedWiresRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 11111111))
+
+	ruleSet := &ComplicatedWiresRuleSet{}
+	hasCut := false
+	for i := range ruleSet.Instructions {
+		ruleSet.Instructions[i] = complicatedInstructions[rng.Intn(len(complicatedInstructions))]
+		hasCut = hasCut || ruleSet.Instructions[i] == ComplicatedCut
+	}
+	if !hasCut {
+		ruleSet.Instructions[rng.Intn(complicatedCombinations)] = ComplicatedCut
+	}
+
+	manualRules := make([]ManualRule, complicatedCombinations)
+	table := make(map[string]ComplicatedWireInstruction, complicatedCombinations)
+	for i, instruction := range ruleSet.Instructions {
+		description := complicatedWireFromCombination(i).describe()
+		table[description.Render(DefaultLanguage)] = instruction
+		manualRules[i] = manualRule(i+1, msg("complicatedWires.rule", description, msg(complicatedInstructionText[instruction])))
+	}
+
+	moduleManual := newModuleManual(msg("complicatedWires.title"), msg("complicatedWires.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"table": table,
+	}
+
+	return ruleSet, moduleManual
+}
+
+// Type returns the module type
+func (cm *ComplicatedWiresModule) Type() string {
+	return ModuleTypeComplicatedWires
+}
+
+// Solved reports whether the complicated wires module is disarmed
+func (cm *ComplicatedWiresModule) Solved() bool {
+	return cm.IsSolved
+}
+
+// View returns the defuser-facing state of the complicated wires module
+func (cm *ComplicatedWiresModule) View() interface{} {
+	return cm.DefuserView()
+}
+
+// Manual returns the manual the complicated wires module was generated with
+func (cm *ComplicatedWiresModule) Manual() *ModuleManual {
+	return cm.manual
+}
+
+// HandleAction applies a defuser action to the complicated wires module
+// Supports "cut" with a wireIndex; cutting a wire twice is not a mistake
+func (cm *ComplicatedWiresModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "cut" {
+		return ActionResult{}, unknownActionError(ModuleTypeComplicatedWires, action)
+	}
+
+	var data struct {
+		WireIndex int `json:"wireIndex"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	if data.WireIndex < 0 || data.WireIndex >= len(cm.Wires) || cm.isCut(data.WireIndex) {
+		return ActionResult{}, fmt.Errorf("invalid or already cut wire")
+	}
+	return actionOutcome(cm.CutWire(data.WireIndex)), nil
+}
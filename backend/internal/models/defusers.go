@@ -0,0 +1,334 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// MinDefuserCount and MaxDefuserCount bound how many players defuse the bomb together
+	MinDefuserCount = 1
+	MaxDefuserCount = 3
+	// maxDefuserHistory bounds the rounds whose defusers are remembered
+	maxDefuserHistory = 50
+)
+
+// RotationMode is how a rotating random defuser pick avoids giving the bomb to the same players
+type RotationMode string
+
+const (
+	RotationAvoidRepeat RotationMode = "avoidRepeat" // Random, without last round's defusers
+	RotationJoinOrder   RotationMode = "joinOrder"   // Cycle through the players in join order
+)
+
+// RolePreference is the role a player would like when defusers are picked at random
+type RolePreference string
+
+const (
+	RolePreferDefuser RolePreference = "preferDefuser" // Volunteers to defuse
+	RolePreferExpert  RolePreference = "preferExpert"  // Only defuses when nobody else can
+	RoleNoPreference  RolePreference = "noPreference"
+)
+
+// ValidateRolePreference returns an error if preference isn't a known role preference
+func ValidateRolePreference(preference RolePreference) error {
+	switch preference {
+	case RolePreferDefuser, RolePreferExpert, RoleNoPreference:
+		return nil
+	}
+	return fmt.Errorf("role preference must be %q, %q or %q", RolePreferDefuser, RolePreferExpert, RoleNoPreference)
+}
+
+// SetRolePreference sets the role a player would like in random defuser picks
+// The host choosing defusers still overrides it
+func (gs *GameSession) SetRolePreference(playerID string, preference RolePreference) error {
+	if err := ValidateRolePreference(preference); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists {
+		return fmt.Errorf("player not found")
+	}
+	if player.RolePreference != preference {
+		gs.upNext = nil
+	}
+	player.RolePreference = preference
+	return nil
+}
+
+// ValidateRotationMode returns an error if mode isn't a known rotation mode
+func ValidateRotationMode(mode RotationMode) error {
+	switch mode {
+	case RotationAvoidRepeat, RotationJoinOrder:
+		return nil
+	}
+	return fmt.Errorf("rotation mode must be %q or %q", RotationAvoidRepeat, RotationJoinOrder)
+}
+
+// SetDefuserCount sets how many players defuse the bomb, the others are experts
+func (gs *GameSession) SetDefuserCount(count int) error {
+	if err := validateDefuserCount(count); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.DefuserCount != count {
+		gs.upNext = nil
+	}
+	gs.DefuserCount = count
+	return nil
+}
+
+// validateDefuserCount returns an error if count isn't a valid number of defusers
+func validateDefuserCount(count int) error {
+	if count < MinDefuserCount || count > MaxDefuserCount {
+		return fmt.Errorf("defuser count must be between %d and %d", MinDefuserCount, MaxDefuserCount)
+	}
+	return nil
+}
+
+// GetDefuserCount returns how many players defuse the bomb in a thread-safe way
+func (gs *GameSession) GetDefuserCount() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.DefuserCount
+}
+
+// SetDefuserIDs sets the players the host chose as defusers, an empty list leaves it to defuserId
+// Chosen players who aren't in the session when the game starts are skipped
+func (gs *GameSession) SetDefuserIDs(ids []string) error {
+	if err := validateDefuserIDs(ids); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.DefuserIDs = append([]string{}, ids...)
+	gs.upNext = nil
+	return nil
+}
+
+// validateDefuserIDs returns an error if the host chose more defusers than can play
+func validateDefuserIDs(ids []string) error {
+	if len(ids) > MaxDefuserCount {
+		return fmt.Errorf("at most %d defusers can be chosen", MaxDefuserCount)
+	}
+	return nil
+}
+
+// SetRotateDefuser sets whether random defuser picks rotate between rounds
+func (gs *GameSession) SetRotateDefuser(rotate bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.RotateDefuser != rotate {
+		gs.upNext = nil
+	}
+	gs.RotateDefuser = rotate
+}
+
+// GetRotateDefuser returns whether random defuser picks rotate in a thread-safe way
+func (gs *GameSession) GetRotateDefuser() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.RotateDefuser
+}
+
+// SetRotationMode sets how rotating defuser picks work
+func (gs *GameSession) SetRotationMode(mode RotationMode) error {
+	if err := ValidateRotationMode(mode); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.RotationMode != mode {
+		gs.upNext = nil
+	}
+	gs.RotationMode = mode
+	return nil
+}
+
+// GetRotationMode returns how rotating defuser picks work in a thread-safe way
+func (gs *GameSession) GetRotationMode() RotationMode {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.RotationMode
+}
+
+// GetUpNextDefusers returns who defuses when the game starts next, so they can prepare
+// Random picks are drawn now and kept until a setting they depend on changes or one of them leaves
+func (gs *GameSession) GetUpNextDefusers() []string {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.LobbyState != LobbyStateWaiting {
+		return nil
+	}
+	return append([]string{}, gs.upNextLocked()...)
+}
+
+// upNextLocked returns the cached next defusers, drawing them again if they are stale
+// Caller must hold gs.mu
+func (gs *GameSession) upNextLocked() []string {
+	count := gs.DefuserCount
+	if gs.PracticeMode && gs.eligibleCountLocked() == 1 {
+		// A lone practice player defuses whatever the settings say
+		count = 1
+	}
+
+	valid := len(gs.upNext) == count
+	for _, id := range gs.upNext {
+		if player, exists := gs.Players[id]; !exists || !gs.eligibleLocked(player) {
+			valid = false
+		}
+	}
+	if !valid {
+		gs.upNext = gs.pickDefusersLocked(count)
+	}
+	return gs.upNext
+}
+
+// recordDefusersLocked remembers the defusers of the game that starts (caller must hold gs.mu)
+func (gs *GameSession) recordDefusersLocked(defusers []string) {
+	gs.DefuserHistory = append(gs.DefuserHistory, defusers)
+	if len(gs.DefuserHistory) > maxDefuserHistory {
+		gs.DefuserHistory = gs.DefuserHistory[len(gs.DefuserHistory)-maxDefuserHistory:]
+	}
+	gs.upNext = nil
+}
+
+// unrecordDefusersLocked forgets the defusers of a game cancelled during its countdown,
+// they are up next again (caller must hold gs.mu)
+func (gs *GameSession) unrecordDefusersLocked() {
+	if len(gs.DefuserHistory) == 0 {
+		return
+	}
+	gs.upNext = gs.DefuserHistory[len(gs.DefuserHistory)-1]
+	gs.DefuserHistory = gs.DefuserHistory[:len(gs.DefuserHistory)-1]
+}
+
+// GetDefuserIDs returns the players the host chose as defusers in a thread-safe way
+func (gs *GameSession) GetDefuserIDs() []string {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return append([]string{}, gs.DefuserIDs...)
+}
+
+// pickDefusersLocked returns the defusers of a new game: the chosen players first,
+// then players drawn by drawDefusersLocked until there are count of them (caller must hold gs.mu)
+func (gs *GameSession) pickDefusersLocked(count int) []string {
+	defusers := make([]string, 0, count)
+	taken := make(map[string]bool, count)
+	if !gs.IsRandomDefuser {
+		chosen := gs.DefuserIDs
+		if len(chosen) == 0 && gs.DefuserID != "" {
+			chosen = []string{gs.DefuserID}
+		}
+		for _, id := range chosen {
+			if player, exists := gs.Players[id]; exists && gs.eligibleLocked(player) && !taken[id] && len(defusers) < count {
+				defusers = append(defusers, id)
+				taken[id] = true
+			}
+		}
+	}
+
+	if len(defusers) < count {
+		defusers = append(defusers, gs.drawDefusersLocked(count-len(defusers), taken)...)
+	}
+	return defusers
+}
+
+// drawDefusersLocked draws n defusers among the players not taken, following the rotation
+// settings (fully random, random without last round's defusers, or cycling in join order)
+// and the players' role preferences. Caller must hold gs.mu
+func (gs *GameSession) drawDefusersLocked(n int, taken map[string]bool) []string {
+	// Join order, so cycling follows it and the draws only depend on the session's random source
+	candidates := make([]*Player, 0, len(gs.Players))
+	for id, player := range gs.Players {
+		if !taken[id] && gs.eligibleLocked(player) {
+			candidates = append(candidates, player)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].JoinedAt.Equal(candidates[j].JoinedAt) {
+			return candidates[i].JoinedAt.Before(candidates[j].JoinedAt)
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+	ids := make([]string, len(candidates))
+	for i, player := range candidates {
+		ids[i] = player.ID
+	}
+	if n > len(ids) {
+		n = len(ids)
+	}
+
+	last := make(map[string]bool)
+	if len(gs.DefuserHistory) > 0 {
+		for _, id := range gs.DefuserHistory[len(gs.DefuserHistory)-1] {
+			last[id] = true
+		}
+	}
+
+	if gs.RotateDefuser && gs.RotationMode == RotationJoinOrder {
+		// Players who'd rather be experts are skipped, unless too few players would be left
+		willing := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if gs.Players[id].RolePreference != RolePreferExpert {
+				willing = append(willing, id)
+			}
+		}
+		if len(willing) >= n {
+			ids = willing
+		}
+
+		// Start right after the last round's defuser who joined last
+		start := 0
+		for i, id := range ids {
+			if last[id] {
+				start = i + 1
+			}
+		}
+		drawn := make([]string, 0, n)
+		for k := 0; k < n; k++ {
+			drawn = append(drawn, ids[(start+k)%len(ids)])
+		}
+		return drawn
+	}
+
+	if gs.RotateDefuser && gs.eligibleCountLocked() > 2 {
+		// Leave out last round's defusers, unless too few players would be left
+		fresh := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if !last[id] {
+				fresh = append(fresh, id)
+			}
+		}
+		if len(fresh) >= n {
+			ids = fresh
+		}
+	}
+
+	// Volunteers are drawn first, players who'd rather be experts only when nobody else is left
+	tiers := map[RolePreference][]string{}
+	for _, id := range ids {
+		preference := gs.Players[id].RolePreference
+		if preference != RolePreferDefuser && preference != RolePreferExpert {
+			preference = RoleNoPreference
+		}
+		tiers[preference] = append(tiers[preference], id)
+	}
+	drawn := make([]string, 0, len(ids))
+	for _, preference := range []RolePreference{RolePreferDefuser, RoleNoPreference, RolePreferExpert} {
+		tier := tiers[preference]
+		gs.rng.Shuffle(len(tier), func(i, j int) {
+			tier[i], tier[j] = tier[j], tier[i]
+		})
+		drawn = append(drawn, tier...)
+	}
+	return drawn[:n]
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDefuserViewHidesSolution(t *testing.T) {
+	data, err := json.Marshal(newTestSession(t, BombConfig{MaxStrikes: 10, ModuleMix: OneOfEachModule()}, 3).Bombs[0].DefuserView())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, field := range []string{`"correctCut"`, `"ruleSet"`, `"solution"`} {
+		if strings.Contains(string(data), field) {
+			t.Errorf("defuser view contains %s", field)
+		}
+	}
+}
+
+func TestDefuserViewIsNotChangedByLaterActions(t *testing.T) {
+	actions := []struct {
+		moduleType string
+		action     string
+		payload    string
+	}{
+		{ModuleTypeWires, "cut", `{"wireIndex":0}`},
+		{ModuleTypeTerminal, "command", `{"command":"help"}`},
+		{ModuleTypeKeypad, "press", `{"position":0}`},
+		{ModuleTypePassword, "spin", `{"column":0,"direction":"down"}`},
+		{ModuleTypeComplicatedWires, "cut", `{"wireIndex":0}`},
+	}
+
+	bomb := newTestSession(t, BombConfig{MaxStrikes: 10, ModuleMix: OneOfEachModule()}, 3).Bombs[0]
+	view := bomb.DefuserView()
+	before, _ := json.Marshal(view)
+	for _, tt := range actions {
+		if _, err := bomb.HandleModuleAction(tt.moduleType, 0, tt.action, json.RawMessage(tt.payload), "player-1", 0); err != nil {
+			t.Fatalf("%s %s: %v", tt.moduleType, tt.action, err)
+		}
+	}
+	after, _ := json.Marshal(view)
+	if string(before) != string(after) {
+		t.Errorf("defuser view changed after it was built:\nbefore %s\nafter  %s", before, after)
+	}
+}
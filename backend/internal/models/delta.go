@@ -0,0 +1,193 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// moduleKey identifies a module by its type and its index among the modules of that type
+type moduleKey struct {
+	Type  string
+	Index int
+}
+
+// stateStamp is a cheap summary of the bomb state: while it doesn't change, there is nothing new to send
+type stateStamp struct {
+	bomb          *Bomb
+	revision      uint64 // Bumped by every module change
+	state         BombState
+	strikes       int
+	timeRemaining int
+	paused        bool
+	nextBombIn    int
+}
+
+// ModuleDelta is the new defuser-facing state of one module
+type ModuleDelta struct {
+	ModuleType  string      `json:"moduleType"`
+	ModuleIndex int         `json:"moduleIndex"`
+	ModuleID    string      `json:"moduleId"`
+	Position    int         `json:"position"` // Position in the unified module list
+	State       interface{} `json:"state"`    // Same shape as the module in the gameState message
+}
+
+// StateDelta is what changed on the bomb since the previous version
+// The timer fields are always set, Modules only lists modules that changed
+type StateDelta struct {
+	Version         uint64          `json:"version"` // Follows the previous delta's version, a gap means a delta was missed
+	State           BombState       `json:"state"`
+	Strikes         int             `json:"strikes"`
+	MaxStrikes      int             `json:"maxStrikes"`
+	TimeRemaining   int             `json:"timeRemaining"`
+	SpeedMultiplier float64         `json:"speedMultiplier"`
+	Paused          bool            `json:"paused"`
+	BombIndex       int             `json:"bombIndex"`
+	BombCount       int             `json:"bombCount"`
+	NextBombIn      int             `json:"nextBombIn,omitempty"`
+	Deadline        int64           `json:"deadline,omitempty"` // Unix millis when the timer runs out, 0 while it isn't running
+	Modules         []ModuleDelta   `json:"modules,omitempty"`
+	Progress        *ExpertProgress `json:"progress"` // Same as the manual's, so experts keep their status bar without a new manual
+}
+
+// markDirty records that a module changed and must be in the next state delta
+func (b *Bomb) markDirty(moduleType string, moduleIndex int) {
+	if b.dirty == nil {
+		b.dirty = make(map[moduleKey]bool)
+	}
+	b.dirty[moduleKey{Type: moduleType, Index: moduleIndex}] = true
+	b.revision++
+}
+
+// stampLocked summarizes the state of the bomb being played
+func (gs *GameSession) stampLocked() stateStamp {
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return stateStamp{}
+	}
+	return stateStamp{
+		bomb:          bomb,
+		revision:      bomb.revision,
+		state:         bomb.State,
+		strikes:       bomb.Strikes,
+		timeRemaining: bomb.TimeRemaining,
+		paused:        bomb.Paused,
+		nextBombIn:    gs.nextBombInLocked(),
+	}
+}
+
+// GetManualContentJSON returns the serialized manual content of the bomb being played, rendered in language
+// It is only rebuilt when the bomb state changed, so every expert reading a language shares one copy
+func (gs *GameSession) GetManualContentJSON(language string) json.RawMessage {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	stamp := gs.stampLocked()
+	if gs.manualCache == nil || stamp != gs.manualStamp {
+		gs.manualCache = make(map[string]json.RawMessage)
+		gs.manualStamp = stamp
+	}
+	if data, ok := gs.manualCache[language]; ok {
+		return data
+	}
+	data, err := json.Marshal(gs.manualContentLocked(language))
+	if err != nil {
+		return nil
+	}
+	gs.manualCache[language] = data
+	return data
+}
+
+// moduleView returns the defuser-facing state of a module, needy modules included
+func (b *Bomb) moduleView(key moduleKey) interface{} {
+	switch key.Type {
+	case ModuleTypeNeedyVent:
+		if key.Index >= 0 && key.Index < len(b.NeedyVentModules) {
+			return b.NeedyVentModules[key.Index].DefuserView()
+		}
+	case ModuleTypeNeedyCapacitor:
+		if key.Index >= 0 && key.Index < len(b.NeedyCapacitorModules) {
+			return b.NeedyCapacitorModules[key.Index].DefuserView()
+		}
+	default:
+		if module, err := b.ModuleOfType(key.Type, key.Index); err == nil {
+			return module.View()
+		}
+	}
+	return nil
+}
+
+// GetStateVersion returns the state version of the bomb being played, 0 if there is none
+func (gs *GameSession) GetStateVersion() uint64 {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return 0
+	}
+	return bomb.version
+}
+
+// EmitStateDelta advances the current bomb to its next state version and passes
+// what changed since the previous one to emit; nothing is emitted if nothing changed
+// Deltas are emitted one at a time so they reach the players in version order; the
+// first version of a bomb has nothing to build on and should be sent as a full state
+func (gs *GameSession) EmitStateDelta(emit func(delta *StateDelta)) {
+	gs.deltaMu.Lock()
+	defer gs.deltaMu.Unlock()
+
+	delta := gs.nextStateDelta()
+	if delta != nil {
+		emit(delta)
+	}
+}
+
+// nextStateDelta bumps the current bomb's version and collects its changed modules
+func (gs *GameSession) nextStateDelta() *StateDelta {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return nil
+	}
+	stamp := gs.stampLocked()
+	if stamp == gs.lastStamp {
+		return nil
+	}
+	gs.lastStamp = stamp
+
+	bomb.version++
+	delta := &StateDelta{
+		Version:         bomb.version,
+		State:           bomb.State,
+		Strikes:         bomb.Strikes,
+		MaxStrikes:      bomb.MaxStrikes,
+		TimeRemaining:   bomb.TimeRemaining,
+		SpeedMultiplier: bomb.SpeedMultiplier,
+		Paused:          bomb.Paused,
+		BombIndex:       gs.CurrentBombIndex,
+		BombCount:       len(gs.Bombs),
+		NextBombIn:      gs.nextBombInLocked(),
+		Deadline:        bomb.DeadlineMillis(),
+		Progress:        bomb.Progress(),
+	}
+
+	keys := make([]moduleKey, 0, len(bomb.dirty))
+	for key := range bomb.dirty {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+		return keys[i].Index < keys[j].Index
+	})
+	for _, key := range keys {
+		if view := bomb.moduleView(key); view != nil {
+			delta.Modules = append(delta.Modules, ModuleDelta{ModuleType: key.Type, ModuleIndex: key.Index, ModuleID: bomb.ModuleIDOf(key.Type, key.Index), Position: bomb.ModulePosition(key.Type, key.Index), State: view})
+		}
+	}
+	bomb.dirty = nil
+	return delta
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Difficulty is a lobby preset that tunes the manual rules, the timer and the strike limit together
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyNormal Difficulty = "normal"
+	DifficultyHard   Difficulty = "hard"
+	DifficultyExpert Difficulty = "expert"
+)
+
+// AllDifficulties lists every difficulty, from easiest to hardest
+var AllDifficulties = []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard, DifficultyExpert}
+
+// RuleGenOptions tunes how the wire, button and terminal manuals are generated
+// Generation stays deterministic for a given seed and set of options
+type RuleGenOptions struct {
+	MinRules int // Fewest conditional rules per wire count and in the button manual
+	MaxRules int // Most conditional rules per wire count and in the button manual
+	// CompoundConditions lets wire and button rules also require a condition on the bomb's edgework
+	CompoundConditions bool
+	// TerminalRules is the number of prompt -> command entries in the terminal manual
+	TerminalRules int
+	// StripedWires adds wire rules about stripes, for bombs whose wires can be striped
+	StripedWires bool
+}
+
+// DifficultyPreset is what a difficulty changes about the game
+type DifficultyPreset struct {
+	Rules      RuleGenOptions
+	MaxStrikes int // Strike limit the lobby switches to when the difficulty is picked
+	// TimeMultiplier scales the time limit of every bomb
+	TimeMultiplier float64
+}
+
+// difficultyPresets maps each difficulty to its preset; normal matches the classic rules
+var difficultyPresets = map[Difficulty]DifficultyPreset{
+	DifficultyEasy: {
+		Rules:          RuleGenOptions{MinRules: 2, MaxRules: 3, TerminalRules: 12},
+		MaxStrikes:     5,
+		TimeMultiplier: 1.5,
+	},
+	DifficultyNormal: {
+		Rules:          RuleGenOptions{MinRules: 3, MaxRules: 5, TerminalRules: 20},
+		MaxStrikes:     DefaultMaxStrikes,
+		TimeMultiplier: 1,
+	},
+	DifficultyHard: {
+		Rules:          RuleGenOptions{MinRules: 4, MaxRules: 6, CompoundConditions: true, TerminalRules: 28},
+		MaxStrikes:     2,
+		TimeMultiplier: 0.8,
+	},
+	DifficultyExpert: {
+		Rules:          RuleGenOptions{MinRules: 5, MaxRules: 7, CompoundConditions: true, TerminalRules: 36},
+		MaxStrikes:     1,
+		TimeMultiplier: 0.6,
+	},
+}
+
+// ValidateDifficulty returns an error if the difficulty is not one of AllDifficulties
+func ValidateDifficulty(difficulty Difficulty) error {
+	if _, ok := difficultyPresets[difficulty]; !ok {
+		return fmt.Errorf("unknown difficulty %q", difficulty)
+	}
+	return nil
+}
+
+// PresetFor returns the preset of a difficulty, the normal one for unknown difficulties
+func PresetFor(difficulty Difficulty) DifficultyPreset {
+	if preset, ok := difficultyPresets[difficulty]; ok {
+		return preset
+	}
+	return difficultyPresets[DifficultyNormal]
+}
+
+// DefaultRuleGenOptions returns the rule options of the normal difficulty
+func DefaultRuleGenOptions() RuleGenOptions {
+	return difficultyPresets[DifficultyNormal].Rules
+}
+
+// ScaleTimeLimit applies the preset's time multiplier, never going below MinTimeLimit
+func (p DifficultyPreset) ScaleTimeLimit(seconds int) int {
+	scaled := int(float64(seconds) * p.TimeMultiplier)
+	if scaled < MinTimeLimit {
+		return MinTimeLimit
+	}
+	return scaled
+}
+
+// numRules draws how many conditional rules a manual section gets
+func (o RuleGenOptions) numRules(rng *rand.Rand) int {
+	return o.MinRules + rng.Intn(o.MaxRules-o.MinRules+1)
+}
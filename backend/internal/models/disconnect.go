@@ -0,0 +1,200 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PlayerDrop describes a player whose connection dropped during a game
+type PlayerDrop struct {
+	PlayerID   string     `json:"playerId"`
+	Name       string     `json:"name"`
+	Role       PlayerType `json:"role"`
+	Autopaused bool       `json:"autopaused"` // The bomb is paused until they come back or the host resumes
+}
+
+// SetAutopauseOnDefuserDrop sets whether the bomb pauses when its last connected defuser drops
+func (gs *GameSession) SetAutopauseOnDefuserDrop(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.AutopauseOnDefuserDrop = enabled
+}
+
+// GetAutopauseOnDefuserDrop returns whether the bomb pauses when its last defuser drops in a thread-safe way
+func (gs *GameSession) GetAutopauseOnDefuserDrop() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.AutopauseOnDefuserDrop
+}
+
+// dropPlayerLocked records a player removed during a game, pausing the bomb if they were
+// its last defuser and the lobby asked for it (caller must hold gs.mu, after the removal)
+// Returns nil if no game is being played
+func (gs *GameSession) dropPlayerLocked(player *Player) *PlayerDrop {
+	if gs.LobbyState != LobbyStateActive {
+		return nil
+	}
+
+	drop := &PlayerDrop{PlayerID: player.ID, Name: player.Name, Role: player.Type}
+	if player.Type == PlayerTypeDefuser && gs.AutopauseOnDefuserDrop && !gs.hasDefuserLocked() {
+		if bomb := gs.currentBombLocked(); bomb != nil && bomb.State == BombStateActive && !bomb.Paused {
+			bomb.Pause()
+			gs.autopausedBy = player.ID
+			drop.Autopaused = true
+		}
+	}
+
+	if gs.drops == nil {
+		gs.drops = make(map[string]*PlayerDrop)
+	}
+	gs.drops[player.ID] = drop
+	return drop
+}
+
+// hasDefuserLocked reports whether a connected player is defusing (caller must hold gs.mu)
+func (gs *GameSession) hasDefuserLocked() bool {
+	for _, player := range gs.Players {
+		if player.Type == PlayerTypeDefuser && !player.Disconnected {
+			return true
+		}
+	}
+	return false
+}
+
+// ReturnDroppedPlayer gives a player who dropped during the current game their name and role back
+// Returns the earlier drop (nil if they didn't drop during this game) and whether the bomb,
+// paused for them, resumed
+func (gs *GameSession) ReturnDroppedPlayer(playerID string) (*PlayerDrop, bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	drop, dropped := gs.drops[playerID]
+	player, exists := gs.Players[playerID]
+	if !dropped || !exists || gs.LobbyState != LobbyStateActive {
+		return nil, false
+	}
+	delete(gs.drops, playerID)
+	player.Name = drop.Name
+	player.Type = drop.Role
+
+	resumed := false
+	if gs.autopausedBy == playerID {
+		gs.autopausedBy = ""
+		if bomb := gs.currentBombLocked(); bomb != nil && bomb.State == BombStateActive && bomb.Paused {
+			bomb.Resume()
+			resumed = true
+		}
+	}
+	returned := *drop
+	returned.Autopaused = false
+	return &returned, resumed
+}
+
+// graceTimer is the pending removal of a disconnected player
+// Its identity tells a removal that fires late from one scheduled after a reconnect
+type graceTimer struct {
+	timer *time.Timer
+}
+
+// SetWaitForReconnecting sets whether starting a game waits for disconnected players
+// Otherwise they are left out of the game: not counted, not asked to be ready and not picked as defusers
+func (gs *GameSession) SetWaitForReconnecting(wait bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.WaitForReconnecting = wait
+	gs.upNext = nil
+}
+
+// GetWaitForReconnecting returns whether starting a game waits for disconnected players in a thread-safe way
+func (gs *GameSession) GetWaitForReconnecting() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.WaitForReconnecting
+}
+
+// DisconnectPlayer keeps a player whose socket closed as reconnecting, if conn is still their connection
+// The player is removed after grace unless they reconnect first; removed is then called with the
+// new host ID if the host role migrated. Returns whether the player was marked, and the drop to
+// announce if a game is being played
+func (gs *GameSession) DisconnectPlayer(playerID string, conn *Connection, grace time.Duration, removed func(newHostID string)) (bool, *PlayerDrop) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists || player.Conn != conn {
+		return false, nil
+	}
+
+	// Replace the entry like ReattachPlayer does; without a connection broadcasts skip the player
+	disconnected := *player
+	disconnected.Conn = nil
+	disconnected.Disconnected = true
+	gs.Players[playerID] = &disconnected
+	gs.LastActivity = time.Now()
+
+	gs.stopGraceTimerLocked(playerID)
+	pending := &graceTimer{}
+	pending.timer = time.AfterFunc(grace, func() {
+		if ok, newHostID := gs.expireGraceTimer(playerID, pending); ok {
+			removed(newHostID)
+		}
+	})
+	if gs.graceTimers == nil {
+		gs.graceTimers = make(map[string]*graceTimer)
+	}
+	gs.graceTimers[playerID] = pending
+
+	return true, gs.dropPlayerLocked(&disconnected)
+}
+
+// expireGraceTimer removes a player whose grace period ended, unless they reconnected since
+// Returns whether they were removed and the new host ID if the host role migrated
+func (gs *GameSession) expireGraceTimer(playerID string, pending *graceTimer) (bool, string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.graceTimers[playerID] != pending {
+		return false, ""
+	}
+	delete(gs.graceTimers, playerID)
+	return true, gs.removePlayerLocked(playerID)
+}
+
+// stopGraceTimerLocked cancels the pending removal of a player, if any (caller must hold gs.mu)
+func (gs *GameSession) stopGraceTimerLocked(playerID string) {
+	if pending, ok := gs.graceTimers[playerID]; ok {
+		pending.timer.Stop()
+		delete(gs.graceTimers, playerID)
+	}
+}
+
+// eligibleLocked reports whether a player takes part in the next game (caller must hold gs.mu)
+// Disconnected players are left out unless the lobby waits for them
+func (gs *GameSession) eligibleLocked(player *Player) bool {
+	return !player.Disconnected || gs.WaitForReconnecting
+}
+
+// eligibleCountLocked returns how many players take part in the next game (caller must hold gs.mu)
+func (gs *GameSession) eligibleCountLocked() int {
+	count := 0
+	for _, player := range gs.Players {
+		if gs.eligibleLocked(player) {
+			count++
+		}
+	}
+	return count
+}
+
+// reconnectingErrorLocked returns an error naming a disconnected player if the lobby waits for them
+// (caller must hold gs.mu)
+func (gs *GameSession) reconnectingErrorLocked() error {
+	if !gs.WaitForReconnecting {
+		return nil
+	}
+	for _, player := range gs.Players {
+		if player.Disconnected {
+			return fmt.Errorf("waiting for %s to reconnect", player.Name)
+		}
+	}
+	return nil
+}
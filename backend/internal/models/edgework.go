@@ -0,0 +1,110 @@
+package models
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// serialLetters are the letters a serial number can use
+// O is left out so it can't be mistaken for a zero
+const serialLetters = "ABCDEFGHIJKLMNPQRSTUVWXZ"
+
+// serialVowels are the vowels rules about the serial number look for
+const serialVowels = "AEIU"
+
+// IndicatorLabels are the labels an indicator light can carry
+var IndicatorLabels = []string{"SND", "CLR", "CAR", "IND", "FRQ", "SIG", "NSA", "MSA", "TRN", "BOB", "FRK"}
+
+const (
+	// MaxBatteries is the largest number of batteries on a bomb
+	MaxBatteries = 4
+	// MaxIndicators is the largest number of indicator lights on a bomb
+	MaxIndicators = 3
+)
+
+// Indicator is a labelled light on the bomb casing
+type Indicator struct {
+	Label string `json:"label"`
+	Lit   bool   `json:"lit"`
+}
+
+// BombContext is the bomb information (edgework) rules can reference beyond the module itself
+type BombContext struct {
+	SerialNumber string      `json:"serialNumber"`
+	Batteries    int         `json:"batteries"`
+	Indicators   []Indicator `json:"indicators"`
+}
+
+// NewBombContext generates the edgework of a bomb from its seed
+func NewBombContext(seed int64) *BombContext {
+	rng := rand.New(rand.NewSource(seed + int64(30000000)))
+	serialNumber := generateSerialNumber(rng)
+	batteries := rng.Intn(MaxBatteries + 1)
+
+	// Pick distinct indicator labels, each one randomly lit or unlit
+	numIndicators := rng.Intn(MaxIndicators + 1)
+	labels := rng.Perm(len(IndicatorLabels))[:numIndicators]
+	indicators := make([]Indicator, numIndicators)
+	for i, labelIndex := range labels {
+		indicators[i] = Indicator{
+			Label: IndicatorLabels[labelIndex],
+			Lit:   rng.Intn(2) == 0,
+		}
+	}
+
+	return &BombContext{
+		SerialNumber: serialNumber,
+		Batteries:    batteries,
+		Indicators:   indicators,
+	}
+}
+
+// generateSerialNumber builds a 6 character serial like "AB3K92"
+// The last character is always a digit so rules about the last digit always apply
+func generateSerialNumber(rng *rand.Rand) string {
+	var sb strings.Builder
+	for i := 0; i < 5; i++ {
+		if rng.Intn(2) == 0 {
+			sb.WriteByte(serialLetters[rng.Intn(len(serialLetters))])
+		} else {
+			sb.WriteByte(byte('0' + rng.Intn(10)))
+		}
+	}
+	sb.WriteByte(byte('0' + rng.Intn(10)))
+	return sb.String()
+}
+
+// SerialLastDigit returns the last digit of the serial number, or -1 if there is none
+func (c *BombContext) SerialLastDigit() int {
+	if c == nil {
+		return -1
+	}
+	for i := len(c.SerialNumber) - 1; i >= 0; i-- {
+		ch := c.SerialNumber[i]
+		if ch >= '0' && ch <= '9' {
+			return int(ch - '0')
+		}
+	}
+	return -1
+}
+
+// SerialHasVowel reports whether the serial number contains a vowel
+func (c *BombContext) SerialHasVowel() bool {
+	if c == nil {
+		return false
+	}
+	return strings.ContainsAny(c.SerialNumber, serialVowels)
+}
+
+// HasLitIndicator reports whether a lit indicator with the given label is on the bomb
+func (c *BombContext) HasLitIndicator(label string) bool {
+	if c == nil {
+		return false
+	}
+	for _, indicator := range c.Indicators {
+		if indicator.Label == label && indicator.Lit {
+			return true
+		}
+	}
+	return false
+}
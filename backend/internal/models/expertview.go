@@ -0,0 +1,128 @@
+package models
+
+import "time"
+
+// ExpertModuleStatus is what an expert knows of a module without seeing it
+type ExpertModuleStatus struct {
+	ModuleType  string             `json:"moduleType"`
+	ModuleIndex int                `json:"moduleIndex"` // Position among the modules of its type
+	ModuleID    string             `json:"moduleId"`
+	Position    int                `json:"position"` // Position in the unified module list
+	Manual      string             `json:"manual"`   // Key of the module's manual in the manual content's modules
+	Solved      bool               `json:"solved"`
+	Actions     []AttributedAction `json:"actions,omitempty"` // Who did what on the module, for the modules that record it
+}
+
+// ExpertBombView is the bomb as experts are shown it: what the defuser could tell them
+// It never has the solutions or the rule seed. The casing and the modules (wire colors,
+// button text...) are only included when the lobby lets experts see module details
+type ExpertBombView struct {
+	*DefuserBombView                      // Module details as the defuser sees them, nil when experts don't see them
+	ID               string               `json:"id"`
+	State            BombState            `json:"state"`
+	Strikes          int                  `json:"strikes"`
+	MaxStrikes       int                  `json:"maxStrikes"`
+	TimeRemaining    int                  `json:"timeRemaining"`
+	SpeedMultiplier  float64              `json:"speedMultiplier"`
+	Paused           bool                 `json:"paused"`
+	Difficulty       Difficulty           `json:"difficulty"`
+	ManualCode       string               `json:"manualCode"`
+	Deadline         int64                `json:"deadline,omitempty"` // Unix millis when the timer runs out, 0 while it isn't running
+	StartTime        time.Time            `json:"startTime"`
+	Modules          []ExpertModuleStatus `json:"modules"` // Every solvable module, in bomb order
+}
+
+// ExpertView returns the expert-facing view of the bomb
+// details is the defuser view to include, nil to leave the casing and the modules out
+func (b *Bomb) ExpertView(details *DefuserBombView) *ExpertBombView {
+	modules := make([]ExpertModuleStatus, 0, len(b.Modules))
+	indices := make(map[string]int)
+	for position, module := range b.Modules {
+		moduleType := module.Type()
+		modules = append(modules, ExpertModuleStatus{
+			Position:    position,
+			ModuleType:  moduleType,
+			ModuleIndex: indices[moduleType],
+			ModuleID:    module.ModuleID(),
+			Manual:      manualKey(moduleType),
+			Solved:      module.Solved(),
+			Actions:     moduleActionLog(module),
+		})
+		indices[moduleType]++
+	}
+
+	return &ExpertBombView{
+		DefuserBombView: details,
+		ID:              b.ID,
+		State:           b.State,
+		Strikes:         b.Strikes,
+		MaxStrikes:      b.MaxStrikes,
+		TimeRemaining:   b.TimeRemaining,
+		SpeedMultiplier: b.SpeedMultiplier,
+		Paused:          b.Paused,
+		Difficulty:      b.Difficulty,
+		ManualCode:      b.ManualCode,
+		Deadline:        b.DeadlineMillis(),
+		StartTime:       b.StartTime,
+		Modules:         modules,
+	}
+}
+
+// SetExpertSeesModuleDetails sets whether experts are shown the bomb casing and the modules
+func (gs *GameSession) SetExpertSeesModuleDetails(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.ExpertSeesModuleDetails = enabled
+}
+
+// GetExpertSeesModuleDetails returns whether experts are shown the bomb casing and the modules in a thread-safe way
+func (gs *GameSession) GetExpertSeesModuleDetails() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.ExpertSeesModuleDetails
+}
+
+// ModuleProgress counts the solved modules of one type
+type ModuleProgress struct {
+	Solved int `json:"solved"`
+	Total  int `json:"total"`
+}
+
+// ExpertProgress is a compact status of the bomb for the experts' status bar
+// It is small enough to be sent with every broadcast, while the manual itself stays cached
+type ExpertProgress struct {
+	Modules       map[string]*ModuleProgress `json:"modules"` // By module type
+	Solved        int                        `json:"solved"`
+	Total         int                        `json:"total"`
+	Unsolved      []int                      `json:"unsolved"` // Positions in the module list (as in the expert view's modules) of the modules left to solve
+	Strikes       int                        `json:"strikes"`
+	MaxStrikes    int                        `json:"maxStrikes"`
+	TimeRemaining int                        `json:"timeRemaining"`
+}
+
+// Progress summarizes how far the defusal went
+func (b *Bomb) Progress() *ExpertProgress {
+	progress := &ExpertProgress{
+		Modules:       make(map[string]*ModuleProgress),
+		Unsolved:      []int{},
+		Total:         len(b.Modules),
+		Strikes:       b.Strikes,
+		MaxStrikes:    b.MaxStrikes,
+		TimeRemaining: b.TimeRemaining,
+	}
+	for i, module := range b.Modules {
+		moduleProgress := progress.Modules[module.Type()]
+		if moduleProgress == nil {
+			moduleProgress = &ModuleProgress{}
+			progress.Modules[module.Type()] = moduleProgress
+		}
+		moduleProgress.Total++
+		if module.Solved() {
+			moduleProgress.Solved++
+			progress.Solved++
+		} else {
+			progress.Unsolved = append(progress.Unsolved, i)
+		}
+	}
+	return progress
+}
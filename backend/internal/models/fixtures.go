@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Game fixtures shared by the tests of this package and of the packages built on it
+
+// OneOfEachModule returns a module mix with one module of every type
+func OneOfEachModule() map[string]int {
+	mix := make(map[string]int, len(AllModuleTypes))
+	for _, moduleType := range AllModuleTypes {
+		mix[moduleType] = 1
+	}
+	return mix
+}
+
+// NewTestSession returns a solo practice session of playerID, already playing a bomb built
+// from the module config: its module mix sets the module count, and its other non-zero
+// options replace the session defaults. The same seed gives the same bomb
+func NewTestSession(playerID string, config BombConfig, seed int64) (*GameSession, error) {
+	timeLimit := config.TimeLimit
+	if timeLimit == 0 {
+		timeLimit = 300
+	}
+	gs := NewGameSession("SESSION", playerID, timeLimit, rand.New(rand.NewSource(seed)))
+	if err := gs.AddPlayer(playerID, "Defuser", PlayerTypeDefuser, NewConnection()); err != nil {
+		return nil, fmt.Errorf("AddPlayer: %w", err)
+	}
+	gs.SetPracticeMode(true)
+	if len(config.ModuleMix) > 0 {
+		if err := gs.SetModuleCount(ModuleMixTotal(config.ModuleMix)); err != nil {
+			return nil, fmt.Errorf("SetModuleCount: %w", err)
+		}
+		if err := gs.SetModuleMix(config.ModuleMix); err != nil {
+			return nil, fmt.Errorf("SetModuleMix: %w", err)
+		}
+	}
+
+	if config.MaxStrikes > 0 {
+		gs.MaxStrikes = config.MaxStrikes
+	}
+	if config.Difficulty != "" {
+		gs.Difficulty = config.Difficulty
+	}
+	if config.ReleaseWindow > 0 {
+		gs.ReleaseWindow = config.ReleaseWindow
+	}
+	if config.TerminalStrictness != "" {
+		gs.TerminalStrictness = config.TerminalStrictness
+	}
+	if config.TerminalSequenceLength > 0 {
+		gs.TerminalSequenceLength = config.TerminalSequenceLength
+	}
+	if config.TerminalLockoutSeconds > 0 {
+		gs.TerminalLockoutSeconds = config.TerminalLockoutSeconds
+	}
+	gs.StrikeTimePenalty = config.StrikeTimePenalty
+	gs.TimerAcceleration = config.TimerAcceleration
+	gs.EnableNeedyModules = config.EnableNeedyModules
+	gs.ModuleTypes = config.ModuleTypes
+	gs.StripedWires = config.StripedWires
+	gs.ButtonCyclingGauge = config.ButtonCyclingGauge
+	gs.TerminalHardMode = config.TerminalHardMode
+	gs.TerminalLockoutThreshold = config.TerminalLockoutThreshold
+
+	if err := StartTestGame(gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+// StartTestGame starts a game in a waiting session, skipping the start countdown
+func StartTestGame(gs *GameSession) error {
+	if err := gs.StartGame(); err != nil {
+		return fmt.Errorf("StartGame: %w", err)
+	}
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.Bombs[0].Start()
+	gs.LobbyState = LobbyStateActive
+	return nil
+}
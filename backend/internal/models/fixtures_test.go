@@ -0,0 +1,13 @@
+package models
+
+import "testing"
+
+// newTestSession is NewTestSession for player-1, failing the test if the game can't start
+func newTestSession(t *testing.T, config BombConfig, seed int64) *GameSession {
+	t.Helper()
+	gs, err := NewTestSession("player-1", config, seed)
+	if err != nil {
+		t.Fatalf("NewTestSession: %v", err)
+	}
+	return gs
+}
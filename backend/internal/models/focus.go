@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefuserFocus is the module a defuser has open, so experts can follow along
+// ModuleID is empty once the defuser left the module
+type DefuserFocus struct {
+	PlayerID    string `json:"playerId"`
+	ModuleID    string `json:"moduleId"`
+	ModuleType  string `json:"moduleType,omitempty"`
+	ModuleIndex int    `json:"moduleIndex"`
+	Position    int    `json:"position"` // Position of the module in the unified module list
+}
+
+// SetDefuserFocus records the module a defuser opened, an empty moduleID clearing their focus
+// Each defuser has their own focus; it is forgotten when the next bomb starts
+func (gs *GameSession) SetDefuserFocus(playerID string, moduleID string) (*DefuserFocus, error) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	bomb := gs.currentBombLocked()
+	if gs.LobbyState != LobbyStateActive || bomb == nil {
+		return nil, ErrGameNotActive
+	}
+	player, exists := gs.Players[playerID]
+	if !exists || player.Type != PlayerTypeDefuser {
+		return nil, fmt.Errorf("only defusers can focus a module")
+	}
+
+	focus := &DefuserFocus{PlayerID: playerID, ModuleID: moduleID, Position: -1}
+	if moduleID == "" {
+		delete(gs.focus, playerID)
+		return focus, nil
+	}
+	moduleType, moduleIndex, err := bomb.ModuleByID(moduleID)
+	if err != nil {
+		return nil, err
+	}
+	focus.ModuleType = moduleType
+	focus.ModuleIndex = moduleIndex
+	focus.Position = bomb.ModulePosition(moduleType, moduleIndex)
+
+	if gs.focus == nil {
+		gs.focus = make(map[string]*DefuserFocus)
+	}
+	gs.focus[playerID] = focus
+	return focus, nil
+}
+
+// GetDefuserFocus returns the focus of every defuser who has a module open, by player ID order
+func (gs *GameSession) GetDefuserFocus() []*DefuserFocus {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	focus := make([]*DefuserFocus, 0, len(gs.focus))
+	for _, f := range gs.focus {
+		copied := *f
+		focus = append(focus, &copied)
+	}
+	sort.Slice(focus, func(i, j int) bool { return focus[i].PlayerID < focus[j].PlayerID })
+	return focus
+}
+
+// ClearDefuserFocus clears the focus of the defusers on a module, or of every defuser if
+// moduleID is empty, and returns the cleared focus of each of them
+func (gs *GameSession) ClearDefuserFocus(moduleID string) []*DefuserFocus {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	var cleared []*DefuserFocus
+	for playerID, f := range gs.focus {
+		if moduleID != "" && f.ModuleID != moduleID {
+			continue
+		}
+		delete(gs.focus, playerID)
+		cleared = append(cleared, &DefuserFocus{PlayerID: playerID, Position: -1})
+	}
+	sort.Slice(cleared, func(i, j int) bool { return cleared[i].PlayerID < cleared[j].PlayerID })
+	return cleared
+}
@@ -0,0 +1,214 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// KeypadSymbols is the pool of symbol identifiers keypad keys and manual columns draw from
+var KeypadSymbols = []string{
+	"balloon", "at", "lambda", "lightning", "spider", "h-curl", "c-dot",
+	"euro", "cursive-q", "star-empty", "question", "copyright", "pumpkin",
+	"double-k", "melted-3", "six", "paragraph", "bt", "smiley", "psi",
+	"c-reverse", "three-prongs", "star-filled", "puzzle", "omega",
+}
+
+const (
+	// keypadKeyCount is the number of keys on a keypad module
+	keypadKeyCount = 4
+	// keypadColumnCount and keypadColumnLength shape the manual's symbol table
+	keypadColumnCount  = 6
+	keypadColumnLength = 7
+	// keypadMaxAttempts bounds the search for a key set that fits exactly one column
+	keypadMaxAttempts = 100
+)
+
+// KeypadModule represents the keypad module on the bomb
+// The four keys must be pressed in the order their symbols appear in the
+// only manual column that contains all four
+type KeypadModule struct {
+	moduleIdentity
+	Symbols      []string       `json:"symbols"`      // Symbol on each key, in key position order
+	Pressed      []bool         `json:"pressed"`      // Whether each key was pressed correctly
+	CorrectOrder []int          `json:"correctOrder"` // Key positions in the order they must be pressed
+	NextPress    int            `json:"nextPress"`    // Index in CorrectOrder of the next expected key
+	IsSolved     bool           `json:"isSolved"`
+	RuleSet      *KeypadRuleSet `json:"-"` // Rules for this module (not serialized)
+	manual       *ModuleManual  // Manual generated alongside the module
+}
+
+// KeypadModuleView is the defuser-facing view of a keypad module (no solution data)
+type KeypadModuleView struct {
+	ID       string   `json:"id"` // Stable module ID
+	Symbols  []string `json:"symbols"`
+	Pressed  []bool   `json:"pressed"`
+	IsSolved bool     `json:"isSolved"`
+}
+
+// DefuserView returns the keypad module state that can be shown to the defuser
+func (km *KeypadModule) DefuserView() *KeypadModuleView {
+	return &KeypadModuleView{
+		ID:       km.ID,
+		Symbols:  km.Symbols,
+		Pressed:  append([]bool{}, km.Pressed...),
+		IsSolved: km.IsSolved,
+	}
+}
+
+// KeypadRuleSet contains the symbol columns printed in the manual
+type KeypadRuleSet struct {
+	Columns [][]string `json:"-"`
+}
+
+// columnsContaining returns the indices of the columns that contain every given symbol
+func (rs *KeypadRuleSet) columnsContaining(symbols []string) []int {
+	matches := []int{}
+	for i, column := range rs.Columns {
+		containsAll := true
+		for _, symbol := range symbols {
+			if indexOf(column, symbol) < 0 {
+				containsAll = false
+				break
+			}
+		}
+		if containsAll {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// indexOf returns the position of value in values, or -1 if it is missing
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewKeypadModuleWithRules creates a new keypad module whose keys fit exactly one manual column
+// keypadSeed: seed for picking the keys (different for each module)
+// ruleSeed: seed for generating the columns (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewKeypadModuleWithRules(keypadSeed int64, ruleSeed int64) (*KeypadModule, *ModuleManual) {
+	rng := rand.New(rand.NewSource(keypadSeed))
+	ruleSet, moduleManual := GenerateKeypadModuleRulesWithSeed(ruleSeed)
+
+	var symbols []string
+	var column []string
+	for attempt := 0; attempt < keypadMaxAttempts; attempt++ {
+		column = ruleSet.Columns[rng.Intn(len(ruleSet.Columns))]
+		symbols = make([]string, 0, keypadKeyCount)
+		for _, i := range rng.Perm(len(column))[:keypadKeyCount] {
+			symbols = append(symbols, column[i])
+		}
+		// Keep the first set that can't be confused with another column
+		if len(ruleSet.columnsContaining(symbols)) == 1 {
+			break
+		}
+	}
+
+	// The solution is the order the symbols appear in their column
+	correctOrder := make([]int, 0, keypadKeyCount)
+	for _, symbol := range column {
+		if position := indexOf(symbols, symbol); position >= 0 {
+			correctOrder = append(correctOrder, position)
+		}
+	}
+
+	module := &KeypadModule{
+		Symbols:      symbols,
+		Pressed:      make([]bool, keypadKeyCount),
+		CorrectOrder: correctOrder,
+		RuleSet:      ruleSet,
+		manual:       moduleManual,
+	}
+	return module, moduleManual
+}
+
+// Press handles the defuser pressing the key at a position
+// Returns true if it was the next key in the order, false if out of order (strike)
+func (km *KeypadModule) Press(position int) bool {
+	if km.IsSolved || position < 0 || position >= len(km.Symbols) {
+		return false
+	}
+
+	if km.CorrectOrder[km.NextPress] != position {
+		return false
+	}
+
+	km.Pressed[position] = true
+	km.NextPress++
+	if km.NextPress >= len(km.CorrectOrder) {
+		km.IsSolved = true
+	}
+	return true
+}
+
+// GenerateKeypadModuleRulesWithSeed generates the symbol columns for keypad modules
+func GenerateKeypadModuleRulesWithSeed(seed int64) (*KeypadRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 6666666))
+
+	columns := make([][]string, keypadColumnCount)
+	manualRules := make([]ManualRule, 0, keypadColumnCount)
+	for i := range columns {
+		column := make([]string, 0, keypadColumnLength)
+		for _, symbolIndex := range rng.Perm(len(KeypadSymbols))[:keypadColumnLength] {
+			column = append(column, KeypadSymbols[symbolIndex])
+		}
+		columns[i] = column
+
+		manualRules = append(manualRules, manualRule(i+1, msg("keypad.rule", i+1, strings.Join(column, ", "))))
+	}
+
+	moduleManual := newModuleManual(msg("keypad.title"), msg("keypad.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"columns": columns,
+		"symbols": KeypadSymbols,
+	}
+
+	return &KeypadRuleSet{Columns: columns}, moduleManual
+}
+
+// Type returns the module type
+func (km *KeypadModule) Type() string {
+	return ModuleTypeKeypad
+}
+
+// Solved reports whether the keypad module is disarmed
+func (km *KeypadModule) Solved() bool {
+	return km.IsSolved
+}
+
+// View returns the defuser-facing state of the keypad module
+func (km *KeypadModule) View() interface{} {
+	return km.DefuserView()
+}
+
+// Manual returns the manual the keypad module was generated with
+func (km *KeypadModule) Manual() *ModuleManual {
+	return km.manual
+}
+
+// HandleAction applies a defuser action to the keypad module
+// Supports "press" with a key position; pressing a key twice is not a mistake
+func (km *KeypadModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "press" {
+		return ActionResult{}, unknownActionError(ModuleTypeKeypad, action)
+	}
+
+	var data struct {
+		Position int `json:"position"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	if data.Position < 0 || data.Position >= len(km.Pressed) || km.Pressed[data.Position] {
+		return ActionResult{}, fmt.Errorf("invalid or already pressed key")
+	}
+	return actionOutcome(km.Press(data.Position)), nil
+}
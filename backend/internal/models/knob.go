@@ -0,0 +1,208 @@
+package models
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+)
+
+// KnobDirection is where the knob points
+type KnobDirection string
+
+const (
+	KnobUp    KnobDirection = "up"
+	KnobRight KnobDirection = "right"
+	KnobDown  KnobDirection = "down"
+	KnobLeft  KnobDirection = "left"
+)
+
+// knobDirections is the clockwise order the knob turns through
+var knobDirections = []KnobDirection{KnobUp, KnobRight, KnobDown, KnobLeft}
+
+const (
+	// knobLEDCount is the number of LEDs above the knob, in two rows of six
+	knobLEDCount = 12
+	// knobPatternsPerDirection is how many LED patterns the manual lists for each direction
+	knobPatternsPerDirection = 2
+)
+
+// KnobModule represents the knob module on the bomb
+// The LEDs show one of the manual's patterns; the defuser turns the knob to the
+// direction the manual gives for it and confirms
+type KnobModule struct {
+	moduleIdentity
+	LEDs         []bool        `json:"leds"`         // State of each LED, top row then bottom row
+	Direction    KnobDirection `json:"direction"`    // Where the knob points right now
+	PatternIndex int           `json:"patternIndex"` // Index of the shown pattern in RuleSet.Patterns
+	IsSolved     bool          `json:"isSolved"`
+	RuleSet      *KnobRuleSet  `json:"-"` // Rules for this module (not serialized)
+	rng          *rand.Rand    // Seeded source for patterns
+	manual       *ModuleManual // Manual generated alongside the module
+}
+
+// KnobModuleView is the defuser-facing view of a knob module (no solution data)
+type KnobModuleView struct {
+	ID        string        `json:"id"` // Stable module ID
+	LEDs      []bool        `json:"leds"`
+	Direction KnobDirection `json:"direction"`
+	IsSolved  bool          `json:"isSolved"`
+}
+
+// DefuserView returns the knob module state that can be shown to the defuser
+func (km *KnobModule) DefuserView() *KnobModuleView {
+	return &KnobModuleView{
+		ID:        km.ID,
+		LEDs:      km.LEDs,
+		Direction: km.Direction,
+		IsSolved:  km.IsSolved,
+	}
+}
+
+// KnobPattern is an LED pattern of the manual and the direction it asks for
+type KnobPattern struct {
+	LEDs      []bool        `json:"leds"`
+	Direction KnobDirection `json:"direction"`
+}
+
+// KnobRuleSet contains the LED patterns printed in the manual
+type KnobRuleSet struct {
+	Patterns []KnobPattern `json:"-"`
+}
+
+// NewKnobModuleWithRules creates a new knob module showing one of the manual's patterns
+// knobSeed: seed for picking patterns and the starting direction (different for each module)
+// ruleSeed: seed for generating the patterns (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewKnobModuleWithRules(knobSeed int64, ruleSeed int64) (*KnobModule, *ModuleManual) {
+	rng := rand.New(rand.NewSource(knobSeed))
+	ruleSet, moduleManual := GenerateKnobModuleRulesWithSeed(ruleSeed)
+
+	module := &KnobModule{
+		Direction: knobDirections[rng.Intn(len(knobDirections))],
+		RuleSet:   ruleSet,
+		rng:       rng,
+		manual:    moduleManual,
+	}
+	module.rollPattern()
+	return module, moduleManual
+}
+
+// rollPattern shows a new pattern from the manual
+func (km *KnobModule) rollPattern() {
+	km.PatternIndex = km.rng.Intn(len(km.RuleSet.Patterns))
+	km.LEDs = km.RuleSet.Patterns[km.PatternIndex].LEDs
+}
+
+// Rotate turns the knob a quarter turn clockwise
+func (km *KnobModule) Rotate() bool {
+	if km.IsSolved {
+		return false
+	}
+
+	for i, direction := range knobDirections {
+		if direction == km.Direction {
+			km.Direction = knobDirections[(i+1)%len(knobDirections)]
+			break
+		}
+	}
+	return true
+}
+
+// Confirm checks the knob direction against the pattern shown
+// Returns true if correct, false if wrong (strike); the pattern changes after a wrong confirm
+func (km *KnobModule) Confirm() bool {
+	if km.IsSolved {
+		return false
+	}
+
+	if km.Direction != km.RuleSet.Patterns[km.PatternIndex].Direction {
+		km.rollPattern()
+		return false
+	}
+
+	km.IsSolved = true
+	return true
+}
+
+// describeKnobLEDs draws a pattern as two rows of six, "X" for lit and "." for off
+func describeKnobLEDs(leds []bool) string {
+	var sb strings.Builder
+	for i, lit := range leds {
+		if i == knobLEDCount/2 {
+			sb.WriteString(" / ")
+		}
+		if lit {
+			sb.WriteByte('X')
+		} else {
+			sb.WriteByte('.')
+		}
+	}
+	return sb.String()
+}
+
+// GenerateKnobModuleRulesWithSeed generates the LED patterns for knob modules
+// Every pattern is distinct so the LEDs always point to a single direction
+func GenerateKnobModuleRulesWithSeed(seed int64) (*KnobRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 13131313))
+
+	ruleSet := &KnobRuleSet{}
+	seen := make(map[string]bool)
+	manualRules := []ManualRule{}
+	for _, direction := range knobDirections {
+		for added := 0; added < knobPatternsPerDirection; {
+			leds := make([]bool, knobLEDCount)
+			for i := range leds {
+				leds[i] = rng.Intn(2) == 0
+			}
+			key := describeKnobLEDs(leds)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			added++
+
+			ruleSet.Patterns = append(ruleSet.Patterns, KnobPattern{LEDs: leds, Direction: direction})
+			manualRules = append(manualRules, manualRule(len(ruleSet.Patterns), msg("knob.rule", key, msg("knob.direction."+string(direction)))))
+		}
+	}
+
+	moduleManual := newModuleManual(msg("knob.title"), msg("knob.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"patterns": ruleSet.Patterns,
+	}
+
+	return ruleSet, moduleManual
+}
+
+// Type returns the module type
+func (km *KnobModule) Type() string {
+	return ModuleTypeKnob
+}
+
+// Solved reports whether the knob module is disarmed
+func (km *KnobModule) Solved() bool {
+	return km.IsSolved
+}
+
+// View returns the defuser-facing state of the knob module
+func (km *KnobModule) View() interface{} {
+	return km.DefuserView()
+}
+
+// Manual returns the manual the knob module was generated with
+func (km *KnobModule) Manual() *ModuleManual {
+	return km.manual
+}
+
+// HandleAction applies a defuser action to the knob module
+// Supports "rotate" and "confirm"; rotating never strikes
+func (km *KnobModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	switch action {
+	case "rotate":
+		km.Rotate()
+		return ActionResult{Correct: true}, nil
+	case "confirm":
+		return actionOutcome(km.Confirm()), nil
+	}
+	return ActionResult{}, unknownActionError(ModuleTypeKnob, action)
+}
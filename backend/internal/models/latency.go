@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// rttSmoothing is the weight of a new round-trip sample in a connection's moving average
+const rttSmoothing = 0.2
+
+// RecordRTT folds a round-trip time sample into the connection's moving average
+func (c *Connection) RecordRTT(sample time.Duration) {
+	if sample < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rtt == 0 {
+		c.rtt = sample
+		return
+	}
+	c.rtt = time.Duration(rttSmoothing*float64(sample) + (1-rttSmoothing)*float64(c.rtt))
+}
+
+// RTT returns the connection's smoothed round-trip time, zero until measured
+func (c *Connection) RTT() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rtt
+}
+
+// LatencyMillis returns a player's smoothed round-trip time in milliseconds, 0 if unknown
+func (p *Player) LatencyMillis() int {
+	if p.Conn == nil {
+		return 0
+	}
+	return int(p.Conn.RTT().Milliseconds())
+}
+
+// GetLatencies returns the round-trip time of every connected player in milliseconds
+// Players whose latency isn't measured yet are left out
+func (gs *GameSession) GetLatencies() map[string]int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	latencies := make(map[string]int, len(gs.Players))
+	for id, player := range gs.Players {
+		if ms := player.LatencyMillis(); ms > 0 {
+			latencies[id] = ms
+		}
+	}
+	return latencies
+}
+
+// GetPlayerRTT returns a player's smoothed round-trip time, zero if unknown
+func (gs *GameSession) GetPlayerRTT(playerID string) time.Duration {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists || player.Conn == nil {
+		return 0
+	}
+	return player.Conn.RTT()
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"bombs/internal/utils"
+	"fmt"
+)
+
+// MaxLobbyPasswordLength bounds the join password a host can set
+const MaxLobbyPasswordLength = 64
+
+// SetPassword sets the password players need to join the session
+// Only a salted hash is kept. An empty password makes the lobby public again
+func (gs *GameSession) SetPassword(password string) error {
+	hash, err := hashLobbyPassword(password)
+	if err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.PasswordHash = hash
+	return nil
+}
+
+// hashLobbyPassword validates a join password and returns its salted hash, empty for no password
+func hashLobbyPassword(password string) (string, error) {
+	if len(password) > MaxLobbyPasswordLength {
+		return "", fmt.Errorf("password must be at most %d characters", MaxLobbyPasswordLength)
+	}
+	if password == "" {
+		return "", nil
+	}
+	return utils.HashPassword(password)
+}
+
+// HasPassword returns whether joining the session requires a password
+func (gs *GameSession) HasPassword() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.PasswordHash != ""
+}
+
+// CheckPassword returns whether password lets a player join the session
+// Any password is accepted when the session has none
+func (gs *GameSession) CheckPassword(password string) bool {
+	gs.mu.RLock()
+	hash := gs.PasswordHash
+	gs.mu.RUnlock()
+	return hash == "" || utils.CheckPassword(hash, password)
+}
+
+// GetPasswordHash returns the hash of the join password in a thread-safe way, empty for a public lobby
+func (gs *GameSession) GetPasswordHash() string {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.PasswordHash
+}
@@ -0,0 +1,255 @@
+package models
+
+// LobbySettings is an update of the lobby settings by the host
+// Zero values and nil fields leave the corresponding setting unchanged
+type LobbySettings struct {
+	ModuleCount              int                 `json:"moduleCount"` // 3-12
+	DefuserID                string              `json:"defuserId"`   // Empty if random
+	IsRandomDefuser          bool                `json:"isRandomDefuser"`
+	DefuserCount             int                 `json:"defuserCount"`                       // Players defusing together (1-3)
+	DefuserIDs               *[]string           `json:"defuserIds,omitempty"`               // Defusers chosen by the host (empty to use defuserId), nil leaves them unchanged
+	RotateDefuser            *bool               `json:"rotateDefuser,omitempty"`            // Random picks rotate between rounds, nil leaves it unchanged
+	RotationMode             *RotationMode       `json:"rotationMode,omitempty"`             // avoidRepeat or joinOrder, nil leaves it unchanged
+	TimeLimit                int                 `json:"timeLimit"`                          // Time limit in seconds (60-3600)
+	RequireReady             *bool               `json:"requireReady,omitempty"`             // Nil leaves the setting unchanged
+	MaxStrikes               int                 `json:"maxStrikes"`                         // Strikes before explosion (1-10)
+	StrikeTimePenalty        *int                `json:"strikeTimePenalty,omitempty"`        // Seconds lost per strike, nil leaves it unchanged
+	TimerAcceleration        *bool               `json:"timerAcceleration,omitempty"`        // Strikes speed up the timer, nil leaves it unchanged
+	Mission                  *[]MissionBomb      `json:"mission,omitempty"`                  // Bombs played back-to-back, nil leaves it unchanged
+	CarryStrikes             *bool               `json:"carryStrikes,omitempty"`             // Strikes carry over between mission bombs, nil leaves it unchanged
+	AutopauseOnDefuserDrop   *bool               `json:"autopauseOnDefuserDrop,omitempty"`   // Pause the bomb while its last defuser is disconnected, nil leaves it unchanged
+	ExpertSeesModuleDetails  *bool               `json:"expertSeesModuleDetails,omitempty"`  // Experts see the casing and the modules, not only their status, nil leaves it unchanged
+	WaitForReconnecting      *bool               `json:"waitForReconnecting,omitempty"`      // Starting waits for disconnected players instead of leaving them out, nil leaves it unchanged
+	EnableNeedyModules       *bool               `json:"enableNeedyModules,omitempty"`       // Add needy modules to bombs, nil leaves it unchanged
+	StripedWires             *bool               `json:"stripedWires,omitempty"`             // Wires can have a stripe of a second color, nil leaves it unchanged
+	ButtonCyclingGauge       *bool               `json:"buttonCyclingGauge,omitempty"`       // The gauge of a held button cycles through colors, nil leaves it unchanged
+	TerminalHardMode         *bool               `json:"terminalHardMode,omitempty"`         // A wrong terminal command restarts the sequence with new texts, nil leaves it unchanged
+	TerminalSequenceLength   int                 `json:"terminalSequenceLength"`             // Commands that solve a terminal (2-6), 0 leaves it unchanged
+	TerminalStrictness       *TerminalStrictness `json:"terminalStrictness,omitempty"`       // strict, forgiving or hardcore command matching, nil leaves it unchanged
+	TerminalLockoutThreshold *int                `json:"terminalLockoutThreshold,omitempty"` // Wrong commands that lock a terminal out (0 disables the lockout), nil leaves it unchanged
+	TerminalLockoutSeconds   int                 `json:"terminalLockoutSeconds"`             // How long a terminal lockout lasts (5-300 seconds), 0 leaves it unchanged
+	ModuleTypes              *[]string           `json:"moduleTypes,omitempty"`              // Module types bombs can use (empty for all), nil leaves it unchanged
+	ModuleMix                *map[string]int     `json:"moduleMix,omitempty"`                // Modules per type, adding up to moduleCount (empty for a random split), nil leaves it unchanged
+	Difficulty               *Difficulty         `json:"difficulty,omitempty"`               // Difficulty preset, also resets maxStrikes to the preset's; nil leaves it unchanged
+	PracticeMode             *bool               `json:"practiceMode,omitempty"`             // Allow starting alone and mark results as practice, nil leaves it unchanged
+	WebhookURL               *string             `json:"webhookUrl,omitempty"`               // Called when a game ends (empty for the server default), nil leaves it unchanged
+	Password                 *string             `json:"password,omitempty"`                 // Join password (empty for a public lobby), nil leaves it unchanged
+}
+
+// ApplyLobbySettings validates every setting of an update, then applies them all at once
+// Nothing changes if one of them is invalid, and no other update can interleave with it
+// It returns ErrNotInLobby once a game is starting or running
+func (gs *GameSession) ApplyLobbySettings(settings LobbySettings) error {
+	if err := validateLobbySettings(settings); err != nil {
+		return err
+	}
+
+	var webhookURL, passwordHash string
+	if settings.WebhookURL != nil {
+		var err error
+		if webhookURL, err = normalizeWebhookURL(*settings.WebhookURL); err != nil {
+			return err
+		}
+	}
+	if settings.Password != nil {
+		var err error
+		if passwordHash, err = hashLobbyPassword(*settings.Password); err != nil {
+			return err
+		}
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.LobbyState != LobbyStateWaiting {
+		return ErrNotInLobby
+	}
+
+	// The module mix has to add up to the module count it is applied with
+	moduleCount := gs.ModuleCount
+	if settings.ModuleCount > 0 {
+		moduleCount = settings.ModuleCount
+	}
+	if settings.ModuleMix != nil {
+		if err := checkModuleMixTotal(*settings.ModuleMix, moduleCount); err != nil {
+			return err
+		}
+	}
+
+	gs.ModuleCount = moduleCount
+	gs.setDefuserLocked(settings.DefuserID, settings.IsRandomDefuser)
+	if settings.DefuserCount > 0 && settings.DefuserCount != gs.DefuserCount {
+		gs.DefuserCount = settings.DefuserCount
+		gs.upNext = nil
+	}
+	if settings.DefuserIDs != nil {
+		gs.DefuserIDs = append([]string{}, *settings.DefuserIDs...)
+		gs.upNext = nil
+	}
+	if settings.RotateDefuser != nil && *settings.RotateDefuser != gs.RotateDefuser {
+		gs.RotateDefuser = *settings.RotateDefuser
+		gs.upNext = nil
+	}
+	if settings.RotationMode != nil && *settings.RotationMode != gs.RotationMode {
+		gs.RotationMode = *settings.RotationMode
+		gs.upNext = nil
+	}
+
+	// The difficulty comes before the strike limit so an explicit maxStrikes still wins
+	if settings.Difficulty != nil {
+		gs.Difficulty = *settings.Difficulty
+		gs.MaxStrikes = PresetFor(*settings.Difficulty).MaxStrikes
+	}
+	if settings.TimeLimit > 0 {
+		gs.TimeLimit = settings.TimeLimit
+	}
+	if settings.MaxStrikes > 0 {
+		gs.MaxStrikes = settings.MaxStrikes
+	}
+	if settings.StrikeTimePenalty != nil {
+		gs.StrikeTimePenalty = *settings.StrikeTimePenalty
+	}
+	if settings.TimerAcceleration != nil {
+		gs.TimerAcceleration = *settings.TimerAcceleration
+	}
+	if settings.Mission != nil {
+		gs.Mission = append([]MissionBomb(nil), *settings.Mission...)
+	}
+	if settings.CarryStrikes != nil {
+		gs.CarryStrikes = *settings.CarryStrikes
+	}
+	if settings.AutopauseOnDefuserDrop != nil {
+		gs.AutopauseOnDefuserDrop = *settings.AutopauseOnDefuserDrop
+	}
+	if settings.ExpertSeesModuleDetails != nil {
+		gs.ExpertSeesModuleDetails = *settings.ExpertSeesModuleDetails
+	}
+	if settings.WaitForReconnecting != nil {
+		gs.WaitForReconnecting = *settings.WaitForReconnecting
+		gs.upNext = nil
+	}
+	if settings.EnableNeedyModules != nil {
+		gs.EnableNeedyModules = *settings.EnableNeedyModules
+	}
+	if settings.StripedWires != nil {
+		gs.StripedWires = *settings.StripedWires
+	}
+	if settings.ButtonCyclingGauge != nil {
+		gs.ButtonCyclingGauge = *settings.ButtonCyclingGauge
+	}
+	if settings.TerminalHardMode != nil {
+		gs.TerminalHardMode = *settings.TerminalHardMode
+	}
+	if settings.TerminalSequenceLength > 0 {
+		gs.TerminalSequenceLength = settings.TerminalSequenceLength
+	}
+	if settings.TerminalStrictness != nil {
+		gs.TerminalStrictness = *settings.TerminalStrictness
+	}
+	if settings.TerminalLockoutThreshold != nil {
+		gs.TerminalLockoutThreshold = *settings.TerminalLockoutThreshold
+	}
+	if settings.TerminalLockoutSeconds > 0 {
+		gs.TerminalLockoutSeconds = settings.TerminalLockoutSeconds
+	}
+	if settings.PracticeMode != nil {
+		gs.PracticeMode = *settings.PracticeMode
+	}
+	if settings.WebhookURL != nil {
+		gs.WebhookURL = webhookURL
+	}
+	if settings.Password != nil {
+		gs.PasswordHash = passwordHash
+	}
+	if settings.ModuleTypes != nil {
+		gs.ModuleTypes = append([]string(nil), *settings.ModuleTypes...)
+	}
+	if settings.ModuleMix != nil {
+		gs.setModuleMixLocked(*settings.ModuleMix)
+	}
+	if settings.RequireReady != nil {
+		gs.RequireReady = *settings.RequireReady
+	}
+	return nil
+}
+
+// validateLobbySettings returns the first invalid setting of an update
+// The module mix total and the webhook and password are checked by ApplyLobbySettings
+func validateLobbySettings(settings LobbySettings) error {
+	if settings.ModuleCount > 0 {
+		if err := validateModuleCount(settings.ModuleCount); err != nil {
+			return err
+		}
+	}
+	if settings.DefuserCount > 0 {
+		if err := validateDefuserCount(settings.DefuserCount); err != nil {
+			return err
+		}
+	}
+	if settings.DefuserIDs != nil {
+		if err := validateDefuserIDs(*settings.DefuserIDs); err != nil {
+			return err
+		}
+	}
+	if settings.RotationMode != nil {
+		if err := ValidateRotationMode(*settings.RotationMode); err != nil {
+			return err
+		}
+	}
+	if settings.Difficulty != nil {
+		if err := ValidateDifficulty(*settings.Difficulty); err != nil {
+			return err
+		}
+	}
+	if settings.TimeLimit > 0 {
+		if err := validateTimeLimit(settings.TimeLimit); err != nil {
+			return err
+		}
+	}
+	if settings.MaxStrikes > 0 {
+		if err := validateMaxStrikes(settings.MaxStrikes); err != nil {
+			return err
+		}
+	}
+	if settings.StrikeTimePenalty != nil {
+		if err := validateStrikeTimePenalty(*settings.StrikeTimePenalty); err != nil {
+			return err
+		}
+	}
+	if settings.Mission != nil {
+		if err := validateMission(*settings.Mission); err != nil {
+			return err
+		}
+	}
+	if settings.TerminalSequenceLength > 0 {
+		if err := ValidateTerminalSequenceLength(settings.TerminalSequenceLength); err != nil {
+			return err
+		}
+	}
+	if settings.TerminalStrictness != nil {
+		if err := ValidateTerminalStrictness(*settings.TerminalStrictness); err != nil {
+			return err
+		}
+	}
+	if settings.TerminalLockoutThreshold != nil {
+		if err := validateTerminalLockoutThreshold(*settings.TerminalLockoutThreshold); err != nil {
+			return err
+		}
+	}
+	if settings.TerminalLockoutSeconds > 0 {
+		if err := validateTerminalLockoutSeconds(settings.TerminalLockoutSeconds); err != nil {
+			return err
+		}
+	}
+	if settings.ModuleTypes != nil {
+		if err := validateModuleTypes(*settings.ModuleTypes); err != nil {
+			return err
+		}
+	}
+	if settings.ModuleMix != nil {
+		if err := validateModuleMix(*settings.ModuleMix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestApplyLobbySettings(t *testing.T) {
+	strict := TerminalStrictnessHardcore
+	hard := DifficultyHard
+	penalty := 99999
+	tests := []struct {
+		name      string
+		settings  LobbySettings
+		wantErr   bool
+		wantCount int
+		wantMix   map[string]int
+	}{
+		{"every setting valid", LobbySettings{ModuleCount: 4, ModuleMix: &map[string]int{ModuleTypeWires: 4}, TerminalStrictness: &strict}, false, 4, map[string]int{ModuleTypeWires: 4}},
+		{"mix checked against the new count", LobbySettings{ModuleCount: 4, ModuleMix: &map[string]int{ModuleTypeWires: 3}}, true, DefaultModuleCount, map[string]int{}},
+		{"invalid setting after valid ones", LobbySettings{ModuleCount: 4, Difficulty: &hard, StrikeTimePenalty: &penalty}, true, DefaultModuleCount, map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameSession("SESSION", "player-1", 300, rand.New(rand.NewSource(1)))
+			err := gs.ApplyLobbySettings(tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyLobbySettings error = %v, want error %v", err, tt.wantErr)
+			}
+			if _, got, _, _ := gs.GetLobbyInfo(); got != tt.wantCount {
+				t.Errorf("module count = %d, want %d", got, tt.wantCount)
+			}
+			if got := gs.GetModuleMix(); len(got) != len(tt.wantMix) || got[ModuleTypeWires] != tt.wantMix[ModuleTypeWires] {
+				t.Errorf("module mix = %v, want %v", got, tt.wantMix)
+			}
+			if tt.wantErr && (gs.GetDifficulty() != DifficultyNormal || gs.GetTerminalStrictness() != TerminalStrictnessStrict) {
+				t.Errorf("a rejected update changed the difficulty to %s or the strictness to %s", gs.GetDifficulty(), gs.GetTerminalStrictness())
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language manuals are generated in, and the fallback for missing translations
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// locales maps each language to its messages, by key
+// Messages are text with {0}, {1}... placeholders for their arguments
+var locales = loadLocales()
+
+// loadLocales reads the embedded locale files, named after their language
+func loadLocales() map[string]map[string]string {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("locale %s: %v", file.Name(), err))
+		}
+		loaded[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	return loaded
+}
+
+// SupportedLanguages returns the languages manuals can be rendered in, sorted
+func SupportedLanguages() []string {
+	languages := make([]string, 0, len(locales))
+	for language := range locales {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ParseLanguage returns the supported language a language tag asks for
+// Regions are ignored ("fr-CA" is "fr"), and an empty tag is the default language
+func ParseLanguage(tag string) (string, error) {
+	if tag == "" {
+		return DefaultLanguage, nil
+	}
+	language := strings.ToLower(tag)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if _, ok := locales[language]; !ok {
+		return "", fmt.Errorf("language must be one of %s", strings.Join(SupportedLanguages(), ", "))
+	}
+	return language, nil
+}
+
+// Message is a piece of manual text that can be rendered in any supported language
+// Args are strings or numbers, which read the same in every language, or nested messages
+type Message struct {
+	Key  string
+	Args []interface{}
+}
+
+// msg creates a message
+func msg(key string, args ...interface{}) *Message {
+	return &Message{Key: key, Args: args}
+}
+
+// Render returns the message in a language, falling back to the default language and then to its key
+func (m *Message) Render(language string) string {
+	if m == nil {
+		return ""
+	}
+	format, ok := locales[language][m.Key]
+	if !ok {
+		format, ok = locales[DefaultLanguage][m.Key]
+	}
+	if !ok {
+		return m.Key
+	}
+
+	replacements := make([]string, 0, 2*len(m.Args))
+	for i, arg := range m.Args {
+		value := fmt.Sprint(arg)
+		if nested, ok := arg.(*Message); ok {
+			value = nested.Render(language)
+		}
+		replacements = append(replacements, "{"+strconv.Itoa(i)+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(format)
+}
+
+// manualRule creates a manual rule whose description is text in the default language
+func manualRule(number int, text *Message) ManualRule {
+	return ManualRule{Number: number, Description: text.Render(DefaultLanguage), Text: text}
+}
+
+// newModuleManual creates a module manual whose title and instructions are rendered in the default language
+func newModuleManual(title, instructions *Message, rules []ManualRule) *ModuleManual {
+	return &ModuleManual{
+		Title:            title.Render(DefaultLanguage),
+		Rules:            rules,
+		Instructions:     instructions.Render(DefaultLanguage),
+		TitleText:        title,
+		InstructionsText: instructions,
+	}
+}
+
+// withNote adds a sentence after the manual's instructions, e.g. about a lobby setting
+func (m *ModuleManual) withNote(note *Message) {
+	m.InstructionsText = msg("manual.instructionsWithNote", m.InstructionsText, note)
+	m.Instructions = m.InstructionsText.Render(DefaultLanguage)
+}
+
+// localizeRules renders rules in a language; rules without a message read the same in every language
+func localizeRules(rules []ManualRule, language string) []ManualRule {
+	localized := make([]ManualRule, len(rules))
+	for i, rule := range rules {
+		localized[i] = rule
+		if rule.Text != nil {
+			localized[i].Description = rule.Text.Render(language)
+		}
+	}
+	return localized
+}
+
+// Localize returns the manual rendered in a language
+// Manuals are generated in the default language, so they are returned as is for it
+func (m *ModuleManual) Localize(language string) *ModuleManual {
+	if m == nil || language == DefaultLanguage {
+		return m
+	}
+	localized := *m
+	if m.TitleText != nil {
+		localized.Title = m.TitleText.Render(language)
+	}
+	if m.InstructionsText != nil {
+		localized.Instructions = m.InstructionsText.Render(language)
+	}
+	localized.Rules = localizeRules(m.Rules, language)
+	return &localized
+}
+
+// Localize returns the wires manual rendered in a language
+func (m *WireModuleManual) Localize(language string) *WireModuleManual {
+	if m == nil || language == DefaultLanguage {
+		return m
+	}
+	localized := *m
+	if m.TitleText != nil {
+		localized.Title = m.TitleText.Render(language)
+	}
+	if m.InstructionsText != nil {
+		localized.Instructions = m.InstructionsText.Render(language)
+	}
+	localized.Rules = localizeRules(m.Rules, language)
+	return &localized
+}
+
+// Localize returns the manual content with every module manual rendered in a language
+// The bomb state is shared, only the text is copied
+func (c *ManualContent) Localize(language string) *ManualContent {
+	if c == nil || language == DefaultLanguage {
+		return c
+	}
+	localized := *c
+	localized.WireModule = c.WireModule.Localize(language)
+	localized.Modules = make(map[string]*ModuleManual, len(c.Modules))
+	for moduleType, manual := range c.Modules {
+		localized.Modules[moduleType] = manual.Localize(language)
+	}
+	return &localized
+}
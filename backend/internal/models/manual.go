@@ -23,25 +23,50 @@ func getOrdinalSuffix(n int) string {
 	}
 }
 
-// isDefaultRule checks if a rule description is a default "Otherwise" rule
-func isDefaultRule(description string) bool {
-	return len(description) > 0 && description[:9] == "Otherwise"
-}
-
 // ManualRule represents a single rule in the manual
+// Rules built from a structure carry it along with their text: a condition and an action for
+// wires and button rules, the terminal text and command for terminal rules
 type ManualRule struct {
-	Number      int    `json:"number"`
-	Description string `json:"description"`
+	Number       int            `json:"number"`
+	Description  string         `json:"description"`
+	Text         *Message       `json:"-"`                   // Description in any language, nil if it reads the same in all of them
+	Condition    *RuleCondition `json:"condition,omitempty"` // Nil for titles and default rules
+	Action       *RuleAction    `json:"action,omitempty"`
+	TerminalText string         `json:"terminalText,omitempty"`
+	Command      string         `json:"command,omitempty"`
 }
 
 // WireRuleEvaluator is a function that evaluates a condition on wires and returns the wire index to cut if condition matches, or -1 if it doesn't match
-type WireRuleEvaluator func(wires []WireColor) int
+// ctx carries the bomb's edgework (serial number...) and may be nil for wire-only rules
+type WireRuleEvaluator func(wires []Wire, ctx *BombContext) int
+
+// wiresOnly adapts a condition that only looks at the wires to a WireRuleEvaluator
+func wiresOnly(evaluator func(wires []Wire) int) WireRuleEvaluator {
+	return func(wires []Wire, ctx *BombContext) int {
+		return evaluator(wires)
+	}
+}
+
+// edgeworkCondition adapts a condition on the bomb's edgework to a WireRuleEvaluator
+// The condition never matches without a bomb context
+func edgeworkCondition(matches func(ctx *BombContext) bool) WireRuleEvaluator {
+	return func(wires []Wire, ctx *BombContext) int {
+		if ctx != nil && matches(ctx) {
+			return 0 // Condition matches
+		}
+		return -1 // Condition doesn't match
+	}
+}
 
 // WireRule represents a rule with both description and evaluator function
 type WireRule struct {
 	Number      int               `json:"number"`
 	Description string            `json:"description"`
-	Evaluator   WireRuleEvaluator `json:"-"` // Not serialized, used for evaluation
+	Text        *Message          `json:"-"` // Description in any language
+	Condition   *RuleCondition    `json:"-"` // Structure of the rule, nil for the default rule
+	Action      *RuleAction       `json:"-"`
+	Default     bool              `json:"default"` // The "Otherwise" rule, always matches
+	Evaluator   WireRuleEvaluator `json:"-"`       // Not serialized, used for evaluation
 }
 
 // ModuleManual represents the manual content for any module type
@@ -51,6 +76,9 @@ type ModuleManual struct {
 	Instructions string       `json:"instructions"`
 	// Module-specific data (e.g., WireColors for wire module)
 	ModuleData map[string]interface{} `json:"moduleData,omitempty"`
+	// TitleText and InstructionsText render Title and Instructions in any language
+	TitleText        *Message `json:"-"`
+	InstructionsText *Message `json:"-"`
 }
 
 // WireModuleManual contains the manual content for the wires module
@@ -60,78 +88,109 @@ type WireModuleManual struct {
 	Rules        []ManualRule `json:"rules"`
 	WireColors   []string     `json:"wireColors"`
 	Instructions string       `json:"instructions"`
+	// TitleText and InstructionsText render Title and Instructions in any language
+	TitleText        *Message `json:"-"`
+	InstructionsText *Message `json:"-"`
 }
 
+// MinWires and MaxWires bound the number of wires on a wires module
+const (
+	MinWires = 3
+	MaxWires = 6
+)
+
 // WireRuleSet contains the rules with evaluators for a wire module
+// It is generated once per seed and wire count, and is the only source of both
+// the module's correct cut and the manual's rule text
 type WireRuleSet struct {
-	Rules []WireRule `json:"-"`
+	WireCount   int        `json:"-"`
+	Rules       []WireRule `json:"-"` // Conditional rules in manual order, then the default rule
+	DefaultWire int        `json:"-"` // Wire the default rule cuts
+	Striped     bool       `json:"-"` // Wires can have stripes, which some rules are about
+}
+
+// CorrectCut returns the wire the rules dictate: the first rule that matches, top to bottom
+// ctx may be nil, in which case rules about the bomb's edgework never match
+func (rs *WireRuleSet) CorrectCut(wires []Wire, ctx *BombContext) int {
+	for _, rule := range rs.Rules {
+		if result := rule.Evaluator(wires, ctx); result >= 0 {
+			return result
+		}
+	}
+	// The default rule always matches, this is only reached for an empty set
+	return rs.DefaultWire
+}
+
+// Manual returns the manual of a single module with these rules
+func (rs *WireRuleSet) Manual() *ModuleManual {
+	manualRules := make([]ManualRule, 0, len(rs.Rules))
+	for _, rule := range rs.Rules {
+		manualRules = append(manualRules, structuredRule(rule.Number, rule.Condition, rule.Action, rule.Text))
+	}
+	moduleManual := newModuleManual(msg("wires.title"), msg("wires.instructions.single"), manualRules)
+	if rs.Striped {
+		moduleManual.withNote(msg("wires.note.stripes"))
+	}
+	moduleManual.ModuleData = map[string]interface{}{
+		"wireColors": []string{"red", "blue", "green", "white", "yellow"},
+	}
+	return moduleManual
 }
 
 // GenerateWireModuleRules generates random rules for wire modules based on the number of wires
-// Uses global random source (not deterministic)
-func GenerateWireModuleRules(numWires int) (*WireRuleSet, *ModuleManual) {
-	seed := rand.Int63()
-	return generateWireModuleRulesWithRNG(numWires, rand.New(rand.NewSource(seed)), seed)
+// rng draws the seed, so the rules are as deterministic as rng
+func GenerateWireModuleRules(numWires int, rng *rand.Rand) (*WireRuleSet, *ModuleManual) {
+	seed := rng.Int63()
+	return generateWireModuleRulesWithRNG(numWires, rand.New(rand.NewSource(seed)), seed, DefaultRuleGenOptions())
+}
+
+// GenerateWireRuleSets generates the wire rules of a bomb, one set per wire count (3-6)
+// Wires modules and the comprehensive manual both read these sets, so they can't disagree
+func GenerateWireRuleSets(seed int64, opts RuleGenOptions) map[int]*WireRuleSet {
+	sets := make(map[int]*WireRuleSet, MaxWires-MinWires+1)
+	for wireCount := MinWires; wireCount <= MaxWires; wireCount++ {
+		// Each wire count gets different but deterministic rules
+		sets[wireCount], _ = GenerateWireModuleRulesWithSeed(wireCount, seed+int64(wireCount), opts)
+	}
+	return sets
 }
 
 // GenerateComprehensiveWireModuleManual generates a manual with rules for all wire counts (3, 4, 5, 6)
 // Uses a seed to ensure deterministic generation (rules don't change)
-func GenerateComprehensiveWireModuleManual(seed int64) *WireModuleManual {
+// opts must be the ones the bomb's wire modules were generated with
+func GenerateComprehensiveWireModuleManual(seed int64, opts RuleGenOptions) *WireModuleManual {
+	return ComprehensiveWireModuleManual(GenerateWireRuleSets(seed, opts))
+}
+
+// ComprehensiveWireModuleManual builds the manual with rules for all wire counts from a bomb's rule sets
+func ComprehensiveWireModuleManual(ruleSets map[int]*WireRuleSet) *WireModuleManual {
 	allRules := []ManualRule{}
 	ruleNumber := 1
 
-	// Generate rules for each wire count (3, 4, 5, 6)
-	for wireCount := 3; wireCount <= 6; wireCount++ {
+	// Add rules for each wire count (3, 4, 5, 6)
+	for wireCount := MinWires; wireCount <= MaxWires; wireCount++ {
+		ruleSet := ruleSets[wireCount]
+		if ruleSet == nil {
+			continue
+		}
+
 		// Add section header
-		allRules = append(allRules, ManualRule{
-			Number:      ruleNumber,
-			Description: fmt.Sprintf("=== Rules for %d wires ===", wireCount),
-		})
+		allRules = append(allRules, manualRule(ruleNumber, msg("wires.section", wireCount)))
 		ruleNumber++
 
-		// Generate rules for this wire count with a deterministic seed
-		// Use seed + wireCount to get different but deterministic rules for each count
-		_, moduleManual := GenerateWireModuleRulesWithSeed(wireCount, seed+int64(wireCount))
-
-		// Add rules from this wire count (excluding the default "Otherwise" rule for now)
-		for _, rule := range moduleManual.Rules {
-			// Skip the default rule as we'll add our own
-			if !isDefaultRule(rule.Description) {
-				allRules = append(allRules, ManualRule{
-					Number:      ruleNumber,
-					Description: rule.Description,
-				})
-				ruleNumber++
+		// Add the conditional rules, then the default rule naming the wire count
+		for _, rule := range ruleSet.Rules {
+			condition, text := rule.Condition, rule.Text
+			if rule.Default {
+				condition = ruleCondition(ConditionWireCount, "count", wireCount)
+				text = wireRuleText(condition, rule.Action)
 			}
+			allRules = append(allRules, structuredRule(ruleNumber, condition, rule.Action, text))
+			ruleNumber++
 		}
 
-		// Add default rule for this wire count
-		// Use the same seed calculation as in generateWireModuleRulesWithRNG
-		// The seed passed to GenerateWireModuleRulesWithSeed is seed + int64(wireCount)
-		// In generateWireModuleRulesWithRNG, the default wire seed is: (seed + int64(wireCount)) + 777777 + int64(wireCount)
-		// Which simplifies to: seed + 2*int64(wireCount) + 777777
-		ruleSeed := seed + int64(wireCount) // This is what's passed to GenerateWireModuleRulesWithSeed
-		defaultRNG := rand.New(rand.NewSource(ruleSeed + 777777 + int64(wireCount)))
-		defaultWireIndex := defaultRNG.Intn(wireCount)
-		wirePosition := "first"
-		if defaultWireIndex == wireCount-1 {
-			wirePosition = "last"
-		} else if defaultWireIndex == 1 {
-			wirePosition = "second"
-		} else if defaultWireIndex == 2 {
-			wirePosition = "third"
-		} else {
-			wirePosition = fmt.Sprintf("%d%s", defaultWireIndex+1, getOrdinalSuffix(defaultWireIndex+1))
-		}
-
-		allRules = append(allRules, ManualRule{
-			Number:      ruleNumber,
-			Description: fmt.Sprintf("For %d wires, otherwise cut the %s one.", wireCount, wirePosition),
-		})
-		ruleNumber++
-
 		// Add spacing between sections
-		if wireCount < 6 {
+		if wireCount < MaxWires {
 			allRules = append(allRules, ManualRule{
 				Number:      ruleNumber,
 				Description: "",
@@ -140,60 +199,126 @@ func GenerateComprehensiveWireModuleManual(seed int64) *WireModuleManual {
 		}
 	}
 
+	title := msg("wires.title")
+	instructions := msg("wires.instructions")
 	return &WireModuleManual{
-		Title:        "Bombz Manual - Wires Module",
-		Rules:        allRules,
-		WireColors:   []string{"red", "blue", "green", "white", "yellow"},
-		Instructions: "As an expert, your job is to guide the defuser through the wires module using these rules. Look at the number of wires in each module and use the corresponding rules section. Tell the defuser which wire to cut based on the rules above.",
+		Title:            title.Render(DefaultLanguage),
+		Rules:            allRules,
+		WireColors:       []string{"red", "blue", "green", "white", "yellow"},
+		Instructions:     instructions.Render(DefaultLanguage),
+		TitleText:        title,
+		InstructionsText: instructions,
 	}
 }
 
-// GenerateWireModuleRulesWithSeed generates random rules for wire modules with a specific seed for determinism
-func GenerateWireModuleRulesWithSeed(numWires int, seed int64) (*WireRuleSet, *ModuleManual) {
-	// Create a new random source with the given seed
-	rng := rand.New(rand.NewSource(seed))
+// ordinalPosition names a 0-based position counted from the first wire
+func ordinalPosition(index int) *Message {
+	switch index {
+	case 0:
+		return msg("position.first")
+	case 1:
+		return msg("position.second")
+	case 2:
+		return msg("position.third")
+	}
+	return msg("position.nth", index+1, getOrdinalSuffix(index+1))
+}
 
-	// Use the same logic as GenerateWireModuleRules but with the seeded RNG
-	return generateWireModuleRulesWithRNG(numWires, rng, seed)
+// colorName names a wire, button, gauge or Simon color in the manual
+func colorName(color string) *Message {
+	return msg("color." + color)
+}
+
+// ModuleManual returns the wires manual in the format of the other module manuals
+func (m *WireModuleManual) ModuleManual() *ModuleManual {
+	return &ModuleManual{
+		Title:        m.Title,
+		Rules:        m.Rules,
+		Instructions: m.Instructions,
+		ModuleData: map[string]interface{}{
+			"wireColors": m.WireColors,
+		},
+		TitleText:        m.TitleText,
+		InstructionsText: m.InstructionsText,
+	}
+}
+
+// legacyWireManual returns a wires manual in the format kept for older frontends
+func legacyWireManual(m *ModuleManual) *WireModuleManual {
+	if m == nil {
+		return nil
+	}
+	wireColors, _ := m.ModuleData["wireColors"].([]string)
+	return &WireModuleManual{
+		Title:            m.Title,
+		Rules:            m.Rules,
+		WireColors:       wireColors,
+		Instructions:     m.Instructions,
+		TitleText:        m.TitleText,
+		InstructionsText: m.InstructionsText,
+	}
+}
+
+// GenerateWireModuleRulesWithSeed generates random rules for wire modules with a specific seed for determinism
+// Sets with a rule that never matters are re-rolled with a seed derived from the given one
+func GenerateWireModuleRulesWithSeed(numWires int, seed int64, opts RuleGenOptions) (*WireRuleSet, *ModuleManual) {
+	var ruleSet *WireRuleSet
+	var moduleManual *ModuleManual
+	for attempt := 0; attempt <= maxWireRuleRerolls; attempt++ {
+		// Create a new random source with the (derived) seed
+		attemptSeed := seed + int64(attempt)*7919
+		ruleSet, moduleManual = generateWireModuleRulesWithRNG(numWires, rand.New(rand.NewSource(attemptSeed)), attemptSeed, opts)
+
+		// The layouts checked only depend on the seed, so validation is deterministic too
+		if ruleSet.validate(rand.New(rand.NewSource(attemptSeed+424242))) == nil {
+			break
+		}
+	}
+	return ruleSet, moduleManual
 }
 
 // generateWireModuleRulesWithRNG is the internal implementation that uses a specific RNG
 // seed is the original seed used to create the RNG, needed for deterministic default wire selection
-func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*WireRuleSet, *ModuleManual) {
+// opts sets how many rules are generated and whether they can combine two conditions
+func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64, opts RuleGenOptions) (*WireRuleSet, *ModuleManual) {
 	// Pools of all possible conditions and actions
 	allConditions := []struct {
-		name      string
+		condition *RuleCondition
 		evaluator WireRuleEvaluator
 		appliesTo func(int) bool
+		edgework  bool // Condition on the bomb rather than the wires, can be added to a compound rule
+		stripes   bool // Condition on stripes, only in the pool when wires can be striped
+		// impliesStripe is true when the condition only matches if a wire is striped
+		impliesStripe bool
 	}{
 		{
-			name: "there are no red wires",
-			evaluator: func(wires []WireColor) int {
+			condition: ruleCondition(ConditionNoWires, "color", string(Red)),
+			evaluator: wiresOnly(func(wires []Wire) int {
 				for _, w := range wires {
-					if w == Red {
+					if w.Color == Red {
 						return -1 // Condition doesn't match
 					}
 				}
 				return 0 // Condition matches (0 means true, -1 means false)
-			},
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "the last wire is white",
-			evaluator: func(wires []WireColor) int {
-				if len(wires) > 0 && wires[len(wires)-1] == White {
+			condition: ruleCondition(ConditionLastWireIs, "color", string(White)),
+			evaluator: wiresOnly(func(wires []Wire) int {
+				if len(wires) > 0 && wires[len(wires)-1].Color == White {
 					return 0 // Condition matches
 				}
 				return -1 // Condition doesn't match
-			},
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "there is more than one blue wire",
-			evaluator: func(wires []WireColor) int {
+			condition: ruleCondition(ConditionMoreThanOneWire, "color", string(Blue)),
+			evaluator: wiresOnly(func(wires []Wire) int {
 				count := 0
 				for _, w := range wires {
-					if w == Blue {
+					if w.Color == Blue {
 						count++
 					}
 				}
@@ -201,27 +326,27 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 					return 0 // Condition matches
 				}
 				return -1 // Condition doesn't match
-			},
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "there are no blue wires",
-			evaluator: func(wires []WireColor) int {
+			condition: ruleCondition(ConditionNoWires, "color", string(Blue)),
+			evaluator: wiresOnly(func(wires []Wire) int {
 				for _, w := range wires {
-					if w == Blue {
+					if w.Color == Blue {
 						return -1 // Condition doesn't match
 					}
 				}
 				return 0 // Condition matches
-			},
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "there is more than one yellow wire",
-			evaluator: func(wires []WireColor) int {
+			condition: ruleCondition(ConditionMoreThanOneWire, "color", string(Yellow)),
+			evaluator: wiresOnly(func(wires []Wire) int {
 				count := 0
 				for _, w := range wires {
-					if w == Yellow {
+					if w.Color == Yellow {
 						count++
 					}
 				}
@@ -229,25 +354,25 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 					return 0 // Condition matches
 				}
 				return -1 // Condition doesn't match
-			},
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "the first wire is green",
-			evaluator: func(wires []WireColor) int {
-				if len(wires) > 0 && wires[0] == Green {
+			condition: ruleCondition(ConditionFirstWireIs, "color", string(Green)),
+			evaluator: wiresOnly(func(wires []Wire) int {
+				if len(wires) > 0 && wires[0].Color == Green {
 					return 0
 				}
 				return -1
-			},
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "there is more than one red wire",
-			evaluator: func(wires []WireColor) int {
+			condition: ruleCondition(ConditionMoreThanOneWire, "color", string(Red)),
+			evaluator: wiresOnly(func(wires []Wire) int {
 				count := 0
 				for _, w := range wires {
-					if w == Red {
+					if w.Color == Red {
 						count++
 					}
 				}
@@ -255,29 +380,97 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 					return 0 // Condition matches
 				}
 				return -1 // Condition doesn't match
-			},
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "the last wire is yellow",
-			evaluator: func(wires []WireColor) int {
-				if len(wires) > 0 && wires[len(wires)-1] == Yellow {
+			condition: ruleCondition(ConditionLastWireIs, "color", string(Yellow)),
+			evaluator: wiresOnly(func(wires []Wire) int {
+				if len(wires) > 0 && wires[len(wires)-1].Color == Yellow {
 					return 0 // Condition matches
 				}
 				return -1 // Condition doesn't match
-			},
+			}),
+			appliesTo: func(n int) bool { return true }, // Works for all counts
+		},
+		{
+			condition: ruleCondition(ConditionSerialOdd),
+			evaluator: edgeworkCondition(func(ctx *BombContext) bool {
+				return ctx.SerialLastDigit()%2 == 1
+			}),
+			appliesTo: func(n int) bool { return true }, // Works for all counts
+			edgework:  true,
+		},
+		{
+			condition: ruleCondition(ConditionSerialEven),
+			evaluator: edgeworkCondition(func(ctx *BombContext) bool {
+				digit := ctx.SerialLastDigit()
+				return digit >= 0 && digit%2 == 0
+			}),
 			appliesTo: func(n int) bool { return true }, // Works for all counts
+			edgework:  true,
+		},
+		{
+			condition: ruleCondition(ConditionSerialVowel),
+			evaluator: edgeworkCondition(func(ctx *BombContext) bool {
+				return ctx.SerialHasVowel()
+			}),
+			appliesTo: func(n int) bool { return true }, // Works for all counts
+			edgework:  true,
+		},
+		{
+			condition: ruleCondition(ConditionAnyStripe, "color", string(Red)),
+			evaluator: wiresOnly(func(wires []Wire) int {
+				for _, w := range wires {
+					if w.Stripe == Red {
+						return 0 // Condition matches
+					}
+				}
+				return -1 // Condition doesn't match
+			}),
+			appliesTo:     func(n int) bool { return true }, // Works for all counts
+			stripes:       true,
+			impliesStripe: true,
+		},
+		{
+			condition: ruleCondition(ConditionAnyStripe, "color", string(Blue)),
+			evaluator: wiresOnly(func(wires []Wire) int {
+				for _, w := range wires {
+					if w.Stripe == Blue {
+						return 0 // Condition matches
+					}
+				}
+				return -1 // Condition doesn't match
+			}),
+			appliesTo:     func(n int) bool { return true }, // Works for all counts
+			stripes:       true,
+			impliesStripe: true,
+		},
+		{
+			condition: ruleCondition(ConditionNoStripes),
+			evaluator: wiresOnly(func(wires []Wire) int {
+				for _, w := range wires {
+					if w.Striped() {
+						return -1 // Condition doesn't match
+					}
+				}
+				return 0 // Condition matches
+			}),
+			appliesTo: func(n int) bool { return true }, // Works for all counts
+			stripes:   true,
 		},
 	}
 
 	allActions := []struct {
-		name      string
-		executor  func(wires []WireColor) int
+		action    *RuleAction
+		executor  func(wires []Wire) int
 		appliesTo func(int) bool // Function to check if action applies to wire count
+		// needsStripe is true when the action only makes sense if a wire is striped
+		needsStripe bool
 	}{
 		{
-			name: "cut the second one",
-			executor: func(wires []WireColor) int {
+			action: ruleAction(ActionCutWire, "wire", 2),
+			executor: func(wires []Wire) int {
 				if len(wires) >= 2 {
 					return 1
 				}
@@ -286,22 +479,22 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 			appliesTo: func(n int) bool { return n >= 2 }, // Requires at least 2 wires
 		},
 		{
-			name: "cut the last one",
-			executor: func(wires []WireColor) int {
+			action: ruleAction(ActionCutLastWire),
+			executor: func(wires []Wire) int {
 				return len(wires) - 1
 			},
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "cut the first one",
-			executor: func(wires []WireColor) int {
+			action: ruleAction(ActionCutWire, "wire", 1),
+			executor: func(wires []Wire) int {
 				return 0
 			},
 			appliesTo: func(n int) bool { return true }, // Works for all counts
 		},
 		{
-			name: "cut the third one",
-			executor: func(wires []WireColor) int {
+			action: ruleAction(ActionCutWire, "wire", 3),
+			executor: func(wires []Wire) int {
 				if len(wires) >= 3 {
 					return 2
 				}
@@ -309,51 +502,83 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 			},
 			appliesTo: func(n int) bool { return n >= 3 }, // Requires at least 3 wires
 		},
+		{
+			action: ruleAction(ActionCutFirstStriped),
+			executor: func(wires []Wire) int {
+				for i, w := range wires {
+					if w.Striped() {
+						return i
+					}
+				}
+				return -1 // Not reached: only paired with conditions implying a striped wire
+			},
+			appliesTo:   func(n int) bool { return true }, // Works for all counts
+			needsStripe: true,
+		},
 	}
 
 	// Filter conditions and actions based on wire count
+	// Rules about stripes only exist when wires can be striped
 	conditions := make([]struct {
-		name      string
-		evaluator WireRuleEvaluator
+		condition     *RuleCondition
+		evaluator     WireRuleEvaluator
+		edgework      bool
+		impliesStripe bool
 	}, 0)
 	for _, cond := range allConditions {
-		if cond.appliesTo(numWires) {
+		if cond.appliesTo(numWires) && (opts.StripedWires || !cond.stripes) {
 			conditions = append(conditions, struct {
-				name      string
-				evaluator WireRuleEvaluator
+				condition     *RuleCondition
+				evaluator     WireRuleEvaluator
+				edgework      bool
+				impliesStripe bool
 			}{
-				name:      cond.name,
-				evaluator: cond.evaluator,
+				condition:     cond.condition,
+				evaluator:     cond.evaluator,
+				edgework:      cond.edgework,
+				impliesStripe: cond.impliesStripe,
 			})
 		}
 	}
 
 	actions := make([]struct {
-		name     string
-		executor func(wires []WireColor) int
+		action   *RuleAction
+		executor func(wires []Wire) int
 	}, 0)
 	for _, act := range allActions {
-		if act.appliesTo(numWires) {
+		if act.appliesTo(numWires) && (opts.StripedWires || !act.needsStripe) {
 			actions = append(actions, struct {
-				name     string
-				executor func(wires []WireColor) int
+				action   *RuleAction
+				executor func(wires []Wire) int
 			}{
-				name:     act.name,
+				action:   act.action,
 				executor: act.executor,
 			})
 		}
 	}
 
+	// Actions on a striped wire come last, so the ones before can follow any condition
+	plainActions := 0
+	for _, act := range allActions {
+		if act.appliesTo(numWires) && !act.needsStripe {
+			plainActions++
+		}
+	}
+
 	// Ensure we have at least some valid conditions and actions
 	if len(conditions) == 0 {
 		// Fallback: use all conditions if filtering removed everything (shouldn't happen)
 		for _, cond := range allConditions {
 			conditions = append(conditions, struct {
-				name      string
-				evaluator WireRuleEvaluator
+				condition     *RuleCondition
+				evaluator     WireRuleEvaluator
+				edgework      bool
+				impliesStripe bool
 			}{
-				name:      cond.name,
-				evaluator: cond.evaluator,
+				condition:     cond.condition,
+				evaluator:     cond.evaluator,
+				edgework:      cond.edgework,
+				impliesStripe: cond.impliesStripe,
 			})
 		}
 	}
@@ -361,19 +586,26 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 		// Fallback: use all actions if filtering removed everything (shouldn't happen)
 		for _, act := range allActions {
 			actions = append(actions, struct {
-				name     string
-				executor func(wires []WireColor) int
+				action   *RuleAction
+				executor func(wires []Wire) int
 			}{
-				name:     act.name,
+				action:   act.action,
 				executor: act.executor,
 			})
 		}
 	}
 
-	// Generate 3-5 random rules using the seeded RNG
-	numRules := rng.Intn(3) + 3 // 3-5 rules
-	rules := make([]WireRule, 0, numRules)
-	manualRules := make([]ManualRule, 0, numRules+1)
+	// Edgework conditions a compound rule can add on top of its main condition
+	edgeworkConditions := []int{}
+	for i, cond := range conditions {
+		if cond.edgework {
+			edgeworkConditions = append(edgeworkConditions, i)
+		}
+	}
+
+	// Generate the random rules using the seeded RNG (3-5 on normal difficulty)
+	numRules := opts.numRules(rng)
+	rules := make([]WireRule, 0, numRules+1)
 
 	// Track used condition indices to avoid duplicates
 	usedConditions := make(map[int]bool)
@@ -393,17 +625,31 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 		}
 
 		// Pick a random action using seeded RNG
-		actionIndex := rng.Intn(len(actions))
+		// A striped wire is only cut when the condition guarantees there is one
+		actionPool := plainActions
+		if conditions[condIndex].impliesStripe {
+			actionPool = len(actions)
+		}
+		actionIndex := rng.Intn(actionPool)
 		condition := conditions[condIndex]
 		action := actions[actionIndex]
 
+		// Compound rules also require an edgework condition (harder difficulties only)
+		fullCondition := condition.condition
+		var extraEvaluator WireRuleEvaluator
+		if opts.CompoundConditions && !condition.edgework && len(edgeworkConditions) > 0 && rng.Intn(2) == 0 {
+			extra := conditions[edgeworkConditions[rng.Intn(len(edgeworkConditions))]]
+			fullCondition = allOf(fullCondition, extra.condition)
+			extraEvaluator = extra.evaluator
+		}
+
 		// Create combined evaluator
 		// The condition evaluator checks if condition matches (returns >= 0 if match)
 		// If it matches, we execute the action
-		evaluator := func(wires []WireColor) int {
+		evaluator := func(wires []Wire, ctx *BombContext) int {
 			// Check if condition matches
-			conditionResult := condition.evaluator(wires)
-			if conditionResult >= 0 {
+			conditionResult := condition.evaluator(wires, ctx)
+			if conditionResult >= 0 && (extraEvaluator == nil || extraEvaluator(wires, ctx) >= 0) {
 				// Condition matched, execute the action
 				return action.executor(wires)
 			}
@@ -411,65 +657,40 @@ func generateWireModuleRulesWithRNG(numWires int, rng *rand.Rand, seed int64) (*
 			return -1
 		}
 
-		// Create description - combine condition and action naturally
-		description := "If " + condition.name + ", " + action.name + "."
+		// Create description from the rule's structure
+		text := wireRuleText(fullCondition, action.action)
 
 		rules = append(rules, WireRule{
 			Number:      i + 1,
-			Description: description,
+			Description: text.Render(DefaultLanguage),
+			Text:        text,
+			Condition:   fullCondition,
+			Action:      action.action,
 			Evaluator:   evaluator,
 		})
-
-		manualRules = append(manualRules, ManualRule{
-			Number:      i + 1,
-			Description: description,
-		})
 	}
 
 	// Add default rule with random wire selection (deterministic based on seed)
-	// Use a deterministic seed for the default wire to ensure consistency between
-	// GenerateComprehensiveWireModuleManual and generateWireModuleRulesWithRNG
-	// We use seed + 777777 + numWires to create a unique but deterministic seed
+	// It has an RNG of its own so the default wire doesn't depend on how many
+	// numbers the conditional rules drew
 	defaultRNG := rand.New(rand.NewSource(seed + 777777 + int64(numWires)))
 	defaultWireIndex := defaultRNG.Intn(numWires)
-	wirePosition := "first"
-	if defaultWireIndex == numWires-1 {
-		wirePosition = "last"
-	} else if defaultWireIndex == 1 {
-		wirePosition = "second"
-	} else if defaultWireIndex == 2 {
-		wirePosition = "third"
-	} else {
-		wirePosition = fmt.Sprintf("%d%s", defaultWireIndex+1, getOrdinalSuffix(defaultWireIndex+1))
-	}
-
-	manualRules = append(manualRules, ManualRule{
-		Number:      len(manualRules) + 1,
-		Description: fmt.Sprintf("Otherwise, cut the %s one.", wirePosition),
-	})
-
-	// Create default rule evaluator that always returns the chosen wire index
-	defaultEvaluator := func(wires []WireColor) int {
-		return defaultWireIndex
-	}
 
+	defaultAction := cutWireAction(defaultWireIndex, numWires)
+	defaultText := wireRuleText(nil, defaultAction)
 	rules = append(rules, WireRule{
 		Number:      len(rules) + 1,
-		Description: fmt.Sprintf("Otherwise, cut the %s one.", wirePosition),
-		Evaluator:   defaultEvaluator,
-	})
-
-	// Create ModuleManual
-	moduleManual := &ModuleManual{
-		Title:        "Bombz Manual - Wires Module",
-		Rules:        manualRules,
-		Instructions: "As an expert, your job is to guide the defuser through the wires module using these rules. Look at the wires configuration and tell the defuser which wire to cut based on the rules above.",
-		ModuleData: map[string]interface{}{
-			"wireColors": []string{"red", "blue", "green", "white", "yellow"},
+		Description: defaultText.Render(DefaultLanguage),
+		Text:        defaultText,
+		Action:      defaultAction,
+		Default:     true,
+		Evaluator: func(wires []Wire, ctx *BombContext) int {
+			return defaultWireIndex
 		},
-	}
+	})
 
-	return &WireRuleSet{Rules: rules}, moduleManual
+	ruleSet := &WireRuleSet{WireCount: numWires, Rules: rules, DefaultWire: defaultWireIndex, Striped: opts.StripedWires}
+	return ruleSet, ruleSet.Manual()
 }
 
 // ButtonRuleResult represents the result of evaluating a button rule
@@ -480,8 +701,9 @@ type ButtonRuleResult struct {
 }
 
 // ButtonRuleEvaluator is a function that evaluates a condition on button text and color
+// ctx carries the bomb's edgework (batteries, indicators...) and may be nil
 // Returns nil if condition doesn't match, or ButtonRuleResult if it matches
-type ButtonRuleEvaluator func(text ButtonText, color ButtonColor) *ButtonRuleResult
+type ButtonRuleEvaluator func(text ButtonText, color ButtonColor, ctx *BombContext) *ButtonRuleResult
 
 // ButtonRule represents a rule with both description and evaluator function
 type ButtonRule struct {
@@ -497,67 +719,119 @@ type ButtonRuleSet struct {
 }
 
 // GenerateButtonModuleRulesWithSeed generates random rules for button modules with a specific seed for determinism
-func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManual) {
+// opts sets how many rules are generated and whether they can combine two conditions
+func GenerateButtonModuleRulesWithSeed(seed int64, opts RuleGenOptions) (*ButtonRuleSet, *ModuleManual) {
 	// Create a new random source with the given seed
 	rng := rand.New(rand.NewSource(seed))
 
-	// Pools of all possible conditions (button text + color combinations)
+	// Pools of all possible conditions (button text + color combinations, other checks on the
+	// button, or the bomb's edgework)
 	// These only check if the condition matches - action (press/hold) is randomly assigned
 	allConditions := []struct {
-		name  string
-		text  ButtonText
-		color ButtonColor
+		condition *RuleCondition
+		label     ButtonText
+		color     ButtonColor
+		button    func(text ButtonText, color ButtonColor) bool // Set for button conditions other than a text and color pair
+		edgework  func(ctx *BombContext) bool                   // Set for conditions on the bomb rather than the button
 	}{
 		{
-			name:  "button says \"ABORT\" and is red",
-			text:  ButtonTextAbort,
-			color: ButtonColorRed,
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextAbort), "color", string(ButtonColorRed)),
+			label:     ButtonTextAbort,
+			color:     ButtonColorRed,
 		},
 		{
-			name:  "button says \"DETONATE\" and is white",
-			text:  ButtonTextDetonate,
-			color: ButtonColorWhite,
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextDetonate), "color", string(ButtonColorWhite)),
+			label:     ButtonTextDetonate,
+			color:     ButtonColorWhite,
 		},
 		{
-			name:  "button says \"HOLD\" and is blue",
-			text:  ButtonTextHold,
-			color: ButtonColorBlue,
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextHold), "color", string(ButtonColorBlue)),
+			label:     ButtonTextHold,
+			color:     ButtonColorBlue,
 		},
 		{
-			name:  "button says \"PRESS\" and is red",
-			text:  ButtonTextPress,
-			color: ButtonColorRed,
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextPress), "color", string(ButtonColorRed)),
+			label:     ButtonTextPress,
+			color:     ButtonColorRed,
 		},
 		{
-			name:  "button says \"OTHER\" and is any color",
-			text:  ButtonTextOther,
-			color: "", // Any color
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextOther)),
+			label:     ButtonTextOther,
+			color:     "", // Any color
 		},
 		// Additional random combinations
 		{
-			name:  "button says \"ABORT\" and is blue",
-			text:  ButtonTextAbort,
-			color: ButtonColorBlue,
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextAbort), "color", string(ButtonColorBlue)),
+			label:     ButtonTextAbort,
+			color:     ButtonColorBlue,
+		},
+		{
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextDetonate), "color", string(ButtonColorRed)),
+			label:     ButtonTextDetonate,
+			color:     ButtonColorRed,
+		},
+		{
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextHold), "color", string(ButtonColorRed)),
+			label:     ButtonTextHold,
+			color:     ButtonColorRed,
+		},
+		{
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextPress), "color", string(ButtonColorBlue)),
+			label:     ButtonTextPress,
+			color:     ButtonColorBlue,
+		},
+		{
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextAbort), "color", string(ButtonColorWhite)),
+			label:     ButtonTextAbort,
+			color:     ButtonColorWhite,
+		},
+		{
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextDefuse), "color", string(ButtonColorGreen)),
+			label:     ButtonTextDefuse,
+			color:     ButtonColorGreen,
+		},
+		{
+			condition: ruleCondition(ConditionButton, "text", string(ButtonTextWait), "color", string(ButtonColorYellow)),
+			label:     ButtonTextWait,
+			color:     ButtonColorYellow,
+		},
+		// Conditions on the button that aren't a text and color pair
+		{
+			condition: ruleCondition(ConditionButtonColor, "color", string(ButtonColorRed)),
+			button:    func(text ButtonText, color ButtonColor) bool { return color == ButtonColorRed },
 		},
 		{
-			name:  "button says \"DETONATE\" and is red",
-			text:  ButtonTextDetonate,
-			color: ButtonColorRed,
+			condition: ruleCondition(ConditionButtonColor, "color", string(ButtonColorYellow)),
+			button:    func(text ButtonText, color ButtonColor) bool { return color == ButtonColorYellow },
 		},
 		{
-			name:  "button says \"HOLD\" and is red",
-			text:  ButtonTextHold,
-			color: ButtonColorRed,
+			condition: ruleCondition(ConditionButtonTextLonger, "count", 5),
+			button:    func(text ButtonText, color ButtonColor) bool { return len(text) > 5 },
 		},
 		{
-			name:  "button says \"PRESS\" and is blue",
-			text:  ButtonTextPress,
-			color: ButtonColorBlue,
+			condition: ruleCondition(ConditionButtonSharesLetter),
+			button:    buttonTextSharesLetter,
 		},
+		// Edgework conditions
 		{
-			name:  "button says \"ABORT\" and is white",
-			text:  ButtonTextAbort,
-			color: ButtonColorWhite,
+			condition: ruleCondition(ConditionBatteriesMoreThan, "count", 2),
+			edgework:  func(ctx *BombContext) bool { return ctx.Batteries > 2 },
+		},
+		{
+			condition: ruleCondition(ConditionNoBatteries),
+			edgework:  func(ctx *BombContext) bool { return ctx.Batteries == 0 },
+		},
+		{
+			condition: ruleCondition(ConditionLitIndicator, "label", "FRK"),
+			edgework:  func(ctx *BombContext) bool { return ctx.HasLitIndicator("FRK") },
+		},
+		{
+			condition: ruleCondition(ConditionLitIndicator, "label", "CAR"),
+			edgework:  func(ctx *BombContext) bool { return ctx.HasLitIndicator("CAR") },
+		},
+		{
+			condition: ruleCondition(ConditionLitIndicator, "label", "BOB"),
+			edgework:  func(ctx *BombContext) bool { return ctx.HasLitIndicator("BOB") },
 		},
 	}
 
@@ -582,8 +856,16 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 		gaugeColorToDigitRules[gaugeColor] = colorDigitRNG.Intn(10)
 	}
 
-	// Generate 3-5 random rules using the seeded RNG
-	numRules := rng.Intn(3) + 3 // 3-5 rules
+	// Edgework conditions a compound rule can add on top of a button condition
+	edgeworkConditions := []int{}
+	for i, cond := range allConditions {
+		if cond.edgework != nil {
+			edgeworkConditions = append(edgeworkConditions, i)
+		}
+	}
+
+	// Generate the random rules using the seeded RNG (3-5 on normal difficulty)
+	numRules := opts.numRules(rng)
 	rules := make([]ButtonRule, 0, numRules)
 	preHoldRules := make([]ManualRule, 0, numRules+2) // Pre-hold rules section
 
@@ -591,10 +873,7 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 	usedConditions := make(map[int]bool)
 
 	// Add section title for pre-hold logic (Number 0 indicates it's a title, not a rule)
-	preHoldRules = append(preHoldRules, ManualRule{
-		Number:      0,
-		Description: "Pre-Hold Logic: Press vs Hold",
-	})
+	preHoldRules = append(preHoldRules, manualRule(0, msg("button.section.preHold")))
 
 	ruleNum := 1
 	for i := 0; i < numRules; i++ {
@@ -613,6 +892,15 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 
 		condition := allConditions[condIndex]
 
+		// Compound rules also require an edgework condition (harder difficulties only)
+		fullCondition := condition.condition
+		var extraCondition func(ctx *BombContext) bool
+		if opts.CompoundConditions && condition.edgework == nil && rng.Intn(2) == 0 {
+			extra := allConditions[edgeworkConditions[rng.Intn(len(edgeworkConditions))]]
+			fullCondition = allOf(fullCondition, extra.condition)
+			extraCondition = extra.edgework
+		}
+
 		// Randomly assign action type (press or hold) for this condition
 		// Use a deterministic seed based on condition index to ensure same assignment per game
 		actionRNG := rand.New(rand.NewSource(seed + int64(condIndex*1000)))
@@ -623,15 +911,25 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 
 		// Create evaluator that only checks the condition and returns the action
 		// Gauge color will be randomly selected when button is pressed (for hold actions)
-		finalEvaluator := func(text ButtonText, color ButtonColor) *ButtonRuleResult {
+		finalEvaluator := func(text ButtonText, color ButtonColor, ctx *BombContext) *ButtonRuleResult {
 			// Check if condition matches
 			matches := false
-			if condition.color == "" {
+			if condition.edgework != nil {
+				// Edgework condition - never matches without a bomb context
+				matches = ctx != nil && condition.edgework(ctx)
+			} else if condition.button != nil {
+				// Other condition on the button
+				matches = condition.button(text, color)
+			} else if condition.color == "" {
 				// "Any color" condition - only check text
-				matches = (text == condition.text)
+				matches = (text == condition.label)
 			} else {
 				// Specific color condition - check both text and color
-				matches = (text == condition.text && color == condition.color)
+				matches = (text == condition.label && color == condition.color)
+			}
+			if matches && extraCondition != nil {
+				// Compound condition - the edgework part never matches without a bomb context
+				matches = ctx != nil && extraCondition(ctx)
 			}
 
 			if matches {
@@ -644,38 +942,32 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 			return nil
 		}
 
-		// Create description
-		var description string
+		// Create description from the rule's structure
+		action := ruleAction(ActionHoldButton)
 		if actionType == ButtonActionPress {
-			description = fmt.Sprintf("If %s, press and release immediately.", condition.name)
-		} else {
-			description = fmt.Sprintf("If %s, hold the button. When pressed, a random gauge color will appear.", condition.name)
+			action = ruleAction(ActionPressButton)
 		}
+		ruleText := buttonRuleText(fullCondition, action)
 
 		rules = append(rules, ButtonRule{
 			Number:      i + 1,
-			Description: description,
+			Description: ruleText.Render(DefaultLanguage),
 			Evaluator:   finalEvaluator,
 		})
 
-		preHoldRules = append(preHoldRules, ManualRule{
-			Number:      ruleNum,
-			Description: description,
-		})
+		preHoldRules = append(preHoldRules, structuredRule(ruleNum, fullCondition, action, ruleText))
 		ruleNum++
 	}
 
 	// Add default rule: hold (gauge color will be randomly selected when pressed)
-	defaultDescription := "Otherwise, hold the button. When pressed, a random gauge color will appear."
+	defaultAction := ruleAction(ActionHoldButton)
+	defaultText := buttonRuleText(nil, defaultAction)
 
-	preHoldRules = append(preHoldRules, ManualRule{
-		Number:      ruleNum,
-		Description: defaultDescription,
-	})
+	preHoldRules = append(preHoldRules, structuredRule(ruleNum, nil, defaultAction, defaultText))
 	ruleNum++
 
 	// Create default rule evaluator (matches any condition not covered by specific rules)
-	defaultEvaluator := func(text ButtonText, color ButtonColor) *ButtonRuleResult {
+	defaultEvaluator := func(text ButtonText, color ButtonColor, ctx *BombContext) *ButtonRuleResult {
 		return &ButtonRuleResult{
 			Action:           ButtonActionHold,
 			WaitForGauge:     "", // Will be randomly selected when button is pressed
@@ -685,23 +977,19 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 
 	rules = append(rules, ButtonRule{
 		Number:      len(rules) + 1,
-		Description: defaultDescription,
+		Description: defaultText.Render(DefaultLanguage),
 		Evaluator:   defaultEvaluator,
 	})
 
 	// Add section title for post-hold logic (Number 0 indicates it's a title, not a rule)
 	postHoldRules := []ManualRule{}
-	postHoldRules = append(postHoldRules, ManualRule{
-		Number:      0,
-		Description: "Post-Hold Logic: Gauge Color to Timer Digit",
-	})
+	postHoldRules = append(postHoldRules, manualRule(0, msg("button.section.postHold")))
 
 	for _, gaugeColor := range gaugeColors {
 		digit := gaugeColorToDigitRules[gaugeColor]
-		postHoldRules = append(postHoldRules, ManualRule{
-			Number:      ruleNum,
-			Description: fmt.Sprintf("If gauge shows %s, release when timer's last digit is %d.", gaugeColor, digit),
-		})
+		gaugeCondition := ruleCondition(ConditionGaugeColor, "color", string(gaugeColor))
+		releaseAction := ruleAction(ActionReleaseOnDigit, "digit", digit)
+		postHoldRules = append(postHoldRules, structuredRule(ruleNum, gaugeCondition, releaseAction, buttonRuleText(gaugeCondition, releaseAction)))
 		ruleNum++
 	}
 
@@ -709,15 +997,11 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 	allManualRules := append(preHoldRules, postHoldRules...)
 
 	// Create ModuleManual
-	moduleManual := &ModuleManual{
-		Title:        "Bombz Manual - Button Module",
-		Rules:        allManualRules,
-		Instructions: "As an expert, your job is to guide the defuser through the button module using these rules. First, look at the button text and color to determine if you should press immediately or hold. If holding, when the button is pressed, a random gauge color (red, white, or blue) will appear. Use the gauge color mapping rules to determine which timer digit to wait for. Release the button when the timer's last digit matches the specified value.",
-		ModuleData: map[string]interface{}{
-			"buttonTexts":  []string{"ABORT", "DETONATE", "HOLD", "PRESS", "OTHER"},
-			"buttonColors": []string{"red", "blue", "white"},
-			"gaugeColors":  []string{"red", "blue", "white"},
-		},
+	moduleManual := newModuleManual(msg("button.title"), msg("button.instructions"), allManualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"buttonTexts":  buttonTexts,
+		"buttonColors": buttonColors,
+		"gaugeColors":  []string{"red", "blue", "white"},
 	}
 
 	return &ButtonRuleSet{
@@ -726,18 +1010,10 @@ func GenerateButtonModuleRulesWithSeed(seed int64) (*ButtonRuleSet, *ModuleManua
 	}, moduleManual
 }
 
-// GenerateComprehensiveButtonModuleManual generates a single comprehensive manual for all button modules
-// Uses a seed to ensure deterministic generation (rules don't change)
-func GenerateComprehensiveButtonModuleManual(seed int64) *ModuleManual {
-	// Generate rules using the seed - all button modules will use the same rules
-	_, moduleManual := GenerateButtonModuleRulesWithSeed(seed)
-	return moduleManual
-}
-
 // GetWireModuleManual returns the manual content for the wires module
 func GetWireModuleManual() *WireModuleManual {
 	// Use a default seed for static manual
-	return GenerateComprehensiveWireModuleManual(12345)
+	return GenerateComprehensiveWireModuleManual(12345, DefaultRuleGenOptions())
 }
 
 // GenerateTerminalModuleRulesWithSeed generates random rules for terminal modules with a specific seed for determinism
@@ -755,15 +1031,15 @@ func GenerateTerminalModuleRulesWithSeed(seed int64, terminalTexts []string) (*T
 		"YES", "NO", "ACCEPT", "DENY", "CONFIRM",
 	}
 
-	// Generate 3 rules (one for each command step)
-	rules := make([]TerminalRule, 0, 3)
-	manualRules := make([]ManualRule, 0, 3)
+	// Generate one rule for each command step
+	rules := make([]TerminalRule, 0, len(terminalTexts))
+	manualRules := make([]ManualRule, 0, len(terminalTexts))
 
 	// Track used command words to avoid duplicates
 	usedCommands := make(map[string]bool)
 
 	// Generate rules based on terminal texts
-	for i := 0; i < 3 && i < len(terminalTexts); i++ {
+	for i := 0; i < len(terminalTexts); i++ {
 		terminalText := terminalTexts[i]
 
 		// Pick a random command word (avoid duplicates)
@@ -785,7 +1061,7 @@ func GenerateTerminalModuleRulesWithSeed(seed int64, terminalTexts []string) (*T
 
 		// Create rule based on terminal text
 		// The rule checks what text is displayed and tells what command to type
-		description := fmt.Sprintf("If terminal says \"%s\", type %s.", terminalText, commandWord)
+		rule := terminalManualRule(i+1, terminalText, commandWord)
 
 		evaluator := func(text string) string {
 			// Check if the terminal text matches
@@ -797,34 +1073,29 @@ func GenerateTerminalModuleRulesWithSeed(seed int64, terminalTexts []string) (*T
 		}
 
 		rules = append(rules, TerminalRule{
-			Number:      i + 1,
-			Description: description,
-			Evaluator:   evaluator,
-			Command:     commandWord,
+			Number:       i + 1,
+			Description:  rule.Description,
+			Evaluator:    evaluator,
+			TerminalText: terminalText,
+			Command:      commandWord,
 		})
 
-		manualRules = append(manualRules, ManualRule{
-			Number:      i + 1,
-			Description: description,
-		})
+		manualRules = append(manualRules, rule)
 	}
 
 	// Create ModuleManual
-	moduleManual := &ModuleManual{
-		Title:        "Bombz Manual - Terminal Module",
-		Rules:        manualRules,
-		Instructions: "As an expert, your job is to guide the defuser through the terminal module. Look at what text is displayed in the terminal and tell the defuser which command to type based on these rules. The defuser must type 3 commands in order. After each correct command, the terminal will display new text.",
-		ModuleData: map[string]interface{}{
-			"commandWords": commandWords,
-		},
+	moduleManual := newModuleManual(msg("terminal.title"), msg("terminal.instructions.single", len(manualRules)), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"commandWords": commandWords,
 	}
 
 	return &TerminalRuleSet{Rules: rules}, moduleManual
 }
 
 // GenerateComprehensiveTerminalModuleManual generates a comprehensive manual for terminal modules
-// Creates 20 different terminal text → command mappings
-func GenerateComprehensiveTerminalModuleManual(seed int64) *ModuleManual {
+// Creates opts.TerminalRules different terminal text → command mappings (20 on normal difficulty)
+// sequenceLength is how many commands each terminal asks for, stated in the instructions
+func GenerateComprehensiveTerminalModuleManual(seed int64, opts RuleGenOptions, sequenceLength int) *ModuleManual {
 	// Create a seeded RNG for deterministic generation
 	rng := rand.New(rand.NewSource(seed))
 
@@ -842,12 +1113,12 @@ func GenerateComprehensiveTerminalModuleManual(seed int64) *ModuleManual {
 	// Use the terminal prompts list
 	allTerminalTexts := terminalPrompts
 
-	// Generate 20 unique combinations
-	manualRules := make([]ManualRule, 0, 20)
+	// Generate the unique combinations
+	manualRules := make([]ManualRule, 0, opts.TerminalRules)
 	usedTexts := make(map[string]bool)
 	usedCommands := make(map[string]bool)
 
-	for i := 0; i < 20; i++ {
+	for i := 0; i < opts.TerminalRules; i++ {
 		// Pick a random terminal text (avoid duplicates)
 		var terminalText string
 		var attempts int
@@ -881,20 +1152,12 @@ func GenerateComprehensiveTerminalModuleManual(seed int64) *ModuleManual {
 		}
 
 		// Create rule
-		description := fmt.Sprintf("If terminal says \"%s\", type %s.", terminalText, commandWord)
-		manualRules = append(manualRules, ManualRule{
-			Number:      i + 1,
-			Description: description,
-		})
+		manualRules = append(manualRules, terminalManualRule(i+1, terminalText, commandWord))
 	}
 
-	moduleManual := &ModuleManual{
-		Title:        "Bombz Manual - Terminal Module",
-		Rules:        manualRules,
-		Instructions: "As an expert, your job is to guide the defuser through the terminal module. Look at what text is displayed in the terminal and tell the defuser which command to type based on these rules. The defuser must type 3 commands in order. Each terminal will randomly use 3 of these 20 rules. After each correct command, the terminal will display new text.",
-		ModuleData: map[string]interface{}{
-			"commandWords": commandWords,
-		},
+	moduleManual := newModuleManual(msg("terminal.title"), msg("terminal.instructions", len(manualRules), sequenceLength), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"commandWords": commandWords,
 	}
 
 	return moduleManual
@@ -904,55 +1167,39 @@ func GenerateComprehensiveTerminalModuleManual(seed int64) *ModuleManual {
 type ManualContent struct {
 	WireModule *WireModuleManual        `json:"wireModule,omitempty"` // For backward compatibility
 	Modules    map[string]*ModuleManual `json:"modules,omitempty"`    // New extensible format
-	BombState  *Bomb                    `json:"bombState,omitempty"`  // Include bomb state so experts can see wire configurations
+	BombState  *ExpertBombView          `json:"bombState,omitempty"`  // What experts are shown of the bomb, without its solutions
+	BombIndex  int                      `json:"bombIndex"`            // Position of the bomb in the mission
+	BombCount  int                      `json:"bombCount"`            // Number of bombs in the mission
+	ManualCode string                   `json:"manualCode,omitempty"` // Fingerprint of the rules, matches the defuser's bomb
+	Version    uint64                   `json:"version"`              // State version of BombState, later deltas build on it
+	Progress   *ExpertProgress          `json:"progress,omitempty"`   // Solved modules, strikes and timer at a glance
+	// ModuleManuals is the key in Modules of each module's manual, by module ID
+	ModuleManuals map[string]string `json:"moduleManuals,omitempty"`
 }
 
 // GetManualContent returns the complete manual content
-// Always returns comprehensive manual with rules for all wire counts (3, 4, 5, 6)
-// Uses the bomb's stored seed to ensure rules match the modules
-func GetManualContent(bomb *Bomb) *ManualContent {
-	content := &ManualContent{}
-
-	if bomb != nil {
-		content.BombState = bomb
-	}
-
-	// Use the bomb's stored seed (or use a default seed if no bomb)
-	seed := int64(12345) // Default seed
-	if bomb != nil {
-		seed = bomb.Seed
-	}
-
-	// Always use comprehensive manual with rules for all wire counts
-	// Uses the same seed as the bomb's modules to ensure alignment
-	content.WireModule = GenerateComprehensiveWireModuleManual(seed)
-
-	// Also populate Modules map for consistency
-	content.Modules = make(map[string]*ModuleManual)
-	content.Modules["wireModule"] = &ModuleManual{
-		Title:        content.WireModule.Title,
-		Rules:        content.WireModule.Rules,
-		Instructions: content.WireModule.Instructions,
-		ModuleData: map[string]interface{}{
-			"wireColors": content.WireModule.WireColors,
-		},
-	}
-
-	// Add single comprehensive button module manual if bomb has button modules
-	if bomb != nil && len(bomb.ButtonModules) > 0 {
-		// Generate one comprehensive manual for all button modules (they all use the same rules)
-		buttonManual := GenerateComprehensiveButtonModuleManual(seed)
-		content.Modules["buttonModule"] = buttonManual
-	}
-
-	// Add terminal module manual if bomb has terminal modules
-	// All terminal modules share the same rules
-	if bomb != nil && len(bomb.TerminalModules) > 0 {
-		// All terminals use the same manual from ModuleRules
-		if manual, exists := bomb.ModuleRules["terminalModule"]; exists {
-			content.Modules["terminalModule"] = manual
+// The module manuals are the ones the bomb was built with (Bomb.ModuleRules), so they always
+// match its modules; without a bomb there is only the default wires manual
+// The text is rendered in language, rules are the same in every language
+func GetManualContent(bomb *Bomb, language string) *ManualContent {
+	content := &ManualContent{Modules: make(map[string]*ModuleManual)}
+
+	if bomb == nil {
+		content.Modules["wireModule"] = GetWireModuleManual().ModuleManual()
+	} else {
+		content.ManualCode = bomb.ManualCode
+		content.ModuleManuals = make(map[string]string)
+		bomb.eachModule(func(moduleType string, moduleIndex int, module identifiedModule) bool {
+			content.ModuleManuals[module.ModuleID()] = manualKey(moduleType)
+			return true
+		})
+		for moduleType, manual := range bomb.ModuleRules {
+			content.Modules[moduleType] = manual
 		}
 	}
 
-	return content
+	// Comprehensive manual with rules for all wire counts, in the format older frontends read
+	content.WireModule = legacyWireManual(content.Modules["wireModule"])
+
+	return content.Localize(language)
 }
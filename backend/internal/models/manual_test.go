@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestManualContentServesModuleRules(t *testing.T) {
+	for _, difficulty := range []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard, DifficultyExpert} {
+		t.Run(string(difficulty), func(t *testing.T) {
+			for seed := int64(0); seed < 20; seed++ {
+				bomb := newTestSession(t, BombConfig{ModuleMix: OneOfEachModule(), Difficulty: difficulty}, seed).Bombs[0]
+				content := GetManualContent(bomb, DefaultLanguage)
+
+				if len(content.Modules) != len(bomb.ModuleRules) {
+					t.Fatalf("seed %d: %d manuals served, the bomb has %d", seed, len(content.Modules), len(bomb.ModuleRules))
+				}
+				for key, manual := range bomb.ModuleRules {
+					served, _ := json.Marshal(content.Modules[key])
+					built, _ := json.Marshal(manual)
+					if string(served) != string(built) {
+						t.Errorf("seed %d: served %s manual differs from the bomb's:\n%s\n%s", seed, key, served, built)
+					}
+				}
+
+				// Each module was generated with the manual served for its type; wires modules only
+				// have their wire count's section of it
+				for _, module := range bomb.Modules {
+					key := manualKey(module.Type())
+					if content.ModuleManuals[module.ModuleID()] != key {
+						t.Errorf("seed %d: module %s points at manual %q, want %q", seed, module.ModuleID(), content.ModuleManuals[module.ModuleID()], key)
+					}
+					if module.Type() == ModuleTypeWires {
+						continue
+					}
+					generated, _ := json.Marshal(module.Manual())
+					served, _ := json.Marshal(content.Modules[key])
+					if string(generated) != string(served) {
+						t.Errorf("seed %d: %s module was generated with another manual than the one served", seed, module.Type())
+					}
+				}
+			}
+		})
+	}
+}
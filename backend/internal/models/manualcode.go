@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"hash/fnv"
+)
+
+// manualCodeAlphabet leaves out the characters that are easy to mix up (0/O, 1/I)
+const manualCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// manualCodeLength is how many characters a manual code has
+const manualCodeLength = 4
+
+// computeManualCode fingerprints the rules of a bomb's manual as a short code like "K7Q2"
+// Defusers and experts are shown the code of the bomb and of the manual they read,
+// so a manual printed or loaded for another game is spotted at once
+// It is computed from the rules in the default language, so it is the same in every language
+func computeManualCode(bomb *Bomb) string {
+	content := GetManualContent(bomb, DefaultLanguage)
+	data, err := json.Marshal(struct {
+		WireModule *WireModuleManual
+		Modules    map[string]*ModuleManual
+	}{content.WireModule, content.Modules})
+	if err != nil {
+		return ""
+	}
+
+	hash := fnv.New64a()
+	hash.Write(data)
+	return encodeCode(hash.Sum64(), manualCodeLength)
+}
+
+// encodeCode writes a hash as length characters of manualCodeAlphabet
+func encodeCode(sum uint64, length int) string {
+	code := make([]byte, length)
+	for i := range code {
+		code[i] = manualCodeAlphabet[sum%uint64(len(manualCodeAlphabet))]
+		sum /= uint64(len(manualCodeAlphabet))
+	}
+	return string(code)
+}
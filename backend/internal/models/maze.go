@@ -0,0 +1,276 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+const (
+	// mazeSize is the width and height of every maze
+	mazeSize = 6
+	// mazeCount is the number of mazes printed in the manual
+	mazeCount = 6
+)
+
+// Wall bits of a maze cell, one per side
+const (
+	mazeWallUp    = 1
+	mazeWallRight = 2
+	mazeWallDown  = 4
+	mazeWallLeft  = 8
+)
+
+// mazeDirections maps each move to its wall bit, the opposite wall bit and its offset
+var mazeDirections = map[string]struct {
+	wall, opposite int
+	dx, dy         int
+}{
+	"up":    {mazeWallUp, mazeWallDown, 0, -1},
+	"right": {mazeWallRight, mazeWallLeft, 1, 0},
+	"down":  {mazeWallDown, mazeWallUp, 0, 1},
+	"left":  {mazeWallLeft, mazeWallRight, -1, 0},
+}
+
+// mazeDirectionOrder is the fixed order directions are tried in, so generation stays deterministic
+var mazeDirectionOrder = []string{"up", "right", "down", "left"}
+
+// MazePosition is a cell of a maze, counted from the top-left corner starting at 0
+type MazePosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Maze is one maze of the manual: its walls and the two markers that identify it
+type Maze struct {
+	Walls   [mazeSize][mazeSize]int `json:"walls"`   // Wall bits of each cell, indexed [y][x]
+	Markers [2]MazePosition         `json:"markers"` // Cells marked on the module
+}
+
+// MazeModule represents the maze module on the bomb
+// The defuser sees the markers, their position and the goal but not the walls;
+// the expert finds the maze from the markers and guides the defuser to the goal
+type MazeModule struct {
+	moduleIdentity
+	MazeIndex int           `json:"mazeIndex"` // Index of the maze in RuleSet.Mazes
+	Position  MazePosition  `json:"position"`
+	Goal      MazePosition  `json:"goal"`
+	IsSolved  bool          `json:"isSolved"`
+	RuleSet   *MazeRuleSet  `json:"-"` // Rules for this module (not serialized)
+	manual    *ModuleManual // Manual generated alongside the module
+}
+
+// MazeModuleView is the defuser-facing view of a maze module (no walls)
+type MazeModuleView struct {
+	ID       string          `json:"id"` // Stable module ID
+	Markers  [2]MazePosition `json:"markers"`
+	Position MazePosition    `json:"position"`
+	Goal     MazePosition    `json:"goal"`
+	IsSolved bool            `json:"isSolved"`
+}
+
+// DefuserView returns the maze module state that can be shown to the defuser
+func (mm *MazeModule) DefuserView() *MazeModuleView {
+	return &MazeModuleView{
+		ID:       mm.ID,
+		Markers:  mm.RuleSet.Mazes[mm.MazeIndex].Markers,
+		Position: mm.Position,
+		Goal:     mm.Goal,
+		IsSolved: mm.IsSolved,
+	}
+}
+
+// MazeRuleSet contains every maze printed in the manual
+type MazeRuleSet struct {
+	Mazes []Maze `json:"-"`
+}
+
+// NewMazeModuleWithRules creates a new maze module on one of the manual's mazes
+// mazeSeed: seed for picking the maze, start and goal (different for each module)
+// ruleSeed: seed for generating the mazes (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewMazeModuleWithRules(mazeSeed int64, ruleSeed int64) (*MazeModule, *ModuleManual) {
+	rng := rand.New(rand.NewSource(mazeSeed))
+	ruleSet, moduleManual := GenerateMazeModuleRulesWithSeed(ruleSeed)
+
+	// Start and goal are two distinct cells
+	cells := rng.Perm(mazeSize * mazeSize)
+	module := &MazeModule{
+		MazeIndex: rng.Intn(len(ruleSet.Mazes)),
+		Position:  MazePosition{X: cells[0] % mazeSize, Y: cells[0] / mazeSize},
+		Goal:      MazePosition{X: cells[1] % mazeSize, Y: cells[1] / mazeSize},
+		RuleSet:   ruleSet,
+		manual:    moduleManual,
+	}
+	return module, moduleManual
+}
+
+// IsValidMazeDirection reports whether direction is one of up, down, left or right
+func IsValidMazeDirection(direction string) bool {
+	_, ok := mazeDirections[direction]
+	return ok
+}
+
+// Move moves the defuser one cell in a direction
+// Returns true if the move went through, false if a wall (or the edge) is in the way (strike)
+func (mm *MazeModule) Move(direction string) bool {
+	move, ok := mazeDirections[direction]
+	if mm.IsSolved || !ok {
+		return false
+	}
+
+	maze := mm.RuleSet.Mazes[mm.MazeIndex]
+	if maze.Walls[mm.Position.Y][mm.Position.X]&move.wall != 0 {
+		return false
+	}
+
+	mm.Position.X += move.dx
+	mm.Position.Y += move.dy
+	if mm.Position == mm.Goal {
+		mm.IsSolved = true
+	}
+	return true
+}
+
+// generateMaze carves a perfect maze (exactly one path between any two cells) with a depth-first search
+func generateMaze(rng *rand.Rand) [mazeSize][mazeSize]int {
+	var walls [mazeSize][mazeSize]int
+	var visited [mazeSize][mazeSize]bool
+	for y := range walls {
+		for x := range walls[y] {
+			walls[y][x] = mazeWallUp | mazeWallRight | mazeWallDown | mazeWallLeft
+		}
+	}
+
+	stack := []MazePosition{{X: rng.Intn(mazeSize), Y: rng.Intn(mazeSize)}}
+	visited[stack[0].Y][stack[0].X] = true
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+
+		// Collect the unvisited neighbours
+		options := []string{}
+		for _, direction := range mazeDirectionOrder {
+			move := mazeDirections[direction]
+			x, y := cell.X+move.dx, cell.Y+move.dy
+			if x >= 0 && x < mazeSize && y >= 0 && y < mazeSize && !visited[y][x] {
+				options = append(options, direction)
+			}
+		}
+		if len(options) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// Knock down the wall towards a random neighbour and continue from there
+		move := mazeDirections[options[rng.Intn(len(options))]]
+		next := MazePosition{X: cell.X + move.dx, Y: cell.Y + move.dy}
+		walls[cell.Y][cell.X] &^= move.wall
+		walls[next.Y][next.X] &^= move.opposite
+		visited[next.Y][next.X] = true
+		stack = append(stack, next)
+	}
+	return walls
+}
+
+// renderMaze draws a maze as text rows for the manual
+// Markers are drawn as "O" and every other cell as "."
+func renderMaze(maze Maze) []string {
+	rows := []string{"+" + strings.Repeat("--+", mazeSize)}
+	for y := 0; y < mazeSize; y++ {
+		line := "|"
+		below := "+"
+		for x := 0; x < mazeSize; x++ {
+			cell := MazePosition{X: x, Y: y}
+			if cell == maze.Markers[0] || cell == maze.Markers[1] {
+				line += "O "
+			} else {
+				line += ". "
+			}
+			if maze.Walls[y][x]&mazeWallRight != 0 {
+				line += "|"
+			} else {
+				line += " "
+			}
+			if maze.Walls[y][x]&mazeWallDown != 0 {
+				below += "--+"
+			} else {
+				below += "  +"
+			}
+		}
+		rows = append(rows, line, below)
+	}
+	return rows
+}
+
+// GenerateMazeModuleRulesWithSeed generates the mazes for maze modules
+// Each maze gets its own first marker cell, and no second marker sits on a first marker cell,
+// so the two markers always identify a single maze
+func GenerateMazeModuleRulesWithSeed(seed int64) (*MazeRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 12121212))
+
+	ruleSet := &MazeRuleSet{Mazes: make([]Maze, mazeCount)}
+	manualRules := make([]ManualRule, mazeCount)
+	layouts := make([][]string, mazeCount)
+	cells := rng.Perm(mazeSize * mazeSize)
+	firstCells, secondCells := cells[:mazeCount], cells[mazeCount:]
+	for i := range ruleSet.Mazes {
+		secondCell := secondCells[rng.Intn(len(secondCells))]
+		first := MazePosition{X: firstCells[i] % mazeSize, Y: firstCells[i] / mazeSize}
+		second := MazePosition{X: secondCell % mazeSize, Y: secondCell / mazeSize}
+
+		ruleSet.Mazes[i] = Maze{
+			Walls:   generateMaze(rng),
+			Markers: [2]MazePosition{first, second},
+		}
+		layouts[i] = renderMaze(ruleSet.Mazes[i])
+		manualRules[i] = manualRule(i+1, msg("maze.rule", i+1, first.X+1, first.Y+1, second.X+1, second.Y+1))
+	}
+
+	moduleManual := newModuleManual(msg("maze.title"), msg("maze.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"mazes":   ruleSet.Mazes,
+		"layouts": layouts,
+	}
+
+	return ruleSet, moduleManual
+}
+
+// Type returns the module type
+func (mm *MazeModule) Type() string {
+	return ModuleTypeMaze
+}
+
+// Solved reports whether the maze module is disarmed
+func (mm *MazeModule) Solved() bool {
+	return mm.IsSolved
+}
+
+// View returns the defuser-facing state of the maze module
+func (mm *MazeModule) View() interface{} {
+	return mm.DefuserView()
+}
+
+// Manual returns the manual the maze module was generated with
+func (mm *MazeModule) Manual() *ModuleManual {
+	return mm.manual
+}
+
+// HandleAction applies a defuser action to the maze module
+// Supports "move" with a direction; walking into a wall strikes
+func (mm *MazeModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "move" {
+		return ActionResult{}, unknownActionError(ModuleTypeMaze, action)
+	}
+
+	var data struct {
+		Direction string `json:"direction"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	if !IsValidMazeDirection(data.Direction) {
+		return ActionResult{}, fmt.Errorf("invalid direction %q", data.Direction)
+	}
+	return actionOutcome(mm.Move(data.Direction)), nil
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+)
+
+const (
+	// memoryStageCount is the number of stages to clear on a memory module
+	memoryStageCount = 5
+	// memoryButtonCount is the number of buttons (and labels 1-4) on a memory module
+	memoryButtonCount = 4
+)
+
+// MemoryRuleKind identifies how a memory rule picks the button to press
+type MemoryRuleKind string
+
+const (
+	MemoryPressPosition      MemoryRuleKind = "position"      // Press the button in a fixed position
+	MemoryPressLabel         MemoryRuleKind = "label"         // Press the button with a fixed label
+	MemoryPressStagePosition MemoryRuleKind = "stagePosition" // Press the button in the position pressed in an earlier stage
+	MemoryPressStageLabel    MemoryRuleKind = "stageLabel"    // Press the button with the label pressed in an earlier stage
+)
+
+// MemoryRule tells which button to press for one display value of one stage
+// Value is a position or label (1-4) for fixed rules, or a stage number (1-based) for stage rules
+type MemoryRule struct {
+	Kind  MemoryRuleKind `json:"kind"`
+	Value int            `json:"value"`
+}
+
+// MemoryPress records the button pressed to clear a stage
+type MemoryPress struct {
+	Position int `json:"position"` // 0-based position pressed
+	Label    int `json:"label"`    // Label of the pressed button
+}
+
+// MemoryModule represents the memory module on the bomb
+// Each stage shows a display number and four labelled buttons; the button to press
+// depends on the display and on what was pressed in earlier stages
+type MemoryModule struct {
+	moduleIdentity
+	Stage    int            `json:"stage"`   // Current stage (0-based)
+	Display  int            `json:"display"` // Number on the display (1-4)
+	Labels   []int          `json:"labels"`  // Label of each button, in position order
+	History  []MemoryPress  `json:"history"` // Buttons pressed in the cleared stages
+	IsSolved bool           `json:"isSolved"`
+	RuleSet  *MemoryRuleSet `json:"-"` // Rules for this module (not serialized)
+	rng      *rand.Rand     // Seeded source for displays and labels
+	manual   *ModuleManual  // Manual generated alongside the module
+}
+
+// MemoryModuleView is the defuser-facing view of a memory module (no solution data)
+type MemoryModuleView struct {
+	ID       string `json:"id"` // Stable module ID
+	Stage    int    `json:"stage"`
+	Stages   int    `json:"stages"`
+	Display  int    `json:"display"`
+	Labels   []int  `json:"labels"`
+	IsSolved bool   `json:"isSolved"`
+}
+
+// DefuserView returns the memory module state that can be shown to the defuser
+func (mm *MemoryModule) DefuserView() *MemoryModuleView {
+	return &MemoryModuleView{
+		ID:       mm.ID,
+		Stage:    mm.Stage,
+		Stages:   memoryStageCount,
+		Display:  mm.Display,
+		Labels:   mm.Labels,
+		IsSolved: mm.IsSolved,
+	}
+}
+
+// MemoryRuleSet holds one rule per display value for every stage
+type MemoryRuleSet struct {
+	Rules [memoryStageCount][memoryButtonCount]MemoryRule `json:"-"`
+}
+
+// NewMemoryModuleWithRules creates a new memory module at stage 1 with the shared rules
+// memorySeed: seed for generating displays and labels (different for each module)
+// ruleSeed: seed for generating rules (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewMemoryModuleWithRules(memorySeed int64, ruleSeed int64) (*MemoryModule, *ModuleManual) {
+	ruleSet, moduleManual := GenerateMemoryModuleRulesWithSeed(ruleSeed)
+
+	module := &MemoryModule{
+		RuleSet: ruleSet,
+		rng:     rand.New(rand.NewSource(memorySeed)),
+		manual:  moduleManual,
+	}
+	module.reset()
+	return module, moduleManual
+}
+
+// reset goes back to stage 1 and forgets every press
+func (mm *MemoryModule) reset() {
+	mm.Stage = 0
+	mm.History = []MemoryPress{}
+	mm.rollStage()
+}
+
+// rollStage draws a new display number and button labels for the current stage
+func (mm *MemoryModule) rollStage() {
+	mm.Display = 1 + mm.rng.Intn(memoryButtonCount)
+	mm.Labels = make([]int, memoryButtonCount)
+	for i, label := range mm.rng.Perm(memoryButtonCount) {
+		mm.Labels[i] = label + 1
+	}
+}
+
+// correctPosition resolves the current stage's rule to the position to press
+func (mm *MemoryModule) correctPosition() int {
+	rule := mm.RuleSet.Rules[mm.Stage][mm.Display-1]
+	switch rule.Kind {
+	case MemoryPressPosition:
+		return rule.Value - 1
+	case MemoryPressLabel:
+		return mm.positionOfLabel(rule.Value)
+	case MemoryPressStagePosition:
+		return mm.History[rule.Value-1].Position
+	case MemoryPressStageLabel:
+		return mm.positionOfLabel(mm.History[rule.Value-1].Label)
+	}
+	return -1
+}
+
+// positionOfLabel returns the position of the button carrying a label
+func (mm *MemoryModule) positionOfLabel(label int) int {
+	for i, l := range mm.Labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// Press handles the defuser pressing the button at a position
+// Returns true if correct, false if wrong (strike); a wrong press resets the module to stage 1
+func (mm *MemoryModule) Press(position int) bool {
+	if mm.IsSolved || position < 0 || position >= len(mm.Labels) {
+		return false
+	}
+
+	if position != mm.correctPosition() {
+		mm.reset()
+		return false
+	}
+
+	mm.History = append(mm.History, MemoryPress{Position: position, Label: mm.Labels[position]})
+	mm.Stage++
+	if mm.Stage >= memoryStageCount {
+		mm.IsSolved = true
+		return true
+	}
+	mm.rollStage()
+	return true
+}
+
+// GenerateMemoryModuleRulesWithSeed generates the per-stage rules for memory modules
+// Stage 1 only uses fixed positions and labels; later stages can also refer back to earlier stages
+func GenerateMemoryModuleRulesWithSeed(seed int64) (*MemoryRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 7777777))
+
+	ruleSet := &MemoryRuleSet{}
+	manualRules := []ManualRule{}
+	ruleNum := 1
+
+	for stage := 0; stage < memoryStageCount; stage++ {
+		kinds := []MemoryRuleKind{MemoryPressPosition, MemoryPressLabel}
+		if stage > 0 {
+			kinds = append(kinds, MemoryPressStagePosition, MemoryPressStageLabel)
+		}
+
+		// Section title (Number 0 indicates it's a title, not a rule)
+		manualRules = append(manualRules, manualRule(0, msg("memory.section", stage+1)))
+
+		for display := 1; display <= memoryButtonCount; display++ {
+			rule := MemoryRule{Kind: kinds[rng.Intn(len(kinds))]}
+			var action *Message
+			switch rule.Kind {
+			case MemoryPressPosition:
+				rule.Value = 1 + rng.Intn(memoryButtonCount)
+				action = msg("memory.action.position", msg(fmt.Sprintf("memory.ordinal.%d", rule.Value)))
+			case MemoryPressLabel:
+				rule.Value = 1 + rng.Intn(memoryButtonCount)
+				action = msg("memory.action.label", rule.Value)
+			case MemoryPressStagePosition:
+				rule.Value = 1 + rng.Intn(stage)
+				action = msg("memory.action.stagePosition", rule.Value)
+			case MemoryPressStageLabel:
+				rule.Value = 1 + rng.Intn(stage)
+				action = msg("memory.action.stageLabel", rule.Value)
+			}
+			ruleSet.Rules[stage][display-1] = rule
+
+			manualRules = append(manualRules, manualRule(ruleNum, msg("memory.rule", display, action)))
+			ruleNum++
+		}
+	}
+
+	moduleManual := newModuleManual(msg("memory.title"), msg("memory.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"stages": memoryStageCount,
+	}
+
+	return ruleSet, moduleManual
+}
+
+// Type returns the module type
+func (mm *MemoryModule) Type() string {
+	return ModuleTypeMemory
+}
+
+// Solved reports whether the memory module is disarmed
+func (mm *MemoryModule) Solved() bool {
+	return mm.IsSolved
+}
+
+// View returns the defuser-facing state of the memory module
+func (mm *MemoryModule) View() interface{} {
+	return mm.DefuserView()
+}
+
+// Manual returns the manual the memory module was generated with
+func (mm *MemoryModule) Manual() *ModuleManual {
+	return mm.manual
+}
+
+// HandleAction applies a defuser action to the memory module
+// Supports "press" with a button position
+func (mm *MemoryModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "press" {
+		return ActionResult{}, unknownActionError(ModuleTypeMemory, action)
+	}
+
+	var data struct {
+		Position int `json:"position"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	if data.Position < 0 || data.Position >= len(mm.Labels) {
+		return ActionResult{}, fmt.Errorf("invalid button position")
+	}
+	return actionOutcome(mm.Press(data.Position)), nil
+}
@@ -0,0 +1,290 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxMissionBombs is the largest number of bombs a mission can hold
+	MaxMissionBombs = 10
+	// MissionCountdown is how long players get between a defused bomb and the next one
+	MissionCountdown = 5 * time.Second
+)
+
+// MissionBomb configures one bomb of a mission
+type MissionBomb struct {
+	ModuleCount int `json:"moduleCount"` // 3-12
+	TimeLimit   int `json:"timeLimit"`   // Time limit in seconds (60-3600)
+}
+
+// BombSummary is the outcome of one bomb of a mission
+type BombSummary struct {
+	Index         int       `json:"index"`
+	ModuleCount   int       `json:"moduleCount"`
+	State         BombState `json:"state"`
+	TimeRemaining int       `json:"timeRemaining"`
+	Strikes       int       `json:"strikes"`
+	Played        bool      `json:"played"` // False for bombs the mission never reached
+}
+
+// SetMission sets the list of bombs played back-to-back
+// An empty list plays a single bomb built from the regular lobby settings
+func (gs *GameSession) SetMission(bombs []MissionBomb) error {
+	if err := validateMission(bombs); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	gs.Mission = append([]MissionBomb(nil), bombs...)
+	return nil
+}
+
+// validateMission returns an error if a mission has too many bombs or a bomb out of bounds
+func validateMission(bombs []MissionBomb) error {
+	if len(bombs) > MaxMissionBombs {
+		return fmt.Errorf("a mission can have at most %d bombs", MaxMissionBombs)
+	}
+	for i, bomb := range bombs {
+		if bomb.ModuleCount < MinModuleCount || bomb.ModuleCount > MaxModuleCount {
+			return fmt.Errorf("bomb %d: module count must be between %d and %d", i+1, MinModuleCount, MaxModuleCount)
+		}
+		if bomb.TimeLimit < MinTimeLimit || bomb.TimeLimit > MaxTimeLimit {
+			return fmt.Errorf("bomb %d: time limit must be between %d and %d seconds", i+1, MinTimeLimit, MaxTimeLimit)
+		}
+	}
+	return nil
+}
+
+// GetMission returns a copy of the mission bomb list in a thread-safe way
+func (gs *GameSession) GetMission() []MissionBomb {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return append([]MissionBomb{}, gs.Mission...)
+}
+
+// SetCarryStrikes sets whether strikes carry over from one mission bomb to the next
+func (gs *GameSession) SetCarryStrikes(carry bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.CarryStrikes = carry
+}
+
+// GetCarryStrikes returns whether strikes carry over in a thread-safe way
+func (gs *GameSession) GetCarryStrikes() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.CarryStrikes
+}
+
+// buildBombsLocked creates every bomb of the mission
+// Caller must hold gs.mu
+func (gs *GameSession) buildBombsLocked() []*Bomb {
+	mission := gs.Mission
+	if len(mission) == 0 {
+		mission = []MissionBomb{{ModuleCount: gs.ModuleCount, TimeLimit: gs.TimeLimit}}
+	}
+
+	bombs := make([]*Bomb, len(mission))
+	for i, entry := range mission {
+		id := gs.ID
+		if len(mission) > 1 {
+			id = fmt.Sprintf("%s-%d", gs.ID, i+1)
+		}
+		bombs[i] = NewBomb(id, BombConfig{
+			TimeLimit:                entry.TimeLimit,
+			ModuleCount:              entry.ModuleCount,
+			MaxStrikes:               gs.MaxStrikes,
+			StrikeTimePenalty:        gs.StrikeTimePenalty,
+			TimerAcceleration:        gs.TimerAcceleration,
+			EnableNeedyModules:       gs.EnableNeedyModules,
+			ModuleTypes:              gs.ModuleTypes,
+			ModuleMix:                gs.ModuleMix,
+			Difficulty:               gs.Difficulty,
+			Practice:                 gs.PracticeMode,
+			ReleaseWindow:            gs.ReleaseWindow,
+			StripedWires:             gs.StripedWires,
+			ButtonCyclingGauge:       gs.ButtonCyclingGauge,
+			TerminalHardMode:         gs.TerminalHardMode,
+			TerminalSequenceLength:   gs.TerminalSequenceLength,
+			TerminalStrictness:       gs.TerminalStrictness,
+			TerminalLockoutThreshold: gs.TerminalLockoutThreshold,
+			TerminalLockoutSeconds:   gs.TerminalLockoutSeconds,
+		}, gs.rng)
+	}
+	return bombs
+}
+
+// currentBombLocked returns the bomb being played, or nil if no game is running
+// Caller must hold gs.mu
+func (gs *GameSession) currentBombLocked() *Bomb {
+	if gs.CurrentBombIndex < 0 || gs.CurrentBombIndex >= len(gs.Bombs) {
+		return nil
+	}
+	return gs.Bombs[gs.CurrentBombIndex]
+}
+
+// GetCurrentBomb returns the bomb being played in a thread-safe way, or nil if no game is running
+func (gs *GameSession) GetCurrentBomb() *Bomb {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.currentBombLocked()
+}
+
+// GetModuleCount returns how many modules of a type the bomb being played has
+// ok is false if no game is running
+func (gs *GameSession) GetModuleCount(moduleType string) (count int, ok bool) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return 0, false
+	}
+	return bomb.ModuleCount(moduleType), true
+}
+
+// GetModuleByID returns the type and the index among the modules of that type of the module
+// of the bomb being played with an ID; ok is false if no game is running
+func (gs *GameSession) GetModuleByID(id string) (moduleType string, moduleIndex int, ok bool, err error) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return "", 0, false, nil
+	}
+	moduleType, moduleIndex, err = bomb.ModuleByID(id)
+	return moduleType, moduleIndex, true, err
+}
+
+// GetModuleID returns the ID of the moduleIndex-th module of a type of the bomb being played,
+// empty if there is no such module or no game is running
+func (gs *GameSession) GetModuleID(moduleType string, moduleIndex int) string {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return ""
+	}
+	return bomb.ModuleIDOf(moduleType, moduleIndex)
+}
+
+// GetManualContent returns the expert manual for the bomb being played, rendered in language
+func (gs *GameSession) GetManualContent(language string) *ManualContent {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.manualContentLocked(language)
+}
+
+// manualContentLocked builds the manual of the bomb being played
+func (gs *GameSession) manualContentLocked(language string) *ManualContent {
+	bomb := gs.currentBombLocked()
+	content := GetManualContent(bomb, language)
+	content.BombIndex = gs.CurrentBombIndex
+	content.BombCount = len(gs.Bombs)
+	if bomb != nil {
+		var details *DefuserBombView
+		if gs.ExpertSeesModuleDetails {
+			details = gs.defuserViewLocked()
+		}
+		content.BombState = bomb.ExpertView(details)
+		content.Progress = bomb.Progress()
+		content.Version = bomb.version
+	}
+	return content
+}
+
+// GetGameOverReport returns the outcome of the bomb being played, or nil if no game is running
+// The report carries the bomb index so mission players know which bomb ended the game
+func (gs *GameSession) GetGameOverReport() *GameOverReport {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return nil
+	}
+	report := bomb.GameOverReport()
+	report.BombIndex = gs.CurrentBombIndex
+	report.BombCount = len(gs.Bombs)
+	for i := range report.Modules {
+		report.Modules[i].BombIndex = gs.CurrentBombIndex
+	}
+	return report
+}
+
+// IsGameRunning reports whether the mission is still in progress
+// A defused bomb keeps the game running while another bomb is waiting
+func (gs *GameSession) IsGameRunning() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return false
+	}
+	if bomb.State == BombStateActive {
+		return true
+	}
+	return bomb.State == BombStateDefused && gs.CurrentBombIndex < len(gs.Bombs)-1
+}
+
+// advanceMissionLocked moves on to the next bomb once the countdown after a defusal ran out
+// Caller must hold gs.mu
+func (gs *GameSession) advanceMissionLocked() {
+	bomb := gs.currentBombLocked()
+	if bomb == nil || bomb.State != BombStateDefused || gs.CurrentBombIndex >= len(gs.Bombs)-1 {
+		return
+	}
+
+	if gs.nextBombAt.IsZero() {
+		gs.nextBombAt = time.Now().Add(MissionCountdown)
+		return
+	}
+	if time.Now().Before(gs.nextBombAt) {
+		return
+	}
+
+	gs.nextBombAt = time.Time{}
+	gs.CurrentBombIndex++
+	gs.focus = nil // The modules of the last bomb are gone
+	next := gs.Bombs[gs.CurrentBombIndex]
+	if gs.CarryStrikes {
+		next.Strikes = bomb.Strikes
+		next.applyStrikeSpeed()
+	}
+	next.Start()
+}
+
+// nextBombInLocked returns the seconds left before the next bomb starts, or 0 if none is pending
+// Caller must hold gs.mu
+func (gs *GameSession) nextBombInLocked() int {
+	if gs.nextBombAt.IsZero() {
+		return 0
+	}
+	remaining := int(time.Until(gs.nextBombAt).Round(time.Second).Seconds())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// GetMissionSummary summarizes every bomb of the current or last mission
+func (gs *GameSession) GetMissionSummary() []BombSummary {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	summaries := make([]BombSummary, len(gs.Bombs))
+	for i, bomb := range gs.Bombs {
+		summaries[i] = BombSummary{
+			Index:         i,
+			ModuleCount:   bomb.SolvableModuleCount(),
+			State:         bomb.State,
+			TimeRemaining: bomb.TimeRemaining,
+			Strikes:       bomb.Strikes,
+			Played:        i <= gs.CurrentBombIndex,
+		}
+	}
+	return summaries
+}
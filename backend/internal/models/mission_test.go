@@ -0,0 +1,45 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestCarriedStrikesSpeedUpNextBomb(t *testing.T) {
+	tests := []struct {
+		name         string
+		carry        bool
+		acceleration bool
+		wantStrikes  int
+		wantSpeed    float64
+	}{
+		{"carried with acceleration", true, true, 2, 1 + 2*TimerAccelerationStep},
+		{"carried without acceleration", true, false, 2, 1},
+		{"not carried", false, true, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			config := BombConfig{TimeLimit: 300, ModuleCount: 3, MaxStrikes: 5, TimerAcceleration: tt.acceleration}
+			first, next := NewBomb("BOMB1", config, rng), NewBomb("BOMB2", config, rng)
+			first.Start()
+			first.AddStrike()
+			first.AddStrike()
+			first.State = BombStateDefused
+
+			gs := NewGameSession("SESSION", "host", 300, rng)
+			gs.Bombs = []*Bomb{first, next}
+			gs.CarryStrikes = tt.carry
+			gs.nextBombAt = time.Now().Add(-time.Second)
+			gs.advanceMissionLocked()
+
+			if gs.CurrentBombIndex != 1 {
+				t.Fatalf("current bomb = %d, want the next one", gs.CurrentBombIndex)
+			}
+			if next.Strikes != tt.wantStrikes || next.SpeedMultiplier != tt.wantSpeed {
+				t.Errorf("next bomb has %d strikes at speed %v, want %d at %v", next.Strikes, next.SpeedMultiplier, tt.wantStrikes, tt.wantSpeed)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrModuleSolved is returned for actions on a module that is already solved; they don't cost a strike
+var ErrModuleSolved = errors.New("module is already solved")
+
+// ErrModuleLocked is returned for actions on a module that is locked out; they don't cost a strike
+var ErrModuleLocked = errors.New("module is locked out")
+
+// ErrNoSuchModule is returned for actions on a module index the bomb doesn't have
+var ErrNoSuchModule = errors.New("no module at that index")
+
+// noSuchModuleError returns ErrNoSuchModule for a module type
+func noSuchModuleError(moduleType string) error {
+	return fmt.Errorf("%w: %s", ErrNoSuchModule, moduleType)
+}
+
+// Module is the behaviour shared by every solvable module on the bomb
+// Needy modules are not Modules: they can't be solved and run on the bomb clock instead
+type Module interface {
+	// Type returns the module type (one of the ModuleType constants)
+	Type() string
+	// ModuleID returns the module's stable ID, see Bomb.ModuleByID
+	ModuleID() string
+	// Solved reports whether the module is disarmed
+	Solved() bool
+	// HandleAction applies a defuser action to the module
+	// An error means the action was malformed or not applicable and must not cost a strike
+	HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error)
+	// View returns the defuser-facing state of the module (no solution data)
+	View() interface{}
+	// Manual returns the manual the module was generated with
+	Manual() *ModuleManual
+}
+
+// ActionContext is the bomb information a module can use to judge an action
+type ActionContext struct {
+	Edgework      *BombContext
+	TimeRemaining int // Seconds left on the timer
+	Strikes       int // Strikes before the action
+	// TimeShownLowest and TimeShownHighest are the times the timer showed within the
+	// release window around the action, for actions judged on the timer digits
+	TimeShownLowest  int
+	TimeShownHighest int
+	By               ActionRecord // Who performs the action, and when
+}
+
+// ActionResult is the outcome of a module action
+type ActionResult struct {
+	Correct bool `json:"correct"`
+	Strike  bool `json:"strike"`
+}
+
+// actionOutcome builds the result of an action that is either right or a mistake (strike)
+func actionOutcome(correct bool) ActionResult {
+	return ActionResult{Correct: correct, Strike: !correct}
+}
+
+// decodeActionPayload unmarshals the payload of a module action
+func decodeActionPayload(payload json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("invalid action payload: %w", err)
+	}
+	return nil
+}
+
+// unknownActionError reports an action a module type doesn't support
+func unknownActionError(moduleType string, action string) error {
+	return fmt.Errorf("unknown %s action %q", moduleType, action)
+}
+
+// actionContext returns the context modules judge actions against
+// The timer must have just been updated, so the times shown are taken around now
+func (b *Bomb) actionContext(playerID string) *ActionContext {
+	lowest, highest := b.timeShownAround(b.ReleaseWindow)
+	return &ActionContext{
+		Edgework: &BombContext{
+			SerialNumber: b.SerialNumber,
+			Batteries:    b.Batteries,
+			Indicators:   b.Indicators,
+		},
+		TimeRemaining:    b.TimeRemaining,
+		Strikes:          b.Strikes,
+		TimeShownLowest:  lowest,
+		TimeShownHighest: highest,
+		By:               actionBy(playerID),
+	}
+}
+
+// ModuleOfType returns the moduleIndex-th module of a type, in bomb order
+func (b *Bomb) ModuleOfType(moduleType string, moduleIndex int) (Module, error) {
+	if moduleIndex >= 0 {
+		for _, module := range b.Modules {
+			if module.Type() != moduleType {
+				continue
+			}
+			if moduleIndex == 0 {
+				return module, nil
+			}
+			moduleIndex--
+		}
+	}
+	return nil, noSuchModuleError(moduleType)
+}
+
+// ModuleCount returns how many modules of a type the bomb has, needy modules included
+func (b *Bomb) ModuleCount(moduleType string) int {
+	switch moduleType {
+	case ModuleTypeNeedyVent:
+		return len(b.NeedyVentModules)
+	case ModuleTypeNeedyCapacitor:
+		return len(b.NeedyCapacitorModules)
+	}
+	count := 0
+	for _, module := range b.Modules {
+		if module.Type() == moduleType {
+			count++
+		}
+	}
+	return count
+}
+
+// HandleModuleAction applies a defuser action to the moduleIndex-th module of a type
+// playerID is who performs it, recorded by the modules that keep their actions
+// graceSeconds widens the release window of actions judged on the timer digits, for players on a slow connection
+// A wrong action adds a strike; a correct one may defuse the bomb
+func (b *Bomb) HandleModuleAction(moduleType string, moduleIndex int, action string, payload json.RawMessage, playerID string, graceSeconds int) (ActionResult, error) {
+	// Judge the action against the timer as it is now, not as of the last broadcast
+	b.UpdateTimeRemaining()
+	if b.State != BombStateActive {
+		return ActionResult{}, fmt.Errorf("bomb is not active")
+	}
+
+	module, err := b.ModuleOfType(moduleType, moduleIndex)
+	if err != nil {
+		return ActionResult{}, err
+	}
+	b.markDirty(moduleType, moduleIndex)
+	if module.Solved() {
+		return ActionResult{}, ErrModuleSolved
+	}
+	if lockable, ok := module.(lockableModule); ok && lockable.Lockout() > 0 {
+		return ActionResult{}, ErrModuleLocked
+	}
+
+	ctx := b.actionContext(playerID)
+	ctx.TimeShownHighest += graceSeconds
+	result, err := module.HandleAction(action, payload, ctx)
+	if err != nil {
+		return ActionResult{}, err
+	}
+
+	if result.Strike {
+		b.strike(moduleType, moduleIndex, playerID, action)
+		if lockable, ok := module.(lockableModule); ok && lockable.Lockout() > 0 {
+			b.announceLockout(moduleType, moduleIndex, lockable.Lockout())
+		}
+	} else if result.Correct {
+		// Check if all modules are solved
+		b.CheckWinCondition()
+	}
+	return result, nil
+}
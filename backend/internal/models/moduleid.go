@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// moduleIDLength is how many characters a module ID has
+const moduleIDLength = 6
+
+// moduleIdentity gives a module its stable ID, it is embedded in every module type
+type moduleIdentity struct {
+	ID string `json:"id"` // Stable ID, unique within the bomb
+}
+
+// ModuleID returns the module's stable ID
+func (m *moduleIdentity) ModuleID() string {
+	return m.ID
+}
+
+// setModuleID sets the module's stable ID
+func (m *moduleIdentity) setModuleID(id string) {
+	m.ID = id
+}
+
+// identifiedModule is any module of the bomb, needy modules included
+type identifiedModule interface {
+	ModuleID() string
+	setModuleID(id string)
+}
+
+// moduleIDFor derives the ID of the module at a position of the bomb from its seed
+// attempt is bumped to draw another ID when two modules of a bomb would share one
+func moduleIDFor(seed int64, position int, attempt int) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d/%d/%d", seed, position, attempt)
+	return strings.ToLower(encodeCode(hash.Sum64(), moduleIDLength))
+}
+
+// eachModule calls visit with every module of the bomb, its type and its index among the
+// modules of that type; solvable modules come in bomb order, then the needy ones
+// It stops as soon as visit returns false
+func (b *Bomb) eachModule(visit func(moduleType string, moduleIndex int, module identifiedModule) bool) {
+	indices := make(map[string]int)
+	for _, module := range b.Modules {
+		moduleType := module.Type()
+		if !visit(moduleType, indices[moduleType], module.(identifiedModule)) {
+			return
+		}
+		indices[moduleType]++
+	}
+	for i, module := range b.NeedyVentModules {
+		if !visit(ModuleTypeNeedyVent, i, module) {
+			return
+		}
+	}
+	for i, module := range b.NeedyCapacitorModules {
+		if !visit(ModuleTypeNeedyCapacitor, i, module) {
+			return
+		}
+	}
+}
+
+// assignModuleIDs gives every module of the bomb an ID derived from the seed and its position
+// The same seed always gives the same IDs, so replays and reconnecting clients agree on them
+func (b *Bomb) assignModuleIDs() {
+	used := make(map[string]bool)
+	position := 0
+	b.eachModule(func(moduleType string, moduleIndex int, module identifiedModule) bool {
+		id := moduleIDFor(b.Seed, position, 0)
+		for attempt := 1; used[id]; attempt++ {
+			id = moduleIDFor(b.Seed, position, attempt)
+		}
+		used[id] = true
+		module.setModuleID(id)
+		position++
+		return true
+	})
+}
+
+// ModuleByID returns the type of the module with an ID and its index among the modules of that type
+func (b *Bomb) ModuleByID(id string) (moduleType string, moduleIndex int, err error) {
+	err = fmt.Errorf("%w: unknown module ID %q", ErrNoSuchModule, id)
+	b.eachModule(func(t string, i int, module identifiedModule) bool {
+		if module.ModuleID() != id {
+			return true
+		}
+		moduleType, moduleIndex, err = t, i, nil
+		return false
+	})
+	return moduleType, moduleIndex, err
+}
+
+// ModuleIDOf returns the ID of the moduleIndex-th module of a type, empty if the bomb has no such module
+func (b *Bomb) ModuleIDOf(moduleType string, moduleIndex int) string {
+	id := ""
+	b.eachModule(func(t string, i int, module identifiedModule) bool {
+		if t != moduleType || i != moduleIndex {
+			return true
+		}
+		id = module.ModuleID()
+		return false
+	})
+	return id
+}
+
+// manualKey returns the key of a module type's manual in ManualContent.Modules
+func manualKey(moduleType string) string {
+	if moduleType == ModuleTypeWires {
+		return "wireModule"
+	}
+	return moduleType + "Module"
+}
@@ -0,0 +1,57 @@
+package models
+
+import "encoding/json"
+
+// ModuleEntry is one module of the unified module list, in the bomb's canonical order
+// It serializes as the module's defuser view with its type and per-type index added:
+// {"id", "type", "moduleIndex", ...the view's fields}
+type ModuleEntry struct {
+	Type        string
+	ModuleIndex int         // Index among the modules of that type, as in the per-type lists
+	State       interface{} // Same shape as the module in its per-type list
+}
+
+// MarshalJSON flattens the module view into the entry
+func (e ModuleEntry) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(e.State)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(e.Type)
+	fields["moduleIndex"], _ = json.Marshal(e.ModuleIndex)
+	return json.Marshal(fields)
+}
+
+// ModuleList returns every module of the bomb as one list in canonical order:
+// solvable modules in bomb order, then the needy ones
+// A module's position in it is the same for defusers, experts and events
+func (b *Bomb) ModuleList() []ModuleEntry {
+	list := make([]ModuleEntry, 0, len(b.Modules)+len(b.NeedyVentModules)+len(b.NeedyCapacitorModules))
+	b.eachModule(func(moduleType string, moduleIndex int, module identifiedModule) bool {
+		list = append(list, ModuleEntry{
+			Type:        moduleType,
+			ModuleIndex: moduleIndex,
+			State:       b.moduleView(moduleKey{Type: moduleType, Index: moduleIndex}),
+		})
+		return true
+	})
+	return list
+}
+
+// ModulePosition returns the position of the moduleIndex-th module of a type in the module list, -1 if there is none
+func (b *Bomb) ModulePosition(moduleType string, moduleIndex int) int {
+	position, found := 0, -1
+	b.eachModule(func(t string, i int, module identifiedModule) bool {
+		if t == moduleType && i == moduleIndex {
+			found = position
+			return false
+		}
+		position++
+		return true
+	})
+	return found
+}
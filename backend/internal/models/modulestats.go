@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// ModuleStats is how a module was played, for the post-game analysis
+type ModuleStats struct {
+	WrongAttempts      int        `json:"wrongAttempts"`                // Actions on the module that gave a strike
+	FirstInteractionAt *time.Time `json:"firstInteractionAt,omitempty"` // First action of a player on the module
+	SolvedAt           *time.Time `json:"solvedAt,omitempty"`
+	SolvedBy           string     `json:"solvedBy,omitempty"`     // ID of the player whose action solved the module
+	SolvedByName       string     `json:"solvedByName,omitempty"` // Kept so the stats still read well once the player left
+	SolveSeconds       int        `json:"solveSeconds,omitempty"` // From the first interaction to the solve
+}
+
+// statsOf returns the stats of a module, creating them on first use
+func (b *Bomb) statsOf(moduleID string) *ModuleStats {
+	if b.stats == nil {
+		b.stats = make(map[string]*ModuleStats)
+	}
+	stats, exists := b.stats[moduleID]
+	if !exists {
+		stats = &ModuleStats{}
+		b.stats[moduleID] = stats
+	}
+	return stats
+}
+
+// recordInteraction notes a player acting on a module, only the first action is kept
+func (b *Bomb) recordInteraction(moduleID string, at time.Time) {
+	stats := b.statsOf(moduleID)
+	if stats.FirstInteractionAt == nil {
+		stats.FirstInteractionAt = &at
+	}
+}
+
+// recordWrongAttempt notes an action on a module that gave a strike
+func (b *Bomb) recordWrongAttempt(moduleID string) {
+	b.statsOf(moduleID).WrongAttempts++
+}
+
+// recordSolve notes the action that solved a module and who performed it
+func (b *Bomb) recordSolve(moduleID string, playerID string, playerName string, at time.Time) {
+	stats := b.statsOf(moduleID)
+	stats.SolvedAt = &at
+	stats.SolvedBy = playerID
+	stats.SolvedByName = playerName
+	if stats.FirstInteractionAt != nil {
+		stats.SolveSeconds = int(at.Sub(*stats.FirstInteractionAt).Seconds())
+	}
+}
+
+// ModuleStats returns how a module was played, zero stats if no player acted on it
+func (b *Bomb) ModuleStats(moduleID string) ModuleStats {
+	if stats, exists := b.stats[moduleID]; exists {
+		return *stats
+	}
+	return ModuleStats{}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cutWire cuts a wire of a wires module as player-1
+func cutWire(t *testing.T, gs *GameSession, moduleIndex int, wireIndex int) {
+	t.Helper()
+	payload, _ := json.Marshal(map[string]int{"wireIndex": wireIndex})
+	action := PlayerAction{PlayerID: "player-1", Action: "cutWire", ModuleType: ModuleTypeWires, ModuleIndex: moduleIndex, Payload: payload}
+	if err := gs.DoPlayerAction(action, func(bomb *Bomb) {
+		bomb.HandleModuleAction(ModuleTypeWires, moduleIndex, "cut", payload, "player-1", 0)
+	}); err != nil {
+		t.Fatalf("DoPlayerAction: %v", err)
+	}
+}
+
+func TestModuleStatsSurviveSerialization(t *testing.T) {
+	gs := newTestSession(t, BombConfig{ModuleMix: map[string]int{ModuleTypeWires: 3}}, 1)
+	before := time.Now()
+	played := gs.Bombs[0].WiresModules[0]
+	cutWire(t, gs, 0, wrongWire(played))
+	cutWire(t, gs, 0, played.CorrectCut)
+
+	data, err := json.Marshal(gs.GetGameOverReport())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var report GameOverReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		module        int
+		wrongAttempts int
+		played        bool
+		solvedBy      string
+		solvedByName  string
+	}{
+		{"played", 0, 1, true, "player-1", "Defuser"},
+		{"untouched", 1, 0, false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := report.Modules[tt.module].ModuleStats
+			if stats.WrongAttempts != tt.wrongAttempts || stats.SolvedBy != tt.solvedBy || stats.SolvedByName != tt.solvedByName {
+				t.Errorf("stats = %+v, want %d wrong attempts, solved by %q (%q)", stats, tt.wrongAttempts, tt.solvedBy, tt.solvedByName)
+			}
+			if (stats.FirstInteractionAt != nil) != tt.played || (stats.SolvedAt != nil) != tt.played {
+				t.Fatalf("first interaction %v, solved %v; want both set %v", stats.FirstInteractionAt, stats.SolvedAt, tt.played)
+			}
+			if tt.played && (stats.FirstInteractionAt.Before(before) || stats.SolvedAt.Before(*stats.FirstInteractionAt)) {
+				t.Errorf("first interaction %v, solved %v; want both after %v, in order", stats.FirstInteractionAt, stats.SolvedAt, before)
+			}
+		})
+	}
+	if strings.Count(string(data), `"firstInteractionAt"`) != 1 {
+		t.Errorf("untouched modules serialize a first interaction: %s", data)
+	}
+}
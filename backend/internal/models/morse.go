@@ -0,0 +1,286 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// MorseWords is the pool of words the manual's frequency table is drawn from
+var MorseWords = []string{
+	"SHELL", "HALLS", "SLICK", "TRICK", "BOXES", "LEAKS", "STROBE", "BISTRO",
+	"FLICK", "BOMBS", "BREAK", "BRICK", "STEAK", "STING", "VECTOR", "BEATS",
+	"BRAIN", "CLOCK", "FUSES", "GHOST", "LASER", "PANIC", "RADIO", "SPARK",
+	"TIMER", "WIRES", "CRASH", "DELTA",
+}
+
+// morseAlphabet maps each letter to its dots and dashes
+var morseAlphabet = map[rune]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.",
+	'G': "--.", 'H': "....", 'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..",
+	'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+}
+
+const (
+	// morseTableSize is the number of word/frequency pairs printed in the manual
+	morseTableSize = 16
+	// morseBaseFrequency and morseFrequencyStep define the frequencies in kHz (3505, 3510, ...)
+	morseBaseFrequency = 3500
+	morseFrequencyStep = 5
+	// morseFrequencySlots is how many frequencies the table can be spread over
+	morseFrequencySlots = 20
+	// MorseUnitMillis is the length of one Morse unit (a dot) in milliseconds
+	MorseUnitMillis = 300
+	// morseWordGap is how many dark units separate two repetitions of the word
+	morseWordGap = 7
+)
+
+// MorseModule represents the Morse code module on the bomb
+// The light blinks a word in Morse; the defuser tunes the frequency the manual
+// pairs with that word and transmits it
+type MorseModule struct {
+	moduleIdentity
+	Word          string        `json:"word"`          // Word blinked by the light
+	Frequency     int           `json:"frequency"`     // Frequency to transmit, in kHz
+	SelectedIndex int           `json:"selectedIndex"` // Index of the tuned frequency in RuleSet.Frequencies
+	IsSolved      bool          `json:"isSolved"`
+	RuleSet       *MorseRuleSet `json:"-"` // Rules for this module (not serialized)
+	timeline      []bool        // Light state for each unit of one repetition
+	blinkStart    time.Time     // When the blinking started
+	manual        *ModuleManual // Manual generated alongside the module
+}
+
+// MorseModuleView is the defuser-facing view of a Morse module (no solution data)
+type MorseModuleView struct {
+	ID         string `json:"id"`         // Stable module ID
+	Signal     string `json:"signal"`     // Dots and dashes of the word, letters separated by spaces
+	UnitMillis int    `json:"unitMillis"` // Length of one unit, so clients can animate the light
+	LightOn    bool   `json:"lightOn"`    // Light state right now
+	Frequency  int    `json:"frequency"`  // Tuned frequency, in kHz
+	IsSolved   bool   `json:"isSolved"`
+}
+
+// DefuserView returns the Morse module state that can be shown to the defuser
+func (mm *MorseModule) DefuserView() *MorseModuleView {
+	return &MorseModuleView{
+		ID:         mm.ID,
+		Signal:     morseSignal(mm.Word),
+		UnitMillis: MorseUnitMillis,
+		LightOn:    mm.LightOn(time.Now()),
+		Frequency:  mm.TunedFrequency(),
+		IsSolved:   mm.IsSolved,
+	}
+}
+
+// MorseRuleSet pairs each word of the manual with a frequency
+type MorseRuleSet struct {
+	Words       []string       `json:"-"` // Words of the table, sorted by frequency
+	Frequencies []int          `json:"-"` // Frequencies in kHz, ascending (the ones the module can tune)
+	ByWord      map[string]int `json:"-"`
+}
+
+// NewMorseModuleWithRules creates a new Morse module blinking one of the manual's words
+// morseSeed: seed for picking the word and starting frequency (different for each module)
+// ruleSeed: seed for generating the table (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewMorseModuleWithRules(morseSeed int64, ruleSeed int64) (*MorseModule, *ModuleManual) {
+	rng := rand.New(rand.NewSource(morseSeed))
+	ruleSet, moduleManual := GenerateMorseModuleRulesWithSeed(ruleSeed)
+
+	word := ruleSet.Words[rng.Intn(len(ruleSet.Words))]
+	module := &MorseModule{
+		Word:          word,
+		Frequency:     ruleSet.ByWord[word],
+		SelectedIndex: rng.Intn(len(ruleSet.Frequencies)),
+		RuleSet:       ruleSet,
+		timeline:      morseTimeline(word),
+		blinkStart:    time.Now(),
+		manual:        moduleManual,
+	}
+	return module, moduleManual
+}
+
+// morseSignal spells a word in dots and dashes, letters separated by spaces
+func morseSignal(word string) string {
+	letters := make([]string, 0, len(word))
+	for _, letter := range word {
+		letters = append(letters, morseAlphabet[letter])
+	}
+	return strings.Join(letters, " ")
+}
+
+// morseTimeline expands a word into light states, one per unit
+// A dot is one unit on, a dash three; symbols are split by one dark unit,
+// letters by three, and the word is followed by a longer pause before it repeats
+func morseTimeline(word string) []bool {
+	timeline := []bool{}
+	for i, letter := range word {
+		if i > 0 {
+			timeline = append(timeline, false, false, false)
+		}
+		for j, symbol := range morseAlphabet[letter] {
+			if j > 0 {
+				timeline = append(timeline, false)
+			}
+			timeline = append(timeline, true)
+			if symbol == '-' {
+				timeline = append(timeline, true, true)
+			}
+		}
+	}
+	for i := 0; i < morseWordGap; i++ {
+		timeline = append(timeline, false)
+	}
+	return timeline
+}
+
+// LightOn returns whether the light is on at the given time
+func (mm *MorseModule) LightOn(now time.Time) bool {
+	if mm.IsSolved || len(mm.timeline) == 0 {
+		return false
+	}
+	unit := int(now.Sub(mm.blinkStart).Milliseconds() / MorseUnitMillis)
+	return mm.timeline[unit%len(mm.timeline)]
+}
+
+// TunedFrequency returns the frequency the module is tuned to, in kHz
+func (mm *MorseModule) TunedFrequency() int {
+	return mm.RuleSet.Frequencies[mm.SelectedIndex]
+}
+
+// Tune moves to the previous (direction < 0) or next (direction > 0) frequency
+// Tuning stops at both ends of the band; returns false if nothing changed
+func (mm *MorseModule) Tune(direction int) bool {
+	if mm.IsSolved {
+		return false
+	}
+
+	next := mm.SelectedIndex
+	if direction < 0 {
+		next--
+	} else if direction > 0 {
+		next++
+	}
+	if next < 0 || next >= len(mm.RuleSet.Frequencies) || next == mm.SelectedIndex {
+		return false
+	}
+	mm.SelectedIndex = next
+	return true
+}
+
+// Submit transmits the tuned frequency
+// Returns true if it matches the blinked word, false if wrong (strike)
+func (mm *MorseModule) Submit() bool {
+	if mm.IsSolved {
+		return false
+	}
+
+	if mm.TunedFrequency() != mm.Frequency {
+		return false
+	}
+
+	mm.IsSolved = true
+	return true
+}
+
+// GenerateMorseModuleRulesWithSeed generates the word/frequency table for Morse modules
+func GenerateMorseModuleRulesWithSeed(seed int64) (*MorseRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 9999999))
+
+	// Pick the words and spread them over distinct frequency slots
+	wordIndices := rng.Perm(len(MorseWords))[:morseTableSize]
+	slots := rng.Perm(morseFrequencySlots)[:morseTableSize]
+	ruleSet := &MorseRuleSet{
+		Words:       make([]string, morseTableSize),
+		Frequencies: make([]int, morseTableSize),
+		ByWord:      make(map[string]int, morseTableSize),
+	}
+
+	// List the table by ascending frequency, like a radio dial
+	order := make([]int, morseFrequencySlots)
+	for i := range order {
+		order[i] = -1
+	}
+	for i, slot := range slots {
+		order[slot] = i
+	}
+	row := 0
+	for slot, i := range order {
+		if i < 0 {
+			continue
+		}
+		word := MorseWords[wordIndices[i]]
+		frequency := morseBaseFrequency + (slot+1)*morseFrequencyStep
+		ruleSet.Words[row] = word
+		ruleSet.Frequencies[row] = frequency
+		ruleSet.ByWord[word] = frequency
+		row++
+	}
+
+	manualRules := make([]ManualRule, morseTableSize)
+	for i, word := range ruleSet.Words {
+		frequency := fmt.Sprintf("%d.%03d", ruleSet.Frequencies[i]/1000, ruleSet.Frequencies[i]%1000)
+		manualRules[i] = manualRule(i+1, msg("morse.rule", word, frequency))
+	}
+
+	alphabet := make(map[string]string, len(morseAlphabet))
+	for letter, code := range morseAlphabet {
+		alphabet[string(letter)] = code
+	}
+
+	moduleManual := newModuleManual(msg("morse.title"), msg("morse.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"words":       ruleSet.Words,
+		"frequencies": ruleSet.Frequencies,
+		"alphabet":    alphabet,
+	}
+
+	return ruleSet, moduleManual
+}
+
+// Type returns the module type
+func (mm *MorseModule) Type() string {
+	return ModuleTypeMorse
+}
+
+// Solved reports whether the Morse module is disarmed
+func (mm *MorseModule) Solved() bool {
+	return mm.IsSolved
+}
+
+// View returns the defuser-facing state of the Morse module
+func (mm *MorseModule) View() interface{} {
+	return mm.DefuserView()
+}
+
+// Manual returns the manual the Morse module was generated with
+func (mm *MorseModule) Manual() *ModuleManual {
+	return mm.manual
+}
+
+// HandleAction applies a defuser action to the Morse module
+// Supports "tune" with a direction, and "submit"; tuning never strikes
+func (mm *MorseModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	switch action {
+	case "tune":
+		var data struct {
+			Direction string `json:"direction"` // "down" or "up"
+		}
+		if err := decodeActionPayload(payload, &data); err != nil {
+			return ActionResult{}, err
+		}
+		direction := 1
+		if data.Direction == "down" {
+			direction = -1
+		}
+		mm.Tune(direction)
+		return ActionResult{Correct: true}, nil
+	case "submit":
+		return actionOutcome(mm.Submit()), nil
+	}
+	return ActionResult{}, unknownActionError(ModuleTypeMorse, action)
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+)
+
+const (
+	// NeedyVentResponseTime is how many seconds the defuser has to answer a vent gas prompt
+	NeedyVentResponseTime = 20
+	// needyVentMinInterval and needyVentMaxInterval bound the seconds between two prompts
+	needyVentMinInterval = 30
+	needyVentMaxInterval = 60
+)
+
+// Vent gas prompts and the answer each one expects
+const (
+	NeedyVentPromptVent     = "VENT GAS? Y/N"
+	NeedyVentPromptDetonate = "DETONATE? Y/N"
+)
+
+// NeedyVentModule is a needy module that keeps asking to vent gas until the bomb ends
+// It is never solved; missing a prompt or answering wrong gives a strike
+// Its timers run on the bomb clock so they respect pauses and timer acceleration
+type NeedyVentModule struct {
+	moduleIdentity
+	Active         bool       `json:"active"`       // Whether a prompt is waiting for an answer
+	Prompt         string     `json:"prompt"`       // Prompt shown while active
+	TimeLeft       int        `json:"timeLeft"`     // Seconds left to answer while active
+	ResponseTime   int        `json:"responseTime"` // Seconds given to answer each prompt
+	nextActivation float64    // Bomb clock second at which the next prompt shows up
+	deadline       float64    // Bomb clock second at which the current prompt expires
+	rng            *rand.Rand // Seeded source for intervals and prompts
+}
+
+// NeedyVentModuleView is the defuser-facing view of a vent gas module
+type NeedyVentModuleView struct {
+	ID       string `json:"id"` // Stable module ID
+	Active   bool   `json:"active"`
+	Prompt   string `json:"prompt"`
+	TimeLeft int    `json:"timeLeft"`
+}
+
+// DefuserView returns the vent gas module state that can be shown to the defuser
+func (nm *NeedyVentModule) DefuserView() *NeedyVentModuleView {
+	return &NeedyVentModuleView{
+		ID:       nm.ID,
+		Active:   nm.Active,
+		Prompt:   nm.Prompt,
+		TimeLeft: nm.TimeLeft,
+	}
+}
+
+// NewNeedyVentModule creates a vent gas module whose timings are derived from the seed
+func NewNeedyVentModule(seed int64) *NeedyVentModule {
+	module := &NeedyVentModule{
+		ResponseTime: NeedyVentResponseTime,
+		rng:          rand.New(rand.NewSource(seed)),
+	}
+	module.scheduleNext(0)
+	return module
+}
+
+// scheduleNext picks when the next prompt shows up
+func (nm *NeedyVentModule) scheduleNext(now float64) {
+	interval := needyVentMinInterval + nm.rng.Intn(needyVentMaxInterval-needyVentMinInterval+1)
+	nm.nextActivation = now + float64(interval)
+}
+
+// Tick advances the module to the given bomb clock time
+// Returns true if a prompt expired without an answer (strike)
+func (nm *NeedyVentModule) Tick(elapsed float64) bool {
+	if !nm.Active {
+		if elapsed < nm.nextActivation {
+			return false
+		}
+		nm.Active = true
+		nm.Prompt = NeedyVentPromptVent
+		if nm.rng.Intn(2) == 0 {
+			nm.Prompt = NeedyVentPromptDetonate
+		}
+		nm.deadline = elapsed + float64(nm.ResponseTime)
+	}
+
+	if elapsed >= nm.deadline {
+		nm.deactivate(elapsed)
+		return true
+	}
+
+	nm.TimeLeft = int(math.Ceil(nm.deadline - elapsed))
+	return false
+}
+
+// Answer answers the current prompt ("Y"/"YES" or "N"/"NO")
+// Returns true if the answer is correct; the prompt is cleared either way
+func (nm *NeedyVentModule) Answer(answer string, elapsed float64) bool {
+	if !nm.Active {
+		return false
+	}
+
+	answer = strings.ToUpper(strings.TrimSpace(answer))
+	yes := answer == "Y" || answer == "YES"
+	no := answer == "N" || answer == "NO"
+
+	// Venting gas is always a yes, detonating is always a no
+	correct := (nm.Prompt == NeedyVentPromptVent && yes) || (nm.Prompt == NeedyVentPromptDetonate && no)
+	nm.deactivate(elapsed)
+	return correct
+}
+
+// deactivate clears the current prompt and schedules the next one
+func (nm *NeedyVentModule) deactivate(elapsed float64) {
+	nm.Active = false
+	nm.Prompt = ""
+	nm.TimeLeft = 0
+	nm.scheduleNext(elapsed)
+}
+
+// GenerateNeedyVentModuleManual returns the manual for vent gas modules
+func GenerateNeedyVentModuleManual() *ModuleManual {
+	return newModuleManual(msg("needyVent.title"), msg("needyVent.instructions"), []ManualRule{
+		manualRule(1, msg("needyVent.rule", "VENT GAS?", "YES")),
+		manualRule(2, msg("needyVent.rule", "DETONATE?", "NO")),
+	})
+}
+
+const (
+	// NeedyCapacitorDischargeRate is how many percent per second holding the lever drains
+	NeedyCapacitorDischargeRate = 10.0
+	// needyCapacitorMinRate and needyCapacitorMaxRate bound the seeded charge rate in tenths of a percent per second
+	needyCapacitorMinRate = 8
+	needyCapacitorMaxRate = 15
+)
+
+// NeedyCapacitorModule is a needy module whose charge keeps rising until it is discharged
+// It is never solved; a full capacitor gives a strike and resets to empty
+// Like the vent gas module, it runs on the bomb clock
+type NeedyCapacitorModule struct {
+	moduleIdentity
+	Charge      float64 `json:"charge"`      // Charge in percent (0-100)
+	ChargeRate  float64 `json:"chargeRate"`  // Percent gained per second while not discharging
+	Discharging bool    `json:"discharging"` // Whether the defuser is holding the discharge lever
+	lastTick    float64 // Bomb clock second of the last update
+}
+
+// NeedyCapacitorModuleView is the defuser-facing view of a capacitor module
+type NeedyCapacitorModuleView struct {
+	ID            string `json:"id"` // Stable module ID
+	ChargePercent int    `json:"chargePercent"`
+	Discharging   bool   `json:"discharging"`
+}
+
+// DefuserView returns the capacitor module state that can be shown to the defuser
+func (cm *NeedyCapacitorModule) DefuserView() *NeedyCapacitorModuleView {
+	return &NeedyCapacitorModuleView{
+		ID:            cm.ID,
+		ChargePercent: int(cm.Charge),
+		Discharging:   cm.Discharging,
+	}
+}
+
+// NewNeedyCapacitorModule creates a capacitor module whose charge rate is derived from the seed
+func NewNeedyCapacitorModule(seed int64) *NeedyCapacitorModule {
+	rng := rand.New(rand.NewSource(seed))
+	rate := needyCapacitorMinRate + rng.Intn(needyCapacitorMaxRate-needyCapacitorMinRate+1)
+	return &NeedyCapacitorModule{
+		ChargeRate: float64(rate) / 10,
+	}
+}
+
+// Tick advances the module to the given bomb clock time
+// Returns true if the capacitor filled up (strike)
+func (cm *NeedyCapacitorModule) Tick(elapsed float64) bool {
+	delta := elapsed - cm.lastTick
+	cm.lastTick = elapsed
+	if delta <= 0 {
+		return false
+	}
+
+	if cm.Discharging {
+		cm.Charge = math.Max(0, cm.Charge-NeedyCapacitorDischargeRate*delta)
+		return false
+	}
+
+	cm.Charge += cm.ChargeRate * delta
+	if cm.Charge >= 100 {
+		cm.Charge = 0
+		return true
+	}
+	return false
+}
+
+// GenerateNeedyCapacitorModuleManual returns the manual for capacitor modules
+func GenerateNeedyCapacitorModuleManual() *ModuleManual {
+	return newModuleManual(msg("needyCapacitor.title"), msg("needyCapacitor.instructions"), []ManualRule{
+		manualRule(1, msg("needyCapacitor.rule.hold")),
+		manualRule(2, msg("needyCapacitor.rule.full")),
+	})
+}
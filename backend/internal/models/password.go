@@ -0,0 +1,271 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// PasswordWords is the pool of 5-letter words the manual's password list is drawn from
+var PasswordWords = []string{
+	"ABOUT", "AFTER", "AGAIN", "BELOW", "COULD", "EVERY", "FIRST", "FOUND", "GREAT",
+	"HOUSE", "LARGE", "LEARN", "NEVER", "OTHER", "PLACE", "PLANT", "POINT", "RIGHT",
+	"SMALL", "SOUND", "SPELL", "STILL", "STUDY", "THEIR", "THERE", "THESE", "THING",
+	"THINK", "THREE", "WATER", "WHERE", "WHICH", "WORLD", "WOULD", "WRITE",
+}
+
+const (
+	// passwordLength is the number of letters in a password (and spinner columns)
+	passwordLength = 5
+	// passwordColumnSize is the number of letters on each spinner column
+	passwordColumnSize = 6
+	// passwordCandidateCount is the number of words a module is built from (the answer and its decoys)
+	passwordCandidateCount = 6
+	// passwordManualWords is the number of words listed in the manual
+	passwordManualWords = 20
+	// passwordMaxAttempts bounds the search for columns that spell a single manual word
+	passwordMaxAttempts = 100
+)
+
+// passwordLetters are the letters spinner columns are padded with
+const passwordLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// PasswordModule represents the password module on the bomb
+// Each of the five columns cycles through six letters; exactly one word of the
+// manual can be spelled with them, and the defuser has to dial it in and submit
+type PasswordModule struct {
+	moduleIdentity
+	Columns    []string         `json:"columns"`    // Letters of each column, in spin order
+	Positions  []int            `json:"positions"`  // Letter currently shown by each column
+	Candidates []string         `json:"candidates"` // Words the columns were built from (answer included)
+	Answer     string           `json:"answer"`     // The only manual word the columns can spell
+	IsSolved   bool             `json:"isSolved"`
+	RuleSet    *PasswordRuleSet `json:"-"` // Rules for this module (not serialized)
+	manual     *ModuleManual    // Manual generated alongside the module
+}
+
+// PasswordModuleView is the defuser-facing view of a password module (no solution data)
+type PasswordModuleView struct {
+	ID        string   `json:"id"` // Stable module ID
+	Columns   []string `json:"columns"`
+	Positions []int    `json:"positions"`
+	Word      string   `json:"word"` // Word currently shown by the columns
+	IsSolved  bool     `json:"isSolved"`
+}
+
+// DefuserView returns the password module state that can be shown to the defuser
+func (pm *PasswordModule) DefuserView() *PasswordModuleView {
+	return &PasswordModuleView{
+		ID:        pm.ID,
+		Columns:   pm.Columns,
+		Positions: append([]int{}, pm.Positions...),
+		Word:      pm.CurrentWord(),
+		IsSolved:  pm.IsSolved,
+	}
+}
+
+// PasswordRuleSet contains the password list printed in the manual
+type PasswordRuleSet struct {
+	Words []string `json:"-"`
+}
+
+// countSpellable returns how many words of the list the columns can spell
+func (rs *PasswordRuleSet) countSpellable(columns []string) int {
+	count := 0
+	for _, word := range rs.Words {
+		spellable := true
+		for i := 0; i < passwordLength; i++ {
+			if !strings.ContainsRune(columns[i], rune(word[i])) {
+				spellable = false
+				break
+			}
+		}
+		if spellable {
+			count++
+		}
+	}
+	return count
+}
+
+// NewPasswordModuleWithRules creates a new password module whose columns spell exactly one manual word
+// passwordSeed: seed for picking the words and letters (different for each module)
+// ruleSeed: seed for generating the password list (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewPasswordModuleWithRules(passwordSeed int64, ruleSeed int64) (*PasswordModule, *ModuleManual) {
+	rng := rand.New(rand.NewSource(passwordSeed))
+	ruleSet, moduleManual := GeneratePasswordModuleRulesWithSeed(ruleSeed)
+
+	var candidates []string
+	var columns []string
+	for attempt := 0; attempt < passwordMaxAttempts; attempt++ {
+		candidates = make([]string, 0, passwordCandidateCount)
+		for _, i := range rng.Perm(len(ruleSet.Words))[:passwordCandidateCount] {
+			candidates = append(candidates, ruleSet.Words[i])
+		}
+		columns = buildPasswordColumns(rng, candidates)
+		// Keep the first columns that can't spell any other manual word
+		if ruleSet.countSpellable(columns) == 1 {
+			break
+		}
+	}
+
+	module := &PasswordModule{
+		Columns:    columns,
+		Positions:  make([]int, passwordLength),
+		Candidates: candidates,
+		Answer:     candidates[0],
+		RuleSet:    ruleSet,
+		manual:     moduleManual,
+	}
+	// Start on a random letter for each column
+	for i := range module.Positions {
+		module.Positions[i] = rng.Intn(passwordColumnSize)
+	}
+	return module, moduleManual
+}
+
+// buildPasswordColumns builds the spinner columns for the answer (the first candidate)
+// Each column holds the answer's letter, some of the decoys' letters, and random padding
+func buildPasswordColumns(rng *rand.Rand, candidates []string) []string {
+	columns := make([]string, passwordLength)
+	for i := 0; i < passwordLength; i++ {
+		letters := []byte{candidates[0][i]}
+		for _, decoy := range candidates[1:] {
+			if len(letters) < passwordColumnSize && rng.Intn(2) == 0 && !containsByte(letters, decoy[i]) {
+				letters = append(letters, decoy[i])
+			}
+		}
+		for len(letters) < passwordColumnSize {
+			letter := passwordLetters[rng.Intn(len(passwordLetters))]
+			if !containsByte(letters, letter) {
+				letters = append(letters, letter)
+			}
+		}
+		rng.Shuffle(len(letters), func(a, b int) {
+			letters[a], letters[b] = letters[b], letters[a]
+		})
+		columns[i] = string(letters)
+	}
+	return columns
+}
+
+// containsByte reports whether b is in values
+func containsByte(values []byte, b byte) bool {
+	for _, v := range values {
+		if v == b {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentWord returns the word currently shown by the columns
+func (pm *PasswordModule) CurrentWord() string {
+	word := make([]byte, len(pm.Columns))
+	for i, column := range pm.Columns {
+		word[i] = column[pm.Positions[i]]
+	}
+	return string(word)
+}
+
+// Spin moves a column one letter up (direction < 0) or down (direction > 0), wrapping around
+// Returns false if the column doesn't exist
+func (pm *PasswordModule) Spin(column int, direction int) bool {
+	if pm.IsSolved || column < 0 || column >= len(pm.Columns) || direction == 0 {
+		return false
+	}
+
+	step := 1
+	if direction < 0 {
+		step = passwordColumnSize - 1
+	}
+	pm.Positions[column] = (pm.Positions[column] + step) % passwordColumnSize
+	return true
+}
+
+// Submit checks the word currently shown by the columns
+// Returns true if it is the password, false if wrong (strike)
+func (pm *PasswordModule) Submit() bool {
+	if pm.IsSolved {
+		return false
+	}
+
+	if pm.CurrentWord() != pm.Answer {
+		return false
+	}
+
+	pm.IsSolved = true
+	return true
+}
+
+// GeneratePasswordModuleRulesWithSeed generates the password list for password modules
+func GeneratePasswordModuleRulesWithSeed(seed int64) (*PasswordRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 8888888))
+
+	words := make([]string, 0, passwordManualWords)
+	for _, i := range rng.Perm(len(PasswordWords))[:passwordManualWords] {
+		words = append(words, PasswordWords[i])
+	}
+
+	manualRules := make([]ManualRule, len(words))
+	for i, word := range words {
+		manualRules[i] = ManualRule{
+			Number:      i + 1,
+			Description: word,
+		}
+	}
+
+	moduleManual := newModuleManual(msg("password.title"), msg("password.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"words": words,
+	}
+
+	return &PasswordRuleSet{Words: words}, moduleManual
+}
+
+// Type returns the module type
+func (pm *PasswordModule) Type() string {
+	return ModuleTypePassword
+}
+
+// Solved reports whether the password module is disarmed
+func (pm *PasswordModule) Solved() bool {
+	return pm.IsSolved
+}
+
+// View returns the defuser-facing state of the password module
+func (pm *PasswordModule) View() interface{} {
+	return pm.DefuserView()
+}
+
+// Manual returns the manual the password module was generated with
+func (pm *PasswordModule) Manual() *ModuleManual {
+	return pm.manual
+}
+
+// HandleAction applies a defuser action to the password module
+// Supports "spin" with a column and direction, and "submit"; spinning never strikes
+func (pm *PasswordModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	switch action {
+	case "spin":
+		var data struct {
+			Column    int    `json:"column"`
+			Direction string `json:"direction"` // "up" or "down"
+		}
+		if err := decodeActionPayload(payload, &data); err != nil {
+			return ActionResult{}, err
+		}
+		direction := 1
+		if data.Direction == "up" {
+			direction = -1
+		}
+		if !pm.Spin(data.Column, direction) {
+			return ActionResult{}, fmt.Errorf("invalid column")
+		}
+		return ActionResult{Correct: true}, nil
+	case "submit":
+		return actionOutcome(pm.Submit()), nil
+	}
+	return ActionResult{}, unknownActionError(ModuleTypePassword, action)
+}
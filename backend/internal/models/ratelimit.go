@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	// ActionRate and ActionBurst limit the module actions of one connection
+	ActionRate  = 10 // Actions per second, sustained
+	ActionBurst = 20 // Actions allowed at once after a pause
+	// SyncRate and SyncBurst limit pings and state requests, which clients send on their own
+	SyncRate  = 5
+	SyncBurst = 20
+)
+
+// tokenBucket allows rate events per second on average, and up to burst at once
+// The zero value is full, so a new connection starts with its whole burst
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// allow takes a token if one is left, refilling the bucket for the time since the last call
+func (b *tokenBucket) allow(now time.Time, rate, burst float64) bool {
+	if b.updated.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += now.Sub(b.updated).Seconds() * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// AllowAction reports whether the connection may send another module action
+// The limit goes with the connection, so a reconnecting player starts over with a full burst
+func (c *Connection) AllowAction() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.actions.allow(time.Now(), ActionRate, ActionBurst)
+}
+
+// AllowSync reports whether the connection may send another ping or state request
+func (c *Connection) AllowSync() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.syncs.allow(time.Now(), SyncRate, SyncBurst)
+}
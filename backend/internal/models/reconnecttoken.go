@@ -0,0 +1,37 @@
+package models
+
+import (
+	"crypto/subtle"
+
+	"bombs/internal/utils"
+)
+
+// reconnectTokenLength is the length of the secret a player reconnects with
+const reconnectTokenLength = 32
+
+// IssueReconnectToken gives a player the secret that lets them keep their seat when they reconnect,
+// replacing any earlier one. Player IDs are broadcast to everyone, so only this token, sent to
+// the player alone, proves a reconnecting client is them
+func (gs *GameSession) IssueReconnectToken(playerID string) (string, error) {
+	token, err := utils.GenerateRandomString(reconnectTokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.reconnectTokens == nil {
+		gs.reconnectTokens = make(map[string]string)
+	}
+	gs.reconnectTokens[playerID] = token
+	return token, nil
+}
+
+// VerifyReconnectToken reports whether token is the one last issued to the player
+// Tokens outlive the player's seat, so a player dropped from a game can still come back
+func (gs *GameSession) VerifyReconnectToken(playerID string, token string) bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	issued, exists := gs.reconnectTokens[playerID]
+	return exists && token != "" && subtle.ConstantTimeCompare([]byte(issued), []byte(token)) == 1
+}
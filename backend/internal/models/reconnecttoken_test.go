@@ -0,0 +1,35 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestVerifyReconnectToken(t *testing.T) {
+	gs := NewGameSession("SESSION", "player-1", 300, rand.New(rand.NewSource(1)))
+	replaced, _ := gs.IssueReconnectToken("player-1")
+	token, _ := gs.IssueReconnectToken("player-1")
+	otherToken, _ := gs.IssueReconnectToken("player-2")
+
+	tests := []struct {
+		name     string
+		playerID string
+		token    string
+		want     bool
+	}{
+		{"issued token", "player-1", token, true},
+		{"replaced token", "player-1", replaced, false},
+		{"no token", "player-1", "", false},
+		{"other player's token", "player-1", otherToken, false},
+		{"token for another player", "player-2", token, false},
+		{"player without token", "player-3", token, false},
+		{"player without token, no token", "player-3", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gs.VerifyReconnectToken(tt.playerID, tt.token); got != tt.want {
+				t.Errorf("VerifyReconnectToken(%s) = %v, want %v", tt.playerID, got, tt.want)
+			}
+		})
+	}
+}
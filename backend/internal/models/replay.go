@@ -0,0 +1,211 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// MaxReplayEvents bounds the event log of a bomb, later events are counted but not kept
+	MaxReplayEvents = 2000
+	// maxReplayPayloadSize is the largest action payload kept in the log
+	maxReplayPayloadSize = 512
+)
+
+// ReplayEventType is the kind of an entry in a bomb's event log
+type ReplayEventType string
+
+const (
+	ReplayEventAction         ReplayEventType = "action"         // A player acted on a module
+	ReplayEventStrike         ReplayEventType = "strike"         // A module gave a strike
+	ReplayEventModuleSolved   ReplayEventType = "moduleSolved"   // A module was disarmed
+	ReplayEventGameOver       ReplayEventType = "gameOver"       // The bomb was defused or exploded
+	ReplayEventModuleLocked   ReplayEventType = "moduleLocked"   // A module locked itself out after wrong actions
+	ReplayEventModuleUnlocked ReplayEventType = "moduleUnlocked" // A locked out module accepts actions again
+)
+
+// ReplayEvent is one timestamped entry of a bomb's event log
+type ReplayEvent struct {
+	At            time.Time       `json:"at"`
+	TimeRemaining int             `json:"timeRemaining"` // Seconds on the bomb timer when it happened
+	Type          ReplayEventType `json:"type"`
+	PlayerID      string          `json:"playerId,omitempty"`
+	PlayerName    string          `json:"playerName,omitempty"`
+	Action        string          `json:"action,omitempty"`     // WebSocket message type of a player action
+	ModuleType    string          `json:"moduleType,omitempty"` // Set on strikes and solved modules
+	ModuleIndex   *int            `json:"moduleIndex,omitempty"`
+	ModuleID      string          `json:"moduleId,omitempty"` // Set on strikes and solved modules
+	Payload       json.RawMessage `json:"payload,omitempty"`  // Action data as sent by the player
+	Result        string          `json:"result,omitempty"`   // ok, strike or solved for actions, the outcome for gameOver
+}
+
+// PlayerAction describes a player's action, as logged in the replay
+type PlayerAction struct {
+	PlayerID    string
+	Action      string // WebSocket message type
+	ModuleType  string // Type of the module acted on, ModuleIndex counts among the modules of that type
+	ModuleIndex int
+	Payload     json.RawMessage
+	RequestID   string // Client-generated ID of the message, empty if none was sent
+}
+
+// BombReplay is the event log of one bomb of a finished game
+type BombReplay struct {
+	BombIndex     int           `json:"bombIndex"`
+	Seed          int64         `json:"seed"` // With the serial number and edgework, rebuilds the exact bomb
+	SerialNumber  string        `json:"serialNumber"`
+	Difficulty    Difficulty    `json:"difficulty"`
+	ModuleCount   int           `json:"moduleCount"`
+	Outcome       BombState     `json:"outcome"`
+	Events        []ReplayEvent `json:"events"`
+	DroppedEvents int           `json:"droppedEvents"` // Events past MaxReplayEvents
+}
+
+// Replay is the event log of the session's last finished game
+type Replay struct {
+	SessionID  string       `json:"sessionId"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Bombs      []BombReplay `json:"bombs"`
+}
+
+// logEvent appends an event to the bomb's log, stamped with the current time
+func (b *Bomb) logEvent(event ReplayEvent) {
+	if len(b.events) >= MaxReplayEvents {
+		b.droppedEvents++
+		return
+	}
+	event.At = time.Now()
+	event.TimeRemaining = b.TimeRemaining
+	b.events = append(b.events, event)
+}
+
+// logGameOverIfEnded logs the end of the bomb once, the first time it is seen not active
+func (b *Bomb) logGameOverIfEnded() {
+	if b.State == BombStateActive || b.endLogged {
+		return
+	}
+	b.endLogged = true
+	b.logEvent(ReplayEvent{Type: ReplayEventGameOver, Result: string(b.State)})
+}
+
+// solvedModules returns which modules are solved, in bomb order
+func (b *Bomb) solvedModules() []bool {
+	solved := make([]bool, len(b.Modules))
+	for i, module := range b.Modules {
+		solved[i] = module.Solved()
+	}
+	return solved
+}
+
+// replay returns the bomb's event log
+func (b *Bomb) replay(bombIndex int) BombReplay {
+	return BombReplay{
+		BombIndex:     bombIndex,
+		Seed:          b.Seed,
+		SerialNumber:  b.SerialNumber,
+		Difficulty:    b.Difficulty,
+		ModuleCount:   len(b.Modules),
+		Outcome:       b.State,
+		Events:        append([]ReplayEvent{}, b.events...),
+		DroppedEvents: b.droppedEvents,
+	}
+}
+
+// DoPlayerAction runs a player's action against the bomb like DoBombAction, and logs
+// the action, its result and the strikes, solved modules and game over it caused
+func (gs *GameSession) DoPlayerAction(player PlayerAction, action func(bomb *Bomb)) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	bomb := gs.currentBombLocked()
+	if gs.LobbyState != LobbyStateActive || bomb == nil {
+		return ErrGameNotActive
+	}
+	if bomb.Paused {
+		return ErrGamePaused
+	}
+
+	strikesBefore := bomb.Strikes
+	solvedBefore := bomb.solvedModules()
+	eventIndex := len(bomb.events)
+
+	event := ReplayEvent{
+		Type:     ReplayEventAction,
+		PlayerID: player.PlayerID,
+		Action:   player.Action,
+		Result:   "ok",
+	}
+	if p, exists := gs.Players[player.PlayerID]; exists {
+		event.PlayerName = p.Name
+	}
+	moduleIndex := player.ModuleIndex
+	event.ModuleIndex = &moduleIndex
+	if len(player.Payload) <= maxReplayPayloadSize {
+		event.Payload = player.Payload
+	}
+	bomb.logEvent(event)
+
+	now := time.Now()
+	if moduleID := bomb.ModuleIDOf(player.ModuleType, player.ModuleIndex); moduleID != "" {
+		bomb.recordInteraction(moduleID, now)
+	}
+
+	action(bomb)
+	if player.RequestID != "" {
+		gs.markActionRequestAppliedLocked(player.PlayerID, player.RequestID)
+	}
+
+	// Strikes log themselves; fill in the action's result and log what got solved
+	solvedAny := false
+	typeIndex := make(map[string]int)
+	// Solvable modules come first in the module list, so i is also the module's position
+	for i, module := range bomb.Modules {
+		index := typeIndex[module.Type()]
+		typeIndex[module.Type()]++
+		if solvedBefore[i] || !module.Solved() {
+			continue
+		}
+		solvedAny = true
+		bomb.recordSolve(module.ModuleID(), player.PlayerID, event.PlayerName, now)
+		bomb.logEvent(ReplayEvent{
+			Type:        ReplayEventModuleSolved,
+			PlayerID:    player.PlayerID,
+			ModuleType:  module.Type(),
+			ModuleIndex: &index,
+			ModuleID:    module.ModuleID(),
+		})
+		bomb.announce(BombEvent{
+			Type:        ReplayEventModuleSolved,
+			ModuleType:  module.Type(),
+			ModuleIndex: index,
+			ModuleID:    module.ModuleID(),
+			Position:    i,
+			PlayerID:    player.PlayerID,
+		})
+	}
+	if eventIndex < len(bomb.events) {
+		if bomb.Strikes > strikesBefore {
+			bomb.events[eventIndex].Result = "strike"
+		} else if solvedAny {
+			bomb.events[eventIndex].Result = "solved"
+		}
+	}
+	bomb.logGameOverIfEnded()
+	return nil
+}
+
+// GetReplay returns the event log of the last finished game, or nil if no game finished yet
+func (gs *GameSession) GetReplay() *Replay {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.lastReplay
+}
+
+// buildReplayLocked snapshots the event logs of the bombs played (caller must hold gs.mu)
+func (gs *GameSession) buildReplayLocked(finishedAt time.Time) *Replay {
+	replay := &Replay{SessionID: gs.ID, FinishedAt: finishedAt}
+	for i, bomb := range gs.Bombs[:gs.CurrentBombIndex+1] {
+		replay.Bombs = append(replay.Bombs, bomb.replay(i))
+	}
+	return replay
+}
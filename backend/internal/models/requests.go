@@ -0,0 +1,121 @@
+package models
+
+import "time"
+
+const (
+	// ActionRequestWindow is how long a request ID is remembered, retries within it are not applied twice
+	ActionRequestWindow = 30 * time.Second
+	// maxActionRequests bounds the remembered request IDs of a session
+	maxActionRequests = 1000
+	// reliableSendTimeout is how long a result message may wait for room in a full send channel
+	reliableSendTimeout = time.Second
+)
+
+// actionRequest is a player action remembered by its client-generated request ID
+type actionRequest struct {
+	at      time.Time
+	applied bool   // The action reached the bomb
+	result  []byte // Result message sent for it, nil until answered
+}
+
+// actionRequestKey identifies a request ID among the session's players
+func actionRequestKey(playerID, requestID string) string {
+	return playerID + "/" + requestID
+}
+
+// BeginActionRequest remembers a player's request ID before its action is handled
+// If the ID was seen within ActionRequestWindow it reports a duplicate, with the result
+// sent the first time (nil while the first attempt is still being handled)
+func (gs *GameSession) BeginActionRequest(playerID, requestID string) ([]byte, bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	for key, request := range gs.actionRequests {
+		if now.Sub(request.at) > ActionRequestWindow {
+			delete(gs.actionRequests, key)
+		}
+	}
+
+	key := actionRequestKey(playerID, requestID)
+	if request, exists := gs.actionRequests[key]; exists {
+		return request.result, true
+	}
+	if gs.actionRequests == nil {
+		gs.actionRequests = make(map[string]*actionRequest)
+	}
+	if len(gs.actionRequests) < maxActionRequests {
+		gs.actionRequests[key] = &actionRequest{at: now}
+	}
+	return nil, false
+}
+
+// markActionRequestAppliedLocked records that a request's action reached the bomb
+func (gs *GameSession) markActionRequestAppliedLocked(playerID, requestID string) {
+	if request, exists := gs.actionRequests[actionRequestKey(playerID, requestID)]; exists {
+		request.applied = true
+	}
+}
+
+// FinishActionRequest records the result message sent for a request, so retries get it again
+func (gs *GameSession) FinishActionRequest(playerID, requestID string, result []byte) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if request, exists := gs.actionRequests[actionRequestKey(playerID, requestID)]; exists {
+		request.result = result
+	}
+}
+
+// ActionRequestOutcome reports whether a request's action reached the bomb and whether it was answered
+// known is false for request IDs that are not (or no longer) remembered
+func (gs *GameSession) ActionRequestOutcome(playerID, requestID string) (applied bool, answered bool, known bool) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	request, exists := gs.actionRequests[actionRequestKey(playerID, requestID)]
+	if !exists {
+		return false, false, false
+	}
+	return request.applied, request.result != nil, true
+}
+
+// ForgetActionRequest drops a request ID so a retry is handled again, e.g. after the game was paused
+func (gs *GameSession) ForgetActionRequest(playerID, requestID string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	delete(gs.actionRequests, actionRequestKey(playerID, requestID))
+}
+
+// SendReliable queues a message that must not be lost, waiting briefly if the send channel is full
+// A connection that can't take it in time is closed, the client resyncs when it reconnects
+func (c *Connection) SendReliable(message []byte) bool {
+	if c.trySendQuiet(message) {
+		c.recordSent()
+		return true
+	}
+
+	timer := time.NewTimer(reliableSendTimeout)
+	defer timer.Stop()
+	select {
+	case c.Send <- message:
+		c.recordSent()
+		return true
+	case <-c.closed:
+		return false
+	case <-timer.C:
+		c.recordDrop()
+		c.closeSlow()
+		return false
+	}
+}
+
+// trySendQuiet queues a message without blocking, like TrySend but without counting a drop
+func (c *Connection) trySendQuiet(message []byte) bool {
+	select {
+	case c.Send <- message:
+		return true
+	default:
+		return false
+	}
+}
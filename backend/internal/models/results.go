@@ -0,0 +1,191 @@
+package models
+
+import (
+	"bombs/internal/metrics"
+	"sort"
+	"time"
+)
+
+const (
+	// MaxGameResults is the number of finished games a session keeps the full result of
+	MaxGameResults = 20
+	// MaxGameHistory bounds the per-session history so a session left open for days stays small
+	MaxGameHistory = 500
+)
+
+// GameResult is the outcome of a finished game (every bomb of the mission)
+// It is kept on the session after the bombs are cleared by ReturnToLobby
+type GameResult struct {
+	Outcome         BombState       `json:"outcome"` // State of the last bomb played: defused or exploded
+	Reason          BombEndReason   `json:"reason"`
+	DurationSeconds int             `json:"durationSeconds"` // Wall-clock time from start to finish
+	TimeRemaining   int             `json:"timeRemaining"`   // Seconds left on the last bomb played
+	Strikes         int             `json:"strikes"`         // Strikes over every bomb played
+	ModuleCounts    map[string]int  `json:"moduleCounts"`    // Solvable modules per type over every bomb played
+	SolvedModules   int             `json:"solvedModules"`
+	TotalModules    int             `json:"totalModules"`
+	BombsPlayed     int             `json:"bombsPlayed"`
+	BombCount       int             `json:"bombCount"`
+	DefuserID       string          `json:"defuserId"`
+	DefuserName     string          `json:"defuserName"`
+	Modules         []ModuleOutcome `json:"modules"`  // Outcome and stats of every solvable module over every bomb played
+	Seed            int64           `json:"seed"`     // Rule seed of the last bomb played
+	Practice        bool            `json:"practice"` // Practice games shouldn't count in stats
+	StartedAt       time.Time       `json:"startedAt"`
+	FinishedAt      time.Time       `json:"finishedAt"`
+}
+
+// GameHistoryEntry is the short summary of a finished game kept for the whole session
+type GameHistoryEntry struct {
+	Outcome         BombState `json:"outcome"`
+	DurationSeconds int       `json:"durationSeconds"`
+	TimeRemaining   int       `json:"timeRemaining"`
+	Strikes         int       `json:"strikes"`
+	DefuserID       string    `json:"defuserId"`
+	DefuserName     string    `json:"defuserName"` // Kept so the entry still reads well once the defuser left
+	Practice        bool      `json:"practice"`
+	FinishedAt      time.Time `json:"finishedAt"`
+}
+
+// Defusal describes a bomb defused during the last game, as recorded on the leaderboard
+type Defusal struct {
+	ModuleCount int
+	Difficulty  Difficulty
+	Seconds     int // Bomb time used, strike penalties included
+	Strikes     int
+	Players     []string // Names of the players in the session
+	Practice    bool
+}
+
+// RecordGameResult stores the result of the game once it is over
+// It also adds the game to the session history
+// Returns nil if the game is still running, was abandoned, or was already recorded
+func (gs *GameSession) RecordGameResult() *GameResult {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	bomb := gs.currentBombLocked()
+	if bomb == nil || gs.resultRecorded {
+		return nil
+	}
+	if bomb.State == BombStateActive || (bomb.State == BombStateDefused && gs.CurrentBombIndex < len(gs.Bombs)-1) {
+		return nil
+	}
+
+	now := time.Now()
+	result := &GameResult{
+		Outcome:         bomb.State,
+		Reason:          bomb.EndReason,
+		DurationSeconds: int(now.Sub(gs.gameStartedAt).Seconds()),
+		TimeRemaining:   bomb.TimeRemaining,
+		ModuleCounts:    make(map[string]int),
+		BombsPlayed:     gs.CurrentBombIndex + 1,
+		BombCount:       len(gs.Bombs),
+		Seed:            bomb.Seed,
+		Practice:        bomb.Practice,
+		StartedAt:       gs.gameStartedAt,
+		FinishedAt:      now,
+	}
+	for i, played := range gs.Bombs[:gs.CurrentBombIndex+1] {
+		result.Strikes += played.Strikes
+		result.Modules = append(result.Modules, played.ModuleOutcomes(i)...)
+		for _, module := range played.Modules {
+			result.ModuleCounts[module.Type()]++
+			result.TotalModules++
+			if module.Solved() {
+				result.SolvedModules++
+			}
+		}
+	}
+	for id, player := range gs.Players {
+		if player.Type == PlayerTypeDefuser {
+			result.DefuserID = id
+			result.DefuserName = player.Name
+			break
+		}
+	}
+
+	gs.Results = append(gs.Results, result)
+	if len(gs.Results) > MaxGameResults {
+		gs.Results = gs.Results[len(gs.Results)-MaxGameResults:]
+	}
+
+	gs.History = append(gs.History, GameHistoryEntry{
+		Outcome:         result.Outcome,
+		DurationSeconds: result.DurationSeconds,
+		TimeRemaining:   result.TimeRemaining,
+		Strikes:         result.Strikes,
+		DefuserID:       result.DefuserID,
+		DefuserName:     result.DefuserName,
+		Practice:        result.Practice,
+		FinishedAt:      result.FinishedAt,
+	})
+	if len(gs.History) > MaxGameHistory {
+		gs.History = gs.History[len(gs.History)-MaxGameHistory:]
+	}
+
+	bomb.logGameOverIfEnded()
+	gs.lastReplay = gs.buildReplayLocked(now)
+
+	if result.Outcome == BombStateDefused {
+		metrics.GamesDefused.Inc()
+	} else {
+		metrics.GamesExploded.Inc()
+	}
+
+	gs.resultRecorded = true
+	return result
+}
+
+// GetHistory returns every finished game of the session, oldest first, in a thread-safe way
+func (gs *GameSession) GetHistory() []GameHistoryEntry {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return append([]GameHistoryEntry{}, gs.History...)
+}
+
+// GetLastGame returns the most recent finished game, or nil if none finished yet
+func (gs *GameSession) GetLastGame() *GameHistoryEntry {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	if len(gs.History) == 0 {
+		return nil
+	}
+	last := gs.History[len(gs.History)-1]
+	return &last
+}
+
+// GetResults returns the results of the last finished games, oldest first, in a thread-safe way
+func (gs *GameSession) GetResults() []*GameResult {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return append([]*GameResult{}, gs.Results...)
+}
+
+// GetDefusals returns the bombs defused during the current or last game
+func (gs *GameSession) GetDefusals() []Defusal {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	players := make([]string, 0, len(gs.Players))
+	for _, player := range gs.Players {
+		players = append(players, player.Name)
+	}
+	sort.Strings(players)
+
+	defusals := []Defusal{}
+	for _, bomb := range gs.Bombs {
+		if bomb.State != BombStateDefused {
+			continue
+		}
+		defusals = append(defusals, Defusal{
+			ModuleCount: bomb.SolvableModuleCount(),
+			Difficulty:  bomb.Difficulty,
+			Seconds:     bomb.TimeLimit - bomb.TimeRemaining,
+			Strikes:     bomb.Strikes,
+			Players:     players,
+			Practice:    bomb.Practice,
+		})
+	}
+	return defusals
+}
@@ -0,0 +1,195 @@
+package models
+
+import "fmt"
+
+// Condition types of structured manual rules, with the params they take
+const (
+	ConditionNoWires            = "noWires"           // color: there is no wire of this color
+	ConditionMoreThanOneWire    = "moreThanOneWire"   // color: there are at least two wires of this color
+	ConditionFirstWireIs        = "firstWireIs"       // color
+	ConditionLastWireIs         = "lastWireIs"        // color
+	ConditionAnyStripe          = "anyStripe"         // color: a wire has a stripe of this color
+	ConditionNoStripes          = "noStripes"         // No wire is striped
+	ConditionWireCount          = "wireCount"         // count: the module has this many wires
+	ConditionSerialOdd          = "serialOdd"         // The serial number ends with an odd digit
+	ConditionSerialEven         = "serialEven"        // The serial number ends with an even digit
+	ConditionSerialVowel        = "serialVowel"       // The serial number contains a vowel
+	ConditionBatteriesMoreThan  = "batteriesMoreThan" // count
+	ConditionNoBatteries        = "noBatteries"
+	ConditionLitIndicator       = "litIndicator"       // label
+	ConditionButton             = "button"             // text, and color unless any color matches
+	ConditionButtonColor        = "buttonColor"        // color: the button is this color, whatever its text
+	ConditionButtonTextLonger   = "buttonTextLonger"   // count: the button's text has more letters than this
+	ConditionButtonSharesLetter = "buttonSharesLetter" // The button's text and the name of its color share a letter
+	ConditionGaugeColor         = "gaugeColor"         // color: the gauge shown while the button is held
+	ConditionAll                = "all"                // Every one of Conditions matches
+)
+
+// Action types of structured manual rules, with the params they take
+const (
+	ActionCutWire         = "cutWire"             // wire: position from the first wire, starting at 1
+	ActionCutLastWire     = "cutLastWire"         // Cut the last wire, whatever the wire count
+	ActionCutFirstStriped = "cutFirstStripedWire" // Cut the first wire that has a stripe
+	ActionPressButton     = "pressButton"         // Press and release immediately
+	ActionHoldButton      = "holdButton"          // Hold until the gauge says when to release
+	ActionReleaseOnDigit  = "releaseOnDigit"      // digit: release when the timer's last digit is this one
+)
+
+// RuleCondition is the structured form of what a manual rule checks
+// Clients can render, translate or search rules from it instead of reading the text
+type RuleCondition struct {
+	Type       string                 `json:"type"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	Conditions []*RuleCondition       `json:"conditions,omitempty"` // Parts of an "all" condition
+}
+
+// RuleAction is the structured form of what a manual rule tells the defuser to do
+type RuleAction struct {
+	Type   string                 `json:"type"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// ruleCondition creates a condition, params being name/value pairs
+func ruleCondition(conditionType string, params ...interface{}) *RuleCondition {
+	return &RuleCondition{Type: conditionType, Params: pairs(params)}
+}
+
+// allOf creates a condition matching when every one of conditions does
+func allOf(conditions ...*RuleCondition) *RuleCondition {
+	return &RuleCondition{Type: ConditionAll, Conditions: conditions}
+}
+
+// ruleAction creates an action, params being name/value pairs
+func ruleAction(actionType string, params ...interface{}) *RuleAction {
+	return &RuleAction{Type: actionType, Params: pairs(params)}
+}
+
+// pairs turns name/value pairs into params
+func pairs(params []interface{}) map[string]interface{} {
+	if len(params) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(params)/2)
+	for i := 0; i+1 < len(params); i += 2 {
+		values[params[i].(string)] = params[i+1]
+	}
+	return values
+}
+
+// param returns a param as text, as messages take it
+func param(params map[string]interface{}, name string) string {
+	return fmt.Sprint(params[name])
+}
+
+// cutWireAction is the action cutting the wire at a 0-based index, naming it as the manual reads it
+func cutWireAction(index int, numWires int) *RuleAction {
+	if index == numWires-1 {
+		return ruleAction(ActionCutLastWire)
+	}
+	return ruleAction(ActionCutWire, "wire", index+1)
+}
+
+// Message returns the condition's text
+func (c *RuleCondition) Message() *Message {
+	switch c.Type {
+	case ConditionNoWires:
+		return msg("wires.condition.none", colorName(param(c.Params, "color")))
+	case ConditionMoreThanOneWire:
+		return msg("wires.condition.moreThanOne", colorName(param(c.Params, "color")))
+	case ConditionFirstWireIs:
+		return msg("wires.condition.firstIs", colorName(param(c.Params, "color")))
+	case ConditionLastWireIs:
+		return msg("wires.condition.lastIs", colorName(param(c.Params, "color")))
+	case ConditionAnyStripe:
+		return msg("wires.condition.anyStripe", colorName(param(c.Params, "color")))
+	case ConditionNoStripes:
+		return msg("wires.condition.noStripes")
+	case ConditionSerialOdd:
+		return msg("condition.serialOdd")
+	case ConditionSerialEven:
+		return msg("condition.serialEven")
+	case ConditionSerialVowel:
+		return msg("condition.serialVowel")
+	case ConditionBatteriesMoreThan:
+		return msg("condition.batteriesMoreThan", c.Params["count"])
+	case ConditionNoBatteries:
+		return msg("condition.noBatteries")
+	case ConditionLitIndicator:
+		return msg("condition.litIndicator", c.Params["label"])
+	case ConditionButton:
+		if _, ok := c.Params["color"]; !ok {
+			return msg("button.condition.textAnyColor", c.Params["text"])
+		}
+		return msg("button.condition.textColor", c.Params["text"], colorName(param(c.Params, "color")))
+	case ConditionButtonColor:
+		return msg("button.condition.color", colorName(param(c.Params, "color")))
+	case ConditionButtonTextLonger:
+		return msg("button.condition.textLonger", c.Params["count"])
+	case ConditionButtonSharesLetter:
+		return msg("button.condition.sharesLetter")
+	case ConditionAll:
+		var text *Message
+		for _, part := range c.Conditions {
+			if text == nil {
+				text = part.Message()
+				continue
+			}
+			text = msg("condition.and", text, part.Message())
+		}
+		return text
+	}
+	return msg(c.Type)
+}
+
+// wirePosition names the wire a cut action is about
+func (a *RuleAction) wirePosition() *Message {
+	if a.Type == ActionCutLastWire {
+		return msg("position.last")
+	}
+	index, _ := a.Params["wire"].(int)
+	return ordinalPosition(index - 1)
+}
+
+// wireRuleText renders a wires rule from its structure
+// A rule without condition is the default rule of its set
+func wireRuleText(condition *RuleCondition, action *RuleAction) *Message {
+	switch {
+	case condition == nil:
+		return msg("wires.rule.otherwise", action.wirePosition())
+	case condition.Type == ConditionWireCount:
+		return msg("wires.rule.otherwiseFor", condition.Params["count"], action.wirePosition())
+	case action.Type == ActionCutFirstStriped:
+		return msg("wires.rule", condition.Message(), msg("wires.action.cutFirstStriped"))
+	}
+	return msg("wires.rule", condition.Message(), msg("wires.action.cut", action.wirePosition()))
+}
+
+// buttonRuleText renders a button rule from its structure
+// A rule without condition is the default rule
+func buttonRuleText(condition *RuleCondition, action *RuleAction) *Message {
+	switch {
+	case condition == nil:
+		return msg("button.rule.otherwise")
+	case action.Type == ActionReleaseOnDigit:
+		return msg("button.rule.gauge", colorName(param(condition.Params, "color")), action.Params["digit"])
+	case action.Type == ActionPressButton:
+		return msg("button.rule.press", condition.Message())
+	}
+	return msg("button.rule.hold", condition.Message())
+}
+
+// structuredRule creates a manual rule carrying its structure along with its text
+func structuredRule(number int, condition *RuleCondition, action *RuleAction, text *Message) ManualRule {
+	rule := manualRule(number, text)
+	rule.Condition = condition
+	rule.Action = action
+	return rule
+}
+
+// terminalManualRule creates the terminal rule mapping a terminal text to a command
+func terminalManualRule(number int, terminalText string, command string) ManualRule {
+	rule := manualRule(number, msg("terminal.rule", terminalText, command))
+	rule.TerminalText = terminalText
+	rule.Command = command
+	return rule
+}
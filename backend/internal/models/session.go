@@ -1,14 +1,28 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
-	
+	"unicode/utf8"
+
+	"bombs/internal/metrics"
 	"bombs/internal/utils"
 )
 
+var (
+	// ErrGameNotActive is returned for game actions while no game is being played
+	ErrGameNotActive = errors.New("game is not active")
+	// ErrGamePaused is returned for module actions while the game is paused
+	ErrGamePaused = errors.New("game is paused")
+	// ErrNotInLobby is returned for lobby changes while a game is starting or running
+	ErrNotInLobby = errors.New("only possible in the lobby")
+)
+
 // PlayerType represents the type of player
 type PlayerType string
 
@@ -26,86 +40,418 @@ const (
 	LobbyStateActive   LobbyState = "active"   // Game is active
 )
 
+// StartCountdown is how long players get between the host starting the game and the bomb timer starting
+const StartCountdown = 5 * time.Second
+
+const (
+	// MinTimeLimit and MaxTimeLimit bound the bomb time limit in seconds
+	MinTimeLimit = 60
+	MaxTimeLimit = 3600
+)
+
+// MaxPlayerNameLength is the maximum length of a player display name
+const MaxPlayerNameLength = 20
+
+const (
+	// MaxChatMessageLength is the maximum length of a chat message
+	MaxChatMessageLength = 500
+	// chatRateLimit is how many chat messages a player may send per chatRateWindow
+	chatRateLimit  = 5
+	chatRateWindow = time.Second
+)
+
+// NormalizePlayerName trims a display name and checks it is 1-20 characters long
+func NormalizePlayerName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("player name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > MaxPlayerNameLength {
+		return "", fmt.Errorf("player name must be at most %d characters", MaxPlayerNameLength)
+	}
+	return name, nil
+}
+
 // Player represents a connected player
 type Player struct {
-	ID       string    `json:"id"`
-	Name     string    `json:"name"`     // Display name (defaults to ID if not set)
-	Type     PlayerType `json:"type"`
-	Conn     *Connection `json:"-"`
-	JoinedAt time.Time `json:"joinedAt"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"` // Display name (defaults to ID if not set)
+	Type           PlayerType     `json:"type"`
+	Conn           *Connection    `json:"-"`
+	JoinedAt       time.Time      `json:"joinedAt"`
+	Ready          bool           `json:"ready"`          // Player confirmed they are ready to start
+	RolePreference RolePreference `json:"rolePreference"` // Role the player would like in random picks, kept between games
+	Disconnected   bool           `json:"disconnected"`   // Socket closed, the seat is kept until the reconnect grace period ends
+	Language       string         `json:"language"`       // Language the player reads the manual in
+	chatSentAt     []time.Time    // Send times of the player's recent chat messages, for rate limiting
 }
 
 // Connection wraps a WebSocket connection with a mutex for thread safety
 type Connection struct {
-	Send chan []byte
-	mu   sync.Mutex
+	Send             chan []byte
+	closed           chan struct{} // Closed when the connection is replaced or shut down
+	closeOnce        sync.Once
+	rtt              time.Duration // Smoothed round-trip time, zero until measured
+	droppedMessages  int           // Messages dropped because the send channel was full
+	consecutiveDrops int           // Messages dropped since the last one that got through
+	slow             bool          // Closed for not keeping up, see closeSlow
+	protocol         int           // Message format version negotiated with the client
+	actions          tokenBucket   // Rate limit of module actions, see AllowAction
+	syncs            tokenBucket   // Rate limit of pings and state requests, see AllowSync
+	mu               sync.Mutex
+}
+
+// NewConnection creates a connection wrapper with a buffered send channel
+func NewConnection() *Connection {
+	return &Connection{
+		Send:   make(chan []byte, 256),
+		closed: make(chan struct{}),
+	}
+}
+
+// SetProtocolVersion records the message format version negotiated with the client
+func (c *Connection) SetProtocolVersion(version int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.protocol = version
+}
+
+// ProtocolVersion returns the message format version negotiated with the client
+func (c *Connection) ProtocolVersion() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.protocol
+}
+
+// Close signals the connection's write pump to close the socket
+// Safe to call multiple times
+func (c *Connection) Close() {
+	c.closeOnce.Do(func() {
+		if c.closed != nil {
+			close(c.closed)
+		}
+	})
+}
+
+// TrySend queues a message without blocking
+// The message is dropped (and counted as dropped) if the send channel is full; after
+// MaxConsecutiveDrops drops in a row the connection is closed as too slow
+func (c *Connection) TrySend(message []byte) bool {
+	if c.trySendQuiet(message) {
+		c.recordSent()
+		return true
+	}
+	c.recordDrop()
+	return false
+}
+
+// Closed returns a channel that is closed once Close has been called
+func (c *Connection) Closed() <-chan struct{} {
+	return c.closed
 }
 
 // GameSession manages a multiplayer game session
 type GameSession struct {
-	ID              string             `json:"id"`
-	Bomb            *Bomb              `json:"bomb,omitempty"` // Only set when game is active
-	Players         map[string]*Player `json:"players"`
-	LobbyState      LobbyState         `json:"lobbyState"`
-	HostID          string             `json:"hostId"`
-	ModuleCount     int                `json:"moduleCount"`     // 1-6, default 6
-	DefuserID       string             `json:"defuserId"`       // Empty if random
-	IsRandomDefuser bool               `json:"isRandomDefuser"` // True if defuser should be random
-	TimeLimit       int                `json:"timeLimit"`      // Time limit in seconds
-	broadcastFunc   func([]byte)       // Function to broadcast messages
-	broadcastActive bool               // Track if broadcast loop is running
-	mu              sync.RWMutex
+	ID                       string                    `json:"id"`
+	Bombs                    []*Bomb                   `json:"bombs,omitempty"`         // Bombs of the mission, only set when game is active
+	CurrentBombIndex         int                       `json:"currentBombIndex"`        // Index of the bomb being played in Bombs
+	Mission                  []MissionBomb             `json:"mission"`                 // Bombs played back-to-back, empty for a single bomb
+	CarryStrikes             bool                      `json:"carryStrikes"`            // Strikes carry over from one mission bomb to the next
+	AutopauseOnDefuserDrop   bool                      `json:"autopauseOnDefuserDrop"`  // Pause the bomb while its last defuser is disconnected
+	ExpertSeesModuleDetails  bool                      `json:"expertSeesModuleDetails"` // Experts are shown the casing and the modules, not only their status
+	ReleaseWindow            time.Duration             `json:"-"`                       // How far on each side of a button release the target digit is accepted
+	drops                    map[string]*PlayerDrop    // Players whose connection dropped during the current game, by ID
+	focus                    map[string]*DefuserFocus  // Module each defuser has open, by player ID
+	autopausedBy             string                    // Defuser whose drop paused the bomb, empty otherwise
+	WaitForReconnecting      bool                      `json:"waitForReconnecting"` // Starting waits for disconnected players instead of leaving them out
+	graceTimers              map[string]*graceTimer    // Pending removals of disconnected players, by ID
+	reconnectTokens          map[string]string         // Secret each player reconnects with, by ID, see IssueReconnectToken
+	bannedIDs                map[string]bool           // IDs of kicked players, refused from then on
+	bannedTokens             map[string]bool           // Reconnect tokens of kicked players, refused from then on
+	nextBombAt               time.Time                 // When the next mission bomb starts, zero unless counting down
+	Players                  map[string]*Player        `json:"players"`
+	LobbyState               LobbyState                `json:"lobbyState"`
+	HostID                   string                    `json:"hostId"`
+	ModuleCount              int                       `json:"moduleCount"`     // 3-12, default 6
+	DefuserID                string                    `json:"defuserId"`       // Empty if random
+	IsRandomDefuser          bool                      `json:"isRandomDefuser"` // True if defuser should be random
+	DefuserCount             int                       `json:"defuserCount"`    // Players defusing the bomb together (1-3)
+	DefuserIDs               []string                  `json:"defuserIds"`      // Defusers chosen by the host, empty to use DefuserID
+	RotateDefuser            bool                      `json:"rotateDefuser"`   // Random defuser picks rotate between rounds
+	RotationMode             RotationMode              `json:"rotationMode"`    // How rotating picks work
+	DefuserHistory           [][]string                `json:"defuserHistory"`  // Defusers of every round started, oldest first
+	upNext                   []string                  // Defusers of the next game, drawn ahead so they can prepare
+	TimeLimit                int                       `json:"timeLimit"`                // Time limit in seconds
+	RequireReady             bool                      `json:"requireReady"`             // Start requires all non-host players to be ready
+	MaxStrikes               int                       `json:"maxStrikes"`               // Strikes before the bomb explodes (1-10)
+	StrikeTimePenalty        int                       `json:"strikeTimePenalty"`        // Seconds taken off the timer per strike (0 disables)
+	TimerAcceleration        bool                      `json:"timerAcceleration"`        // Strikes make the timer tick faster
+	EnableNeedyModules       bool                      `json:"enableNeedyModules"`       // Add needy modules to the bomb
+	StripedWires             bool                      `json:"stripedWires"`             // Wires can have a stripe of a second color, with rules about stripes
+	ButtonCyclingGauge       bool                      `json:"buttonCyclingGauge"`       // The gauge of a held button cycles through several colors
+	TerminalHardMode         bool                      `json:"terminalHardMode"`         // A wrong terminal command restarts the sequence with new texts
+	TerminalSequenceLength   int                       `json:"terminalSequenceLength"`   // Commands that solve a terminal (2-6)
+	TerminalStrictness       TerminalStrictness        `json:"terminalStrictness"`       // How closely terminal commands have to match
+	TerminalLockoutThreshold int                       `json:"terminalLockoutThreshold"` // Wrong commands that lock a terminal out (0 disables the lockout)
+	TerminalLockoutSeconds   int                       `json:"terminalLockoutSeconds"`   // How long a terminal lockout lasts
+	ModuleTypes              []string                  `json:"moduleTypes"`              // Module types bombs can use, empty for all of them
+	ModuleMix                map[string]int            `json:"moduleMix"`                // Modules per type chosen by the host, empty for a random split
+	Difficulty               Difficulty                `json:"difficulty"`               // Preset tuning the rules, time and strikes
+	PracticeMode             bool                      `json:"practiceMode"`             // A single player can start, results are marked as practice
+	WebhookURL               string                    `json:"-"`                        // Called when a game ends, overrides the server's default (kept from players, it embeds a secret)
+	PasswordHash             string                    `json:"-"`                        // Salted hash of the join password, empty for a public lobby
+	Results                  []*GameResult             `json:"results"`                  // Last finished games, oldest first (at most MaxGameResults)
+	History                  []GameHistoryEntry        `json:"history"`                  // Every finished game of the session, oldest first
+	gameStartsAt             time.Time                 // When the start countdown ends, zero unless starting
+	gameStartedAt            time.Time                 // When the current game started
+	resultRecorded           bool                      // Whether the current game's result is already in Results
+	lastReplay               *Replay                   // Event log of the last finished game
+	CreatedAt                time.Time                 `json:"createdAt"`
+	LastActivity             time.Time                 `json:"lastActivity"` // Last time a player or the host interacted with the session
+	EmptySince               time.Time                 `json:"-"`            // When the last player left, zero while players are connected
+	broadcastFunc            func([]byte)              // Function to broadcast messages
+	broadcastActive          bool                      // Track if broadcast loop is running
+	broadcastStop            chan struct{}             // Closed to stop the running broadcast loop, nil when none runs
+	done                     chan struct{}             // Closed when the session is deleted
+	rng                      *rand.Rand                // Picks bomb seeds and random defusers, guarded by mu
+	actionRequests           map[string]*actionRequest // Recent action request IDs by player, see BeginActionRequest
+	closeOnce                sync.Once
+	deltaMu                  sync.Mutex                 // Keeps state deltas in version order while they are sent
+	lastStamp                stateStamp                 // Bomb state the last delta was emitted for, guarded by mu
+	manualCache              map[string]json.RawMessage // Serialized manual content by language, shared by every expert
+	manualStamp              stateStamp                 // Bomb state manualCache was built for
+	mu                       sync.RWMutex
 }
 
 // NewGameSession creates a new game session in lobby state
-func NewGameSession(id string, hostID string, timeLimit int) *GameSession {
+// rng is owned by the session from then on; the same seed gives the same bombs and defuser picks
+func NewGameSession(id string, hostID string, timeLimit int, rng *rand.Rand) *GameSession {
+	now := time.Now()
 	return &GameSession{
-		ID:              id,
-		Bomb:            nil, // Bomb created when game starts
-		Players:         make(map[string]*Player),
-		LobbyState:      LobbyStateWaiting,
-		HostID:          hostID,
-		ModuleCount:     6, // Default 6 modules
-		DefuserID:       hostID, // Default defuser is the host
-		IsRandomDefuser: false, // Default to host as defuser
-		TimeLimit:       timeLimit,
+		ID:                      id,
+		Bombs:                   nil, // Bombs created when game starts
+		Players:                 make(map[string]*Player),
+		LobbyState:              LobbyStateWaiting,
+		HostID:                  hostID,
+		ModuleCount:             DefaultModuleCount,
+		Difficulty:              DifficultyNormal,
+		DefuserID:               hostID, // Default defuser is the host
+		IsRandomDefuser:         false,  // Default to host as defuser
+		DefuserCount:            MinDefuserCount,
+		RotationMode:            RotationAvoidRepeat,
+		TimeLimit:               timeLimit,
+		MaxStrikes:              DefaultMaxStrikes,
+		ExpertSeesModuleDetails: true, // Current clients draw the bomb on the expert side too
+		ReleaseWindow:           DefaultReleaseWindow,
+		TerminalSequenceLength:  DefaultTerminalSequenceLength,
+		TerminalStrictness:      TerminalStrictnessStrict,
+		TerminalLockoutSeconds:  DefaultTerminalLockoutSeconds,
+		CreatedAt:               now,
+		LastActivity:            now,
+		EmptySince:              now, // Nobody connected yet
+		done:                    make(chan struct{}),
+		rng:                     rng,
 	}
 }
 
+// Close shuts the session down once it is deleted: its broadcast loop stops
+// and every player connection is closed. Safe to call multiple times
+func (gs *GameSession) Close() {
+	gs.closeOnce.Do(func() {
+		close(gs.done)
+	})
+
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	for _, player := range gs.Players {
+		if player.Conn != nil {
+			player.Conn.Close()
+		}
+	}
+	// Nobody is waiting for the disconnected players anymore
+	for _, pending := range gs.graceTimers {
+		pending.timer.Stop()
+	}
+}
+
+// Done returns a channel that is closed once the session is closed
+func (gs *GameSession) Done() <-chan struct{} {
+	return gs.done
+}
+
+// Touch records activity on the session
+func (gs *GameSession) Touch() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.LastActivity = time.Now()
+}
+
+// GetActivityInfo returns the creation and last activity times in a thread-safe way
+func (gs *GameSession) GetActivityInfo() (time.Time, time.Time) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.CreatedAt, gs.LastActivity
+}
+
+// GetPlayerCount returns the number of players in the session
+func (gs *GameSession) GetPlayerCount() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return len(gs.Players)
+}
+
 // AddPlayer adds a player to the session
-func (gs *GameSession) AddPlayer(playerID string, playerType PlayerType, conn *Connection) {
+// name is optional - if empty or invalid, a random default name is generated
+// Players kicked from the session are refused with ErrPlayerBanned
+func (gs *GameSession) AddPlayer(playerID string, name string, playerType PlayerType, conn *Connection) error {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
-	// Generate a random default name (word + 2 digits)
-	defaultName, err := utils.GeneratePlayerName()
+
+	if gs.isBannedLocked(playerID, "") {
+		return ErrPlayerBanned
+	}
+
+	name, err := NormalizePlayerName(name)
 	if err != nil {
-		// Fallback to player ID if name generation fails
-		defaultName = playerID
+		// Generate a random default name (word + 2 digits)
+		name, err = utils.GeneratePlayerName()
+		if err != nil {
+			// Fallback to player ID if name generation fails
+			name = playerID
+		}
 	}
-	
+
 	gs.Players[playerID] = &Player{
-		ID:       playerID,
-		Name:     defaultName,
-		Type:     playerType,
-		Conn:     conn,
-		JoinedAt: time.Now(),
+		ID:             playerID,
+		Name:           name,
+		Type:           playerType,
+		Conn:           conn,
+		JoinedAt:       time.Now(),
+		RolePreference: RoleNoPreference,
+		Language:       DefaultLanguage,
+	}
+	gs.LastActivity = time.Now()
+	gs.EmptySince = time.Time{}
+	return nil
+}
+
+// ReattachPlayer moves an existing player onto a new connection, keeping their name and role
+// Returns the player's previous connection, or false if the player is not in the session
+func (gs *GameSession) ReattachPlayer(playerID string, conn *Connection) (*Connection, bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists {
+		return nil, false
+	}
+
+	// Replace the player entry rather than mutating it, so goroutines still
+	// holding the old entry keep writing to the old connection
+	reattached := *player
+	reattached.Conn = conn
+	reattached.Disconnected = false
+	gs.Players[playerID] = &reattached
+	gs.stopGraceTimerLocked(playerID)
+	gs.LastActivity = time.Now()
+
+	return player.Conn, true
+}
+
+// RemovePlayerConnection removes a player only if conn is still their current connection
+// Used when a socket closes, so a stale socket doesn't remove a player who reconnected
+// Returns whether the player was removed, the new host ID if the host role migrated,
+// and the drop to announce if a game is being played
+func (gs *GameSession) RemovePlayerConnection(playerID string, conn *Connection) (bool, string, *PlayerDrop) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists || player.Conn != conn {
+		return false, "", nil
 	}
+
+	newHostID := gs.removePlayerLocked(playerID)
+	return true, newHostID, gs.dropPlayerLocked(player)
+}
+
+// KickPlayer removes a player at the host's request
+// The host can't be kicked, and the defuser can't be kicked while a game is running
+// The player is banned: neither their ID nor their reconnect token can join again
+// Returns the removed player so the caller can notify and disconnect them
+func (gs *GameSession) KickPlayer(playerID string) (*Player, error) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists {
+		return nil, fmt.Errorf("player not found")
+	}
+
+	if playerID == gs.HostID {
+		return nil, fmt.Errorf("the host cannot be kicked")
+	}
+
+	if gs.LobbyState != LobbyStateWaiting && player.Type == PlayerTypeDefuser {
+		return nil, fmt.Errorf("the defuser cannot be kicked during a game")
+	}
+
+	delete(gs.Players, playerID)
+	gs.stopGraceTimerLocked(playerID)
+	gs.banPlayerLocked(playerID)
+	gs.LastActivity = time.Now()
+	return player, nil
 }
 
 // RemovePlayer removes a player from the session
-func (gs *GameSession) RemovePlayer(playerID string) {
+// Returns the new host ID if the host left and the role migrated to another player
+func (gs *GameSession) RemovePlayer(playerID string) string {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
+
+	return gs.removePlayerLocked(playerID)
+}
+
+// removePlayerLocked removes a player and migrates the host role if needed (caller must hold gs.mu)
+// When the host leaves, the longest-connected remaining player becomes host
+// Returns the new host ID, or an empty string if the host didn't change
+func (gs *GameSession) removePlayerLocked(playerID string) string {
 	delete(gs.Players, playerID)
+	gs.stopGraceTimerLocked(playerID)
+	gs.LastActivity = time.Now()
+
+	if len(gs.Players) == 0 {
+		// Nobody left, mark the session for cleanup
+		gs.EmptySince = gs.LastActivity
+		return ""
+	}
+
+	if playerID != gs.HostID {
+		return ""
+	}
+
+	var newHost *Player
+	for _, player := range gs.Players {
+		if newHost == nil || player.JoinedAt.Before(newHost.JoinedAt) {
+			newHost = player
+		}
+	}
+	gs.HostID = newHost.ID
+	return newHost.ID
+}
+
+// GetEmptySince returns when the last player left, or the zero time if players are connected
+func (gs *GameSession) GetEmptySince() time.Time {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.EmptySince
 }
 
 // GetPlayer returns a player by ID
 func (gs *GameSession) GetPlayer(playerID string) (*Player, bool) {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
-	
+
 	player, exists := gs.Players[playerID]
 	return player, exists
 }
@@ -114,12 +460,11 @@ func (gs *GameSession) GetPlayer(playerID string) (*Player, bool) {
 func (gs *GameSession) Broadcast(message []byte) {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
-	
+
 	for _, player := range gs.Players {
-		select {
-		case player.Conn.Send <- message:
-		default:
-			// Skip if channel is full
+		// Disconnected players have no connection until they come back
+		if player.Conn != nil {
+			player.Conn.TrySend(message)
 		}
 	}
 }
@@ -132,122 +477,490 @@ func (gs *GameSession) SetBroadcastFunc(fn func([]byte)) {
 }
 
 // StartBroadcast marks the broadcast loop as active
-func (gs *GameSession) StartBroadcast() bool {
+// Returns false if a loop already runs. Otherwise the caller owns the new loop: the
+// returned channel is closed when the game returns to lobby, and the loop must call
+// FinishBroadcast with it when it exits
+func (gs *GameSession) StartBroadcast() (<-chan struct{}, bool) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 	if gs.broadcastActive {
-		return false
+		return nil, false
 	}
 	gs.broadcastActive = true
-	return true
+	gs.broadcastStop = make(chan struct{})
+	return gs.broadcastStop, true
 }
 
-// SetModuleCount sets the number of modules (1-6)
-func (gs *GameSession) SetModuleCount(count int) error {
+// FinishBroadcast marks the broadcast loop owning stop as exited, so the next game can start one
+// A loop that was already stopped (and maybe replaced by a newer one) leaves the flag alone
+func (gs *GameSession) FinishBroadcast(stop <-chan struct{}) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
-	if count < 1 || count > 6 {
-		return fmt.Errorf("module count must be between 1 and 6")
+	if gs.broadcastStop == stop {
+		gs.broadcastActive = false
+		gs.broadcastStop = nil
 	}
-	
+}
+
+// stopBroadcastLocked stops the running broadcast loop, if any (caller must hold gs.mu)
+func (gs *GameSession) stopBroadcastLocked() {
+	if gs.broadcastStop != nil {
+		close(gs.broadcastStop)
+		gs.broadcastStop = nil
+	}
+	gs.broadcastActive = false
+}
+
+// SetModuleCount sets the number of modules (MinModuleCount-MaxModuleCount)
+func (gs *GameSession) SetModuleCount(count int) error {
+	if err := validateModuleCount(count); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
 	gs.ModuleCount = count
 	return nil
 }
 
+// validateModuleCount returns an error if count isn't a valid module count
+func validateModuleCount(count int) error {
+	if count < MinModuleCount || count > MaxModuleCount {
+		return fmt.Errorf("module count must be between %d and %d", MinModuleCount, MaxModuleCount)
+	}
+	return nil
+}
+
 // SetDefuser sets the defuser selection
 func (gs *GameSession) SetDefuser(defuserID string, isRandom bool) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
+	gs.setDefuserLocked(defuserID, isRandom)
+}
+
+// setDefuserLocked sets the defuser selection, drawing the next defusers again if it changed
+// Caller must hold gs.mu
+func (gs *GameSession) setDefuserLocked(defuserID string, isRandom bool) {
+	if gs.DefuserID != defuserID || gs.IsRandomDefuser != isRandom {
+		gs.upNext = nil
+	}
 	gs.DefuserID = defuserID
 	gs.IsRandomDefuser = isRandom
 }
 
-// SetTimeLimit sets the time limit in seconds (60-300)
+// SetTimeLimit sets the time limit in seconds (60-3600)
+// The new value is used for the bomb of the next game started
 func (gs *GameSession) SetTimeLimit(seconds int) error {
+	if err := validateTimeLimit(seconds); err != nil {
+		return err
+	}
+
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
-	if seconds < 60 || seconds > 300 {
-		return fmt.Errorf("time limit must be between 60 and 300 seconds")
-	}
-	
 	gs.TimeLimit = seconds
 	return nil
 }
 
-// StartGame creates the bomb and transitions to active state
+// validateTimeLimit returns an error if seconds isn't a valid time limit
+func validateTimeLimit(seconds int) error {
+	if seconds < MinTimeLimit || seconds > MaxTimeLimit {
+		return fmt.Errorf("time limit must be between %d and %d seconds", MinTimeLimit, MaxTimeLimit)
+	}
+	return nil
+}
+
+// StartGame creates the bombs, assigns the roles and starts the countdown
+// The session stays in starting state until Update sees the countdown ran out
 func (gs *GameSession) StartGame() error {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
+
 	if gs.LobbyState != LobbyStateWaiting {
 		return fmt.Errorf("game can only be started from waiting state")
 	}
-	
-	if len(gs.Players) < 2 {
-		return fmt.Errorf("at least 2 players required to start game")
-	}
-	
-	// Determine defuser
-	defuserID := gs.DefuserID
-	if gs.IsRandomDefuser || defuserID == "" {
-		// Select random player
-		playerIDs := make([]string, 0, len(gs.Players))
-		for id := range gs.Players {
-			playerIDs = append(playerIDs, id)
+
+	// Disconnected players are waited for, or left out of the game
+	if err := gs.reconnectingErrorLocked(); err != nil {
+		return err
+	}
+
+	// Every defuser needs at least one expert
+	players := gs.eligibleCountLocked()
+	soloPractice := gs.PracticeMode && players == 1
+	if players < gs.DefuserCount+1 && !soloPractice {
+		if gs.DefuserCount == 1 {
+			return fmt.Errorf("at least 2 players required to start game (or enable practice mode)")
 		}
-		if len(playerIDs) > 0 {
-			// Use math/rand for better randomness
-			rand.Seed(time.Now().UnixNano())
-			defuserID = playerIDs[rand.Intn(len(playerIDs))]
+		return fmt.Errorf("at least %d players required to start a game with %d defusers", gs.DefuserCount+1, gs.DefuserCount)
+	}
+
+	if gs.RequireReady {
+		for id, player := range gs.Players {
+			if id != gs.HostID && !player.Ready && gs.eligibleLocked(player) {
+				return fmt.Errorf("all players must be ready to start the game")
+			}
 		}
 	}
-	
-	// Create bomb with specified module count
-	gs.Bomb = NewBomb(gs.ID, gs.TimeLimit, gs.ModuleCount)
-	
-	// Set all players as experts first, then set the defuser
+
+	// Determine the defusers, the ones shown as up next in the lobby
+	defusers := make(map[string]bool)
+	upNext := gs.upNextLocked()
+	for _, id := range upNext {
+		defusers[id] = true
+	}
+
+	// A module mix left over from an older module count would silently fall back to a random split
+	if total := ModuleMixTotal(gs.ModuleMix); len(gs.Mission) == 0 && total > 0 && total != gs.ModuleCount {
+		return fmt.Errorf("module mix adds up to %d modules but the module count is %d", total, gs.ModuleCount)
+	}
+
+	// Create every bomb of the mission, the first one starts once the countdown is over
+	gs.Bombs = gs.buildBombsLocked()
+	gs.CurrentBombIndex = 0
+	gs.nextBombAt = time.Time{}
+	gs.gameStartsAt = time.Now().Add(StartCountdown)
+	gs.resultRecorded = false
+	gs.drops = nil
+	gs.focus = nil
+	gs.autopausedBy = ""
+	gs.recordDefusersLocked(upNext)
+
+	// Set all players as experts first, then set the defusers
 	for id, player := range gs.Players {
-		if id == defuserID {
+		if defusers[id] {
 			player.Type = PlayerTypeDefuser
 		} else {
 			player.Type = PlayerTypeExpert
 		}
 	}
-	
-	gs.LobbyState = LobbyStateActive
+
+	gs.LobbyState = LobbyStateStarting
 	return nil
 }
 
+// beginGameLocked starts the first bomb once the start countdown ran out
+// Caller must hold gs.mu
+func (gs *GameSession) beginGameLocked() {
+	if gs.LobbyState != LobbyStateStarting || time.Now().Before(gs.gameStartsAt) {
+		return
+	}
+
+	gs.gameStartsAt = time.Time{}
+	gs.Bombs[0].Start()
+	gs.gameStartedAt = time.Now()
+	gs.LobbyState = LobbyStateActive
+	metrics.GamesStarted.Inc()
+}
+
+// GetStartCountdown returns the seconds left before the bomb starts, or 0 if the game isn't starting
+func (gs *GameSession) GetStartCountdown() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	if gs.LobbyState != LobbyStateStarting {
+		return 0
+	}
+	remaining := int(time.Until(gs.gameStartsAt).Round(time.Second).Seconds())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
 // ReturnToLobby resets the game state back to lobby
+// During the start countdown it cancels the start
 func (gs *GameSession) ReturnToLobby() error {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
-	if gs.LobbyState != LobbyStateActive {
-		return fmt.Errorf("can only return to lobby from active game state")
+
+	if gs.LobbyState != LobbyStateActive && gs.LobbyState != LobbyStateStarting {
+		return fmt.Errorf("can only return to lobby from a starting or active game")
+	}
+
+	// A start cancelled during the countdown doesn't count as a round
+	if gs.LobbyState == LobbyStateStarting {
+		gs.unrecordDefusersLocked()
 	}
-	
-	// Clear the bomb
-	gs.Bomb = nil
-	
+
+	// Clear the bombs
+	gs.Bombs = nil
+	gs.CurrentBombIndex = 0
+	gs.focus = nil
+	gs.nextBombAt = time.Time{}
+	gs.gameStartsAt = time.Time{}
+
 	// Reset lobby state
 	gs.LobbyState = LobbyStateWaiting
-	
-	// Reset player types back to default (defuser)
+
+	// Reset player types back to default (defuser) and clear ready flags
 	// They will be reassigned when the game starts again
 	for _, player := range gs.Players {
 		player.Type = PlayerTypeDefuser
+		player.Ready = false
 	}
-	
-	// Stop broadcast loop if running
-	gs.broadcastActive = false
-	
+
+	// Stop broadcast loop if running, before the next game can start its own
+	gs.stopBroadcastLocked()
+
 	return nil
 }
 
+// SetMaxStrikes sets the number of strikes before the bomb explodes (1-10)
+func (gs *GameSession) SetMaxStrikes(maxStrikes int) error {
+	if err := validateMaxStrikes(maxStrikes); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.MaxStrikes = maxStrikes
+	return nil
+}
+
+// validateMaxStrikes returns an error if maxStrikes isn't a valid strike limit
+func validateMaxStrikes(maxStrikes int) error {
+	if maxStrikes < MinMaxStrikes || maxStrikes > MaxMaxStrikes {
+		return fmt.Errorf("max strikes must be between %d and %d", MinMaxStrikes, MaxMaxStrikes)
+	}
+	return nil
+}
+
+// GetMaxStrikes returns the strike limit in a thread-safe way
+func (gs *GameSession) GetMaxStrikes() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.MaxStrikes
+}
+
+// SetStrikeTimePenalty sets how many seconds each strike costs (0 disables the penalty)
+func (gs *GameSession) SetStrikeTimePenalty(seconds int) error {
+	if err := validateStrikeTimePenalty(seconds); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.StrikeTimePenalty = seconds
+	return nil
+}
+
+// validateStrikeTimePenalty returns an error if seconds isn't a valid strike time penalty
+func validateStrikeTimePenalty(seconds int) error {
+	if seconds < 0 || seconds > MaxStrikeTimePenalty {
+		return fmt.Errorf("strike time penalty must be between 0 and %d seconds", MaxStrikeTimePenalty)
+	}
+	return nil
+}
+
+// GetStrikeTimePenalty returns the strike time penalty in a thread-safe way
+func (gs *GameSession) GetStrikeTimePenalty() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.StrikeTimePenalty
+}
+
+// SetTimerAcceleration sets whether strikes make the timer tick faster
+func (gs *GameSession) SetTimerAcceleration(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.TimerAcceleration = enabled
+}
+
+// GetTimerAcceleration returns whether timer acceleration is enabled in a thread-safe way
+func (gs *GameSession) GetTimerAcceleration() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.TimerAcceleration
+}
+
+// SetEnableNeedyModules sets whether bombs get needy modules
+func (gs *GameSession) SetEnableNeedyModules(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.EnableNeedyModules = enabled
+}
+
+// GetEnableNeedyModules returns whether needy modules are enabled in a thread-safe way
+func (gs *GameSession) GetEnableNeedyModules() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.EnableNeedyModules
+}
+
+// SetModuleTypes sets the module types bombs can use
+// An empty list enables every module type
+func (gs *GameSession) SetModuleTypes(moduleTypes []string) error {
+	if err := validateModuleTypes(moduleTypes); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	gs.ModuleTypes = append([]string(nil), moduleTypes...)
+	return nil
+}
+
+// validateModuleTypes returns an error if a module type isn't known
+func validateModuleTypes(moduleTypes []string) error {
+	for _, moduleType := range moduleTypes {
+		if indexOf(AllModuleTypes, moduleType) < 0 {
+			return fmt.Errorf("unknown module type %q", moduleType)
+		}
+	}
+	return nil
+}
+
+// GetModuleTypes returns a copy of the enabled module types in a thread-safe way
+func (gs *GameSession) GetModuleTypes() []string {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return append([]string{}, gs.ModuleTypes...)
+}
+
+// SetModuleMix sets how many modules of each type bombs get
+// The counts must add up to the module count; an empty mix goes back to a random split
+func (gs *GameSession) SetModuleMix(mix map[string]int) error {
+	if err := validateModuleMix(mix); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if err := checkModuleMixTotal(mix, gs.ModuleCount); err != nil {
+		return err
+	}
+	gs.setModuleMixLocked(mix)
+	return nil
+}
+
+// validateModuleMix returns an error if a module mix has an unknown module type or a negative count
+func validateModuleMix(mix map[string]int) error {
+	for moduleType, count := range mix {
+		if indexOf(AllModuleTypes, moduleType) < 0 {
+			return fmt.Errorf("unknown module type %q", moduleType)
+		}
+		if count < 0 {
+			return fmt.Errorf("module count for %s can't be negative", moduleType)
+		}
+	}
+	return nil
+}
+
+// checkModuleMixTotal returns an error if a non-empty module mix doesn't add up to the module count
+func checkModuleMixTotal(mix map[string]int, moduleCount int) error {
+	if total := ModuleMixTotal(mix); total != 0 && total != moduleCount {
+		return fmt.Errorf("module mix adds up to %d modules but the module count is %d", total, moduleCount)
+	}
+	return nil
+}
+
+// setModuleMixLocked stores a validated module mix without its empty types, nil for a random split
+// Caller must hold gs.mu
+func (gs *GameSession) setModuleMixLocked(mix map[string]int) {
+	if ModuleMixTotal(mix) == 0 {
+		gs.ModuleMix = nil
+		return
+	}
+	gs.ModuleMix = make(map[string]int, len(mix))
+	for moduleType, count := range mix {
+		if count > 0 {
+			gs.ModuleMix[moduleType] = count
+		}
+	}
+}
+
+// GetModuleMix returns a copy of the module mix in a thread-safe way
+func (gs *GameSession) GetModuleMix() map[string]int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	mix := make(map[string]int, len(gs.ModuleMix))
+	for moduleType, count := range gs.ModuleMix {
+		mix[moduleType] = count
+	}
+	return mix
+}
+
+// SetDifficulty sets the difficulty preset of the next bombs
+// It also switches the strike limit to the preset's; the host can still change it afterwards
+func (gs *GameSession) SetDifficulty(difficulty Difficulty) error {
+	if err := ValidateDifficulty(difficulty); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	gs.Difficulty = difficulty
+	gs.MaxStrikes = PresetFor(difficulty).MaxStrikes
+	return nil
+}
+
+// GetDifficulty returns the difficulty preset in a thread-safe way
+func (gs *GameSession) GetDifficulty() Difficulty {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.Difficulty
+}
+
+// SetPracticeMode sets whether the session is for practice
+// Practice games can be started alone and their results are marked as practice
+func (gs *GameSession) SetPracticeMode(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.PracticeMode = enabled
+}
+
+// GetPracticeMode returns whether practice mode is enabled in a thread-safe way
+func (gs *GameSession) GetPracticeMode() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.PracticeMode
+}
+
+// SetRequireReady sets whether all non-host players must be ready before starting
+func (gs *GameSession) SetRequireReady(requireReady bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.RequireReady = requireReady
+}
+
+// GetRequireReady returns whether the ready check is required in a thread-safe way
+func (gs *GameSession) GetRequireReady() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.RequireReady
+}
+
+// SetPlayerReady sets a player's ready flag, or toggles it if ready is nil
+func (gs *GameSession) SetPlayerReady(playerID string, ready *bool) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists {
+		return fmt.Errorf("player not found")
+	}
+
+	if ready != nil {
+		player.Ready = *ready
+	} else {
+		player.Ready = !player.Ready
+	}
+	return nil
+}
+
+// ResetReady clears every player's ready flag
+func (gs *GameSession) ResetReady() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, player := range gs.Players {
+		player.Ready = false
+	}
+}
+
 // GetLobbyState returns the current lobby state
 func (gs *GameSession) GetLobbyState() LobbyState {
 	gs.mu.RLock()
@@ -262,6 +975,48 @@ func (gs *GameSession) IsHost(playerID string) bool {
 	return gs.HostID == playerID
 }
 
+// TransferHost hands the host role to another player in the session
+func (gs *GameSession) TransferHost(newHostID string) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if _, exists := gs.Players[newHostID]; !exists {
+		return fmt.Errorf("player not found")
+	}
+
+	gs.HostID = newHostID
+	gs.LastActivity = time.Now()
+	return nil
+}
+
+// AllowChatMessage records a chat message from a player if they are under the rate limit
+// Returns false if the player is unknown or has sent too many messages recently
+func (gs *GameSession) AllowChatMessage(playerID string) bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists {
+		return false
+	}
+
+	// Only keep send times inside the current window
+	now := time.Now()
+	recent := player.chatSentAt[:0]
+	for _, sentAt := range player.chatSentAt {
+		if now.Sub(sentAt) < chatRateWindow {
+			recent = append(recent, sentAt)
+		}
+	}
+	player.chatSentAt = recent
+
+	if len(player.chatSentAt) >= chatRateLimit {
+		return false
+	}
+	player.chatSentAt = append(player.chatSentAt, now)
+	return true
+}
+
 // GetLobbyInfo returns lobby information in a thread-safe way
 func (gs *GameSession) GetLobbyInfo() (LobbyState, int, string, bool) {
 	gs.mu.RLock()
@@ -287,7 +1042,7 @@ func (gs *GameSession) GetTimeLimit() int {
 func (gs *GameSession) GetPlayersCopy() map[string]*Player {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
-	
+
 	playersCopy := make(map[string]*Player, len(gs.Players))
 	for id, player := range gs.Players {
 		playersCopy[id] = player
@@ -299,27 +1054,134 @@ func (gs *GameSession) GetPlayersCopy() map[string]*Player {
 func (gs *GameSession) SetPlayerName(playerID string, name string) error {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
+
 	player, exists := gs.Players[playerID]
 	if !exists {
 		return fmt.Errorf("player not found")
 	}
-	
-	if name == "" {
-		return fmt.Errorf("player name cannot be empty")
+
+	if gs.LobbyState != LobbyStateWaiting {
+		return fmt.Errorf("players can only be renamed in the lobby")
+	}
+
+	name, err := NormalizePlayerName(name)
+	if err != nil {
+		return err
 	}
-	
+
 	player.Name = name
 	return nil
 }
 
+// SetPlayerLanguage sets the language a player reads the manual in
+// It can change at any time, only the manual text depends on it
+func (gs *GameSession) SetPlayerLanguage(playerID string, tag string) error {
+	language, err := ParseLanguage(tag)
+	if err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	player, exists := gs.Players[playerID]
+	if !exists {
+		return fmt.Errorf("player not found")
+	}
+	player.Language = language
+	return nil
+}
+
+// GetDefuserView returns the defuser-facing bomb view, or nil if no game is running
+// The view copies what actions change, so it can be marshaled once the lock is released
+func (gs *GameSession) GetDefuserView() *DefuserBombView {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.defuserViewLocked()
+}
+
+// defuserViewLocked builds the defuser view of the bomb being played
+func (gs *GameSession) defuserViewLocked() *DefuserBombView {
+	bomb := gs.currentBombLocked()
+	if bomb == nil {
+		return nil
+	}
+
+	view := bomb.DefuserView()
+	view.BombIndex = gs.CurrentBombIndex
+	view.BombCount = len(gs.Bombs)
+	view.NextBombIn = gs.nextBombInLocked()
+	return view
+}
+
+// DoBombAction runs an action against the bomb while holding the session lock
+// Returns an error without running the action if the game isn't active
+func (gs *GameSession) DoBombAction(action func(bomb *Bomb)) error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	bomb := gs.currentBombLocked()
+	if gs.LobbyState != LobbyStateActive || bomb == nil {
+		return ErrGameNotActive
+	}
+	if bomb.Paused {
+		return ErrGamePaused
+	}
+
+	action(bomb)
+	return nil
+}
+
+// PauseGame freezes the bomb timer and blocks module actions until resumed
+func (gs *GameSession) PauseGame() error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	bomb := gs.currentBombLocked()
+	if gs.LobbyState != LobbyStateActive || bomb == nil || bomb.State != BombStateActive {
+		return ErrGameNotActive
+	}
+	if bomb.Paused {
+		return fmt.Errorf("game is already paused")
+	}
+
+	bomb.Pause()
+	return nil
+}
+
+// ResumeGame restarts the bomb timer after a pause
+func (gs *GameSession) ResumeGame() error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	bomb := gs.currentBombLocked()
+	if gs.LobbyState != LobbyStateActive || bomb == nil || bomb.State != BombStateActive {
+		return ErrGameNotActive
+	}
+	if !bomb.Paused {
+		return fmt.Errorf("game is not paused")
+	}
+
+	// The host took over, the bomb no longer waits for a dropped defuser
+	gs.autopausedBy = ""
+	bomb.Resume()
+	return nil
+}
+
 // Update updates the bomb state (time remaining, etc.)
+// It also starts the game once the start countdown is over
 func (gs *GameSession) Update() {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
-	
-	if gs.Bomb != nil {
-		gs.Bomb.UpdateTimeRemaining()
+
+	gs.beginGameLocked()
+
+	// The bomb isn't ticking yet while the game is starting
+	if bomb := gs.currentBombLocked(); gs.LobbyState == LobbyStateActive && bomb != nil {
+		bomb.UpdateTimeRemaining()
+		bomb.logGameOverIfEnded()
 	}
-}
 
+	// Start the countdown to, or the next bomb of a mission after a defusal
+	gs.advanceMissionLocked()
+}
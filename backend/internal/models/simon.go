@@ -0,0 +1,220 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// SimonColor represents the color of a Simon Says button
+type SimonColor string
+
+const (
+	SimonRed    SimonColor = "red"
+	SimonBlue   SimonColor = "blue"
+	SimonGreen  SimonColor = "green"
+	SimonYellow SimonColor = "yellow"
+)
+
+// simonColors is the fixed order colors are listed in (rules, manual)
+var simonColors = []SimonColor{SimonRed, SimonBlue, SimonGreen, SimonYellow}
+
+const (
+	// simonMinStages and simonMaxStages bound how many stages a Simon module has
+	simonMinStages = 3
+	simonMaxStages = 5
+	// simonFlashPause is how many seconds the module stays dark between two playbacks of the sequence
+	simonFlashPause = 2
+	// simonStrikeTiers is how many strike counts get their own translation table (0, 1, 2+)
+	simonStrikeTiers = 3
+)
+
+// SimonModule represents the Simon Says module on the bomb
+// The module flashes the first CurrentStage+1 colors of the sequence, one per second,
+// and the defuser replays them translated through the manual's table
+type SimonModule struct {
+	moduleIdentity
+	Sequence     []SimonColor  `json:"sequence"`     // Full color sequence (one color per stage)
+	Stages       int           `json:"stages"`       // Number of stages to clear (3-5)
+	CurrentStage int           `json:"currentStage"` // Current stage (0-based)
+	InputIndex   int           `json:"inputIndex"`   // How many colors of the current stage were entered
+	IsSolved     bool          `json:"isSolved"`
+	RuleSet      *SimonRuleSet `json:"-"` // Rules for this module (not serialized)
+	flashStart   time.Time     // When the current playback cycle started
+	manual       *ModuleManual // Manual generated alongside the module
+}
+
+// SimonModuleView is the defuser-facing view of a Simon module (no solution data)
+type SimonModuleView struct {
+	ID            string     `json:"id"`            // Stable module ID
+	FlashingColor SimonColor `json:"flashingColor"` // Color lit right now, empty between flashes
+	CurrentStage  int        `json:"currentStage"`
+	Stages        int        `json:"stages"`
+	InputCount    int        `json:"inputCount"`
+	IsSolved      bool       `json:"isSolved"`
+}
+
+// DefuserView returns the Simon module state that can be shown to the defuser
+func (sm *SimonModule) DefuserView() *SimonModuleView {
+	return &SimonModuleView{
+		ID:            sm.ID,
+		FlashingColor: sm.FlashingColor(time.Now()),
+		CurrentStage:  sm.CurrentStage,
+		Stages:        sm.Stages,
+		InputCount:    sm.InputIndex,
+		IsSolved:      sm.IsSolved,
+	}
+}
+
+// SimonRuleSet maps a flashing color to the color to press, per strike count
+type SimonRuleSet struct {
+	Translations [simonStrikeTiers]map[SimonColor]SimonColor `json:"-"`
+}
+
+// Translate returns the color to press for a flashing color given the current strikes
+func (rs *SimonRuleSet) Translate(flashed SimonColor, strikes int) SimonColor {
+	if strikes >= simonStrikeTiers {
+		strikes = simonStrikeTiers - 1
+	}
+	return rs.Translations[strikes][flashed]
+}
+
+// NewSimonModuleWithRules creates a new Simon module with a random sequence and the shared rules
+// simonSeed: seed for generating the sequence (different for each module)
+// ruleSeed: seed for generating rules (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewSimonModuleWithRules(simonSeed int64, ruleSeed int64) (*SimonModule, *ModuleManual) {
+	rng := rand.New(rand.NewSource(simonSeed))
+
+	stages := simonMinStages + rng.Intn(simonMaxStages-simonMinStages+1)
+	sequence := make([]SimonColor, stages)
+	for i := range sequence {
+		sequence[i] = simonColors[rng.Intn(len(simonColors))]
+	}
+
+	ruleSet, moduleManual := GenerateSimonModuleRulesWithSeed(ruleSeed)
+
+	module := &SimonModule{
+		Sequence:   sequence,
+		Stages:     stages,
+		RuleSet:    ruleSet,
+		flashStart: time.Now(),
+		manual:     moduleManual,
+	}
+	return module, moduleManual
+}
+
+// FlashingColor returns the color lit at the given time, or an empty color between playbacks
+// Each color of the current stage flashes for one second, followed by a short pause
+func (sm *SimonModule) FlashingColor(now time.Time) SimonColor {
+	if sm.IsSolved {
+		return ""
+	}
+
+	length := sm.CurrentStage + 1
+	cycle := length + simonFlashPause
+	position := int(now.Sub(sm.flashStart).Seconds()) % cycle
+	if position < length {
+		return sm.Sequence[position]
+	}
+	return ""
+}
+
+// Press handles the defuser pressing a color
+// strikes is the bomb's current strike count, which selects the translation table
+// Returns true if correct, false if wrong (strike); a wrong press restarts the current stage
+func (sm *SimonModule) Press(color SimonColor, strikes int) bool {
+	if sm.IsSolved {
+		return false
+	}
+
+	expected := sm.RuleSet.Translate(sm.Sequence[sm.InputIndex], strikes)
+	if color != expected {
+		sm.InputIndex = 0
+		sm.flashStart = time.Now()
+		return false
+	}
+
+	sm.InputIndex++
+	if sm.InputIndex > sm.CurrentStage {
+		// Stage cleared, move on to a longer sequence
+		sm.CurrentStage++
+		sm.InputIndex = 0
+		sm.flashStart = time.Now()
+		if sm.CurrentStage >= sm.Stages {
+			sm.IsSolved = true
+		}
+	}
+	return true
+}
+
+// GenerateSimonModuleRulesWithSeed generates the color translation tables for Simon modules
+// There is one table per strike count (no strikes, one strike, two or more strikes)
+func GenerateSimonModuleRulesWithSeed(seed int64) (*SimonRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 5555555))
+
+	ruleSet := &SimonRuleSet{}
+	manualRules := []ManualRule{}
+	ruleNum := 1
+
+	for tier := 0; tier < simonStrikeTiers; tier++ {
+		// Each table is a permutation so every flashed color maps to a distinct button
+		perm := rng.Perm(len(simonColors))
+		table := make(map[SimonColor]SimonColor, len(simonColors))
+		for i, flashed := range simonColors {
+			table[flashed] = simonColors[perm[i]]
+		}
+		ruleSet.Translations[tier] = table
+
+		// Section title (Number 0 indicates it's a title, not a rule)
+		manualRules = append(manualRules, manualRule(0, msg("simon.section", msg(fmt.Sprintf("simon.strikes.%d", tier)))))
+		for _, flashed := range simonColors {
+			manualRules = append(manualRules, manualRule(ruleNum, msg("simon.rule", colorName(string(flashed)), colorName(string(table[flashed])))))
+			ruleNum++
+		}
+	}
+
+	moduleManual := newModuleManual(msg("simon.title"), msg("simon.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"colors": simonColors,
+	}
+
+	return ruleSet, moduleManual
+}
+
+// Type returns the module type
+func (sm *SimonModule) Type() string {
+	return ModuleTypeSimon
+}
+
+// Solved reports whether the Simon module is disarmed
+func (sm *SimonModule) Solved() bool {
+	return sm.IsSolved
+}
+
+// View returns the defuser-facing state of the Simon module
+func (sm *SimonModule) View() interface{} {
+	return sm.DefuserView()
+}
+
+// Manual returns the manual the Simon module was generated with
+func (sm *SimonModule) Manual() *ModuleManual {
+	return sm.manual
+}
+
+// HandleAction applies a defuser action to the Simon module
+// Supports "press" with a color; the strike count picks the translation table
+func (sm *SimonModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "press" {
+		return ActionResult{}, unknownActionError(ModuleTypeSimon, action)
+	}
+
+	var data struct {
+		Color SimonColor `json:"color"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	return actionOutcome(sm.Press(data.Color, ctx.Strikes)), nil
+}
@@ -1,19 +1,92 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
 )
 
+// MaxTerminalCommandLength is the maximum length of a command typed in a terminal
+const MaxTerminalCommandLength = 64
+
+// Terminal sequence lengths: how many commands the defuser types to solve a terminal
+const (
+	DefaultTerminalSequenceLength = 3
+	MinTerminalSequenceLength     = 2
+	MaxTerminalSequenceLength     = 6
+)
+
 // TerminalModule represents the terminal module on the bomb
 type TerminalModule struct {
-	TerminalTexts   []string         `json:"terminalTexts"`   // Text displayed at each step (initial + after each command)
-	CurrentStep     int              `json:"currentStep"`     // Current command step (0-2)
-	EnteredCommands []string         `json:"enteredCommands"` // Commands player has typed
-	CorrectCommands []string         `json:"correctCommands"` // Correct commands determined by rules
-	IsSolved        bool             `json:"isSolved"`
-	RuleSet         *TerminalRuleSet `json:"-"` // Rules for this module (not serialized)
-	TerminalSeed    int64            `json:"-"` // Seed used for this module
+	moduleIdentity
+	TerminalTexts    []string           `json:"terminalTexts"`   // Text displayed at each step (initial + after each command)
+	CurrentStep      int                `json:"currentStep"`     // Current command step, from 0 to the sequence length
+	EnteredCommands  []EnteredCommand   `json:"enteredCommands"` // Commands players have typed, with who typed them
+	CorrectCommands  []string           `json:"correctCommands"` // Correct commands determined by rules
+	IsSolved         bool               `json:"isSolved"`
+	RuleSet          *TerminalRuleSet   `json:"-"`                // Rules for this module (not serialized)
+	TerminalSeed     int64              `json:"-"`                // Seed used for this module
+	HardMode         bool               `json:"hardMode"`         // A wrong command restarts the sequence with new texts
+	Strictness       TerminalStrictness `json:"strictness"`       // How closely commands have to match, strict if empty
+	Resets           int                `json:"resets"`           // Sequences restarted so far, mixed into the seed of the new texts
+	LockoutThreshold int                `json:"-"`                // Wrong commands that lock the terminal out, 0 disables the lockout
+	LockoutSeconds   int                `json:"-"`                // How long a lockout lasts, in bomb clock seconds
+	LockoutRemaining int                `json:"lockoutRemaining"` // Seconds left before the terminal accepts commands again, 0 when unlocked
+	wrongCommands    int                // Wrong commands since the last lockout
+	lockoutLeft      float64            // Bomb clock seconds left in the current lockout
+	lastTick         float64            // Bomb clock second of the last update
+	commandTable     map[string]string  // Terminal texts of the manual and their commands, to draw new sequences from
+	manual           *ModuleManual      // Manual generated alongside the module
+}
+
+// EnteredCommand is a command typed in a terminal and who typed it
+type EnteredCommand struct {
+	Command string `json:"command"` // Normalized (trimmed and uppercased) unless the terminal is hardcore
+	ActionRecord
+}
+
+// TerminalModuleView is the defuser-facing view of a terminal module (no solution data)
+type TerminalModuleView struct {
+	ID                  string   `json:"id"` // Stable module ID
+	TerminalTexts       []string `json:"terminalTexts"`
+	CurrentStep         int      `json:"currentStep"`
+	EnteredCommands     []string `json:"enteredCommands"` // What the defuser typed, shown in the terminal history
+	EnteredCommandCount int      `json:"enteredCommandCount"`
+	IsSolved            bool     `json:"isSolved"`
+	LockoutRemaining    int      `json:"lockoutRemaining"` // Seconds left before commands are accepted again, 0 when unlocked
+}
+
+// DefuserView returns the terminal module state that can be shown to the defuser
+func (tm *TerminalModule) DefuserView() *TerminalModuleView {
+	return &TerminalModuleView{
+		ID:                  tm.ID,
+		TerminalTexts:       tm.TerminalTexts,
+		CurrentStep:         tm.CurrentStep,
+		EnteredCommands:     tm.Commands(),
+		EnteredCommandCount: len(tm.EnteredCommands),
+		IsSolved:            tm.IsSolved,
+		LockoutRemaining:    tm.LockoutRemaining,
+	}
+}
+
+// Commands returns the commands typed so far, in order
+func (tm *TerminalModule) Commands() []string {
+	commands := make([]string, len(tm.EnteredCommands))
+	for i, entered := range tm.EnteredCommands {
+		commands[i] = entered.Command
+	}
+	return commands
+}
+
+// ActionLog returns every command typed with who typed it
+func (tm *TerminalModule) ActionLog() []AttributedAction {
+	log := make([]AttributedAction, len(tm.EnteredCommands))
+	for i, entered := range tm.EnteredCommands {
+		log[i] = AttributedAction{Action: "command", Command: entered.Command, ActionRecord: entered.ActionRecord}
+	}
+	return log
 }
 
 // GetCurrentTerminalText returns the text that should be displayed in the terminal at the current step
@@ -34,10 +107,11 @@ type TerminalRuleSet struct {
 
 // TerminalRule represents a rule with both description and evaluator function
 type TerminalRule struct {
-	Number      int                   `json:"number"`
-	Description string                `json:"description"`
-	Evaluator   TerminalRuleEvaluator `json:"-"`       // Not serialized, used for evaluation
-	Command     string                `json:"command"` // The command word for this rule
+	Number       int                   `json:"number"`
+	Description  string                `json:"description"`
+	Evaluator    TerminalRuleEvaluator `json:"-"`            // Not serialized, used for evaluation
+	TerminalText string                `json:"terminalText"` // The text this rule is about
+	Command      string                `json:"command"`      // The command word for this rule
 }
 
 // TerminalRuleEvaluator is a function that evaluates conditions based on terminal text and returns the command to type
@@ -86,24 +160,25 @@ var terminalPrompts = []string{
 // NewTerminalModuleWithRules creates a new terminal module with random configuration and generates rules
 // terminalSeed: seed for generating random terminal configuration (different for each module)
 // ruleSeed: seed for generating rules (same for all modules to match the manual)
+// length: number of commands to type, see ValidateTerminalSequenceLength
 // Returns the module and its corresponding manual
-func NewTerminalModuleWithRules(terminalSeed int64, ruleSeed int64) (*TerminalModule, *ModuleManual) {
+func NewTerminalModuleWithRules(terminalSeed int64, ruleSeed int64, length int) (*TerminalModule, *ModuleManual) {
 	// Create a seeded RNG for terminal generation using the terminalSeed (unique per module)
 	rng := rand.New(rand.NewSource(terminalSeed))
 
 	// Generate random terminal texts for each step
-	terminalTexts := make([]string, 3)
-	terminalTexts[0] = terminalPrompts[rng.Intn(len(terminalPrompts))]
-	terminalTexts[1] = terminalPrompts[rng.Intn(len(terminalPrompts))]
-	terminalTexts[2] = terminalPrompts[rng.Intn(len(terminalPrompts))]
+	terminalTexts := make([]string, length)
+	for i := range terminalTexts {
+		terminalTexts[i] = terminalPrompts[rng.Intn(len(terminalPrompts))]
+	}
 
 	// Generate rules and manual using ruleSeed (same for all modules)
 	// Pass the terminal texts so rules can reference them
 	ruleSet, moduleManual := GenerateTerminalModuleRulesWithSeed(ruleSeed, terminalTexts)
 
 	// Determine correct commands based on rules and current terminal text
-	correctCommands := make([]string, 3)
-	for i := 0; i < 3; i++ {
+	correctCommands := make([]string, length)
+	for i := 0; i < length; i++ {
 		if i < len(ruleSet.Rules) {
 			// Evaluate rule based on the terminal text at this step
 			terminalText := terminalTexts[i]
@@ -117,41 +192,49 @@ func NewTerminalModuleWithRules(terminalSeed int64, ruleSeed int64) (*TerminalMo
 	module := &TerminalModule{
 		TerminalTexts:   terminalTexts,
 		CurrentStep:     0,
-		EnteredCommands: []string{},
+		EnteredCommands: []EnteredCommand{},
 		CorrectCommands: correctCommands,
 		IsSolved:        false,
 		RuleSet:         ruleSet,
 		TerminalSeed:    terminalSeed,
+		manual:          moduleManual,
 	}
 
 	return module, moduleManual
 }
 
-// EnterCommand attempts to enter a command at the current step
-// Returns true if correct, false if wrong (strike)
-func (tm *TerminalModule) EnterCommand(command string) bool {
+// EnterCommand attempts to enter a command at the current step, by records who typed it
+// Returns true if correct, false if wrong (strike), or ErrModuleLocked if the terminal is
+// locked out and the command was ignored
+func (tm *TerminalModule) EnterCommand(command string, by ActionRecord) (bool, error) {
 	if tm.IsSolved {
-		return false // Already solved
+		return false, nil // Already solved
+	}
+	if tm.Lockout() > 0 {
+		return false, ErrModuleLocked
 	}
 
-	// Normalize command (trim and uppercase)
-	normalizedCommand := strings.TrimSpace(strings.ToUpper(command))
+	// Normalize command (trim and uppercase, unless hardcore)
+	strictness := tm.Strictness
+	if strictness == "" {
+		strictness = TerminalStrictnessStrict
+	}
+	normalizedCommand := strictness.normalize(command)
 
 	if normalizedCommand == "" {
-		return false // Empty command
+		return false, nil // Empty command
 	}
 
 	// Check if we've already entered all commands
 	if tm.CurrentStep >= len(tm.CorrectCommands) {
-		return false
+		return false, nil
 	}
 
 	// Add to entered commands
-	tm.EnteredCommands = append(tm.EnteredCommands, normalizedCommand)
+	tm.EnteredCommands = append(tm.EnteredCommands, EnteredCommand{Command: normalizedCommand, ActionRecord: by})
 
 	// Check if command matches the correct command for current step
-	correctCommand := strings.ToUpper(tm.CorrectCommands[tm.CurrentStep])
-	if normalizedCommand == correctCommand {
+	if strictness.matches(normalizedCommand, tm.CorrectCommands[tm.CurrentStep]) {
 		tm.CurrentStep++
 
 		// Check if all commands are entered correctly
@@ -159,9 +242,123 @@ func (tm *TerminalModule) EnterCommand(command string) bool {
 			tm.IsSolved = true
 		}
 		// Terminal text will update automatically via GetCurrentTerminalText()
-		return true
+		return true, nil
 	}
 
-	// Wrong command = strike (but don't reset, allow retry)
-	return false
+	// Wrong command = strike; in hard mode the sequence starts over, otherwise the step is retried
+	if tm.HardMode {
+		tm.restartSequence()
+	}
+	tm.countWrongCommand()
+	return false, nil
+}
+
+// restartSequence goes back to the first step with a new sequence of terminal texts
+// The texts are drawn from the manual's table, seeded from the module seed and the number
+// of resets so a replay gives the same sequences
+func (tm *TerminalModule) restartSequence() {
+	tm.CurrentStep = 0
+	if len(tm.commandTable) == 0 {
+		return
+	}
+	tm.Resets++
+	rng := rand.New(rand.NewSource(tm.TerminalSeed + int64(tm.Resets)*7919))
+	tm.TerminalTexts, tm.CorrectCommands = drawTerminalSequence(rng, tm.commandTable, len(tm.CorrectCommands))
+	tm.RuleSet = newTerminalRuleSet(tm.TerminalTexts, tm.CorrectCommands)
+}
+
+// ValidateTerminalSequenceLength checks a terminal sequence length is supported
+func ValidateTerminalSequenceLength(length int) error {
+	if length < MinTerminalSequenceLength || length > MaxTerminalSequenceLength {
+		return fmt.Errorf("terminal sequence length must be between %d and %d", MinTerminalSequenceLength, MaxTerminalSequenceLength)
+	}
+	return nil
+}
+
+// drawTerminalSequence picks length unique terminal texts of a manual's table, and their commands
+func drawTerminalSequence(rng *rand.Rand, table map[string]string, length int) ([]string, []string) {
+	allTexts := make([]string, 0, len(table))
+	for text := range table {
+		allTexts = append(allTexts, text)
+	}
+	// Sorted, map order would make the same seed give different modules
+	sort.Strings(allTexts)
+
+	texts := make([]string, 0, length)
+	commands := make([]string, 0, length)
+	usedIndices := make(map[int]bool)
+	for len(texts) < length && len(texts) < len(allTexts) {
+		idx := rng.Intn(len(allTexts))
+		if !usedIndices[idx] {
+			usedIndices[idx] = true
+			texts = append(texts, allTexts[idx])
+			commands = append(commands, table[allTexts[idx]])
+		}
+	}
+	return texts, commands
+}
+
+// newTerminalRuleSet builds the rules of a terminal module from its texts and their commands
+func newTerminalRuleSet(texts []string, commands []string) *TerminalRuleSet {
+	rules := make([]TerminalRule, 0, len(texts))
+	for j := 0; j < len(texts); j++ {
+		text := texts[j]
+		cmd := commands[j]
+
+		evaluator := func(inputText string) string {
+			if strings.Contains(strings.ToUpper(inputText), strings.ToUpper(text)) {
+				return cmd
+			}
+			return ""
+		}
+
+		rules = append(rules, TerminalRule{
+			Number:       j + 1,
+			Description:  msg("terminal.rule", text, cmd).Render(DefaultLanguage),
+			Evaluator:    evaluator,
+			TerminalText: text,
+			Command:      cmd,
+		})
+	}
+	return &TerminalRuleSet{Rules: rules}
+}
+
+// Type returns the module type
+func (tm *TerminalModule) Type() string {
+	return ModuleTypeTerminal
+}
+
+// Solved reports whether the terminal module is disarmed
+func (tm *TerminalModule) Solved() bool {
+	return tm.IsSolved
+}
+
+// View returns the defuser-facing state of the terminal module
+func (tm *TerminalModule) View() interface{} {
+	return tm.DefuserView()
+}
+
+// Manual returns the manual the terminal module was generated with
+func (tm *TerminalModule) Manual() *ModuleManual {
+	return tm.manual
+}
+
+// HandleAction applies a defuser action to the terminal module
+// Supports "command" with the command text
+func (tm *TerminalModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "command" {
+		return ActionResult{}, unknownActionError(ModuleTypeTerminal, action)
+	}
+
+	var data struct {
+		Command string `json:"command"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	correct, err := tm.EnterCommand(data.Command, ctx.By)
+	if err != nil {
+		return ActionResult{}, err
+	}
+	return actionOutcome(correct), nil
 }
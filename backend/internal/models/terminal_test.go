@@ -0,0 +1,152 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrongTerminalCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		hardMode   bool
+		wantStep   int
+		wantResets int
+	}{
+		{"normal", false, 1, 0},
+		{"hard mode", true, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := BombConfig{ModuleMix: map[string]int{ModuleTypeTerminal: 3}, TerminalHardMode: tt.hardMode}
+			module := newTestSession(t, config, 1).Bombs[0].TerminalModules[0]
+			replay := newTestSession(t, config, 1).Bombs[0].TerminalModules[0]
+			texts := append([]string{}, module.TerminalTexts...)
+			for _, m := range []*TerminalModule{module, replay} {
+				if ok, err := m.EnterCommand(m.CorrectCommands[0], actionBy("player-1")); !ok || err != nil {
+					t.Fatalf("correct command = %v, %v", ok, err)
+				}
+				if ok, _ := m.EnterCommand("NOT A COMMAND", actionBy("player-1")); ok {
+					t.Fatal("wrong command accepted")
+				}
+			}
+
+			if module.CurrentStep != tt.wantStep || module.Resets != tt.wantResets {
+				t.Fatalf("after a wrong command: step %d, %d resets; want step %d, %d resets", module.CurrentStep, module.Resets, tt.wantStep, tt.wantResets)
+			}
+			if restarted := !reflect.DeepEqual(module.TerminalTexts, texts); restarted != tt.hardMode {
+				t.Errorf("texts %v after %v, want new texts %v", module.TerminalTexts, texts, tt.hardMode)
+			}
+			if !reflect.DeepEqual(module.TerminalTexts, replay.TerminalTexts) {
+				t.Errorf("texts %v, the same commands gave %v", module.TerminalTexts, replay.TerminalTexts)
+			}
+			for i, text := range module.TerminalTexts {
+				if module.CorrectCommands[i] != module.commandTable[text] || module.RuleSet.Rules[i].Command != module.CorrectCommands[i] {
+					t.Errorf("step %d shows %q but expects %q, the manual says %q", i, text, module.CorrectCommands[i], module.commandTable[text])
+				}
+			}
+
+			// The module is still solvable from where it is
+			for module.CurrentStep < len(module.CorrectCommands) {
+				if ok, err := module.EnterCommand(module.CorrectCommands[module.CurrentStep], actionBy("player-1")); !ok || err != nil {
+					t.Fatalf("step %d: correct command = %v, %v", module.CurrentStep, ok, err)
+				}
+			}
+			if !module.Solved() {
+				t.Error("every command entered but the terminal is not solved")
+			}
+		})
+	}
+}
+
+func TestTerminalStrictness(t *testing.T) {
+	tests := []struct {
+		strictness TerminalStrictness
+		typed      string
+		expected   string
+		want       bool
+	}{
+		{TerminalStrictnessStrict, "chmod 777", "chmod 777", true},
+		{TerminalStrictnessStrict, "  CHMOD 777 ", "chmod 777", true},
+		{TerminalStrictnessStrict, "chmd 777", "chmod 777", false},
+		{TerminalStrictnessForgiving, " Chmod 777", "chmod 777", true},
+		{TerminalStrictnessForgiving, "chmd 777", "chmod 777", true},
+		{TerminalStrictnessForgiving, "chmodd 777", "chmod 777", true},
+		{TerminalStrictnessForgiving, "chmox 777", "chmod 777", true},
+		{TerminalStrictnessForgiving, "chomd 777", "chmod 777", false}, // A swap is two edits
+		{TerminalStrictnessForgiving, "mv -x", "mv -f", true},          // 5 letters
+		{TerminalStrictnessForgiving, "tine", "time", false},           // 4 letters
+		{TerminalStrictnessForgiving, "time", "time", true},
+		{TerminalStrictnessHardcore, "chmod 777", "chmod 777", true},
+		{TerminalStrictnessHardcore, "CHMOD 777", "chmod 777", false},
+		{TerminalStrictnessHardcore, "chmod 777 ", "chmod 777", false},
+		{TerminalStrictnessHardcore, "chmd 777", "chmod 777", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strictness)+" "+tt.typed, func(t *testing.T) {
+			if got := tt.strictness.matches(tt.strictness.normalize(tt.typed), tt.expected); got != tt.want {
+				t.Errorf("%q for %q = %v, want %v", tt.typed, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForgivingTerminalAcceptsTypo(t *testing.T) {
+	tests := []struct {
+		strictness TerminalStrictness
+		want       bool
+	}{
+		{TerminalStrictnessStrict, false},
+		{TerminalStrictnessForgiving, true},
+		{TerminalStrictnessHardcore, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strictness), func(t *testing.T) {
+			bomb := newTestSession(t, BombConfig{ModuleMix: map[string]int{ModuleTypeTerminal: 3}, TerminalStrictness: tt.strictness}, 1).Bombs[0]
+			typed := 0
+			for _, module := range bomb.TerminalModules {
+				if module.Strictness != tt.strictness {
+					t.Fatalf("terminal strictness = %q, want %q", module.Strictness, tt.strictness)
+				}
+				command := module.CorrectCommands[0]
+				if len([]rune(command)) < forgivingMinLength {
+					continue
+				}
+				typo := command[:len(command)-1] // Last letter missing
+				if ok, err := module.EnterCommand(typo, actionBy("player-1")); ok != tt.want || err != nil {
+					t.Errorf("%q for %q = %v, %v; want %v", typo, command, ok, err, tt.want)
+				}
+				typed++
+			}
+			if typed == 0 {
+				t.Fatal("no terminal expects a command long enough for a typo")
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"chmod", "chmod", 0},
+		{"chmod", "chmd", 1},
+		{"chmod", "chomd", 2},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"café", "cafe", 1}, // Runes, not bytes
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := levenshtein(tt.a, tt.b); got != tt.want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := levenshtein(tt.b, tt.a); got != tt.want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
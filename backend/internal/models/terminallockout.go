@@ -0,0 +1,81 @@
+package models
+
+import "math"
+
+// Terminal lockout settings: after LockoutThreshold wrong commands a terminal ignores commands
+// for LockoutSeconds, which stops players from trying every command of the manual
+const (
+	DefaultTerminalLockoutSeconds = 30
+	MaxTerminalLockoutThreshold   = 10
+	MinTerminalLockoutSeconds     = 5
+	MaxTerminalLockoutSeconds     = 300
+)
+
+// lockableModule is a module that can lock itself out after wrong actions
+type lockableModule interface {
+	// Lockout returns the seconds left before the module accepts actions again, 0 when unlocked
+	Lockout() int
+}
+
+// Lockout returns the seconds left before the terminal accepts commands again, 0 when unlocked
+func (tm *TerminalModule) Lockout() int {
+	return tm.LockoutRemaining
+}
+
+// countWrongCommand locks the terminal out once enough wrong commands were typed
+func (tm *TerminalModule) countWrongCommand() {
+	if tm.LockoutThreshold <= 0 || tm.LockoutSeconds <= 0 {
+		return
+	}
+	tm.wrongCommands++
+	if tm.wrongCommands < tm.LockoutThreshold {
+		return
+	}
+	tm.wrongCommands = 0
+	tm.lockoutLeft = float64(tm.LockoutSeconds)
+	tm.LockoutRemaining = tm.LockoutSeconds
+}
+
+// Tick counts the lockout down to the given bomb clock time
+// Returns true when a lockout ends
+func (tm *TerminalModule) Tick(elapsed float64) bool {
+	delta := elapsed - tm.lastTick
+	tm.lastTick = elapsed
+	if tm.LockoutRemaining == 0 {
+		return false
+	}
+
+	tm.lockoutLeft -= delta
+	if tm.lockoutLeft > 0 {
+		tm.LockoutRemaining = int(math.Ceil(tm.lockoutLeft))
+		return false
+	}
+	tm.lockoutLeft = 0
+	tm.LockoutRemaining = 0
+	return true
+}
+
+// tickTerminalLockouts counts the terminal lockouts down, announcing the terminals that unlock
+func (b *Bomb) tickTerminalLockouts() {
+	for i, module := range b.TerminalModules {
+		before := module.LockoutRemaining
+		unlocked := module.Tick(b.elapsed)
+		if module.LockoutRemaining != before {
+			b.markDirty(ModuleTypeTerminal, i)
+		}
+		if unlocked {
+			b.announceLockout(ModuleTypeTerminal, i, 0)
+		}
+	}
+}
+
+// announceLockout logs and announces a module locked out for seconds, or unlocked if seconds is 0
+func (b *Bomb) announceLockout(moduleType string, moduleIndex int, seconds int) {
+	eventType := ReplayEventModuleLocked
+	if seconds == 0 {
+		eventType = ReplayEventModuleUnlocked
+	}
+	moduleID := b.ModuleIDOf(moduleType, moduleIndex)
+	b.logEvent(ReplayEvent{Type: eventType, ModuleType: moduleType, ModuleIndex: &moduleIndex, ModuleID: moduleID})
+	b.announce(BombEvent{Type: eventType, ModuleType: moduleType, ModuleIndex: moduleIndex, ModuleID: moduleID, Position: b.ModulePosition(moduleType, moduleIndex), Seconds: seconds})
+}
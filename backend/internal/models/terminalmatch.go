@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TerminalStrictness is how closely a typed command has to match the expected one
+type TerminalStrictness string
+
+const (
+	TerminalStrictnessStrict    TerminalStrictness = "strict"    // Exact match, ignoring case and surrounding spaces
+	TerminalStrictnessForgiving TerminalStrictness = "forgiving" // Like strict, one typo allowed in words of 5+ letters
+	TerminalStrictnessHardcore  TerminalStrictness = "hardcore"  // Exact, case-sensitive match, spaces included
+)
+
+// forgivingMinLength is the shortest command a forgiving terminal accepts a typo in
+const forgivingMinLength = 5
+
+// ValidateTerminalStrictness checks a terminal strictness is known
+func ValidateTerminalStrictness(strictness TerminalStrictness) error {
+	switch strictness {
+	case TerminalStrictnessStrict, TerminalStrictnessForgiving, TerminalStrictnessHardcore:
+		return nil
+	}
+	return fmt.Errorf("unknown terminal strictness %q", strictness)
+}
+
+// normalize returns a typed command as it is recorded and compared
+func (s TerminalStrictness) normalize(command string) string {
+	if s == TerminalStrictnessHardcore {
+		return command
+	}
+	return strings.TrimSpace(strings.ToUpper(command))
+}
+
+// matches reports whether a normalized command is accepted for the expected one
+func (s TerminalStrictness) matches(command string, expected string) bool {
+	if s == TerminalStrictnessHardcore {
+		return command == expected
+	}
+	expected = strings.ToUpper(expected)
+	if command == expected {
+		return true
+	}
+	return s == TerminalStrictnessForgiving &&
+		len([]rune(expected)) >= forgivingMinLength &&
+		levenshtein(command, expected) <= 1
+}
+
+// note returns the manual sentence telling experts what the terminal accepts
+func (s TerminalStrictness) note() *Message {
+	switch s {
+	case TerminalStrictnessForgiving:
+		return msg("terminal.note.forgiving", forgivingMinLength)
+	case TerminalStrictnessHardcore:
+		return msg("terminal.note.hardcore")
+	}
+	return msg("terminal.note.strict")
+}
+
+// levenshtein returns the edit distance between two strings: the fewest single-character
+// insertions, deletions and substitutions turning a into b
+func levenshtein(a string, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
@@ -0,0 +1,109 @@
+package models
+
+import "fmt"
+
+// SetTerminalHardMode sets whether a wrong terminal command restarts the sequence with new texts
+func (gs *GameSession) SetTerminalHardMode(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.TerminalHardMode = enabled
+}
+
+// GetTerminalHardMode returns whether terminal hard mode is enabled in a thread-safe way
+func (gs *GameSession) GetTerminalHardMode() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.TerminalHardMode
+}
+
+// SetTerminalSequenceLength sets how many commands solve a terminal (2-6)
+func (gs *GameSession) SetTerminalSequenceLength(length int) error {
+	if err := ValidateTerminalSequenceLength(length); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.TerminalSequenceLength = length
+	return nil
+}
+
+// GetTerminalSequenceLength returns how many commands solve a terminal in a thread-safe way
+func (gs *GameSession) GetTerminalSequenceLength() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.TerminalSequenceLength
+}
+
+// SetTerminalLockoutThreshold sets how many wrong commands lock a terminal out (0 disables the lockout)
+func (gs *GameSession) SetTerminalLockoutThreshold(threshold int) error {
+	if err := validateTerminalLockoutThreshold(threshold); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.TerminalLockoutThreshold = threshold
+	return nil
+}
+
+// validateTerminalLockoutThreshold returns an error if threshold isn't a valid lockout threshold
+func validateTerminalLockoutThreshold(threshold int) error {
+	if threshold < 0 || threshold > MaxTerminalLockoutThreshold {
+		return fmt.Errorf("terminal lockout threshold must be between 0 and %d wrong commands", MaxTerminalLockoutThreshold)
+	}
+	return nil
+}
+
+// GetTerminalLockoutThreshold returns how many wrong commands lock a terminal out in a thread-safe way
+func (gs *GameSession) GetTerminalLockoutThreshold() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.TerminalLockoutThreshold
+}
+
+// SetTerminalLockoutSeconds sets how long a terminal lockout lasts
+func (gs *GameSession) SetTerminalLockoutSeconds(seconds int) error {
+	if err := validateTerminalLockoutSeconds(seconds); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.TerminalLockoutSeconds = seconds
+	return nil
+}
+
+// validateTerminalLockoutSeconds returns an error if seconds isn't a valid lockout duration
+func validateTerminalLockoutSeconds(seconds int) error {
+	if seconds < MinTerminalLockoutSeconds || seconds > MaxTerminalLockoutSeconds {
+		return fmt.Errorf("terminal lockout must last between %d and %d seconds", MinTerminalLockoutSeconds, MaxTerminalLockoutSeconds)
+	}
+	return nil
+}
+
+// GetTerminalLockoutSeconds returns how long a terminal lockout lasts in a thread-safe way
+func (gs *GameSession) GetTerminalLockoutSeconds() int {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.TerminalLockoutSeconds
+}
+
+// SetTerminalStrictness sets how closely terminal commands have to match
+func (gs *GameSession) SetTerminalStrictness(strictness TerminalStrictness) error {
+	if err := ValidateTerminalStrictness(strictness); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.TerminalStrictness = strictness
+	return nil
+}
+
+// GetTerminalStrictness returns how closely terminal commands have to match in a thread-safe way
+func (gs *GameSession) GetTerminalStrictness() TerminalStrictness {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.TerminalStrictness
+}
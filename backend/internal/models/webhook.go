@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// maxWebhookURLLength bounds the webhook URL a host can set
+const maxWebhookURLLength = 2048
+
+// ValidateWebhookURL checks that a webhook URL is an absolute http(s) URL
+// Loopback and private addresses are refused so hosts can't make the server call internal services
+func ValidateWebhookURL(raw string) error {
+	if len(raw) > maxWebhookURLLength {
+		return fmt.Errorf("webhook URL must be at most %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute http or https URL")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("webhook URL must not point to a local address")
+	}
+	if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()) {
+		return fmt.Errorf("webhook URL must not point to a local address")
+	}
+	return nil
+}
+
+// SetWebhookURL sets the webhook called when a game of this session ends
+// An empty URL clears it, games then use the server's default webhook if any
+func (gs *GameSession) SetWebhookURL(webhookURL string) error {
+	webhookURL, err := normalizeWebhookURL(webhookURL)
+	if err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.WebhookURL = webhookURL
+	return nil
+}
+
+// normalizeWebhookURL trims a webhook URL and validates it unless it is empty
+func normalizeWebhookURL(webhookURL string) (string, error) {
+	webhookURL = strings.TrimSpace(webhookURL)
+	if webhookURL != "" {
+		if err := ValidateWebhookURL(webhookURL); err != nil {
+			return "", err
+		}
+	}
+	return webhookURL, nil
+}
+
+// GetWebhookURL returns the session's webhook URL in a thread-safe way, empty if unset
+func (gs *GameSession) GetWebhookURL() string {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.WebhookURL
+}
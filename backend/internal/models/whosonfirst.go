@@ -0,0 +1,216 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// WhosOnFirstDisplayWords is the pool of words the display can show
+var WhosOnFirstDisplayWords = []string{
+	"YES", "FIRST", "DISPLAY", "OKAY", "SAYS", "NOTHING", "BLANK", "NO",
+	"LED", "LEAD", "READ", "RED", "REED", "HOLD ON", "YOU", "YOU ARE",
+	"YOUR", "THERE", "THEY'RE", "THEIR", "SEE", "CEE",
+}
+
+// WhosOnFirstLabels is the pool of words the buttons can carry
+var WhosOnFirstLabels = []string{
+	"READY", "FIRST", "NO", "BLANK", "NOTHING", "YES", "WHAT", "UHHH",
+	"LEFT", "RIGHT", "MIDDLE", "OKAY", "WAIT", "PRESS",
+}
+
+const (
+	// whosOnFirstStages is the number of correct presses needed to solve the module
+	whosOnFirstStages = 3
+	// whosOnFirstButtons is the number of buttons, in two columns of three
+	whosOnFirstButtons = 6
+)
+
+// whosOnFirstPositions names the button positions in the manual
+var whosOnFirstPositions = []string{"top left", "top right", "middle left", "middle right", "bottom left", "bottom right"}
+
+// WhosOnFirstModule represents the Who's on First module on the bomb
+// The display word tells which button to read; that button's label points to a
+// list of labels, and the defuser presses the first one of them on the module
+type WhosOnFirstModule struct {
+	moduleIdentity
+	Stage    int                 `json:"stage"`   // Current stage (0-based)
+	Display  string              `json:"display"` // Word on the display
+	Labels   []string            `json:"labels"`  // Label of each button, in position order
+	IsSolved bool                `json:"isSolved"`
+	RuleSet  *WhosOnFirstRuleSet `json:"-"` // Rules for this module (not serialized)
+	rng      *rand.Rand          // Seeded source for displays and labels
+	manual   *ModuleManual       // Manual generated alongside the module
+}
+
+// WhosOnFirstModuleView is the defuser-facing view of a Who's on First module (no solution data)
+type WhosOnFirstModuleView struct {
+	ID       string   `json:"id"` // Stable module ID
+	Stage    int      `json:"stage"`
+	Stages   int      `json:"stages"`
+	Display  string   `json:"display"`
+	Labels   []string `json:"labels"`
+	IsSolved bool     `json:"isSolved"`
+}
+
+// DefuserView returns the Who's on First module state that can be shown to the defuser
+func (wm *WhosOnFirstModule) DefuserView() *WhosOnFirstModuleView {
+	return &WhosOnFirstModuleView{
+		ID:       wm.ID,
+		Stage:    wm.Stage,
+		Stages:   whosOnFirstStages,
+		Display:  wm.Display,
+		Labels:   wm.Labels,
+		IsSolved: wm.IsSolved,
+	}
+}
+
+// WhosOnFirstRuleSet contains the two lookup tables printed in the manual
+type WhosOnFirstRuleSet struct {
+	ReadPosition map[string]int      `json:"-"` // Display word -> position of the button to read
+	PressOrder   map[string][]string `json:"-"` // Label read -> labels to look for, in order
+}
+
+// NewWhosOnFirstModuleWithRules creates a new Who's on First module with the shared rules
+// wofSeed: seed for generating displays and labels (different for each module)
+// ruleSeed: seed for generating rules (same for all modules to match the manual)
+// Returns the module and its corresponding manual
+func NewWhosOnFirstModuleWithRules(wofSeed int64, ruleSeed int64) (*WhosOnFirstModule, *ModuleManual) {
+	ruleSet, moduleManual := GenerateWhosOnFirstModuleRulesWithSeed(ruleSeed)
+
+	module := &WhosOnFirstModule{
+		RuleSet: ruleSet,
+		rng:     rand.New(rand.NewSource(wofSeed)),
+		manual:  moduleManual,
+	}
+	module.rollStage()
+	return module, moduleManual
+}
+
+// rollStage draws a new display word and button labels for the current stage
+func (wm *WhosOnFirstModule) rollStage() {
+	wm.Display = WhosOnFirstDisplayWords[wm.rng.Intn(len(WhosOnFirstDisplayWords))]
+	wm.Labels = make([]string, 0, whosOnFirstButtons)
+	for _, i := range wm.rng.Perm(len(WhosOnFirstLabels))[:whosOnFirstButtons] {
+		wm.Labels = append(wm.Labels, WhosOnFirstLabels[i])
+	}
+}
+
+// correctPosition resolves both tables to the position to press
+func (wm *WhosOnFirstModule) correctPosition() int {
+	read := wm.Labels[wm.RuleSet.ReadPosition[wm.Display]]
+	for _, label := range wm.RuleSet.PressOrder[read] {
+		if position := indexOf(wm.Labels, label); position >= 0 {
+			return position
+		}
+	}
+	return -1
+}
+
+// Press handles the defuser pressing the button at a position
+// Returns true if correct, false if wrong (strike); a wrong press rerolls the stage
+func (wm *WhosOnFirstModule) Press(position int) bool {
+	if wm.IsSolved || position < 0 || position >= len(wm.Labels) {
+		return false
+	}
+
+	if position != wm.correctPosition() {
+		wm.rollStage()
+		return false
+	}
+
+	wm.Stage++
+	if wm.Stage >= whosOnFirstStages {
+		wm.IsSolved = true
+		return true
+	}
+	wm.rollStage()
+	return true
+}
+
+// GenerateWhosOnFirstModuleRulesWithSeed generates the two lookup tables for Who's on First modules
+// Every press order list holds all the labels, so one of them is always on the module
+func GenerateWhosOnFirstModuleRulesWithSeed(seed int64) (*WhosOnFirstRuleSet, *ModuleManual) {
+	rng := rand.New(rand.NewSource(seed + 10101010))
+
+	ruleSet := &WhosOnFirstRuleSet{
+		ReadPosition: make(map[string]int, len(WhosOnFirstDisplayWords)),
+		PressOrder:   make(map[string][]string, len(WhosOnFirstLabels)),
+	}
+	manualRules := []ManualRule{}
+	ruleNum := 1
+
+	// Section title (Number 0 indicates it's a title, not a rule)
+	manualRules = append(manualRules, manualRule(0, msg("whosOnFirst.section.read")))
+	readPositions := make(map[string]string, len(WhosOnFirstDisplayWords))
+	for _, word := range WhosOnFirstDisplayWords {
+		position := rng.Intn(whosOnFirstButtons)
+		ruleSet.ReadPosition[word] = position
+		readPositions[word] = whosOnFirstPositions[position]
+		positionName := msg(fmt.Sprintf("whosOnFirst.position.%d", position))
+		manualRules = append(manualRules, manualRule(ruleNum, msg("whosOnFirst.rule.read", word, positionName)))
+		ruleNum++
+	}
+
+	manualRules = append(manualRules, manualRule(0, msg("whosOnFirst.section.press")))
+	for _, label := range WhosOnFirstLabels {
+		order := make([]string, 0, len(WhosOnFirstLabels))
+		for _, i := range rng.Perm(len(WhosOnFirstLabels)) {
+			order = append(order, WhosOnFirstLabels[i])
+		}
+		ruleSet.PressOrder[label] = order
+		manualRules = append(manualRules, ManualRule{
+			Number:      ruleNum,
+			Description: fmt.Sprintf("%s: %s", label, strings.Join(order, ", ")),
+		})
+		ruleNum++
+	}
+
+	moduleManual := newModuleManual(msg("whosOnFirst.title"), msg("whosOnFirst.instructions"), manualRules)
+	moduleManual.ModuleData = map[string]interface{}{
+		"readPositions": readPositions,
+		"pressOrders":   ruleSet.PressOrder,
+	}
+
+	return ruleSet, moduleManual
+}
+
+// Type returns the module type
+func (wm *WhosOnFirstModule) Type() string {
+	return ModuleTypeWhosOnFirst
+}
+
+// Solved reports whether the Who's on First module is disarmed
+func (wm *WhosOnFirstModule) Solved() bool {
+	return wm.IsSolved
+}
+
+// View returns the defuser-facing state of the Who's on First module
+func (wm *WhosOnFirstModule) View() interface{} {
+	return wm.DefuserView()
+}
+
+// Manual returns the manual the Who's on First module was generated with
+func (wm *WhosOnFirstModule) Manual() *ModuleManual {
+	return wm.manual
+}
+
+// HandleAction applies a defuser action to the Who's on First module
+// Supports "press" with a button position
+func (wm *WhosOnFirstModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "press" {
+		return ActionResult{}, unknownActionError(ModuleTypeWhosOnFirst, action)
+	}
+
+	var data struct {
+		Position int `json:"position"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	if data.Position < 0 || data.Position >= len(wm.Labels) {
+		return ActionResult{}, fmt.Errorf("invalid button position")
+	}
+	return actionOutcome(wm.Press(data.Position)), nil
+}
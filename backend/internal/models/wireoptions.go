@@ -0,0 +1,15 @@
+package models
+
+// SetStripedWires sets whether wires can have a stripe of a second color
+func (gs *GameSession) SetStripedWires(enabled bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.StripedWires = enabled
+}
+
+// GetStripedWires returns whether wires can be striped in a thread-safe way
+func (gs *GameSession) GetStripedWires() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.StripedWires
+}
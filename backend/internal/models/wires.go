@@ -1,9 +1,14 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"math/rand"
 )
 
+// ErrWireAlreadyCut is returned when cutting a wire that was already cut; it is a no-op, not a strike
+var ErrWireAlreadyCut = errors.New("wire is already cut")
+
 // WireColor represents the color of a wire
 type WireColor string
 
@@ -15,90 +20,166 @@ const (
 	Yellow WireColor = "yellow"
 )
 
+// Wire is one wire of a wires module: its color, and the color of its stripe if it has one
+type Wire struct {
+	Color  WireColor `json:"color"`
+	Stripe WireColor `json:"stripe,omitempty"` // Empty for a plain wire
+}
+
+// Striped reports whether the wire has a stripe
+func (w Wire) Striped() bool {
+	return w.Stripe != ""
+}
+
+// wireColorsOf returns the colors of wires, stripes left out
+func wireColorsOf(wires []Wire) []WireColor {
+	colors := make([]WireColor, len(wires))
+	for i, wire := range wires {
+		colors[i] = wire.Color
+	}
+	return colors
+}
+
+// drawStripes gives about one wire in three a stripe of another color
+func drawStripes(rng *rand.Rand, wires []Wire) {
+	colors := []WireColor{Red, Blue, Green, White, Yellow}
+	for i := range wires {
+		if rng.Intn(3) != 0 {
+			continue
+		}
+		stripe := colors[rng.Intn(len(colors)-1)]
+		if stripe == wires[i].Color {
+			stripe = colors[len(colors)-1] // The one color the draw above leaves out
+		}
+		wires[i].Stripe = stripe
+	}
+}
+
 // WiresModule represents the wires module on the bomb
 type WiresModule struct {
-	Wires      []WireColor  `json:"wires"`
-	CutWires   []int        `json:"cutWires"` // Indices of cut wires
-	IsSolved   bool         `json:"isSolved"`
-	CorrectCut int          `json:"correctCut"` // Index of the correct wire to cut
-	RuleSet    *WireRuleSet `json:"-"`          // Rules for this module (not serialized)
+	moduleIdentity
+	Wires      []Wire        `json:"wires"`
+	WireColors []WireColor   `json:"wireColors"` // Colors of Wires, for clients that read a flat color list
+	CutWires   []WireCut     `json:"cutWires"`   // Cut wires, in cut order
+	IsSolved   bool          `json:"isSolved"`
+	CorrectCut int           `json:"correctCut"` // Index of the correct wire to cut
+	RuleSet    *WireRuleSet  `json:"-"`          // Rules for this module (not serialized)
+	manual     *ModuleManual // Manual generated alongside the module
+}
+
+// WireCut is a cut wire and who cut it
+type WireCut struct {
+	Index int `json:"index"`
+	ActionRecord
+}
+
+// WiresModuleView is the defuser-facing view of a wires module (no solution data)
+type WiresModuleView struct {
+	ID         string      `json:"id"` // Stable module ID
+	Wires      []Wire      `json:"wires"`
+	WireColors []WireColor `json:"wireColors"` // Colors of Wires, for clients that read a flat color list
+	CutWires   []int       `json:"cutWires"`
+	IsSolved   bool        `json:"isSolved"`
+}
+
+// DefuserView returns the wires module state that can be shown to the defuser
+func (wm *WiresModule) DefuserView() *WiresModuleView {
+	return &WiresModuleView{
+		ID:         wm.ID,
+		Wires:      wm.Wires,
+		WireColors: wm.WireColors,
+		CutWires:   wm.CutIndices(),
+		IsSolved:   wm.IsSolved,
+	}
+}
+
+// CutIndices returns the indices of the cut wires, in cut order
+func (wm *WiresModule) CutIndices() []int {
+	indices := make([]int, len(wm.CutWires))
+	for i, cut := range wm.CutWires {
+		indices[i] = cut.Index
+	}
+	return indices
 }
 
-// NewWiresModule creates a new wires module with random wire configuration
-func NewWiresModule() *WiresModule {
+// ActionLog returns every wire cut with who cut it
+func (wm *WiresModule) ActionLog() []AttributedAction {
+	log := make([]AttributedAction, len(wm.CutWires))
+	for i, cut := range wm.CutWires {
+		wire := cut.Index
+		log[i] = AttributedAction{Action: "cut", Wire: &wire, ActionRecord: cut.ActionRecord}
+	}
+	return log
+}
+
+// NewWiresModule creates a new wires module with a wire configuration drawn from rng
+func NewWiresModule(rng *rand.Rand) *WiresModule {
 	// Generate 3-6 wires randomly
-	numWires := rand.Intn(4) + 3 // 3-6 wires
+	numWires := rng.Intn(4) + 3 // 3-6 wires
 	colors := []WireColor{Red, Blue, Green, White, Yellow}
 
-	wires := make([]WireColor, numWires)
+	wires := make([]Wire, numWires)
 	for i := 0; i < numWires; i++ {
-		wires[i] = colors[rand.Intn(len(colors))]
+		wires[i] = Wire{Color: colors[rng.Intn(len(colors))]}
 	}
 
 	module := &WiresModule{
-		Wires:    wires,
-		CutWires: []int{},
-		IsSolved: false,
+		Wires:      wires,
+		WireColors: wireColorsOf(wires),
+		CutWires:   []WireCut{},
+		IsSolved:   false,
 	}
 
-	module.CorrectCut = module.determineCorrectWire()
+	module.CorrectCut = module.determineCorrectWire(nil)
 	return module
 }
 
-// NewWiresModuleWithRules creates a new wires module with random wire configuration and generates rules based on wire count
+// NewWiresModuleWithRules creates a new wires module with random wire configuration and picks the rules for its wire count
 // wireSeed: seed for generating random wire configuration (different for each module)
-// ruleSeed: seed for generating rules (same for all modules to match the manual)
+// ruleSets: the bomb's rules per wire count (see GenerateWireRuleSets), shared with the manual
+// ctx: the bomb's edgework, used by rules about the serial number
 // Returns the module and its corresponding manual
-func NewWiresModuleWithRules(wireSeed int64, ruleSeed int64) (*WiresModule, *ModuleManual) {
+func NewWiresModuleWithRules(wireSeed int64, ruleSets map[int]*WireRuleSet, ctx *BombContext) (*WiresModule, *ModuleManual) {
 	// Create a seeded RNG for wire generation using the wireSeed (unique per module)
 	rng := rand.New(rand.NewSource(wireSeed))
-	
+
 	// Generate 3-6 wires randomly
-	numWires := rng.Intn(4) + 3 // 3-6 wires
+	numWires := rng.Intn(MaxWires-MinWires+1) + MinWires
 	colors := []WireColor{Red, Blue, Green, White, Yellow}
 
-	wires := make([]WireColor, numWires)
+	wires := make([]Wire, numWires)
 	for i := 0; i < numWires; i++ {
-		wires[i] = colors[rng.Intn(len(colors))]
+		wires[i] = Wire{Color: colors[rng.Intn(len(colors))]}
 	}
 
-	// Generate rules and manual based on the number of wires using ruleSeed (same for all modules)
-	// Use ruleSeed + numWires to get the same rules as in the comprehensive manual for this wire count
-	ruleSet, moduleManual := GenerateWireModuleRulesWithSeed(numWires, ruleSeed+int64(numWires))
+	// Same rules as the comprehensive manual's section for this wire count
+	ruleSet := ruleSets[numWires]
+	moduleManual := ruleSet.Manual()
+
+	// Rules about stripes come with striped wires
+	if ruleSet.Striped {
+		drawStripes(rng, wires)
+	}
 
 	module := &WiresModule{
-		Wires:    wires,
-		CutWires: []int{},
-		IsSolved: false,
-		RuleSet:  ruleSet,
+		Wires:      wires,
+		WireColors: wireColorsOf(wires),
+		CutWires:   []WireCut{},
+		IsSolved:   false,
+		RuleSet:    ruleSet,
+		manual:     moduleManual,
 	}
 
-	module.CorrectCut = module.determineCorrectWire()
+	module.CorrectCut = module.determineCorrectWire(ctx)
 	return module, moduleManual
 }
 
 // determineCorrectWire calculates which wire should be cut based on rules
-func (wm *WiresModule) determineCorrectWire() int {
+// ctx may be nil, in which case rules about the bomb's edgework never match
+func (wm *WiresModule) determineCorrectWire(ctx *BombContext) int {
 	// If rules are available, use them
 	if wm.RuleSet != nil && len(wm.RuleSet.Rules) > 0 {
-		// Evaluate rules in order
-		for _, rule := range wm.RuleSet.Rules {
-			result := rule.Evaluator(wm.Wires)
-			if result >= 0 {
-				return result
-			}
-		}
-		// No rule matched, use default rule (should be the last rule in the set)
-		// The default rule evaluator always returns a valid wire index
-		if len(wm.RuleSet.Rules) > 0 {
-			lastRule := wm.RuleSet.Rules[len(wm.RuleSet.Rules)-1]
-			result := lastRule.Evaluator(wm.Wires)
-			if result >= 0 {
-				return result
-			}
-		}
-		// Fallback: cut last wire (shouldn't happen if default rule is properly set)
-		return len(wm.Wires) - 1
+		return wm.RuleSet.CorrectCut(wm.Wires, ctx)
 	}
 
 	// Fallback to old static rules for backward compatibility
@@ -107,7 +188,7 @@ func (wm *WiresModule) determineCorrectWire() int {
 	// Rule 1: If there are no red wires, cut the second wire
 	hasRed := false
 	for _, wire := range wm.Wires {
-		if wire == Red {
+		if wire.Color == Red {
 			hasRed = true
 			break
 		}
@@ -117,7 +198,7 @@ func (wm *WiresModule) determineCorrectWire() int {
 	}
 
 	// Rule 2: If the last wire is white, cut the last wire
-	if wm.Wires[numWires-1] == White {
+	if wm.Wires[numWires-1].Color == White {
 		return numWires - 1
 	}
 
@@ -125,7 +206,7 @@ func (wm *WiresModule) determineCorrectWire() int {
 	blueCount := 0
 	lastBlueIndex := -1
 	for i, wire := range wm.Wires {
-		if wire == Blue {
+		if wire.Color == Blue {
 			blueCount++
 			lastBlueIndex = i
 		}
@@ -138,24 +219,64 @@ func (wm *WiresModule) determineCorrectWire() int {
 	return numWires - 1
 }
 
-// CutWire attempts to cut a wire at the given index
-// Returns true if correct, false if wrong (strike)
-func (wm *WiresModule) CutWire(index int) bool {
+// CutWire attempts to cut a wire at the given index, by records who cut it
+// Returns true if correct, false if wrong (strike), or ErrWireAlreadyCut if nothing happened
+func (wm *WiresModule) CutWire(index int, by ActionRecord) (bool, error) {
 	// Check if wire is already cut
-	for _, cutIndex := range wm.CutWires {
-		if cutIndex == index {
-			return false // Already cut
+	for _, cut := range wm.CutWires {
+		if cut.Index == index {
+			return false, ErrWireAlreadyCut
 		}
 	}
 
 	// Add to cut wires
-	wm.CutWires = append(wm.CutWires, index)
+	wm.CutWires = append(wm.CutWires, WireCut{Index: index, ActionRecord: by})
 
 	// Check if correct wire was cut
 	if index == wm.CorrectCut {
 		wm.IsSolved = true
-		return true
+		return true, nil
+	}
+
+	return false, nil // Wrong wire = strike
+}
+
+// Type returns the module type
+func (wm *WiresModule) Type() string {
+	return ModuleTypeWires
+}
+
+// Solved reports whether the wires module is disarmed
+func (wm *WiresModule) Solved() bool {
+	return wm.IsSolved
+}
+
+// View returns the defuser-facing state of the wires module
+func (wm *WiresModule) View() interface{} {
+	return wm.DefuserView()
+}
+
+// Manual returns the manual the wires module was generated with
+func (wm *WiresModule) Manual() *ModuleManual {
+	return wm.manual
+}
+
+// HandleAction applies a defuser action to the wires module
+// Supports "cut" with a wireIndex
+func (wm *WiresModule) HandleAction(action string, payload json.RawMessage, ctx *ActionContext) (ActionResult, error) {
+	if action != "cut" {
+		return ActionResult{}, unknownActionError(ModuleTypeWires, action)
 	}
 
-	return false // Wrong wire = strike
+	var data struct {
+		WireIndex int `json:"wireIndex"`
+	}
+	if err := decodeActionPayload(payload, &data); err != nil {
+		return ActionResult{}, err
+	}
+	correct, err := wm.CutWire(data.WireIndex, ctx.By)
+	if err != nil {
+		return ActionResult{}, err
+	}
+	return actionOutcome(correct), nil
 }
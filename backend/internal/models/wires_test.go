@@ -0,0 +1,218 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// followWireManual reads the comprehensive wires manual like an expert would: it finds the
+// section for the wire count and applies the first rule whose condition holds
+// It only uses the structured rules, not the evaluators the module is judged with
+func followWireManual(t *testing.T, manual *ModuleManual, wires []Wire, ctx *BombContext) int {
+	t.Helper()
+	section := MinWires - 1
+	for _, rule := range manual.Rules {
+		if rule.Condition == nil {
+			if rule.Description != "" {
+				section++ // Section header
+			}
+			continue
+		}
+		if section == len(wires) && manualConditionHolds(t, rule.Condition, wires, ctx) {
+			return manualWireToCut(t, rule.Action, wires)
+		}
+	}
+	t.Fatalf("no rule of the manual applies to %d wires", len(wires))
+	return -1
+}
+
+// manualConditionHolds evaluates a structured wires manual condition
+func manualConditionHolds(t *testing.T, c *RuleCondition, wires []Wire, ctx *BombContext) bool {
+	color := WireColor(param(c.Params, "color"))
+	count := func(match func(Wire) bool) int {
+		n := 0
+		for _, wire := range wires {
+			if match(wire) {
+				n++
+			}
+		}
+		return n
+	}
+
+	switch c.Type {
+	case ConditionNoWires:
+		return count(func(w Wire) bool { return w.Color == color }) == 0
+	case ConditionMoreThanOneWire:
+		return count(func(w Wire) bool { return w.Color == color }) > 1
+	case ConditionFirstWireIs:
+		return wires[0].Color == color
+	case ConditionLastWireIs:
+		return wires[len(wires)-1].Color == color
+	case ConditionAnyStripe:
+		return count(func(w Wire) bool { return w.Stripe == color }) > 0
+	case ConditionNoStripes:
+		return count(Wire.Striped) == 0
+	case ConditionWireCount:
+		return c.Params["count"] == len(wires)
+	case ConditionSerialOdd:
+		return ctx.SerialLastDigit()%2 == 1
+	case ConditionSerialEven:
+		digit := ctx.SerialLastDigit()
+		return digit >= 0 && digit%2 == 0
+	case ConditionSerialVowel:
+		return ctx.SerialHasVowel()
+	case ConditionBatteriesMoreThan:
+		return ctx.Batteries > c.Params["count"].(int)
+	case ConditionNoBatteries:
+		return ctx.Batteries == 0
+	case ConditionLitIndicator:
+		return ctx.HasLitIndicator(param(c.Params, "label"))
+	case ConditionAll:
+		for _, part := range c.Conditions {
+			if !manualConditionHolds(t, part, wires, ctx) {
+				return false
+			}
+		}
+		return true
+	}
+	t.Fatalf("unknown wires condition %q", c.Type)
+	return false
+}
+
+// manualWireToCut returns the wire a structured wires manual action cuts
+func manualWireToCut(t *testing.T, a *RuleAction, wires []Wire) int {
+	switch a.Type {
+	case ActionCutWire:
+		return a.Params["wire"].(int) - 1
+	case ActionCutLastWire:
+		return len(wires) - 1
+	case ActionCutFirstStriped:
+		for i, wire := range wires {
+			if wire.Striped() {
+				return i
+			}
+		}
+	}
+	t.Fatalf("wires action %q cuts no wire", a.Type)
+	return -1
+}
+
+func TestWireManualMatchesCorrectCut(t *testing.T) {
+	tests := []struct {
+		difficulty Difficulty
+		striped    bool
+	}{
+		{DifficultyEasy, false},
+		{DifficultyNormal, false},
+		{DifficultyHard, false},
+		{DifficultyExpert, false},
+		{DifficultyNormal, true},
+		{DifficultyExpert, true},
+	}
+	for _, tt := range tests {
+		name := string(tt.difficulty)
+		if tt.striped {
+			name += " striped"
+		}
+		t.Run(name, func(t *testing.T) {
+			for seed := int64(0); seed < 200; seed++ {
+				bomb := NewBomb("BOMB", BombConfig{
+					TimeLimit:    300,
+					ModuleCount:  6,
+					MaxStrikes:   3,
+					ModuleMix:    map[string]int{ModuleTypeWires: 6},
+					Difficulty:   tt.difficulty,
+					StripedWires: tt.striped,
+				}, rand.New(rand.NewSource(seed)))
+				ctx := &BombContext{SerialNumber: bomb.SerialNumber, Batteries: bomb.Batteries, Indicators: bomb.Indicators}
+				manual := bomb.ModuleRules["wireModule"]
+
+				for i, module := range bomb.WiresModules {
+					if got := followWireManual(t, manual, module.Wires, ctx); got != module.CorrectCut {
+						t.Fatalf("seed %d module %d %+v: the manual cuts wire %d, the module expects %d", seed, i, module.Wires, got, module.CorrectCut)
+					}
+				}
+			}
+		})
+	}
+}
+
+// conditionTypes returns the types of a condition and of the conditions it combines
+func conditionTypes(c *RuleCondition) []string {
+	if c == nil {
+		return nil
+	}
+	types := []string{c.Type}
+	for _, part := range c.Conditions {
+		types = append(types, conditionTypes(part)...)
+	}
+	return types
+}
+
+func TestStripedWireRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		difficulty Difficulty
+		striped    bool
+	}{
+		{"plain", DifficultyNormal, false},
+		{"plain expert", DifficultyExpert, false},
+		{"striped", DifficultyNormal, true},
+		{"striped expert", DifficultyExpert, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := difficultyPresets[tt.difficulty].Rules
+			opts.StripedWires = tt.striped
+			stripeRules := 0
+			for seed := int64(0); seed < 200; seed++ {
+				for wireCount, ruleSet := range GenerateWireRuleSets(seed, opts) {
+					for _, rule := range ruleSet.Rules {
+						impliesStripe, aboutStripes := false, false
+						for _, conditionType := range conditionTypes(rule.Condition) {
+							impliesStripe = impliesStripe || conditionType == ConditionAnyStripe
+							aboutStripes = aboutStripes || conditionType == ConditionAnyStripe || conditionType == ConditionNoStripes
+						}
+						cutsStriped := rule.Action.Type == ActionCutFirstStriped
+						if (aboutStripes || cutsStriped) && !tt.striped {
+							t.Fatalf("seed %d, %d wires: rule %q is about stripes without striped wires", seed, wireCount, rule.Description)
+						}
+						if cutsStriped && !impliesStripe {
+							t.Fatalf("seed %d, %d wires: rule %q cuts a striped wire there may not be", seed, wireCount, rule.Description)
+						}
+						if aboutStripes || cutsStriped {
+							stripeRules++
+						}
+					}
+				}
+			}
+			if tt.striped && stripeRules == 0 {
+				t.Error("no rule is about stripes")
+			}
+		})
+	}
+}
+
+func TestDrawStripes(t *testing.T) {
+	wires, striped := 0, 0
+	for seed := int64(0); seed < 200; seed++ {
+		drawn := make([]Wire, MaxWires)
+		for i, color := range []WireColor{Red, Blue, Green, White, Yellow, Red} {
+			drawn[i].Color = color
+		}
+		drawStripes(rand.New(rand.NewSource(seed)), drawn)
+		for _, wire := range drawn {
+			wires++
+			if !wire.Striped() {
+				continue
+			}
+			striped++
+			if wire.Stripe == wire.Color {
+				t.Fatalf("seed %d: %s wire with a stripe of its own color", seed, wire.Color)
+			}
+		}
+	}
+	if share := float64(striped) / float64(wires); share < 0.25 || share > 0.42 {
+		t.Errorf("%d of %d wires striped, want about one in three", striped, wires)
+	}
+}
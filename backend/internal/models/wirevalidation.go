@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// wireValidationLayouts is how many random wire layouts a rule set is checked against
+const wireValidationLayouts = 512
+
+// maxWireRuleRerolls bounds how many derived seeds are tried before keeping a set that failed validation
+const maxWireRuleRerolls = 20
+
+// wireValidationContexts covers every combination the edgework conditions look at:
+// odd or even last serial digit, with or without a vowel
+var wireValidationContexts = []*BombContext{
+	{SerialNumber: "AC3DE5"},
+	{SerialNumber: "BC3DF5"},
+	{SerialNumber: "AC3DE4"},
+	{SerialNumber: "BC3DF4"},
+}
+
+// validate checks that the rule set reads unambiguously with first-match semantics
+// Over a sample of wire layouts and bomb contexts, the first matching rule must dictate an
+// existing wire, and every conditional rule must decide at least one layout: be its first
+// match and dictate a different wire than the rules below it would
+func (rs *WireRuleSet) validate(rng *rand.Rand) error {
+	colors := []WireColor{Red, Blue, Green, White, Yellow}
+	decides := make([]bool, len(rs.Rules))
+
+	wires := make([]Wire, rs.WireCount)
+	for sample := 0; sample < wireValidationLayouts; sample++ {
+		for i := range wires {
+			wires[i] = Wire{Color: colors[rng.Intn(len(colors))]}
+		}
+		if rs.Striped {
+			drawStripes(rng, wires)
+		}
+		for _, ctx := range wireValidationContexts {
+			first, cut := rs.firstMatch(wires, ctx, 0)
+			if first < 0 {
+				return fmt.Errorf("no rule matches %v", wires)
+			}
+			if cut >= rs.WireCount {
+				return fmt.Errorf("rule %d cuts wire %d of %d", first+1, cut+1, rs.WireCount)
+			}
+			if !rs.Rules[first].Default {
+				if _, fallback := rs.firstMatch(wires, ctx, first+1); fallback != cut {
+					decides[first] = true
+				}
+			}
+		}
+	}
+
+	for i, rule := range rs.Rules {
+		if !rule.Default && !decides[i] {
+			return fmt.Errorf("rule %d never changes which wire is cut: %s", i+1, rule.Description)
+		}
+	}
+	return nil
+}
+
+// firstMatch returns the index of the first rule from start on that matches, and the wire it cuts
+func (rs *WireRuleSet) firstMatch(wires []Wire, ctx *BombContext, start int) (int, int) {
+	for i := start; i < len(rs.Rules); i++ {
+		if cut := rs.Rules[i].Evaluator(wires, ctx); cut >= 0 {
+			return i, cut
+		}
+	}
+	return -1, -1
+}
@@ -0,0 +1,237 @@
+// Package pdf writes simple paginated text documents as PDF
+// It only uses the standard Helvetica fonts, so no font has to be embedded
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page geometry, in points (A4)
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	margin       = 56.0
+	footerHeight = 24.0
+	textWidth    = pageWidth - 2*margin
+	firstLine    = pageHeight - margin // Baseline of the first line of a page
+)
+
+// Text styles
+const (
+	headingSize    = 16.0
+	subheadingSize = 12.0
+	bodySize       = 11.0
+	footerSize     = 8.0
+	lineSpacing    = 1.35 // Line height as a multiple of the font size
+	numberIndent   = 24.0 // Room left for the number of a numbered paragraph
+	boldWidthRate  = 1.08 // Helvetica-Bold is a bit wider than the widths table below
+)
+
+// helveticaWidths are the widths of the printable ASCII characters in Helvetica,
+// in thousandths of the font size, starting at the space
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// winAnsiExtras maps the characters of WinAnsiEncoding outside Latin-1 to their byte
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// Document is a PDF being laid out, one line of text at a time
+type Document struct {
+	pages []*bytes.Buffer // Content stream of each page, footers are added when writing
+	y     float64         // Baseline of the next line on the last page
+}
+
+// New creates an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// NewPage starts a new page, unless the last page is still empty
+func (d *Document) NewPage() {
+	if len(d.pages) > 0 && d.y == firstLine {
+		return
+	}
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = firstLine
+}
+
+// Heading adds a bold title, with some room above it unless it starts the page
+func (d *Document) Heading(text string) {
+	if len(d.pages) > 0 && d.y != firstLine {
+		d.y -= bodySize
+	}
+	d.write("F2", headingSize, margin, wrap(text, textWidth, headingSize*boldWidthRate))
+	d.y -= bodySize / 2
+}
+
+// Subheading adds a smaller bold title inside a section
+func (d *Document) Subheading(text string) {
+	if len(d.pages) > 0 && d.y != firstLine {
+		d.y -= bodySize / 2
+	}
+	d.write("F2", subheadingSize, margin, wrap(text, textWidth, subheadingSize*boldWidthRate))
+	d.y -= bodySize / 4
+}
+
+// Paragraph adds wrapped text, keeping the line breaks it has
+func (d *Document) Paragraph(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		d.write("F1", bodySize, margin, wrap(line, textWidth, bodySize))
+	}
+	d.y -= bodySize / 2
+}
+
+// Numbered adds a paragraph with its number in the margin, as in a numbered list
+func (d *Document) Numbered(number int, text string) {
+	lines := wrap(text, textWidth-numberIndent, bodySize)
+	d.ensureRoom(bodySize)
+	d.text("F1", bodySize, margin, d.y, fmt.Sprintf("%d.", number))
+	d.write("F1", bodySize, margin+numberIndent, lines)
+	d.y -= bodySize / 4
+}
+
+// Bytes returns the document as a PDF file
+// footer is called for every page with its number (from 1) and the page count
+func (d *Document) Bytes(footer func(page, pages int) string) []byte {
+	if len(d.pages) == 0 {
+		d.NewPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are the catalog, the page tree and the fonts, then each page and its content
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		content := bytes.NewBuffer(append([]byte(nil), page.Bytes()...))
+		if footer != nil {
+			text := footer(i+1, len(d.pages))
+			x := pageWidth - margin - width(text, footerSize)
+			writeText(content, "F1", footerSize, x, margin-footerHeight, text)
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// write adds lines at x, one under the other, breaking pages as needed
+func (d *Document) write(font string, size, x float64, lines []string) {
+	for _, line := range lines {
+		d.ensureRoom(size)
+		d.text(font, size, x, d.y, line)
+		d.y -= size * lineSpacing
+	}
+}
+
+// ensureRoom starts a new page if a line of the given size doesn't fit above the footer
+func (d *Document) ensureRoom(size float64) {
+	if len(d.pages) == 0 || d.y-size < margin {
+		d.pages = append(d.pages, &bytes.Buffer{})
+		d.y = firstLine
+	}
+}
+
+// text draws text on the last page with its baseline at y
+func (d *Document) text(font string, size, x, y float64, text string) {
+	writeText(d.pages[len(d.pages)-1], font, size, x, y, text)
+}
+
+// writeText appends the operators drawing text to a content stream
+func writeText(content *bytes.Buffer, font string, size, x, y float64, text string) {
+	fmt.Fprintf(content, "BT /%s %.1f Tf %.2f %.2f Td (", font, size, x, y)
+	content.Write(encode(text))
+	content.WriteString(") Tj ET\n")
+}
+
+// encode converts text to WinAnsiEncoding, escaped for a PDF string
+// Characters the encoding doesn't have are replaced by a question mark
+func encode(text string) []byte {
+	var out []byte
+	for _, r := range text {
+		var b byte
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			out = append(out, '\\')
+			b = byte(r)
+		case r >= ' ' && r <= '~', r >= 0xA0 && r <= 0xFF:
+			b = byte(r)
+		case r == '\t':
+			b = ' '
+		default:
+			extra, ok := winAnsiExtras[r]
+			if !ok {
+				extra = '?'
+			}
+			b = extra
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// width returns how wide text is in Helvetica of the given size, in points
+func width(text string, size float64) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= ' ' && r <= '~' {
+			total += helveticaWidths[r-' ']
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// wrap breaks text into lines no wider than maxWidth
+// A word longer than a line is left on a line of its own
+func wrap(text string, maxWidth, size float64) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := words[0]
+	for _, word := range words[1:] {
+		if width(line+" "+word, size) > maxWidth {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
@@ -2,21 +2,77 @@ package service
 
 import (
 	"bombs/internal/models"
+	"bombs/internal/storage"
+	"bombs/internal/utils"
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 )
 
+// DefaultCodeQuarantine is how long a session code stays reserved after its session ends
+const DefaultCodeQuarantine = 30 * time.Minute
+
+// DefaultEmptySessionTTL is how long a session with no connected players is kept before it is deleted
+const DefaultEmptySessionTTL = 30 * time.Minute
+
+// maxSessionIDAttempts bounds the retries when generating a free session ID
+const maxSessionIDAttempts = 100
+
+var (
+	// ErrSessionNotFound is returned when no session ever used the code (or its quarantine is over)
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionEnded is returned when the code belonged to a session that recently ended
+	ErrSessionEnded = errors.New("this game has ended")
+)
+
 // GameService manages all game sessions
 type GameService struct {
-	sessions map[string]*models.GameSession
-	mu       sync.RWMutex
+	sessions          map[string]*models.GameSession
+	hostSessions      map[string]map[string]bool // host ID -> IDs of sessions created with it, true if the creator proved the ID
+	tombstones        map[string]time.Time       // ended session ID -> end of its quarantine
+	codeQuarantine    time.Duration
+	emptyTTL          time.Duration         // How long sessions nobody is connected to are kept
+	releaseWindow     time.Duration         // How far on each side of a button release the target digit is accepted
+	leaderboard       Leaderboard           // Fastest defusals over every session
+	recorder          *storage.Recorder     // Persists finished games, nil when persistence is off
+	defaultWebhookURL string                // Called when a game ends in sessions without their own webhook
+	sessionIDFormat   utils.SessionIDFormat // How generated session IDs look
+	hostTokenSecret   []byte                // Signs host tokens
+	allowLegacyHostID bool                  // Accept the bare host ID as host credentials (compatibility)
+	rng               *rand.Rand            // Seeds the random source of each new session
+	ctx               context.Context
+	cancel            context.CancelFunc
+	loopDone          chan struct{} // Closed when the update loop has stopped
+	mu                sync.RWMutex
 }
 
 // NewGameService creates a new game service
 func NewGameService() *GameService {
+	secret, err := newHostTokenSecret()
+	if err != nil {
+		// The system random source failing leaves nothing safe to sign with
+		panic(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	gs := &GameService{
-		sessions: make(map[string]*models.GameSession),
+		sessions:          make(map[string]*models.GameSession),
+		hostSessions:      make(map[string]map[string]bool),
+		tombstones:        make(map[string]time.Time),
+		codeQuarantine:    DefaultCodeQuarantine,
+		emptyTTL:          DefaultEmptySessionTTL,
+		releaseWindow:     models.DefaultReleaseWindow,
+		sessionIDFormat:   utils.SessionIDNumeric,
+		leaderboard:       NewMemoryLeaderboard(),
+		hostTokenSecret:   secret,
+		allowLegacyHostID: true,
+		rng:               rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:               ctx,
+		cancel:            cancel,
+		loopDone:          make(chan struct{}),
 	}
 
 	// Start background task to update bomb timers
@@ -25,18 +81,162 @@ func NewGameService() *GameService {
 	return gs
 }
 
-// CreateSession creates a new game session in lobby state
-func (gs *GameService) CreateSession(sessionID string, hostID string, timeLimit int) *models.GameSession {
+// SetCodeQuarantine sets how long codes of ended sessions are kept out of circulation
+func (gs *GameService) SetCodeQuarantine(d time.Duration) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.codeQuarantine = d
+}
+
+// SetSessionIDFormat sets how session IDs of new sessions look, existing sessions keep theirs
+func (gs *GameService) SetSessionIDFormat(format utils.SessionIDFormat) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.sessionIDFormat = format
+}
+
+// SetRandSeed reseeds the random source sessions are created from
+// Sessions created after it get the same bombs and defuser picks for the same seed
+func (gs *GameService) SetRandSeed(seed int64) {
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
+	gs.rng = rand.New(rand.NewSource(seed))
+}
+
+// SetEmptySessionTTL sets how long a session with no connected players is kept before it is deleted
+func (gs *GameService) SetEmptySessionTTL(d time.Duration) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.emptyTTL = d
+}
 
-	session := models.NewGameSession(sessionID, hostID, timeLimit)
+// SetReleaseWindow sets how far on each side of a button release the target digit is accepted
+// It applies to the sessions created afterwards
+func (gs *GameService) SetReleaseWindow(d time.Duration) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.releaseWindow = d
+}
+
+// CreateSession creates a new game session in lobby state with a fresh session ID
+// IDs of live sessions and quarantined IDs of ended sessions are never handed out
+// hostProven tells whether the creator owns hostID (a new ID, or one its host token verified);
+// only the sessions of a proven host ID vouch for it in VerifyHostIdentity
+func (gs *GameService) CreateSession(hostID string, timeLimit int, hostProven bool) (*models.GameSession, error) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	sessionID, err := gs.generateSessionIDLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	session := models.NewGameSession(sessionID, hostID, timeLimit, rand.New(rand.NewSource(gs.rng.Int63())))
+	session.ReleaseWindow = gs.releaseWindow
 	gs.sessions[sessionID] = session
-	return session
+
+	// Index the session under the host that created it
+	if gs.hostSessions[hostID] == nil {
+		gs.hostSessions[hostID] = make(map[string]bool)
+	}
+	gs.hostSessions[hostID][sessionID] = hostProven
+
+	return session, nil
+}
+
+// generateSessionIDLocked picks a session ID that is neither live nor quarantined (caller must hold gs.mu)
+func (gs *GameService) generateSessionIDLocked() (string, error) {
+	now := time.Now()
+	for attempt := 0; attempt < maxSessionIDAttempts; attempt++ {
+		sessionID, err := utils.GenerateSessionIDFormat(gs.sessionIDFormat)
+		if err != nil {
+			return "", err
+		}
+		if _, exists := gs.sessions[sessionID]; exists {
+			continue
+		}
+		if until, ended := gs.tombstones[sessionID]; ended {
+			if now.Before(until) {
+				continue
+			}
+			// Quarantine is over, the code can be recycled
+			delete(gs.tombstones, sessionID)
+		}
+		return sessionID, nil
+	}
+	return "", fmt.Errorf("failed to generate a free session ID")
+}
+
+// DeleteSession removes a session and drops it from the host index
+// The session is closed, which stops its broadcast loop and disconnects remaining players
+func (gs *GameService) DeleteSession(sessionID string) error {
+	sessionID = utils.NormalizeSessionID(sessionID)
+	gs.mu.Lock()
+	session, exists := gs.sessions[sessionID]
+	if !exists {
+		gs.mu.Unlock()
+		return ErrSessionNotFound
+	}
+
+	delete(gs.sessions, sessionID)
+	gs.unindexSessionLocked(session)
+
+	// Keep the code out of circulation so players typing it get "game ended"
+	// instead of landing in a different lobby
+	gs.tombstones[sessionID] = time.Now().Add(gs.codeQuarantine)
+	gs.mu.Unlock()
+
+	session.Close()
+	return nil
+}
+
+// LookupSession retrieves a live session by ID, distinguishing codes of
+// recently ended sessions (ErrSessionEnded) from unknown ones (ErrSessionNotFound)
+func (gs *GameService) LookupSession(sessionID string) (*models.GameSession, error) {
+	sessionID = utils.NormalizeSessionID(sessionID)
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	if session, exists := gs.sessions[sessionID]; exists {
+		session.Touch()
+		return session, nil
+	}
+	if until, ended := gs.tombstones[sessionID]; ended && time.Now().Before(until) {
+		return nil, ErrSessionEnded
+	}
+	return nil, ErrSessionNotFound
+}
+
+// GetHostSessions returns the sessions created with the given host ID
+func (gs *GameService) GetHostSessions(hostID string) []*models.GameSession {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	sessions := make([]*models.GameSession, 0, len(gs.hostSessions[hostID]))
+	for sessionID := range gs.hostSessions[hostID] {
+		if session, exists := gs.sessions[sessionID]; exists {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// unindexSessionLocked removes a session from the host index (caller must hold gs.mu)
+func (gs *GameService) unindexSessionLocked(session *models.GameSession) {
+	for hostID, sessionIDs := range gs.hostSessions {
+		if _, indexed := sessionIDs[session.ID]; !indexed {
+			continue
+		}
+		delete(sessionIDs, session.ID)
+		if len(sessionIDs) == 0 {
+			delete(gs.hostSessions, hostID)
+		}
+	}
 }
 
 // StartGame starts the game for a session
 func (gs *GameService) StartGame(sessionID string) error {
+	sessionID = utils.NormalizeSessionID(sessionID)
 	gs.mu.RLock()
 	session, exists := gs.sessions[sessionID]
 	gs.mu.RUnlock()
@@ -50,6 +250,7 @@ func (gs *GameService) StartGame(sessionID string) error {
 
 // ReturnToLobby returns the game to lobby state
 func (gs *GameService) ReturnToLobby(sessionID string, hostID string) error {
+	sessionID = utils.NormalizeSessionID(sessionID)
 	gs.mu.RLock()
 	session, exists := gs.sessions[sessionID]
 	gs.mu.RUnlock()
@@ -65,31 +266,112 @@ func (gs *GameService) ReturnToLobby(sessionID string, hostID string) error {
 	return session.ReturnToLobby()
 }
 
-// GetSession retrieves a game session by ID
+// GetSession retrieves a game session by ID and records the request as activity on it
 func (gs *GameService) GetSession(sessionID string) (*models.GameSession, bool) {
+	sessionID = utils.NormalizeSessionID(sessionID)
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
 
 	session, exists := gs.sessions[sessionID]
+	if exists {
+		session.Touch()
+	}
 	return session, exists
 }
 
-// updateLoop periodically updates all active sessions
+// Context returns a context cancelled when the service shuts down
+// Goroutines serving sessions (e.g. broadcast loops) stop when it is done
+func (gs *GameService) Context() context.Context {
+	return gs.ctx
+}
+
+// Sessions returns a snapshot of the live sessions
+func (gs *GameService) Sessions() []*models.GameSession {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	sessions := make([]*models.GameSession, 0, len(gs.sessions))
+	for _, session := range gs.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// Shutdown stops the update loop and closes every session, disconnecting their players
+// Waits for the update loop to stop or for ctx to be done
+func (gs *GameService) Shutdown(ctx context.Context) error {
+	gs.cancel()
+	for _, session := range gs.Sessions() {
+		session.Close()
+	}
+
+	select {
+	case <-gs.loopDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// updateLoop periodically updates all active sessions until the service shuts down
 func (gs *GameService) updateLoop() {
+	defer close(gs.loopDone)
+
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		gs.mu.RLock()
-		sessions := make([]*models.GameSession, 0, len(gs.sessions))
-		for _, session := range gs.sessions {
-			sessions = append(sessions, session)
+	for {
+		select {
+		case <-gs.ctx.Done():
+			return
+		case <-ticker.C:
 		}
+
+		gs.pruneTombstones()
+
+		sessions := gs.Sessions()
+		gs.mu.RLock()
+		emptyTTL := gs.emptyTTL
 		gs.mu.RUnlock()
 
 		for _, session := range sessions {
+			// Clean up sessions nobody has been connected to for the TTL
+			emptySince := session.GetEmptySince()
+			if !emptySince.IsZero() && time.Since(emptySince) > emptyTTL {
+				gs.DeleteSession(session.ID)
+				continue
+			}
+
+			if emptySince.IsZero() {
+				// Connected players keep the session active even when they are idle
+				session.Touch()
+			}
 			session.Update()
 			// The WebSocket handler's broadcastLoop handles broadcasting updates
 		}
 	}
 }
+
+// Stats returns the number of live sessions and of players connected to them
+func (gs *GameService) Stats() (sessions int, players int) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	for _, session := range gs.sessions {
+		players += session.GetPlayerCount()
+	}
+	return len(gs.sessions), players
+}
+
+// pruneTombstones forgets ended session codes whose quarantine is over
+func (gs *GameService) pruneTombstones() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	for sessionID, until := range gs.tombstones {
+		if !now.Before(until) {
+			delete(gs.tombstones, sessionID)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"bombs/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newTestService returns a game service shut down at the end of the test
+func newTestService(t *testing.T) *GameService {
+	t.Helper()
+	gs := NewGameService()
+	t.Cleanup(func() { gs.Shutdown(context.Background()) })
+	return gs
+}
+
+// unusedSessionID is below the numeric range, so never generated
+const unusedSessionID = "0999"
+
+func TestLookupSessionCodeLifecycle(t *testing.T) {
+	tests := []struct {
+		name       string
+		quarantine time.Duration
+		code       func(t *testing.T, gs *GameService) string
+		wantErr    error
+	}{
+		{"live", time.Minute, func(t *testing.T, gs *GameService) string {
+			session, err := gs.CreateSession("host-1", 300, true)
+			if err != nil {
+				t.Fatalf("CreateSession: %v", err)
+			}
+			return session.ID
+		}, nil},
+		{"ended", time.Minute, func(t *testing.T, gs *GameService) string {
+			session, _ := gs.CreateSession("host-1", 300, true)
+			gs.DeleteSession(session.ID)
+			return session.ID
+		}, ErrSessionEnded},
+		{"quarantine over", -time.Second, func(t *testing.T, gs *GameService) string {
+			session, _ := gs.CreateSession("host-1", 300, true)
+			gs.DeleteSession(session.ID)
+			return session.ID
+		}, ErrSessionNotFound},
+		{"never used", time.Minute, func(t *testing.T, gs *GameService) string {
+			return unusedSessionID
+		}, ErrSessionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestService(t)
+			gs.SetCodeQuarantine(tt.quarantine)
+			code := tt.code(t, gs)
+
+			session, err := gs.LookupSession(code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupSession(%s) error = %v, want %v", code, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (session == nil || session.ID != code) {
+				t.Errorf("LookupSession(%s) = %v, want the live session", code, session)
+			}
+		})
+	}
+}
+
+func TestQuarantinedCodesAreNotRecycled(t *testing.T) {
+	tests := []struct {
+		name    string
+		until   time.Duration // End of every code's quarantine, from now
+		wantErr bool
+	}{
+		{"quarantined", time.Minute, true},
+		{"quarantine over", -time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := newTestService(t)
+			for code := 1000; code <= 9999; code++ {
+				gs.tombstones[fmt.Sprintf("%04d", code)] = time.Now().Add(tt.until)
+			}
+
+			session, err := gs.CreateSession("host-1", 300, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateSession error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if _, stillQuarantined := gs.tombstones[session.ID]; stillQuarantined {
+					t.Errorf("recycled code %s is still quarantined", session.ID)
+				}
+			}
+		})
+	}
+}
+
+// playGames plays rounds of games in a new session of the service, returning what each round
+// drew from the session's random source: the bomb and the defusers
+func playGames(t *testing.T, gs *GameService, rounds int) []string {
+	t.Helper()
+	session, err := gs.CreateSession("host-1", 300, true)
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	for _, id := range []string{"host-1", "player-2", "player-3", "player-4"} {
+		if err := session.AddPlayer(id, id, models.PlayerTypeDefuser, models.NewConnection()); err != nil {
+			t.Fatalf("AddPlayer(%s): %v", id, err)
+		}
+	}
+
+	var draws []string
+	for i := 0; i < rounds; i++ {
+		if err := session.StartGame(); err != nil {
+			t.Fatalf("round %d: StartGame: %v", i, err)
+		}
+		bomb := session.GetCurrentBomb()
+		bomb.ID = "" // Named after the session code, which no seed replays
+		drawn, _ := json.Marshal(bomb)
+		draws = append(draws, fmt.Sprintf("%s %v", drawn, session.GetDefuserIDs()))
+
+		session.LobbyState = models.LobbyStateActive // Play the round rather than cancel its countdown
+		if err := session.ReturnToLobby(); err != nil {
+			t.Fatalf("round %d: ReturnToLobby: %v", i, err)
+		}
+	}
+	return draws
+}
+
+func TestRandSeedReplaysGames(t *testing.T) {
+	tests := []struct {
+		name     string
+		seeds    [2]int64
+		wantSame bool
+	}{
+		{"same seed", [2]int64{42, 42}, true},
+		{"other seed", [2]int64{42, 43}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var games [2][]string
+			for i, seed := range tt.seeds {
+				gs := newTestService(t)
+				gs.SetRandSeed(seed)
+				games[i] = playGames(t, gs, 5)
+			}
+			for round := range games[0] {
+				if same := games[0][round] == games[1][round]; same != tt.wantSame {
+					t.Errorf("round %d: same game = %v, want %v\n%s\n%s", round, same, tt.wantSame, games[0][round], games[1][round])
+				}
+			}
+		})
+	}
+}
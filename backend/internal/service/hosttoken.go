@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bombs/internal/utils"
+	"crypto/rand"
+	"fmt"
+)
+
+// newHostTokenSecret generates the secret host tokens are signed with when none is configured
+// Tokens then stop being valid when the server restarts, along with the in-memory sessions
+func newHostTokenSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate host token secret: %w", err)
+	}
+	return secret, nil
+}
+
+// SetHostTokenSecret sets the secret host tokens are signed with, so they survive restarts
+func (gs *GameService) SetHostTokenSecret(secret []byte) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.hostTokenSecret = secret
+}
+
+// SetAllowLegacyHostID sets whether the bare host ID is still accepted as host credentials
+// (hostId query parameter, WebSocket messages without a token)
+func (gs *GameService) SetAllowLegacyHostID(allow bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.allowLegacyHostID = allow
+}
+
+// AllowLegacyHostID reports whether the bare host ID is still accepted as host credentials
+func (gs *GameService) AllowLegacyHostID() bool {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.allowLegacyHostID
+}
+
+// HostToken issues the token proving hostID hosts the session
+func (gs *GameService) HostToken(sessionID string, hostID string) string {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return utils.SignHostToken(gs.hostTokenSecret, sessionID, hostID)
+}
+
+// VerifyHostToken returns the host ID a token was issued to for the session
+// It doesn't check that the ID is still the session's host, IsHost does
+func (gs *GameService) VerifyHostToken(sessionID string, token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return utils.VerifyHostToken(gs.hostTokenSecret, utils.NormalizeSessionID(sessionID), token)
+}
+
+// VerifyHostIdentity returns the host ID a token proves for requests not about one session
+// (the host dashboard, creating a session under an existing host ID): a host token of any
+// live session created by that host is accepted, unless the session was created without
+// proving the host ID
+func (gs *GameService) VerifyHostIdentity(token string) (string, bool) {
+	hostID, ok := utils.HostTokenHostID(token)
+	if !ok {
+		return "", false
+	}
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	for sessionID, proven := range gs.hostSessions[hostID] {
+		if !proven {
+			continue
+		}
+		if _, ok := utils.VerifyHostToken(gs.hostTokenSecret, sessionID, token); ok {
+			return hostID, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"bombs/internal/models"
+	"bombs/internal/utils"
+)
+
+// JoinToken issues the token embedded in invite links to a private session
+// Returns "" for a public session, anyone can join it without one
+func (gs *GameService) JoinToken(session *models.GameSession) string {
+	hash := session.GetPasswordHash()
+	if hash == "" {
+		return ""
+	}
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return utils.SignJoinToken(gs.hostTokenSecret, session.ID, hash)
+}
+
+// CanJoin returns whether a player may join the session with the password or invite token they gave
+func (gs *GameService) CanJoin(session *models.GameSession, password string, joinToken string) bool {
+	if session.CheckPassword(password) {
+		return true
+	}
+	if joinToken == "" {
+		return false
+	}
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return utils.VerifyJoinToken(gs.hostTokenSecret, session.ID, session.GetPasswordHash(), joinToken)
+}
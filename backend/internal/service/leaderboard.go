@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bombs/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxLeaderboardEntries is how many entries are kept per module count and difficulty
+const maxLeaderboardEntries = 100
+
+// LeaderboardEntry is one defused bomb on the leaderboard
+type LeaderboardEntry struct {
+	ModuleCount   int               `json:"moduleCount"`
+	Difficulty    models.Difficulty `json:"difficulty"`
+	DefuseSeconds int               `json:"defuseSeconds"` // Bomb time used to defuse, strike penalties included
+	Strikes       int               `json:"strikes"`
+	Players       []string          `json:"players"` // Names of the players in the session
+	RecordedAt    time.Time         `json:"recordedAt"`
+}
+
+// Leaderboard ranks the fastest defusals per module count and difficulty
+// Implementations must be safe for concurrent use: games end on different goroutines
+type Leaderboard interface {
+	// Record adds an entry and returns its 1-based rank, or 0 if it didn't make the board
+	Record(entry LeaderboardEntry) int
+	// Top returns the best entries for a module count and difficulty, fastest first
+	Top(moduleCount int, difficulty models.Difficulty, limit int) []LeaderboardEntry
+}
+
+// leaderboardKey identifies one board
+type leaderboardKey struct {
+	moduleCount int
+	difficulty  models.Difficulty
+}
+
+// MemoryLeaderboard is a Leaderboard kept in memory, lost when the server restarts
+type MemoryLeaderboard struct {
+	boards map[leaderboardKey][]LeaderboardEntry
+	mu     sync.RWMutex
+}
+
+// NewMemoryLeaderboard creates an empty in-memory leaderboard
+func NewMemoryLeaderboard() *MemoryLeaderboard {
+	return &MemoryLeaderboard{
+		boards: make(map[leaderboardKey][]LeaderboardEntry),
+	}
+}
+
+// Record adds an entry and returns its 1-based rank, or 0 if it didn't make the board
+// Faster defusals rank first, then fewer strikes, then older entries
+func (lb *MemoryLeaderboard) Record(entry LeaderboardEntry) int {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	key := leaderboardKey{moduleCount: entry.ModuleCount, difficulty: entry.Difficulty}
+	board := lb.boards[key]
+	rank := sort.Search(len(board), func(i int) bool {
+		if board[i].DefuseSeconds != entry.DefuseSeconds {
+			return board[i].DefuseSeconds > entry.DefuseSeconds
+		}
+		return board[i].Strikes > entry.Strikes
+	})
+	if rank >= maxLeaderboardEntries {
+		return 0
+	}
+
+	board = append(board, LeaderboardEntry{})
+	copy(board[rank+1:], board[rank:])
+	board[rank] = entry
+	if len(board) > maxLeaderboardEntries {
+		board = board[:maxLeaderboardEntries]
+	}
+	lb.boards[key] = board
+	return rank + 1
+}
+
+// Top returns the best entries for a module count and difficulty, fastest first
+func (lb *MemoryLeaderboard) Top(moduleCount int, difficulty models.Difficulty, limit int) []LeaderboardEntry {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	board := lb.boards[leaderboardKey{moduleCount: moduleCount, difficulty: difficulty}]
+	if limit > len(board) {
+		limit = len(board)
+	}
+	return append([]LeaderboardEntry{}, board[:limit]...)
+}
+
+// RecordDefusals adds every bomb defused in the session's last game to the leaderboard
+// Practice games are left out. Returns the entries that made the board, with their rank
+func (gs *GameService) RecordDefusals(session *models.GameSession) []RankedEntry {
+	var placed []RankedEntry
+	for _, defusal := range session.GetDefusals() {
+		if defusal.Practice {
+			continue
+		}
+		entry := LeaderboardEntry{
+			ModuleCount:   defusal.ModuleCount,
+			Difficulty:    defusal.Difficulty,
+			DefuseSeconds: defusal.Seconds,
+			Strikes:       defusal.Strikes,
+			Players:       defusal.Players,
+			RecordedAt:    time.Now(),
+		}
+		if rank := gs.getLeaderboard().Record(entry); rank > 0 {
+			placed = append(placed, RankedEntry{Rank: rank, Entry: entry})
+		}
+	}
+	return placed
+}
+
+// RankedEntry is a leaderboard entry with the rank it got when it was recorded
+type RankedEntry struct {
+	Rank  int              `json:"rank"`
+	Entry LeaderboardEntry `json:"entry"`
+}
+
+// GetLeaderboard returns the best defusals for a module count and difficulty, fastest first
+func (gs *GameService) GetLeaderboard(moduleCount int, difficulty models.Difficulty, limit int) []LeaderboardEntry {
+	return gs.getLeaderboard().Top(moduleCount, difficulty, limit)
+}
+
+// SetLeaderboard replaces the leaderboard storage, e.g. with a persistent one
+func (gs *GameService) SetLeaderboard(leaderboard Leaderboard) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.leaderboard = leaderboard
+}
+
+// getLeaderboard returns the leaderboard storage in a thread-safe way
+func (gs *GameService) getLeaderboard() Leaderboard {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	return gs.leaderboard
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"bombs/internal/models"
+	"bombs/internal/storage"
+)
+
+// SetRecorder makes finished games persist through the recorder
+func (gs *GameService) SetRecorder(recorder *storage.Recorder) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.recorder = recorder
+}
+
+// GameStore returns the store finished games are persisted to, or nil if persistence is off
+func (gs *GameService) GameStore() storage.Store {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	if gs.recorder == nil {
+		return nil
+	}
+	return gs.recorder.Store()
+}
+
+// PersistGame queues a finished game of the session to be saved
+// Does nothing when persistence is off; never blocks on the database
+func (gs *GameService) PersistGame(sessionID string, result *models.GameResult) {
+	gs.mu.RLock()
+	recorder := gs.recorder
+	gs.mu.RUnlock()
+	if recorder == nil {
+		return
+	}
+
+	recorder.Record(storage.GameRecord{
+		SessionID:       sessionID,
+		Seed:            result.Seed,
+		ModuleCounts:    result.ModuleCounts,
+		DurationSeconds: result.DurationSeconds,
+		Strikes:         result.Strikes,
+		DefuserName:     result.DefuserName,
+		Outcome:         string(result.Outcome),
+		Reason:          string(result.Reason),
+		Practice:        result.Practice,
+		StartedAt:       result.StartedAt,
+		FinishedAt:      result.FinishedAt,
+	})
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bombs/internal/models"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// webhookTimeout bounds a single webhook delivery
+	webhookTimeout = 5 * time.Second
+	// webhookRetryDelay is how long to wait before the one retry of a failed delivery
+	webhookRetryDelay = 2 * time.Second
+)
+
+// webhookClient delivers game over webhooks
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// GameOverWebhook is the JSON body posted to the webhook when a game ends
+// content makes the payload usable as-is by Discord webhooks
+type GameOverWebhook struct {
+	Content         string           `json:"content"`
+	SessionID       string           `json:"sessionId"`
+	Outcome         models.BombState `json:"outcome"`
+	Reason          string           `json:"reason"`
+	DurationSeconds int              `json:"durationSeconds"`
+	Strikes         int              `json:"strikes"`
+	ModuleCount     int              `json:"moduleCount"`
+	Players         []string         `json:"players"`
+	Practice        bool             `json:"practice"`
+	FinishedAt      time.Time        `json:"finishedAt"`
+}
+
+// SetDefaultWebhookURL sets the webhook called when a game ends in sessions without their own
+func (gs *GameService) SetDefaultWebhookURL(webhookURL string) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.defaultWebhookURL = webhookURL
+}
+
+// NotifyGameOver posts the game result to the session's webhook, or the server default
+// Delivery happens on its own goroutine; failures are only logged
+func (gs *GameService) NotifyGameOver(session *models.GameSession, result *models.GameResult) {
+	webhookURL := session.GetWebhookURL()
+	if webhookURL == "" {
+		gs.mu.RLock()
+		webhookURL = gs.defaultWebhookURL
+		gs.mu.RUnlock()
+	}
+	if webhookURL == "" {
+		return
+	}
+
+	players := []string{}
+	for _, player := range session.GetPlayersCopy() {
+		players = append(players, player.Name)
+	}
+	sort.Strings(players)
+	payload := GameOverWebhook{
+		SessionID:       session.ID,
+		Outcome:         result.Outcome,
+		Reason:          string(result.Reason),
+		DurationSeconds: result.DurationSeconds,
+		Strikes:         result.Strikes,
+		ModuleCount:     result.TotalModules,
+		Players:         players,
+		Practice:        result.Practice,
+		FinishedAt:      result.FinishedAt,
+	}
+	payload.Content = webhookContent(payload)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Warn("failed to encode webhook payload", "sessionId", session.ID, "error", err)
+		return
+	}
+	go deliverWebhook(webhookURL, body, session.ID)
+}
+
+// webhookContent is the human readable summary of the game
+func webhookContent(payload GameOverWebhook) string {
+	verb := "exploded"
+	if payload.Outcome == models.BombStateDefused {
+		verb = "defused"
+	}
+	practice := ""
+	if payload.Practice {
+		practice = " (practice)"
+	}
+	return fmt.Sprintf("Bomb %s%s in %d:%02d with %d strike(s) on %d modules - %s",
+		verb, practice, payload.DurationSeconds/60, payload.DurationSeconds%60,
+		payload.Strikes, payload.ModuleCount, strings.Join(payload.Players, ", "))
+}
+
+// deliverWebhook posts the body, retrying once after a short delay
+func deliverWebhook(webhookURL string, body []byte, sessionID string) {
+	err := postWebhook(webhookURL, body)
+	if err == nil {
+		return
+	}
+	time.Sleep(webhookRetryDelay)
+	if err := postWebhook(webhookURL, body); err != nil {
+		slog.Warn("webhook delivery failed", "sessionId", sessionID, "error", err)
+	}
+}
+
+// postWebhook makes one delivery attempt
+func postWebhook(webhookURL string, body []byte) error {
+	resp, err := webhookClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
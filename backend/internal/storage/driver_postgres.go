@@ -0,0 +1,7 @@
+//go:build postgres
+
+package storage
+
+// Links the Postgres driver, registered as "postgres"
+// Build with: go get github.com/lib/pq && go build -tags postgres ./cmd/server
+import _ "github.com/lib/pq"
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// saveTimeout bounds a single write so a stuck database can't hold the queue forever
+const saveTimeout = 10 * time.Second
+
+// Recorder saves game records on its own goroutine, through a buffered queue,
+// so a slow database never stalls the game loops
+type Recorder struct {
+	store  Store
+	queue  chan GameRecord
+	done   chan struct{} // Closed once the queue is drained
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewRecorder starts a recorder writing to store, queuing up to buffer records
+func NewRecorder(store Store, buffer int) *Recorder {
+	r := &Recorder{
+		store: store,
+		queue: make(chan GameRecord, buffer),
+		done:  make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Store returns the store the recorder writes to
+func (r *Recorder) Store() Store {
+	return r.store
+}
+
+// Record queues a record without blocking
+// The record is dropped (and logged) if the queue is full or the recorder is closed
+func (r *Recorder) Record(record GameRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		slog.Warn("game record dropped, recorder closed", "sessionId", record.SessionID)
+		return
+	}
+	select {
+	case r.queue <- record:
+	default:
+		slog.Warn("game record dropped, queue full", "sessionId", record.SessionID)
+	}
+}
+
+// run saves queued records until the queue is closed
+func (r *Recorder) run() {
+	defer close(r.done)
+	for record := range r.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+		if err := r.store.SaveGame(ctx, record); err != nil {
+			slog.Error("failed to save game record", "sessionId", record.SessionID, "error", err)
+		}
+		cancel()
+	}
+}
+
+// Close stops accepting records and waits for the queued ones to be saved, or for ctx to be done
+func (r *Recorder) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
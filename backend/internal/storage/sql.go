@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Database drivers the SQL store knows the dialect of
+// SQLite is always linked in, Postgres with the postgres build tag
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// SQLStore is a Store backed by database/sql (SQLite or Postgres)
+type SQLStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// OpenSQL opens the database, creating the game records table if needed
+func OpenSQL(driver, dsn string) (*SQLStore, error) {
+	if driver != DriverSQLite && driver != DriverPostgres {
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", driver, err)
+	}
+	if driver == DriverSQLite {
+		// SQLite allows a single writer, serialize access instead of failing with "database is locked"
+		db.SetMaxOpenConns(1)
+	}
+
+	store := &SQLStore{db: db, driver: driver}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// migrate creates the game records table and its index
+func (s *SQLStore) migrate() error {
+	idColumn := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.driver == DriverPostgres {
+		idColumn = "BIGSERIAL PRIMARY KEY"
+	}
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS game_records (
+			id ` + idColumn + `,
+			session_id TEXT NOT NULL,
+			seed BIGINT NOT NULL,
+			module_counts TEXT NOT NULL,
+			duration_seconds INTEGER NOT NULL,
+			strikes INTEGER NOT NULL,
+			defuser_name TEXT NOT NULL,
+			outcome TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			practice BOOLEAN NOT NULL,
+			started_at BIGINT NOT NULL,
+			finished_at BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS game_records_finished_at ON game_records (finished_at)`,
+	}
+	for _, statement := range statements {
+		if _, err := s.db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create game records table: %w", err)
+		}
+	}
+	return nil
+}
+
+// rebind turns ? placeholders into the driver's placeholder syntax
+func (s *SQLStore) rebind(query string) string {
+	if s.driver != DriverPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// SaveGame stores a finished game
+func (s *SQLStore) SaveGame(ctx context.Context, record GameRecord) error {
+	moduleCounts, err := json.Marshal(record.ModuleCounts)
+	if err != nil {
+		return fmt.Errorf("failed to encode module counts: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO game_records
+		(session_id, seed, module_counts, duration_seconds, strikes, defuser_name, outcome, reason, practice, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		record.SessionID, record.Seed, string(moduleCounts), record.DurationSeconds, record.Strikes,
+		record.DefuserName, record.Outcome, record.Reason, record.Practice,
+		record.StartedAt.UnixMilli(), record.FinishedAt.UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save game record: %w", err)
+	}
+	return nil
+}
+
+// RecentGames returns the last finished games, most recent first
+func (s *SQLStore) RecentGames(ctx context.Context, limit int) ([]GameRecord, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT
+		id, session_id, seed, module_counts, duration_seconds, strikes, defuser_name, outcome, reason, practice, started_at, finished_at
+		FROM game_records ORDER BY finished_at DESC, id DESC LIMIT ?`), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query game records: %w", err)
+	}
+	defer rows.Close()
+
+	records := []GameRecord{}
+	for rows.Next() {
+		var record GameRecord
+		var moduleCounts string
+		var startedAt, finishedAt int64
+		err := rows.Scan(&record.ID, &record.SessionID, &record.Seed, &moduleCounts, &record.DurationSeconds,
+			&record.Strikes, &record.DefuserName, &record.Outcome, &record.Reason, &record.Practice, &startedAt, &finishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read game record: %w", err)
+		}
+		if err := json.Unmarshal([]byte(moduleCounts), &record.ModuleCounts); err != nil {
+			return nil, fmt.Errorf("failed to decode module counts of game record %d: %w", record.ID, err)
+		}
+		record.StartedAt = time.UnixMilli(startedAt).UTC()
+		record.FinishedAt = time.UnixMilli(finishedAt).UTC()
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read game records: %w", err)
+	}
+	return records, nil
+}
+
+// Summary aggregates every stored game, practice games excluded
+func (s *SQLStore) Summary(ctx context.Context) (Summary, error) {
+	var summary Summary
+	var fastest sql.NullInt64
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT
+		COUNT(*),
+		COALESCE(SUM(CASE WHEN outcome = 'defused' THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(strikes), 0),
+		COALESCE(AVG(duration_seconds), 0),
+		MIN(CASE WHEN outcome = 'defused' THEN duration_seconds END)
+		FROM game_records WHERE practice = ?`), false).Scan(
+		&summary.TotalGames, &summary.Defused, &summary.TotalStrikes, &summary.AverageDurationSeconds, &fastest,
+	)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to summarize game records: %w", err)
+	}
+	summary.Exploded = summary.TotalGames - summary.Defused
+	if fastest.Valid {
+		seconds := int(fastest.Int64)
+		summary.FastestDefuseSeconds = &seconds
+	}
+	return summary, nil
+}
+
+// Close closes the database
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
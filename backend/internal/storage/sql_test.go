@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a SQLite store in a fresh file, closed when the test ends
+func openTestStore(t *testing.T, path string) *SQLStore {
+	t.Helper()
+	store, err := OpenSQL(DriverSQLite, path)
+	if err != nil {
+		t.Fatalf("OpenSQL: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// gameRecord is a finished game of the session, ending seconds after a fixed start
+func gameRecord(sessionID, outcome string, seconds, strikes int, practice bool) GameRecord {
+	startedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	return GameRecord{
+		SessionID:       sessionID,
+		Seed:            42,
+		ModuleCounts:    map[string]int{"wires": 2, "keypad": 1},
+		DurationSeconds: seconds,
+		Strikes:         strikes,
+		DefuserName:     "Alice",
+		Outcome:         outcome,
+		Reason:          "test",
+		Practice:        practice,
+		StartedAt:       startedAt,
+		FinishedAt:      startedAt.Add(time.Duration(seconds) * time.Second),
+	}
+}
+
+func TestOpenSQLCreatesSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bombs.db")
+	store := openTestStore(t, path)
+
+	for _, name := range []string{"game_records", "game_records_finished_at"} {
+		var count int
+		err := store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = ?`, name).Scan(&count)
+		if err != nil || count != 1 {
+			t.Errorf("%s: count = %d, err = %v, want created", name, count, err)
+		}
+	}
+
+	// Reopening an existing database keeps its records
+	if err := store.SaveGame(context.Background(), gameRecord("ABC123", "defused", 60, 0, false)); err != nil {
+		t.Fatalf("SaveGame: %v", err)
+	}
+	store.Close()
+	reopened := openTestStore(t, path)
+	records, err := reopened.RecentGames(context.Background(), 10)
+	if err != nil || len(records) != 1 {
+		t.Errorf("after reopening: %d records, err = %v, want 1", len(records), err)
+	}
+}
+
+func TestOpenSQLUnsupportedDriver(t *testing.T) {
+	if _, err := OpenSQL("mysql", "bombs"); err == nil {
+		t.Error("OpenSQL accepted an unsupported driver")
+	}
+}
+
+func TestSQLStoreRecentGames(t *testing.T) {
+	store := openTestStore(t, filepath.Join(t.TempDir(), "bombs.db"))
+	ctx := context.Background()
+	saved := []GameRecord{
+		gameRecord("AAA111", "defused", 60, 1, false),
+		gameRecord("BBB222", "exploded", 300, 3, false),
+		gameRecord("CCC333", "defused", 120, 0, true),
+	}
+	for _, record := range saved {
+		if err := store.SaveGame(ctx, record); err != nil {
+			t.Fatalf("SaveGame: %v", err)
+		}
+	}
+
+	records, err := store.RecentGames(ctx, 2)
+	if err != nil {
+		t.Fatalf("RecentGames: %v", err)
+	}
+	if len(records) != 2 || records[0].SessionID != "BBB222" || records[1].SessionID != "CCC333" {
+		t.Fatalf("RecentGames(2) = %+v, want BBB222 then CCC333", records)
+	}
+
+	got := records[1]
+	want := saved[2]
+	want.ID = got.ID
+	if got.ID == 0 || !reflect.DeepEqual(got, want) {
+		t.Errorf("record = %+v, want %+v", got, want)
+	}
+}
+
+func TestSQLStoreSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []GameRecord
+		want    Summary
+		fastest int // 0 when no game was defused
+	}{
+		{"no games", nil, Summary{}, 0},
+		{"practice games only", []GameRecord{gameRecord("AAA111", "defused", 60, 0, true)}, Summary{}, 0},
+		{
+			"mixed outcomes",
+			[]GameRecord{
+				gameRecord("AAA111", "defused", 90, 1, false),
+				gameRecord("BBB222", "defused", 60, 0, false),
+				gameRecord("CCC333", "exploded", 300, 3, false),
+				gameRecord("DDD444", "defused", 10, 0, true),
+			},
+			Summary{TotalGames: 3, Defused: 2, Exploded: 1, TotalStrikes: 4, AverageDurationSeconds: 150},
+			60,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := openTestStore(t, filepath.Join(t.TempDir(), "bombs.db"))
+			ctx := context.Background()
+			for _, record := range tt.records {
+				if err := store.SaveGame(ctx, record); err != nil {
+					t.Fatalf("SaveGame: %v", err)
+				}
+			}
+
+			summary, err := store.Summary(ctx)
+			if err != nil {
+				t.Fatalf("Summary: %v", err)
+			}
+			if (summary.FastestDefuseSeconds == nil) != (tt.fastest == 0) ||
+				summary.FastestDefuseSeconds != nil && *summary.FastestDefuseSeconds != tt.fastest {
+				t.Errorf("FastestDefuseSeconds = %v, want %d", summary.FastestDefuseSeconds, tt.fastest)
+			}
+			summary.FastestDefuseSeconds = nil
+			if summary != tt.want {
+				t.Errorf("Summary = %+v, want %+v", summary, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// GameRecord is a finished game as persisted in the game store
+type GameRecord struct {
+	ID              int64          `json:"id"`
+	SessionID       string         `json:"sessionId"`
+	Seed            int64          `json:"seed"`         // Rule seed of the last bomb played
+	ModuleCounts    map[string]int `json:"moduleCounts"` // Solvable modules per type over every bomb played
+	DurationSeconds int            `json:"durationSeconds"`
+	Strikes         int            `json:"strikes"`
+	DefuserName     string         `json:"defuserName"`
+	Outcome         string         `json:"outcome"` // defused or exploded
+	Reason          string         `json:"reason"`
+	Practice        bool           `json:"practice"`
+	StartedAt       time.Time      `json:"startedAt"`
+	FinishedAt      time.Time      `json:"finishedAt"`
+}
+
+// Summary aggregates every persisted game, practice games excluded
+type Summary struct {
+	TotalGames             int     `json:"totalGames"`
+	Defused                int     `json:"defused"`
+	Exploded               int     `json:"exploded"`
+	TotalStrikes           int     `json:"totalStrikes"`
+	AverageDurationSeconds float64 `json:"averageDurationSeconds"`
+	FastestDefuseSeconds   *int    `json:"fastestDefuseSeconds"` // nil until a game is defused
+}
+
+// Store persists finished games
+// Implementations must be safe for concurrent use
+type Store interface {
+	// SaveGame stores a finished game
+	SaveGame(ctx context.Context, record GameRecord) error
+	// RecentGames returns the last finished games, most recent first
+	RecentGames(ctx context.Context, limit int) ([]GameRecord, error)
+	// Summary aggregates every stored game, practice games excluded
+	Summary(ctx context.Context) (Summary, error)
+	// Close releases the store's resources
+	Close() error
+}
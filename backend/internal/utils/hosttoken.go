@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// SignHostToken returns a token proving its bearer is hostID in the given session
+// The token is the host ID and an HMAC-SHA256 of the session and host IDs, both base64url encoded
+func SignHostToken(secret []byte, sessionID string, hostID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(hostID)) + "." +
+		base64.RawURLEncoding.EncodeToString(hostTokenMAC(secret, sessionID, hostID))
+}
+
+// VerifyHostToken checks a token issued by SignHostToken for the session
+// Returns the host ID it was issued to, or false if the token is malformed or forged
+func VerifyHostToken(secret []byte, sessionID string, token string) (string, bool) {
+	encodedHostID, encodedMAC, found := strings.Cut(token, ".")
+	if !found {
+		return "", false
+	}
+	hostID, err := base64.RawURLEncoding.DecodeString(encodedHostID)
+	if err != nil {
+		return "", false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return "", false
+	}
+	if !hmac.Equal(mac, hostTokenMAC(secret, sessionID, string(hostID))) {
+		return "", false
+	}
+	return string(hostID), true
+}
+
+// HostTokenHostID returns the host ID a token claims, without checking its signature
+// Only use it to find what to verify the token against
+func HostTokenHostID(token string) (string, bool) {
+	encodedHostID, _, found := strings.Cut(token, ".")
+	if !found {
+		return "", false
+	}
+	hostID, err := base64.RawURLEncoding.DecodeString(encodedHostID)
+	if err != nil || len(hostID) == 0 {
+		return "", false
+	}
+	return string(hostID), true
+}
+
+// hostTokenMAC signs the session and host IDs, separated so their boundary can't be shifted
+func hostTokenMAC(secret []byte, sessionID string, hostID string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(sessionID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(hostID))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// SignJoinToken returns a token letting its bearer join a private session without the password
+// It is bound to the password hash, so changing the password invalidates issued tokens
+func SignJoinToken(secret []byte, sessionID string, passwordHash string) string {
+	return base64.RawURLEncoding.EncodeToString(joinTokenMAC(secret, sessionID, passwordHash))
+}
+
+// VerifyJoinToken checks a token issued by SignJoinToken for the session and password hash
+func VerifyJoinToken(secret []byte, sessionID string, passwordHash string, token string) bool {
+	mac, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(mac, joinTokenMAC(secret, sessionID, passwordHash))
+}
+
+// joinTokenMAC signs the session ID and password hash, prefixed so it never matches a host token's MAC
+func joinTokenMAC(secret []byte, sessionID string, passwordHash string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("join"))
+	mac.Write([]byte{0})
+	mac.Write([]byte(sessionID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(passwordHash))
+	return mac.Sum(nil)
+}
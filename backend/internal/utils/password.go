@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+const (
+	// passwordSaltLength is the length of the random salt stored with each password hash
+	passwordSaltLength = 16
+	// passwordIterations is the PBKDF2 iteration count, slowing down offline guessing
+	passwordIterations = 10000
+)
+
+// HashPassword derives a salted hash of a password with PBKDF2-HMAC-SHA256
+// The result holds the salt and the key, base64url encoded and separated by "."
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate password salt: %w", err)
+	}
+	key := pbkdf2SHA256([]byte(password), salt, passwordIterations)
+	return base64.RawURLEncoding.EncodeToString(salt) + "." + base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// CheckPassword reports whether password matches a hash returned by HashPassword
+func CheckPassword(hash string, password string) bool {
+	encodedSalt, encodedKey, found := strings.Cut(hash, ".")
+	if !found {
+		return false
+	}
+	salt, err := base64.RawURLEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawURLEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(key, pbkdf2SHA256([]byte(password), salt, passwordIterations))
+}
+
+// pbkdf2SHA256 derives a single 32-byte PBKDF2 block (RFC 8018) with HMAC-SHA256
+func pbkdf2SHA256(password []byte, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, password)
+	prf.Write(salt)
+	prf.Write(binary.BigEndian.AppendUint32(nil, 1))
+	u := prf.Sum(nil)
+
+	key := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
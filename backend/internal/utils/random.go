@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strings"
 )
 
 const (
@@ -12,8 +13,39 @@ const (
 	DefaultIDLength = 16
 	// SessionIDLength is the length for session IDs (4 digits)
 	SessionIDLength = 4
+	// AlphanumericSessionIDLength is the length for alphanumeric session IDs
+	AlphanumericSessionIDLength = 6
 )
 
+// sessionIDAlphabet is the characters of alphanumeric session IDs
+// Lookalikes (0/O, 1/I/L) are left out so codes can be read aloud and typed without mistakes
+const sessionIDAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// SessionIDFormat selects how generated session IDs look
+type SessionIDFormat string
+
+const (
+	// SessionIDNumeric is 4 digits (9000 codes)
+	SessionIDNumeric SessionIDFormat = "numeric"
+	// SessionIDAlphanumeric is 6 letters and digits (about 887 million codes)
+	SessionIDAlphanumeric SessionIDFormat = "alphanumeric"
+)
+
+// ParseSessionIDFormat parses a session ID format name
+func ParseSessionIDFormat(name string) (SessionIDFormat, error) {
+	switch format := SessionIDFormat(strings.ToLower(strings.TrimSpace(name))); format {
+	case SessionIDNumeric, SessionIDAlphanumeric:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown session ID format %q (want %q or %q)", name, SessionIDNumeric, SessionIDAlphanumeric)
+}
+
+// NormalizeSessionID returns the canonical form of a session ID typed by a player
+// Alphanumeric IDs are case-insensitive, numeric IDs are unchanged
+func NormalizeSessionID(sessionID string) string {
+	return strings.ToUpper(strings.TrimSpace(sessionID))
+}
+
 // GenerateRandomString generates a cryptographically secure random string
 func GenerateRandomString(length int) (string, error) {
 	if length <= 0 {
@@ -36,11 +68,33 @@ func GenerateSessionID() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to generate session ID: %w", err)
 	}
-	
+
 	sessionNum := int(n.Int64()) + 1000
 	return fmt.Sprintf("%04d", sessionNum), nil
 }
 
+// GenerateAlphanumericSessionID generates a 6-character session ID from sessionIDAlphabet
+func GenerateAlphanumericSessionID() (string, error) {
+	max := big.NewInt(int64(len(sessionIDAlphabet)))
+	id := make([]byte, AlphanumericSessionIDLength)
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate session ID: %w", err)
+		}
+		id[i] = sessionIDAlphabet[n.Int64()]
+	}
+	return string(id), nil
+}
+
+// GenerateSessionIDFormat generates a session ID in the given format
+func GenerateSessionIDFormat(format SessionIDFormat) (string, error) {
+	if format == SessionIDAlphanumeric {
+		return GenerateAlphanumericSessionID()
+	}
+	return GenerateSessionID()
+}
+
 // GenerateHostID generates a unique host ID
 func GenerateHostID() (string, error) {
 	// Generate a longer random string for host ID
@@ -69,7 +123,7 @@ func GeneratePlayerName() (string, error) {
 		"Quebec", "Romeo", "Sierra", "Tango", "Uniform", "Victor", "Whiskey", "Xray",
 		"Yankee", "Zulu", "Ace", "King", "Queen", "Jack", "Joker", "Wild",
 	}
-	
+
 	// Pick a random word
 	max := big.NewInt(int64(len(words)))
 	n, err := rand.Int(rand.Reader, max)
@@ -77,7 +131,7 @@ func GeneratePlayerName() (string, error) {
 		return "", fmt.Errorf("failed to generate random word: %w", err)
 	}
 	word := words[n.Int64()]
-	
+
 	// Generate 2 random digits (00-99)
 	digitMax := big.NewInt(100)
 	digitN, err := rand.Int(rand.Reader, digitMax)
@@ -85,7 +139,6 @@ func GeneratePlayerName() (string, error) {
 		return "", fmt.Errorf("failed to generate random digits: %w", err)
 	}
 	digits := fmt.Sprintf("%02d", digitN.Int64())
-	
+
 	return fmt.Sprintf("%s%s", word, digits), nil
 }
-
@@ -18,17 +18,19 @@ class APIClient {
         return await response.json();
     }
     
-    async joinGame(sessionId) {
+    async joinGame(sessionId, password = '', joinToken = '') {
         const response = await fetch(`${API_BASE_URL}/game/join`, {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify({ sessionId }),
+            body: JSON.stringify({ sessionId, password, joinToken }),
         });
         
         if (!response.ok) {
-            throw new Error('Failed to join game');
+            const error = new Error('Failed to join game');
+            error.status = response.status;
+            throw error;
         }
         
         return await response.json();
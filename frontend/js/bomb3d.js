@@ -109,6 +109,17 @@ class Bomb3D {
         this.interactionManager.wiresModulesState = () => this.wiresModulesState;
     }
     
+    // moduleIdAt returns the ID of the module on a panel, panels being wires, then buttons, then terminals
+    moduleIdAt(panelIndex) {
+        const modules = [
+            ...(this.wiresModulesState || []),
+            ...(this.buttonModulesState || []),
+            ...(this.terminalModulesState || []),
+        ];
+        const module = modules[panelIndex];
+        return module && module.id ? module.id : '';
+    }
+    
     markWireAsCut(moduleIndex, wireIndex) {
         this.wiresManager.markWireAsCut(moduleIndex, wireIndex);
     }
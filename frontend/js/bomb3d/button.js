@@ -72,6 +72,8 @@ class ButtonManager {
             'red': 0xff0000,
             'blue': 0x0066ff,
             'white': 0xffffff,
+            'green': 0x00cc44,
+            'yellow': 0xffdd00,
         };
         
         const buttonColor = colorMap[color] || 0xffffff;
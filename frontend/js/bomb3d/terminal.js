@@ -549,7 +549,8 @@ class TerminalManager {
                 }
                 
                 lines.push({ text: '', color: '#00ff00' }); // Empty line separator
-                const promptText = `Command ${currentStep + 1}/3:`;
+                const commandCount = terminalTexts && terminalTexts.length ? terminalTexts.length : 3;
+                const promptText = `Command ${currentStep + 1}/${commandCount}:`;
                 // Wrap prompt if needed
                 if (context) {
                     const wrappedPrompt = this.wrapText(context, promptText, maxTextWidth);
@@ -697,14 +698,18 @@ class TerminalManager {
         }
         
         // Add command response to terminal display
-        addCommandResponse(moduleIndex, command, correct) {
+        // lockoutRemaining is set when the terminal ignored the command because it is locked out
+        addCommandResponse(moduleIndex, command, correct, lockoutRemaining) {
             if (!this.commandResponses[moduleIndex]) {
                 this.commandResponses[moduleIndex] = [];
             }
             
-            const responseText = correct 
+            let responseText = correct 
                 ? `> ${command}\nCommand accepted.`
                 : `> ${command}\nError: Invalid command.`;
+            if (lockoutRemaining) {
+                responseText = `> ${command}\nTerminal locked. Try again in ${lockoutRemaining}s.`;
+            }
             
             this.commandResponses[moduleIndex].push({
                 command: command,
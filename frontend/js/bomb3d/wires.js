@@ -35,7 +35,9 @@ class WiresManager {
             const startY = panelCenterY + panelHeight / 2 - wireSpacing;
             
             const moduleWires = [];
-            wiresConfig.wires.forEach((color, wireIndex) => {
+            wiresConfig.wires.forEach((wireConfig, wireIndex) => {
+                // Wires are {color, stripe} objects, or plain colors from older servers
+                const { color, stripe } = typeof wireConfig === 'string' ? { color: wireConfig } : wireConfig;
                 const wire = this.createWire(
                     color, 
                     wireIndex, 
@@ -43,7 +45,8 @@ class WiresManager {
                     wiresConfig.cutWires.includes(wireIndex), 
                     modulePanel.x,
                     moduleIndex,
-                    panelWidth * 0.8
+                    panelWidth * 0.8,
+                    stripe
                 );
                 moduleWires.push(wire);
                 this.bombGroup.add(wire);
@@ -53,7 +56,7 @@ class WiresManager {
         });
     }
     
-    createWire(color, index, yPos, isCut, xPos = 0, moduleIndex = 0, wireLength = 1.1) {
+    createWire(color, index, yPos, isCut, xPos = 0, moduleIndex = 0, wireLength = 1.1, stripe = '') {
         const wireGroup = new THREE.Group();
         
         // Wire color mapping
@@ -108,7 +111,26 @@ class WiresManager {
         }
         
         wireGroup.add(wire);
-        wireGroup.userData = { index, color, isCut, moduleIndex };
+        
+        // Stripe: rings of the second color along the wire
+        if (stripe) {
+            const ringCount = 5;
+            const ringGeometry = new THREE.CylinderGeometry(0.034, 0.034, 0.05, 16);
+            const ringMaterial = new THREE.MeshBasicMaterial({
+                color: colorMap[stripe] || 0xffffff,
+                transparent: isCut,
+                opacity: isCut ? 0.2 : 1,
+            });
+            for (let i = 0; i < ringCount; i++) {
+                const ring = new THREE.Mesh(ringGeometry, ringMaterial);
+                ring.rotation.z = Math.PI / 2;
+                ring.position.set(xPos - wireLength / 2 + wireLength * (i + 0.5) / ringCount, yPos, 0.62);
+                ring.userData = { stripe: true };
+                wireGroup.add(ring);
+            }
+        }
+        
+        wireGroup.userData = { index, color, stripe, isCut, moduleIndex };
         
         return wireGroup;
     }
@@ -121,6 +143,12 @@ class WiresManager {
                 wire.material.transparent = true;
                 wire.userData.isCut = true;
                 this.wires[moduleIndex][wireIndex].userData.isCut = true;
+                this.wires[moduleIndex][wireIndex].children.forEach(child => {
+                    if (child.userData.stripe) {
+                        child.material.opacity = 0.2;
+                        child.material.transparent = true;
+                    }
+                });
             }
         }
     }
@@ -17,6 +17,9 @@ class ZoomManager {
         this.zoomStartPosition = new THREE.Vector3();
         this.zoomTargetPosition = new THREE.Vector3();
         
+        // Called with the zoomed module panel index, or null when leaving the module
+        this.onZoomChange = null;
+        
         // Store original camera position for zoom return
         this.originalCameraPosition.set(0, 0.8, 5);
         this.originalCameraRotation.copy(this.camera.rotation);
@@ -58,6 +61,10 @@ class ZoomManager {
         
         // Store module world position for lookAt
         this.zoomedModuleWorldPosition = moduleWorldPosition.clone();
+        
+        if (this.onZoomChange) {
+            this.onZoomChange(moduleIndex);
+        }
     }
     
     exitZoom() {
@@ -70,6 +77,10 @@ class ZoomManager {
         this.isZoomed = false;
         this.zoomedModuleIndex = null;
         this.zoomedModuleWorldPosition = null;
+        
+        if (this.onZoomChange) {
+            this.onZoomChange(null);
+        }
     }
     
     updateZoomAnimation() {
@@ -26,7 +26,8 @@ class ButtonModule {
                     result = message.data;
                 }
                 
-                if (result && result.correct === false && result.moduleIndex !== undefined) {
+                // Only strikes flash: pressing a solved button (result.reason) does nothing
+                if (result && result.strike && result.moduleIndex !== undefined) {
                     // Show red flash for strike
                     // Note: moduleIndex needs to account for both wire and button modules
                     // We'll need to calculate the actual 3D module index
@@ -12,6 +12,8 @@ const Config = {
     // WebSocket configuration
     MAX_RECONNECT_ATTEMPTS: 5,
     RECONNECT_DELAY_BASE: 1000, // Base delay in milliseconds
+    PROTOCOL_VERSION: 2, // Message format this page speaks, negotiated on connect
+    MANUAL_LANGUAGES: ['en', 'fr'], // Languages the server renders the manual in
     
     // Game constants
     MAX_STRIKES: 3,
@@ -10,10 +10,13 @@ let currentHostId = null;
 let lobbyState = null;
 let isHost = false;
 let currentPlayerType = null;
+let currentPassword = ''; // Join password of a private lobby, resent when the WebSocket connects
+let currentJoinToken = ''; // Join token from an invite link, replaces the password
 
 // Initialize game
 document.addEventListener('DOMContentLoaded', () => {
     setupMenuHandlers();
+    joinFromInviteLink();
     
     // Handle window resize
     window.addEventListener('resize', () => {
@@ -31,6 +34,7 @@ function setupMenuHandlers() {
             currentHostId = result.hostId;
             isHost = true;
             showLobby(result.lobby, true);
+            showInviteLink(result.joinUrl);
         } catch (error) {
             console.error('Failed to create game:', error);
             alert('Failed to create game. Please try again.');
@@ -45,8 +49,20 @@ function setupMenuHandlers() {
         }
         
         try {
-            const result = await apiClient.joinGame(sessionId);
-            currentSessionId = sessionId;
+            let result;
+            try {
+                result = await apiClient.joinGame(sessionId, '', currentJoinToken);
+                currentPassword = '';
+            } catch (error) {
+                // Private lobby: ask for the password and try once more
+                if (error.status !== 403) throw error;
+                const password = prompt('This lobby is private. Enter its password:');
+                if (password === null) return;
+                result = await apiClient.joinGame(sessionId, password);
+                currentPassword = password;
+            }
+            // The server's spelling of the code, alphanumeric codes are case-insensitive
+            currentSessionId = result.sessionId || sessionId;
             
             // Set hostId from lobby if available
             if (result.lobby && result.lobby.hostId) {
@@ -131,6 +147,8 @@ function showLobby(lobby, isHost) {
     if (!websocketClient || !websocketClient.ws || websocketClient.ws.readyState !== WebSocket.OPEN) {
         // Initialize WebSocket client for lobby
         websocketClient = new WebSocketClient(currentSessionId);
+        websocketClient.password = currentPassword;
+        websocketClient.joinToken = currentJoinToken;
     } else {
         // Clear existing callbacks to avoid duplicates
         websocketClient.onLobbyUpdateCallbacks = [];
@@ -188,7 +206,12 @@ function showLobby(lobby, isHost) {
     });
     
     // Handle game starting
-    websocketClient.onGameStarting(() => {
+    websocketClient.onGameStarting((startingData) => {
+        // The roles assigned at start win over whatever the lobby said
+        if (startingData.roles && currentPlayerId && startingData.roles[currentPlayerId]) {
+            currentPlayerType = startingData.roles[currentPlayerId];
+        }
+        
         // Check player type from lobby state if not already set
         if (!currentPlayerType && lobbyState && lobbyState.players && currentPlayerId) {
             const currentPlayer = lobbyState.players.find(p => p.id === currentPlayerId);
@@ -214,6 +237,29 @@ function showLobby(lobby, isHost) {
     websocketClient.connect(isHost ? currentHostId : null);
 }
 
+// Invite links (/join/{sessionId}) land here with ?join=<sessionId>&joinToken=<token>
+function joinFromInviteLink() {
+    const params = new URLSearchParams(window.location.search);
+    const sessionId = params.get('join');
+    if (!sessionId) return;
+    
+    currentJoinToken = params.get('joinToken') || '';
+    // Drop the parameters so a reload doesn't join again
+    window.history.replaceState(null, '', window.location.pathname);
+    
+    document.getElementById('session-id-input').value = sessionId;
+    document.getElementById('join-submit-btn').click();
+}
+
+function showInviteLink(joinUrl) {
+    const invite = document.getElementById('lobby-invite');
+    if (!invite || !joinUrl) return;
+    const link = document.getElementById('lobby-invite-link');
+    link.href = joinUrl;
+    link.textContent = joinUrl;
+    invite.style.display = 'block';
+}
+
 function renderLobby(lobby, isHostParam) {
     // Update isHost if provided, otherwise use global
     if (typeof isHostParam !== 'undefined') {
@@ -244,6 +290,9 @@ function renderLobby(lobby, isHostParam) {
             // Add defuser class if this player is the defuser
             if (!lobby.isRandomDefuser && lobby.defuserId === player.id) {
                 card.classList.add('is-defuser');
+            } else if (lobby.upNext && lobby.upNext.includes(player.id)) {
+                // Drawn ahead of the start so they can prepare
+                card.classList.add('is-up-next');
             }
             
             // Player name input
@@ -272,6 +321,24 @@ function renderLobby(lobby, isHostParam) {
             
             card.appendChild(nameInput);
             
+            // Role preference for random defuser picks (only editable for own card)
+            const preferenceSelect = document.createElement('select');
+            preferenceSelect.className = 'role-preference-select';
+            [['noPreference', 'No preference'], ['preferDefuser', 'Wants to defuse'], ['preferExpert', 'Prefers expert']].forEach(([value, label]) => {
+                const option = document.createElement('option');
+                option.value = value;
+                option.textContent = label;
+                preferenceSelect.appendChild(option);
+            });
+            preferenceSelect.value = player.rolePreference || 'noPreference';
+            if (player.id !== currentPlayerId) {
+                preferenceSelect.disabled = true;
+            }
+            preferenceSelect.addEventListener('change', (e) => {
+                websocketClient.sendRolePreference(e.target.value);
+            });
+            card.appendChild(preferenceSelect);
+            
             // Select as defuser button (only visible to host)
             if (isHost) {
                 const selectBtn = document.createElement('button');
@@ -481,9 +548,14 @@ function transitionToGame() {
     // Initialize terminal module
     terminalModule = new TerminalModule(bomb3d, websocketClient);
     
+    // Tell the experts which module is open
+    bomb3d.zoomManager.onZoomChange = (panelIndex) => {
+        websocketClient.focusModule(panelIndex === null ? '' : bomb3d.moduleIdAt(panelIndex));
+    };
+    
     // Listen for game state updates to detect game end
     websocketClient.onStateUpdate((bombState) => {
-        if (bombState.state === 'defused' || bombState.state === 'exploded') {
+        if (isMissionOver(bombState.state, bombState)) {
             showGameEnd(bombState.state);
         }
     });
@@ -503,7 +575,7 @@ function transitionToGame() {
             terminalModule.updateBombState(bombState);
         }
         // Check if game is already ended
-        if (bombState.state === 'defused' || bombState.state === 'exploded') {
+        if (isMissionOver(bombState.state, bombState)) {
             showGameEnd(bombState.state);
         }
     }).catch(error => {
@@ -533,12 +605,22 @@ function transitionToManual() {
         
         // Check if game is ended
         if (manualContent.bombState) {
-            if (manualContent.bombState.state === 'defused' || manualContent.bombState.state === 'exploded') {
+            if (isMissionOver(manualContent.bombState.state, manualContent)) {
                 showGameEnd(manualContent.bombState.state);
             }
         }
     });
     
+    // Open the manual of the module the defuser is working on
+    websocketClient.onMessage((message) => {
+        if (message.type === 'defuserFocus') {
+            const focus = websocketClient.parseMessageData(message.data, 'defuserFocus');
+            if (focus !== null) {
+                manualDisplay.followDefuserFocus(focus);
+            }
+        }
+    });
+    
     // Make sure return to lobby handler is set up
     websocketClient.onReturnToLobby(() => {
         handleReturnToLobby();
@@ -549,7 +631,7 @@ function transitionToManual() {
         manualDisplay.renderManualContent(manualContent);
         // Check if game is already ended
         if (manualContent.bombState) {
-            if (manualContent.bombState.state === 'defused' || manualContent.bombState.state === 'exploded') {
+            if (isMissionOver(manualContent.bombState.state, manualContent)) {
                 showGameEnd(manualContent.bombState.state);
             }
         }
@@ -558,6 +640,19 @@ function transitionToManual() {
     });
 }
 
+// A defused bomb only ends the game when it was the last bomb of the mission
+function isMissionOver(state, missionInfo) {
+    if (state === 'exploded') {
+        return true;
+    }
+    if (state !== 'defused') {
+        return false;
+    }
+    const bombCount = missionInfo.bombCount || 1;
+    const bombIndex = missionInfo.bombIndex || 0;
+    return bombIndex >= bombCount - 1;
+}
+
 function showGameEnd(gameState) {
     // Hide game container
     document.getElementById('game-container').style.display = 'none';
@@ -708,7 +803,12 @@ function handleReturnToLobby(lobbyDataFromUpdate = null) {
         });
         
         // Set up game starting handler
-        websocketClient.onGameStarting(() => {
+        websocketClient.onGameStarting((startingData) => {
+            // The roles assigned at start win over whatever the lobby said
+            if (startingData.roles && currentPlayerId && startingData.roles[currentPlayerId]) {
+                currentPlayerType = startingData.roles[currentPlayerId];
+            }
+            
             // Check player type from lobby state if not already set
             if (!currentPlayerType && lobbyState && lobbyState.players && currentPlayerId) {
                 const currentPlayer = lobbyState.players.find(p => p.id === currentPlayerId);
@@ -57,6 +57,14 @@ class ManualDisplay {
         if (sessionIdElement && currentSessionId) {
             sessionIdElement.textContent = currentSessionId;
         }
+        
+        // Manual code, the defuser sees the same one unless this manual is stale
+        const manualCodeElement = document.getElementById('manual-code');
+        if (manualCodeElement) {
+            manualCodeElement.textContent = manualContent.manualCode ? `MANUAL ${manualContent.manualCode}` : '-';
+        }
+
+        this.renderProgress(manualContent.progress);
 
         // If we're on menu view, just show menu (content is stored for when user clicks)
         // If we're on detail view, render the current module
@@ -67,6 +75,34 @@ class ManualDisplay {
         }
     }
 
+    // Render the status bar: solved modules, strikes and time left
+    renderProgress(progress) {
+        const progressElement = document.getElementById('manual-progress');
+        if (!progressElement) {
+            return;
+        }
+        if (!progress) {
+            progressElement.textContent = '-';
+            return;
+        }
+        const minutes = Math.floor(progress.timeRemaining / 60);
+        const seconds = String(progress.timeRemaining % 60).padStart(2, '0');
+        progressElement.textContent = `${progress.solved}/${progress.total} solved - ` +
+            `strikes ${progress.strikes}/${progress.maxStrikes} - ${minutes}:${seconds}`;
+    }
+
+    // Show the manual of the module a defuser just opened
+    // A cleared focus (empty moduleId) leaves the expert where they are
+    followDefuserFocus(focus) {
+        if (!focus.moduleId || !this.currentManualContent || !this.currentManualContent.moduleManuals) {
+            return;
+        }
+        const moduleKey = this.currentManualContent.moduleManuals[focus.moduleId];
+        if (moduleKey && moduleKey !== this.currentModule) {
+            this.showModuleDetail(moduleKey);
+        }
+    }
+
     // Show menu view with module cards
     showMenuView() {
         this.currentView = 'menu';
@@ -410,13 +446,10 @@ class ManualDisplay {
                     return; // Skip empty rules
                 }
                 
-                // Parse the rule description to extract terminal text and command
-                // Format: "If terminal says \"{terminalText}\", type {command}."
-                const match = rule.description.match(/If terminal says "([^"]+)", type ([^.]+)\./);
-                
-                if (match) {
-                    const terminalText = match[1];
-                    const command = match[2];
+                // Rules carry the terminal text and command they map, whatever the manual language
+                if (rule.terminalText && rule.command) {
+                    const terminalText = rule.terminalText;
+                    const command = rule.command;
                     
                     // Create a visual rule card linking input to output
                     const ruleCard = document.createElement('div');
@@ -34,12 +34,13 @@ class TerminalModule {
                         this.bomb3d.terminalManager.addCommandResponse(
                             terminalModuleIndex,
                             result.command || '',
-                            result.correct === true
+                            result.correct === true,
+                            result.reason === 'locked' ? result.lockoutRemaining : 0
                         );
                     }
                     
                     // Show red flash for strike if incorrect
-                    if (result.correct === false) {
+                    if (result.strike === true) {
                         const actualModuleIndex = this.getActualModuleIndex(terminalModuleIndex, 'terminal');
                         if (actualModuleIndex !== -1) {
                             this.bomb3d.showModuleStrike(actualModuleIndex);
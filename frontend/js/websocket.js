@@ -1,8 +1,20 @@
+// Messages acting on the bomb, sent with a request ID the server echoes in the result
+const ACTION_MESSAGE_TYPES = new Set([
+    'cutWire', 'pressButton', 'holdButton', 'releaseButton', 'enterTerminalCommand',
+    'simonPress', 'keypadPress', 'memoryPress', 'passwordSpin', 'passwordSubmit',
+    'morseTune', 'morseSubmit', 'wofPress', 'cutComplicatedWire', 'mazeMove',
+    'rotateKnob', 'confirmKnob', 'moduleAction', 'answerNeedy', 'startDischarge', 'stopDischarge',
+]);
+
 // WebSocket client for real-time communication
 class WebSocketClient {
     constructor(sessionId) {
         this.sessionId = sessionId;
         this.hostId = null; // Store hostId for reconnections
+        this.playerId = null; // Store playerId so reconnections keep the same seat
+        this.reconnectToken = null; // Proves the playerId is ours when reconnecting, from the hello
+        this.password = ''; // Join password of a private lobby
+        this.joinToken = ''; // Join token from an invite link, replaces the password
         this.ws = null;
         this.onMessageCallbacks = [];
         this.onStateUpdateCallbacks = [];
@@ -10,8 +22,20 @@ class WebSocketClient {
         this.onLobbyUpdateCallbacks = [];
         this.onGameStartingCallbacks = [];
         this.onReturnToLobbyCallbacks = [];
+        this.nextRequestId = 1; // Numbers the actions sent on this page
+        this.bombState = null; // Last full gameState, ticks are applied to it
+        this.manualContent = null; // Last manualContent, ticks update its timer
+        this.stateVersion = null; // Version of the state the last snapshot or tick brought us to
+        this.protocolVersion = null; // Version the server agreed to speak, from its hello
         this.reconnectAttempts = 0;
         this.maxReconnectAttempts = Config.MAX_RECONNECT_ATTEMPTS;
+
+        // A suspended tab may have missed messages, catch up when it is shown again
+        document.addEventListener('visibilitychange', () => {
+            if (document.visibilityState === 'visible') {
+                this.requestState();
+            }
+        });
     }
     
     connect(hostId = null) {
@@ -28,12 +52,21 @@ class WebSocketClient {
         let wsUrl = `${protocol}//${host}${port}/ws/${this.sessionId}`;
         if (this.hostId) {
             wsUrl += `?hostId=${encodeURIComponent(this.hostId)}`;
+        } else if (this.playerId && this.reconnectToken) {
+            wsUrl += `?playerId=${encodeURIComponent(this.playerId)}&reconnectToken=${encodeURIComponent(this.reconnectToken)}`;
         }
+        if (this.password && !this.hostId) {
+            wsUrl += `${wsUrl.includes('?') ? '&' : '?'}password=${encodeURIComponent(this.password)}`;
+        } else if (this.joinToken && !this.hostId) {
+            wsUrl += `${wsUrl.includes('?') ? '&' : '?'}joinToken=${encodeURIComponent(this.joinToken)}`;
+        }
+        wsUrl += `${wsUrl.includes('?') ? '&' : '?'}protocolVersion=${Config.PROTOCOL_VERSION}`;
         
         this.ws = new WebSocket(wsUrl);
         
         this.ws.onopen = () => {
             this.reconnectAttempts = 0;
+            this.sendLanguage(this.manualLanguage());
             this.onConnect();
         };
         
@@ -56,8 +89,14 @@ class WebSocketClient {
             }
         };
         
-        this.ws.onclose = () => {
+        this.ws.onclose = (event) => {
             this.onDisconnect();
+            if (event.code === 4001) {
+                // The server doesn't speak our protocol version, reconnecting won't help
+                console.error('WebSocket protocol rejected:', event.reason);
+                alert('This page is out of date, please reload it.');
+                return;
+            }
             this.attemptReconnect();
         };
         
@@ -72,28 +111,54 @@ class WebSocketClient {
     
     handleMessage(message) {
         switch (message.type) {
+            case 'hello':
+                const hello = this.parseMessageData(message.data, 'hello');
+                if (hello !== null) {
+                    this.protocolVersion = hello.protocolVersion;
+                    this.playerId = hello.playerId;
+                    this.reconnectToken = hello.reconnectToken;
+                }
+                break;
             case 'gameState':
                 const bombState = this.parseMessageData(message.data, 'gameState');
                 if (bombState !== null) {
+                    this.bombState = bombState;
+                    this.stateVersion = bombState.version;
                     this.onStateUpdateCallbacks.forEach(callback => callback(bombState));
                 }
                 break;
             case 'manualContent':
                 const manualContent = this.parseMessageData(message.data, 'manualContent');
                 if (manualContent !== null) {
+                    this.manualContent = manualContent;
+                    this.stateVersion = manualContent.version;
                     this.onManualContentUpdateCallbacks.forEach(callback => callback(manualContent));
                 }
                 break;
+            case 'tick':
+                const delta = this.parseMessageData(message.data, 'tick');
+                if (delta !== null) {
+                    this.applyStateDelta(delta);
+                }
+                break;
             case 'lobbyUpdate':
                 const lobbyData = this.parseMessageData(message.data, 'lobbyUpdate');
                 if (lobbyData !== null) {
+                    if (lobbyData.playerId) {
+                        this.playerId = lobbyData.playerId;
+                    }
                     this.onLobbyUpdateCallbacks.forEach(callback => callback(lobbyData));
                 }
                 break;
             case 'gameStarting':
-                this.onGameStartingCallbacks.forEach(callback => callback());
+                // Carries the countdown before the bomb starts and every player's role
+                const startingData = this.parseMessageData(message.data, 'gameStarting') || {};
+                this.onGameStartingCallbacks.forEach(callback => callback(startingData));
                 break;
             case 'returnedToLobby':
+                this.bombState = null;
+                this.manualContent = null;
+                this.stateVersion = null;
                 this.onReturnToLobbyCallbacks.forEach(callback => callback());
                 break;
             case 'wireCutResult':
@@ -114,6 +179,11 @@ class WebSocketClient {
                     this.onMessageCallbacks.forEach(callback => callback(message));
                 }
                 break;
+            case 'kicked':
+                // Don't try to reconnect after being removed by the host
+                this.kicked = true;
+                this.onMessageCallbacks.forEach(callback => callback(message));
+                break;
             case 'pong':
                 // Heartbeat response
                 break;
@@ -122,6 +192,45 @@ class WebSocketClient {
         }
     }
     
+    // applyStateDelta applies a tick to the last snapshot, or asks for a new snapshot if a tick was missed
+    applyStateDelta(delta) {
+        if (this.stateVersion === null || delta.version !== this.stateVersion + 1) {
+            this.requestState();
+            return;
+        }
+        this.stateVersion = delta.version;
+
+        const timerFields = ['state', 'strikes', 'maxStrikes', 'timeRemaining', 'speedMultiplier', 'paused', 'deadline'];
+        if (this.bombState) {
+            timerFields.forEach(field => { this.bombState[field] = delta[field]; });
+            this.bombState.bombIndex = delta.bombIndex;
+            this.bombState.bombCount = delta.bombCount;
+            this.bombState.nextBombIn = delta.nextBombIn;
+            this.bombState.version = delta.version;
+            (delta.modules || []).forEach(module => {
+                const modules = this.bombState[`${module.moduleType}Modules`];
+                if (modules && module.moduleIndex < modules.length) {
+                    modules[module.moduleIndex] = module.state;
+                }
+            });
+            this.onStateUpdateCallbacks.forEach(callback => callback(this.bombState));
+        }
+        // Changed modules come with a new manualContent, only the timer needs updating here
+        if (this.manualContent && this.manualContent.bombState) {
+            timerFields.forEach(field => { this.manualContent.bombState[field] = delta[field]; });
+            this.manualContent.progress = delta.progress;
+            this.manualContent.version = delta.version;
+            this.onManualContentUpdateCallbacks.forEach(callback => callback(this.manualContent));
+        }
+    }
+
+    requestState() {
+        this.send({
+            type: 'requestState',
+            sessionId: this.sessionId,
+        });
+    }
+
     // parseMessageData parses message data, handling both string and object types
     parseMessageData(data, messageType) {
         if (data === null || data === undefined) {
@@ -143,6 +252,9 @@ class WebSocketClient {
     }
     
     send(message) {
+        if (ACTION_MESSAGE_TYPES.has(message.type) && !message.requestId) {
+            message.requestId = `${Date.now().toString(36)}-${this.nextRequestId++}`;
+        }
         if (this.ws && this.ws.readyState === WebSocket.OPEN) {
             this.ws.send(JSON.stringify(message));
         }
@@ -161,7 +273,7 @@ class WebSocketClient {
     
     pressButton(moduleIndex) {
         this.send({
-            type: 'buttonPress',
+            type: 'pressButton',
             sessionId: this.sessionId,
             data: {
                 moduleIndex: moduleIndex,
@@ -171,7 +283,7 @@ class WebSocketClient {
     
     holdButton(moduleIndex) {
         this.send({
-            type: 'buttonHold',
+            type: 'holdButton',
             sessionId: this.sessionId,
             data: {
                 moduleIndex: moduleIndex,
@@ -181,7 +293,7 @@ class WebSocketClient {
     
     releaseButton(moduleIndex) {
         this.send({
-            type: 'buttonRelease',
+            type: 'releaseButton',
             sessionId: this.sessionId,
             data: {
                 moduleIndex: moduleIndex,
@@ -191,7 +303,7 @@ class WebSocketClient {
     
     enterTerminalCommand(moduleIndex, command) {
         this.send({
-            type: 'terminalCommand',
+            type: 'enterTerminalCommand',
             sessionId: this.sessionId,
             data: {
                 moduleIndex: moduleIndex,
@@ -200,6 +312,16 @@ class WebSocketClient {
         });
     }
     
+    focusModule(moduleId) {
+        this.send({
+            type: 'focusModule',
+            sessionId: this.sessionId,
+            data: {
+                moduleId: moduleId,
+            },
+        });
+    }
+    
     sendLobbySettings(settings) {
         this.send({
             type: 'updateLobbySettings',
@@ -210,7 +332,7 @@ class WebSocketClient {
     
     sendUpdatePlayerName(name) {
         this.send({
-            type: 'updatePlayerName',
+            type: 'setName',
             sessionId: this.sessionId,
             data: {
                 name: name,
@@ -218,6 +340,263 @@ class WebSocketClient {
         });
     }
     
+    sendKickPlayer(playerId) {
+        this.send({
+            type: 'kickPlayer',
+            sessionId: this.sessionId,
+            data: {
+                playerId: playerId,
+            },
+        });
+    }
+    
+    sendTransferHost(playerId) {
+        this.send({
+            type: 'transferHost',
+            sessionId: this.sessionId,
+            data: {
+                playerId: playerId,
+            },
+        });
+    }
+    
+    sendSimonPress(moduleIndex, color) {
+        this.send({
+            type: 'simonPress',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                color: color,
+            },
+        });
+    }
+    
+    sendKeypadPress(moduleIndex, position) {
+        this.send({
+            type: 'keypadPress',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                position: position,
+            },
+        });
+    }
+    
+    sendMemoryPress(moduleIndex, position) {
+        this.send({
+            type: 'memoryPress',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                position: position,
+            },
+        });
+    }
+    
+    sendPasswordSpin(moduleIndex, column, direction) {
+        this.send({
+            type: 'passwordSpin',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                column: column,
+                direction: direction,
+            },
+        });
+    }
+    
+    sendPasswordSubmit(moduleIndex) {
+        this.send({
+            type: 'passwordSubmit',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+            },
+        });
+    }
+    
+    sendMorseTune(moduleIndex, direction) {
+        this.send({
+            type: 'morseTune',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                direction: direction,
+            },
+        });
+    }
+    
+    sendMorseSubmit(moduleIndex) {
+        this.send({
+            type: 'morseSubmit',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+            },
+        });
+    }
+    
+    sendWofPress(moduleIndex, position) {
+        this.send({
+            type: 'wofPress',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                position: position,
+            },
+        });
+    }
+    
+    sendCutComplicatedWire(moduleIndex, wireIndex) {
+        this.send({
+            type: 'cutComplicatedWire',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                wireIndex: wireIndex,
+            },
+        });
+    }
+    
+    sendMazeMove(moduleIndex, direction) {
+        this.send({
+            type: 'mazeMove',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                direction: direction,
+            },
+        });
+    }
+    
+    sendRotateKnob(moduleIndex) {
+        this.send({
+            type: 'rotateKnob',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+            },
+        });
+    }
+    
+    sendConfirmKnob(moduleIndex) {
+        this.send({
+            type: 'confirmKnob',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+            },
+        });
+    }
+    
+    sendModuleAction(moduleType, moduleIndex, action, payload = {}) {
+        this.send({
+            type: 'moduleAction',
+            sessionId: this.sessionId,
+            data: {
+                moduleType: moduleType,
+                moduleIndex: moduleIndex,
+                action: action,
+                payload: payload,
+            },
+        });
+    }
+    
+    sendAnswerNeedy(moduleIndex, answer) {
+        this.send({
+            type: 'answerNeedy',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+                answer: answer,
+            },
+        });
+    }
+    
+    sendStartDischarge(moduleIndex) {
+        this.send({
+            type: 'startDischarge',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+            },
+        });
+    }
+    
+    sendStopDischarge(moduleIndex) {
+        this.send({
+            type: 'stopDischarge',
+            sessionId: this.sessionId,
+            data: {
+                moduleIndex: moduleIndex,
+            },
+        });
+    }
+    
+    sendPauseGame() {
+        this.send({
+            type: 'pauseGame',
+            sessionId: this.sessionId,
+            data: {},
+        });
+    }
+    
+    sendResumeGame() {
+        this.send({
+            type: 'resumeGame',
+            sessionId: this.sessionId,
+            data: {},
+        });
+    }
+    
+    sendChat(text) {
+        this.send({
+            type: 'chat',
+            sessionId: this.sessionId,
+            data: {
+                text: text,
+            },
+        });
+    }
+    
+    sendSetReady(ready) {
+        this.send({
+            type: 'setReady',
+            sessionId: this.sessionId,
+            data: {
+                ready: ready,
+            },
+        });
+    }
+    
+    sendRolePreference(preference) {
+        this.send({
+            type: 'setRolePreference',
+            sessionId: this.sessionId,
+            data: {
+                preference: preference,
+            },
+        });
+    }
+    
+    // manualLanguage returns the language to read the manual in: the page's lang parameter,
+    // else the browser language when the server has it, else English
+    manualLanguage() {
+        const requested = new URLSearchParams(window.location.search).get('lang') || navigator.language || '';
+        const language = requested.toLowerCase().split(/[-_]/)[0];
+        return Config.MANUAL_LANGUAGES.includes(language) ? language : 'en';
+    }
+    
+    sendLanguage(language) {
+        this.send({
+            type: 'setLanguage',
+            sessionId: this.sessionId,
+            data: {
+                language: language,
+            },
+        });
+    }
+    
     sendStartGame() {
         this.send({
             type: 'startGame',
@@ -265,6 +644,9 @@ class WebSocketClient {
     }
     
     attemptReconnect() {
+        if (this.kicked) {
+            return;
+        }
         if (this.reconnectAttempts < this.maxReconnectAttempts) {
             this.reconnectAttempts++;
             setTimeout(() => {
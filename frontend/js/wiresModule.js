@@ -26,7 +26,8 @@ class WiresModule {
                     result = message.data;
                 }
                 
-                if (result && result.correct === false && result.moduleIndex !== undefined) {
+                // A cut that did nothing (result.reason, e.g. "alreadyCut") isn't a strike
+                if (result && result.correct === false && !result.reason && result.moduleIndex !== undefined) {
                     // Show red flash for strike
                     this.bomb3d.showModuleStrike(result.moduleIndex);
                 }
@@ -120,6 +121,17 @@ class WiresModule {
         // Update strikes
         document.getElementById('strikes-count').textContent = bombState.strikes;
         
+        // Update serial number (needed by some manual rules)
+        document.getElementById('serial-number-value').textContent = bombState.serialNumber || '';
+        
+        // Update manual code, experts compare it with the one on their manual
+        document.getElementById('manual-code-value').textContent = bombState.manualCode || '';
+        
+        // Update batteries and indicator lights (lit indicators are marked with *)
+        document.getElementById('batteries-count').textContent = bombState.batteries || 0;
+        const indicators = (bombState.indicators || []).map(ind => ind.lit ? `${ind.label}*` : ind.label);
+        document.getElementById('indicators-list').textContent = indicators.length ? ` Indicators: ${indicators.join(' ')}` : '';
+        
         // Update game status
         const statusText = document.getElementById('status-text');
         switch (bombState.state) {